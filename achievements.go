@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Achievement is a milestone the player has unlocked
+type Achievement struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	UnlockedAt  time.Time `json:"unlocked_at"` // In-game time of the unlock
+}
+
+// achievementRule declares an achievement and the condition that unlocks it. Conditions are
+// checked after every action and time advance, so they only need to look at the current state.
+type achievementRule struct {
+	ID          string
+	Title       string
+	Description string
+	Unlocked    func(gs *GameState) bool
+}
+
+// achievementRules lists every achievement; add an entry here to add a new one
+var achievementRules = []achievementRule{
+	{
+		ID:          "first_job",
+		Title:       "First Job",
+		Description: "Start your first job",
+		Unlocked:    func(gs *GameState) bool { return gs.Job != nil },
+	},
+	{
+		ID:          "first_home",
+		Title:       "A Roof Overhead",
+		Description: "Rent your first apartment",
+		Unlocked:    func(gs *GameState) bool { return gs.Apartment != nil },
+	},
+	{
+		ID:          "first_investment",
+		Title:       "Investor",
+		Description: "Own stocks or crypto",
+		Unlocked:    func(gs *GameState) bool { return len(gs.Stocks) > 0 || len(gs.Crypto) > 0 },
+	},
+	{
+		ID:          "scams_avoided_5",
+		Title:       "Avoided 5 Scams",
+		Description: "Let 5 scam offers expire without falling for them",
+		Unlocked:    func(gs *GameState) bool { return gs.ScamsAvoided >= 5 },
+	},
+	{
+		ID:          "net_worth_50k",
+		Title:       "Net Worth €50k",
+		Description: "Reach a net worth of €50,000",
+		Unlocked:    func(gs *GameState) bool { return gs.NetWorth() >= 50000 },
+	},
+	{
+		ID:          "homeless_month",
+		Title:       "Survived a Month Homeless",
+		Description: "Get through 30 nights without an apartment",
+		Unlocked:    func(gs *GameState) bool { return gs.NightsHomeless >= 30 && !gs.GameOver },
+	},
+}
+
+// hasAchievement reports whether the achievement with the given ID is already unlocked
+func (gs *GameState) hasAchievement(id string) bool {
+	for _, achievement := range gs.Achievements {
+		if achievement.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkAchievements unlocks every achievement whose condition now holds, records an event for
+// each and queues them for takeNewAchievements
+func (gs *GameState) checkAchievements() {
+	for _, rule := range achievementRules {
+		if gs.hasAchievement(rule.ID) || !rule.Unlocked(gs) {
+			continue
+		}
+		achievement := Achievement{
+			ID:          rule.ID,
+			Title:       rule.Title,
+			Description: rule.Description,
+			UnlockedAt:  gs.CurrentDate,
+		}
+		gs.Achievements = append(gs.Achievements, achievement)
+		gs.newAchievements = append(gs.newAchievements, achievement)
+		gs.addEvent("achievement", fmt.Sprintf("Achievement unlocked: %s - %s", rule.Title, rule.Description), 0)
+	}
+}
+
+// takeNewAchievements returns the achievements unlocked since the last call and clears them
+func (gs *GameState) takeNewAchievements() []Achievement {
+	unlocked := gs.newAchievements
+	gs.newAchievements = nil
+	return unlocked
+}
+
+// sendAchievements pushes an "achievement" message per unlock to the player's WebSocket, if open.
+// Callers must not hold any game lock.
+func (gm *GameManager) sendAchievements(playerID string, achievements []Achievement) {
+	for _, achievement := range achievements {
+		gm.sendToPlayer(playerID, map[string]interface{}{
+			"type":        "achievement",
+			"achievement": achievement,
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckAchievementsUnlocks(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(gs *GameState)
+		want  string
+	}{
+		{"first job", func(gs *GameState) { gs.Job = paidTestJob() }, "first_job"},
+		{"five scams avoided", func(gs *GameState) { gs.ScamsAvoided = 5 }, "scams_avoided_5"},
+		{"net worth", func(gs *GameState) { gs.Money = 50000 }, "net_worth_50k"},
+		{"a month homeless", func(gs *GameState) { gs.NightsHomeless = 30 }, "homeless_month"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			game.Money = 0
+			game.checkAchievements()
+			if game.hasAchievement(tt.want) {
+				t.Fatalf("%s was unlocked before its condition held", tt.want)
+			}
+			
+			tt.setup(game)
+			game.checkAchievements()
+			unlocked := game.takeNewAchievements()
+			if len(unlocked) != 1 || unlocked[0].ID != tt.want {
+				t.Fatalf("got new achievements %+v, want only %s", unlocked, tt.want)
+			}
+			if !unlocked[0].UnlockedAt.Equal(game.CurrentDate) {
+				t.Errorf("unlocked at %v, want the in-game time %v", unlocked[0].UnlockedAt, game.CurrentDate)
+			}
+			if events := eventsOfType(game, "achievement"); len(events) != 1 {
+				t.Errorf("got %d achievement events, want 1", len(events))
+			}
+			
+			// Checking again while the condition still holds unlocks nothing new
+			game.checkAchievements()
+			if again := game.takeNewAchievements(); len(again) != 0 || len(game.Achievements) != 1 {
+				t.Errorf("got %d new and %d total achievements after checking again, want 0 and 1", len(again), len(game.Achievements))
+			}
+		})
+	}
+}
+
+// Achievements are checked after time advances, so a player who gets through 30 nights on the
+// street unlocks the homeless one
+func TestAdvanceTimeUnlocksAchievements(t *testing.T) {
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	for day := 0; day < 30; day++ {
+		game.Health = 100
+		game.AdvanceTime(24 * time.Hour)
+	}
+	if !game.hasAchievement("homeless_month") {
+		t.Errorf("no homeless_month achievement after %d homeless nights", game.NightsHomeless)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// playerActions lists every action POST /api/action accepts, in the order dispatchAction handles them
+var playerActions = []string{
+	"start_work", "accept_job_offer", "quit_job", "take_day_off", "stop_work",
+	"show_hint", "accept_apartment_offer", "show_apartment_hint", "show_stock_hint", "show_news_hint", "show_other_offer_hint",
+	"buy_insurance", "cancel_insurance", "report_offer", "refresh_offers",
+	"quit_apartment", "rent_out", "stop_renting_out", "revoke_invite", "quit_agreement", "advance_time", "rest",
+	"buy_stock", "sell_stock", "short_stock", "cover_short", "buy_crypto", "sell_crypto",
+	"buy_item", "sell_item", "use_item", "accept_offer", "withdraw_offer", "next_day",
+}
+
+// ActionStatus says whether an action can be taken in the game's current state
+type ActionStatus struct {
+	Action    string `json:"action"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // Why it is not available
+}
+
+// actionGuard returns the error the action fails with in the current state, whatever it is
+// applied to, or nil if the state allows it. The action's own method still checks what depends
+// on its arguments (the offer, the amount, the price).
+func (gs *GameState) actionGuard(action string) error {
+	gameOver := &GameError{Message: "Game is over. You cannot perform actions."}
+	working := &GameError{Message: "You are currently working and cannot perform this action"}
+	
+	switch action {
+	case "start_work":
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case gs.IsInHospital:
+			return &GameError{Message: "You are in the hospital and cannot work. " + gs.hospitalReleaseMessage()}
+		case gs.Job == nil:
+			return &GameError{Message: "You don't have a job. Accept a job offer first!"}
+		case gs.Job.WorkType == "fixed_time":
+			return &GameError{Message: "This is a fixed-time job. Work starts and ends automatically at scheduled times."}
+		case gs.IsWorking:
+			return &GameError{Message: "You are already working"}
+		}
+	
+	case "stop_work":
+		switch {
+		case gs.Job == nil:
+			return &GameError{Message: "You don't have a job"}
+		case gs.Job.WorkType == "fixed_time":
+			return &GameError{Message: "This is a fixed-time job. You cannot stop work manually."}
+		case !gs.IsWorking:
+			return &GameError{Message: "You are not currently working"}
+		}
+	
+	case "accept_job_offer":
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case gs.IsInHospital:
+			return &GameError{Message: "You are in the hospital and cannot accept job offers. " + gs.hospitalReleaseMessage()}
+		case gs.Job != nil:
+			return &GameError{Message: "You already have a job: " + gs.Job.Title + ". Quit first to accept a new one."}
+		}
+		// Employers are closed at night
+		return gs.checkOpenHours("accept job offers")
+	
+	case "quit_job":
+		if gs.Job == nil {
+			return &GameError{Message: "You don't have a job to quit"}
+		}
+	
+	case "take_day_off":
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case gs.IsInHospital:
+			return &GameError{Message: "You are in the hospital and cannot work anyway. " + gs.hospitalReleaseMessage()}
+		case gs.Job == nil:
+			return &GameError{Message: "You don't have a job"}
+		case gs.Job.isHourly():
+			return &GameError{Message: "Hourly jobs only pay for the hours you work: just don't start work today"}
+		}
+		shiftStart, _, ok := gs.Job.currentShift(gs.CurrentDate)
+		if !ok {
+			return &GameError{Message: "This job has no fixed schedule to take time off from"}
+		}
+		if gs.CurrentDate.Before(gs.DayOffEnd) {
+			return &GameError{Message: "You already took the " + gs.DayOffStart.Format("2006-01-02") + " shift off"}
+		}
+		if daysOff := GetConfig().Work.DaysOffPerMonth; gs.daysOffIn(shiftStart) >= daysOff {
+			return &GameError{Message: fmt.Sprintf("You have used all %d days off for %s", daysOff, shiftStart.Format("January 2006"))}
+		}
+	
+	case "accept_apartment_offer":
+		if maxApartments := GetConfig().Housing.MaxApartments; gs.apartmentCount() >= maxApartments {
+			if maxApartments == 1 {
+				return &GameError{Message: "You already have an apartment: " + gs.Apartment.Title + ". You can only have one apartment at a time."}
+			}
+			return &GameError{Message: fmt.Sprintf("You already rent %d apartments, the most you can have at a time", gs.apartmentCount())}
+		}
+		return gs.checkOpenHours("rent an apartment")
+	
+	case "quit_apartment":
+		if gs.apartmentCount() == 0 {
+			return &GameError{Message: "You don't have an apartment"}
+		}
+	
+	case "rent_out":
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case !slices.ContainsFunc(gs.Properties, func(a Apartment) bool { return !a.RentedOut }):
+			return &GameError{Message: "You have no apartment to rent out besides the one you live in"}
+		}
+		return gs.checkOpenHours("rent out an apartment")
+	
+	case "stop_renting_out":
+		if !slices.ContainsFunc(gs.Properties, func(a Apartment) bool { return a.RentedOut }) {
+			return &GameError{Message: "You are not renting out any apartment"}
+		}
+	
+	case "buy_insurance":
+		premium := GetConfig().Insurance.MonthlyPremium
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case gs.InsuranceActive:
+			return &GameError{Message: "You already have health insurance"}
+		case gs.IsInHospital:
+			return &GameError{Message: "Insurers don't cover a stay that has already started. Try again once you are released."}
+		case gs.Money < premium:
+			return &GameError{Message: "Not enough money. Need €" + formatMoney(premium) + " for the first premium"}
+		}
+	
+	case "cancel_insurance":
+		if !gs.InsuranceActive {
+			return &GameError{Message: "You don't have health insurance"}
+		}
+	
+	case "rest":
+		switch {
+		case gs.GameOver:
+			return gameOver
+		case gs.IsInHospital:
+			return &GameError{Message: "You are in the hospital and already resting. " + gs.hospitalReleaseMessage()}
+		case gs.IsWorking:
+			return &GameError{Message: "You are currently working and cannot rest"}
+		}
+	
+	case "buy_stock", "sell_stock", "short_stock", "cover_short":
+		if !gs.CanPerformAction() {
+			return working
+		}
+		return gs.checkOpenHours("trade stocks")
+	
+	case "buy_crypto", "sell_crypto":
+		if !gs.CanPerformAction() {
+			return working
+		}
+	
+	case "buy_item", "sell_item":
+		if !gs.CanPerformAction() {
+			return working
+		}
+		return gs.checkOpenHours("buy or sell items")
+	
+	case "use_item", "accept_offer", "report_offer":
+		if gs.GameOver {
+			return gameOver
+		}
+		if !gs.CanPerformAction() {
+			if gs.IsInHospital {
+				return &GameError{Message: "You are in the hospital and cannot perform this action. " + gs.hospitalReleaseMessage()}
+			}
+			return working
+		}
+	}
+	return nil
+}
+
+// AvailableActions reports for every action whether the current state allows it, with the
+// reason for those it doesn't
+func (gs *GameState) AvailableActions() []ActionStatus {
+	statuses := make([]ActionStatus, 0, len(playerActions))
+	for _, action := range playerActions {
+		status := ActionStatus{Action: action, Available: true}
+		if err := gs.actionGuard(action); err != nil {
+			status.Available = false
+			status.Reason = getMessage(err)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// MarshalJSON encodes the state together with its available_actions, so every client sees the
+// same rules as the server
+func (gs *GameState) MarshalJSON() ([]byte, error) {
+	type state GameState // Without this method, so encoding it does not recurse
+	return json.Marshal(struct {
+		*state
+		AvailableActions []ActionStatus `json:"available_actions"`
+	}{(*state)(gs), gs.AvailableActions()})
+}
+
+// HandleAvailableActions returns which actions the player can take right now (GET /api/actions)
+func (gm *GameManager) HandleAvailableActions(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	if playerID == "" {
+		playerID = "default"
+	}
+	
+	var actions []ActionStatus
+	if !gm.readGame(playerID, func(game *GameState) { actions = game.AvailableActions() }) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"actions": actions})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Deals that need someone on the other side in business hours are refused at night, the rest are not
+func TestNightGuard(t *testing.T) {
+	tests := []struct {
+		action string
+		closed bool // Refused at night
+		setup  func(gs *GameState)
+	}{
+		{"accept_job_offer", true, nil},
+		{"accept_apartment_offer", true, nil},
+		{"rent_out", true, func(gs *GameState) { gs.Properties = []Apartment{{ID: "flat", Title: "Flat", Rent: 500}} }},
+		{"buy_stock", true, nil},
+		{"sell_stock", true, nil},
+		{"short_stock", true, nil},
+		{"cover_short", true, nil},
+		{"buy_item", true, nil},
+		{"sell_item", true, nil},
+		{"buy_crypto", false, nil},
+		{"sell_crypto", false, nil},
+		{"accept_offer", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			for _, hour := range []int{3, 10} {
+				game := newTestState(t, time.Date(2025, 1, 6, hour, 0, 0, 0, time.UTC))
+				if tt.setup != nil {
+					tt.setup(game)
+				}
+				err := game.actionGuard(tt.action)
+				night := hour == 3
+				if tt.closed && night {
+					if err == nil || !strings.Contains(err.Error(), "night hours") {
+						t.Errorf("at %02d:00 got %v, want it refused for the night", hour, err)
+					}
+				} else if err != nil {
+					t.Errorf("at %02d:00 got %v, want it allowed", hour, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import "time"
+
+// recentActivityWindow is how long after a player's last HTTP request their network still counts
+// as played without a WebSocket open
+const recentActivityWindow = 5 * time.Minute
+
+// markActive records that playerID has just made a request
+func (gm *GameManager) markActive(playerID string) {
+	now := gm.now()
+	gm.lastActiveMu.Lock()
+	defer gm.lastActiveMu.Unlock()
+	gm.lastActive[playerID] = now
+}
+
+// networkIsLive reports whether someone in playerID's network has a WebSocket open or made a
+// request within recentActivityWindow. The offer generators skip networks that are not, so idle
+// sessions cost no AI calls; a returning player gets offers again when they connect. Callers must
+// not hold any game lock.
+func (gm *GameManager) networkIsLive(playerID string) bool {
+	networkPlayers := gm.getNetworkPlayers(playerID)
+	
+	gm.wsConnectionsMu.RLock()
+	for _, pid := range networkPlayers {
+		if _, connected := gm.wsConnections[pid]; connected {
+			gm.wsConnectionsMu.RUnlock()
+			return true
+		}
+	}
+	gm.wsConnectionsMu.RUnlock()
+	
+	now := gm.now()
+	gm.lastActiveMu.Lock()
+	defer gm.lastActiveMu.Unlock()
+	for _, pid := range networkPlayers {
+		if lastActive, exists := gm.lastActive[pid]; exists && now.Sub(lastActive) < recentActivityWindow {
+			return true
+		}
+	}
+	return false
+}
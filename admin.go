@@ -0,0 +1,143 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// requestAdminToken reads the admin token from "Authorization: Bearer <token>" or the
+// X-Admin-Token header
+func requestAdminToken(r *http.Request) string {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(bearer)
+	}
+	return r.Header.Get("X-Admin-Token")
+}
+
+// adminTokenValid reports whether token is the configured admin token; none is valid when no
+// admin token is configured
+func adminTokenValid(token string) bool {
+	expected := GetConfig().Admin.Token
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// RequireAdmin rejects requests that don't carry the configured admin token, either as
+// "Authorization: Bearer <token>" or in the X-Admin-Token header
+func (gm *GameManager) RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !adminTokenValid(requestAdminToken(r)) {
+			logWarnf("[ADMIN] Rejected %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandleAdminGames lists every game with its network placement, offer and agreement counts and
+// whether the player is connected over WebSocket
+func (gm *GameManager) HandleAdminGames(w http.ResponseWriter, r *http.Request) {
+	// Connections first: wsConnectionsMu is taken before game locks and gm.mu
+	gm.wsConnectionsMu.RLock()
+	connected := make(map[string]bool, len(gm.wsConnections))
+	for pid := range gm.wsConnections {
+		connected[pid] = true
+	}
+	gm.wsConnectionsMu.RUnlock()
+	
+	gm.mu.RLock()
+	entries := make(map[string]*gameEntry, len(gm.games))
+	roots := make(map[string]string, len(gm.games))
+	for pid, entry := range gm.games {
+		entries[pid] = entry
+		roots[pid] = gm.getNetworkRootUnlocked(pid)
+	}
+	gm.mu.RUnlock()
+	
+	playerIDs := make([]string, 0, len(entries))
+	for pid := range entries {
+		playerIDs = append(playerIDs, pid)
+	}
+	sort.Strings(playerIDs)
+	
+	games := make([]map[string]interface{}, 0, len(playerIDs))
+	for _, pid := range playerIDs {
+		entry := entries[pid]
+		entry.mu.RLock()
+		game := entry.game
+		games = append(games, map[string]interface{}{
+			"player_id":        pid,
+			"invited_by":       game.InvitedBy,
+			"is_first_player":  game.IsFirstPlayer,
+			"network_root":     roots[pid],
+			"scenario":         game.Scenario,
+			"current_date":     game.CurrentDate,
+			"money":            game.Money,
+			"game_over":        game.GameOver,
+			"job_offers":       len(game.JobOffers),
+			"apartment_offers": len(game.ApartmentOffers),
+			"stock_offers":     len(game.StockOffers),
+			"other_offers":     len(game.ActiveOffers),
+			"agreements":       len(game.Agreements),
+			"ws_connected":     connected[pid],
+		})
+		entry.mu.RUnlock()
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count": len(games),
+		"games": games,
+	})
+}
+
+// HandleAdminNetworks lists every invite network: its root, its members as found by
+// getNetworkPlayers, and who invited whom
+func (gm *GameManager) HandleAdminNetworks(w http.ResponseWriter, r *http.Request) {
+	gm.mu.RLock()
+	networksByRoot := make(map[string][]string)
+	for pid := range gm.games {
+		root := gm.getNetworkRootUnlocked(pid)
+		if _, seen := networksByRoot[root]; !seen {
+			networksByRoot[root] = gm.getNetworkPlayersUnlocked(root)
+		}
+	}
+	// InvitedBy only changes under gm.mu (see removeGame), so it can be read without the game locks
+	invitedBy := make(map[string]string, len(gm.games))
+	for pid, entry := range gm.games {
+		invitedBy[pid] = entry.game.InvitedBy
+	}
+	gm.mu.RUnlock()
+	
+	roots := make([]string, 0, len(networksByRoot))
+	for root := range networksByRoot {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	
+	networks := make([]map[string]interface{}, 0, len(roots))
+	for _, root := range roots {
+		players := networksByRoot[root]
+		invites := make(map[string][]string)
+		for _, pid := range players {
+			if inviter := invitedBy[pid]; inviter != "" && pid != root {
+				invites[inviter] = append(invites[inviter], pid)
+			}
+		}
+		networks = append(networks, map[string]interface{}{
+			"root":    root,
+			"size":    len(players),
+			"players": players,
+			"invites": invites,
+		})
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"count":    len(networks),
+		"networks": networks,
+	})
+}
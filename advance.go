@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// advanceBudget is how many in-game hours a player's advance_time, rest and next_day actions may
+// still add. It refills with real time at Config.Game.AdvanceHoursPerMinute, up to one minute's worth.
+type advanceBudget struct {
+	hours   float64
+	updated time.Time
+}
+
+// claimAdvance checks that the player may move their clock forward by hours now and takes the
+// hours from their budget. It rejects steps over maxHours (Config.Game.MaxAdvanceHours for
+// advance_time) and, once the budget is used up, steps that come faster than the configured
+// rate. advanceBudgetsMu is a leaf lock, so the game's lock may be held.
+func (gm *GameManager) claimAdvance(playerID string, hours float64, maxHours float64) error {
+	limits := GetConfig().Game
+	if math.IsNaN(hours) || hours <= 0 {
+		return &GameError{Message: "Invalid time duration"}
+	}
+	if hours > maxHours {
+		return &GameError{Message: fmt.Sprintf("You can advance time by at most %g hours at once", maxHours)}
+	}
+
+	now := gm.now()
+	gm.advanceBudgetsMu.Lock()
+	defer gm.advanceBudgetsMu.Unlock()
+
+	budget, exists := gm.advanceBudgets[playerID]
+	if !exists {
+		budget = &advanceBudget{hours: limits.AdvanceHoursPerMinute, updated: now}
+		gm.advanceBudgets[playerID] = budget
+	}
+	perSecond := limits.AdvanceHoursPerMinute / 60
+	budget.hours = min(budget.hours+now.Sub(budget.updated).Seconds()*perSecond, limits.AdvanceHoursPerMinute)
+	budget.updated = now
+
+	if hours > budget.hours {
+		wait := time.Duration(math.Ceil((hours-budget.hours)/perSecond)) * time.Second
+		return &GameError{Message: fmt.Sprintf("Time is moving too fast. Try again in %s.", wait)}
+	}
+	budget.hours -= hours
+	return nil
+}
+
+// releaseAdvance gives back hours claimed for an action that then failed without moving the clock
+func (gm *GameManager) releaseAdvance(playerID string, hours float64) {
+	gm.advanceBudgetsMu.Lock()
+	defer gm.advanceBudgetsMu.Unlock()
+
+	if budget, exists := gm.advanceBudgets[playerID]; exists {
+		budget.hours = min(budget.hours+hours, GetConfig().Game.AdvanceHoursPerMinute)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClaimAdvanceBoundaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   float64
+		wantErr string
+	}{
+		{"zero", 0, "Invalid time duration"},
+		{"negative", -1, "Invalid time duration"},
+		{"not a number", math.NaN(), "Invalid time duration"},
+		{"smallest step", 0.01, ""},
+		{"at the cap", 2, ""},
+		{"just over the cap", 2.01, "at most 2 hours"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, func(config *Config) {
+				config.Game.MaxAdvanceHours = 2
+				config.Game.AdvanceHoursPerMinute = 30
+			})
+			gm, _ := newTestManager(t)
+			err := gm.claimAdvance("p", tt.hours, 2)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("got %v, want the step allowed", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("got %v, want an error saying %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// advance_time, rest and next_day share one budget that refills with real time
+func TestTimeActionsShareAdvanceBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		action  string
+		data    map[string]interface{}
+		hours   float64
+		allowed int // Actions in a row the full 30-hour budget allows
+	}{
+		{"advance_time", "advance_time", map[string]interface{}{"hours": 2.0}, 2, 15},
+		{"rest", "rest", map[string]interface{}{"hours": 12.0}, 12, 2},
+		{"next_day", "next_day", nil, 24, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, func(config *Config) {
+				config.Game.MaxAdvanceHours = 2
+				config.Game.AdvanceHoursPerMinute = 30
+			})
+			gm, clock := newTestManager(t)
+			newTestGame(t, gm, "p", time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			gm.withGame("p", func(game *GameState) { game.Apartment = &Apartment{ID: "home", Title: "Flat"} })
+			
+			for i := 0; i < tt.allowed; i++ {
+				if result := doAction(t, gm, "p", tt.action, tt.data); result["success"] != true {
+					t.Fatalf("%s %d of %d failed: %v", tt.action, i+1, tt.allowed, result["message"])
+				}
+			}
+			result := doAction(t, gm, "p", tt.action, tt.data)
+			if message, _ := result["message"].(string); result["success"] != false || !strings.Contains(message, "Time is moving too fast") {
+				t.Fatalf("got %v, want the action over the budget refused", result)
+			}
+			
+			// The budget refills at 30 hours a minute, half an hour a second
+			clock.Advance(time.Duration(tt.hours*2) * time.Second)
+			if result := doAction(t, gm, "p", tt.action, tt.data); result["success"] != true {
+				t.Errorf("got %v after the budget refilled, want the action allowed", result["message"])
+			}
+		})
+	}
+}
+
+func TestRestOverItsLimitRejected(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "p", time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	if result := doAction(t, gm, "p", "rest", map[string]interface{}{"hours": 12.5}); result["success"] != false {
+		t.Errorf("got %v, want resting over 12 hours refused", result)
+	}
+}
+
+// A rest that fails does not use up the budget
+func TestFailedRestReleasesAdvanceBudget(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Game.MaxAdvanceHours = 2
+		config.Game.AdvanceHoursPerMinute = 30
+	})
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "p", time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	if result := doAction(t, gm, "p", "next_day", nil); result["success"] != true {
+		t.Fatalf("next_day failed: %v", result["message"])
+	}
+	// Under an hour is too short a rest
+	if result := doAction(t, gm, "p", "rest", map[string]interface{}{"hours": 0.5}); result["success"] != false {
+		t.Fatalf("got %v, want the short rest refused", result)
+	}
+	for i := 0; i < 3; i++ {
+		if result := doAction(t, gm, "p", "advance_time", map[string]interface{}{"hours": 2.0}); result["success"] != true {
+			t.Fatalf("advance %d of the 6 hours left failed: %v", i+1, result["message"])
+		}
+	}
+}
+
+// rest and next_day move the rest of the network's clock along, as advance_time does
+func TestTimeActionsAdvanceNetwork(t *testing.T) {
+	tests := []struct {
+		action string
+		data   map[string]interface{}
+		want   time.Duration
+	}{
+		{"advance_time", map[string]interface{}{"hours": 2.0}, 2 * time.Hour},
+		{"rest", map[string]interface{}{"hours": 8.0}, 8 * time.Hour},
+		{"next_day", nil, 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			testConfig(t, nil)
+			gm, _ := newTestManager(t)
+			newTestGame(t, gm, "alice", time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			joinTestNetwork(t, gm, "bob", "alice")
+			if result := doAction(t, gm, "alice", tt.action, tt.data); result["success"] != true {
+				t.Fatalf("%s failed: %v", tt.action, result["message"])
+			}
+			for _, playerID := range []string{"alice", "bob"} {
+				gm.readGame(playerID, func(game *GameState) {
+					if advanced := game.CurrentDate.Sub(time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)); advanced != tt.want {
+						t.Errorf("%s's clock moved %v, want %v", playerID, advanced, tt.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+// Two members of a network advancing at once never move anyone's clock back, and every advance
+// moves the whole network forward once
+func TestConcurrentNetworkAdvanceIsMonotonic(t *testing.T) {
+	testConfig(t, func(config *Config) { config.Game.AdvanceHoursPerMinute = 1000 })
+	gm, _ := newTestManager(t)
+	start := time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC)
+	newTestGame(t, gm, "alice", start)
+	joinTestNetwork(t, gm, "bob", "alice")
+	joinTestNetwork(t, gm, "carol", "bob")
+	players := []string{"alice", "bob", "carol"}
+	const advances = 20
+	
+	stop := make(chan struct{})
+	observed := make(chan error, 1)
+	go func() {
+		last := map[string]time.Time{}
+		for {
+			select {
+			case <-stop:
+				observed <- nil
+				return
+			default:
+			}
+			for _, playerID := range players {
+				var now time.Time
+				gm.readGame(playerID, func(game *GameState) { now = game.CurrentDate })
+				if now.Before(last[playerID]) {
+					observed <- fmt.Errorf("%s's clock went back from %v to %v", playerID, last[playerID], now)
+					return
+				}
+				last[playerID] = now
+			}
+		}
+	}()
+	
+	var wg sync.WaitGroup
+	for _, playerID := range []string{"alice", "carol"} {
+		wg.Add(1)
+		go func(playerID string) {
+			defer wg.Done()
+			for i := 0; i < advances; i++ {
+				if result := doAction(t, gm, playerID, "advance_time", map[string]interface{}{"hours": 1.0}); result["success"] != true {
+					t.Errorf("%s's advance %d failed: %v", playerID, i+1, result["message"])
+				}
+			}
+		}(playerID)
+	}
+	wg.Wait()
+	close(stop)
+	if err := <-observed; err != nil {
+		t.Fatal(err)
+	}
+	
+	want := start.Add(2 * advances * time.Hour)
+	for _, playerID := range players {
+		gm.readGame(playerID, func(game *GameState) {
+			if !game.CurrentDate.Equal(want) {
+				t.Errorf("%s is at %v, want %v after %d one-hour advances", playerID, game.CurrentDate, want, 2*advances)
+			}
+		})
+	}
+}
@@ -2,11 +2,11 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"math/rand"
 	"net/http"
 	"os"
 	"strconv"
@@ -21,12 +21,36 @@ const (
 	AgentGuide    = "guide"
 )
 
-// AIClient handles OpenAI API calls with Featherless fallback
+// Provider is an OpenAI-compatible chat completion endpoint (OpenAI, Featherless, Ollama, proxies, etc.)
+type Provider struct {
+	Name       string   `json:"name"`
+	BaseURL    string   `json:"base_url"`
+	APIKey     string   `json:"api_key"`
+	Model      string   `json:"model"`
+	FailoverOn []string `json:"failover_on,omitempty"` // Error substrings that trigger failover to the next provider
+}
+
+// shouldFailover checks if an error from this provider should trigger failover to the next one
+func (p *Provider) shouldFailover(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	for _, pattern := range p.FailoverOn {
+		if pattern == "*" || strings.Contains(errStr, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// errAIOffline is returned for every AI request in offline mode, so callers use their fallbacks
+var errAIOffline = errors.New("AI is offline (ai.offline), using the built-in fallback")
+
+// AIClient handles AI API calls across an ordered list of providers
 type AIClient struct {
-	APIKey          string
-	BaseURL         string
-	FeatherlessKey  string
-	FeatherlessURL  string
+	Providers       []Provider
+	Offline         bool // Never call a provider; every request fails at once with errAIOffline
 	logFile         *os.File
 	logMu           sync.Mutex
 }
@@ -38,16 +62,17 @@ func NewAIClient() *AIClient {
 	// Open log file for appending
 	logFile, err := os.OpenFile("chatgpt_logs.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
-		log.Printf("Warning: Could not open log file: %v", err)
+		logWarnf("Could not open log file: %v", err)
 		logFile = nil
 	}
 	
+	if config.AI.Offline {
+		logInfof("[AI] Offline mode: offers, chat and lessons come from the built-in fallbacks, no AI provider is called")
+	}
 	return &AIClient{
-		APIKey:         config.OpenAI.APIKey,
-		BaseURL:        config.OpenAI.BaseURL,
-		FeatherlessKey: config.Featherless.APIKey,
-		FeatherlessURL: config.Featherless.BaseURL,
-		logFile:        logFile,
+		Providers: config.Providers,
+		Offline:   config.AI.Offline,
+		logFile:   logFile,
 	}
 }
 
@@ -106,33 +131,86 @@ type Choice struct {
 }
 
 // CallOpenAI makes a request to OpenAI API
-func (c *AIClient) CallOpenAI(messages []Message) (string, error) {
-	return c.CallOpenAIWithAgent("unknown", messages)
+func (c *AIClient) CallOpenAI(ctx context.Context, messages []Message) (string, error) {
+	return c.CallOpenAIWithAgent(ctx, "unknown", messages)
 }
 
-// CallOpenAIWithAgent makes a request to OpenAI API and logs it, with Featherless fallback
-func (c *AIClient) CallOpenAIWithAgent(agentType string, messages []Message) (string, error) {
-	// Try OpenAI first
-	response, err := c.callAPI(c.BaseURL, c.APIKey, "gpt-3.5-turbo", agentType, messages)
-	if err != nil {
-		// Check if it's an insufficient_quota error
-		if c.isInsufficientQuotaError(err) {
-			log.Printf("OpenAI quota exceeded, trying Featherless fallback...")
-			// Try Featherless as fallback
-			featherlessResponse, featherlessErr := c.callAPI(c.FeatherlessURL, c.FeatherlessKey, "meta-llama/Meta-Llama-3.1-8B-Instruct", agentType+"_featherless", messages)
-			if featherlessErr == nil {
-				log.Printf("Successfully used Featherless fallback")
-				return featherlessResponse, nil
+// CallOpenAIWithAgent makes a request to the configured providers and logs it.
+// Providers are tried in order; a failure only moves on to the next provider
+// if the failing provider declares the error as a failover trigger.
+func (c *AIClient) CallOpenAIWithAgent(ctx context.Context, agentType string, messages []Message) (string, error) {
+	if c.Offline {
+		metricsAICalls.WithLabelValues(agentType, "offline").Inc()
+		logDebugf("[AI] Offline, skipping %s request", agentType)
+		return "", errAIOffline
+	}
+	if len(c.Providers) == 0 {
+		return "", fmt.Errorf("no AI providers configured")
+	}
+	
+	timeout := aiTimeout(agentType)
+	var firstErr error
+	for i := range c.Providers {
+		provider := &c.Providers[i]
+		logAgentType := agentType
+		if i > 0 {
+			logAgentType = agentType + "_" + provider.Name
+		}
+		
+		response, err := c.callAPI(ctx, provider.BaseURL, provider.APIKey, provider.Model, logAgentType, timeout, messages)
+		if ctx.Err() != nil {
+			// The caller went away (player disconnected or the server is shutting down); no point in falling back
+			metricsAICalls.WithLabelValues(agentType, "cancelled").Inc()
+			return "", ctx.Err()
+		}
+		if err == nil {
+			if i > 0 {
+				logInfof("Successfully used %s fallback", provider.Name)
+				metricsAICalls.WithLabelValues(agentType, "fallback").Inc()
+			} else {
+				metricsAICalls.WithLabelValues(agentType, "success").Inc()
 			}
-			log.Printf("Featherless fallback also failed: %v", featherlessErr)
+			return response, nil
 		}
-		return "", err
+		
+		if firstErr == nil {
+			firstErr = err
+		} else {
+			logWarnf("%s fallback also failed: %v", provider.Name, err)
+		}
+		
+		if !provider.shouldFailover(err) || i == len(c.Providers)-1 {
+			break
+		}
+		logWarnf("%s request failed (%v), trying %s fallback...", provider.Name, err, c.Providers[i+1].Name)
 	}
-	return response, nil
+	metricsAICalls.WithLabelValues(agentType, "failure").Inc()
+	return "", firstErr
+}
+
+// aiTimeout returns how long one provider gets to answer a request of agentType
+// (ai.agent_timeout_seconds, or ai.timeout_seconds)
+func aiTimeout(agentType string) time.Duration {
+	settings := GetConfig().AI
+	seconds, exists := settings.AgentTimeoutSeconds[agentType]
+	if !exists {
+		seconds = settings.TimeoutSeconds
+	}
+	return time.Duration(seconds * float64(time.Second))
 }
 
-// callAPI makes a generic API call to any OpenAI-compatible endpoint
-func (c *AIClient) callAPI(baseURL, apiKey, model, agentType string, messages []Message) (string, error) {
+// aiWaitTimeout returns how long to wait for CallOpenAIWithAgent to finish a request of agentType:
+// the timeout of every provider it may fail over to plus a little slack, so a caller waiting on
+// it gives up only after the request itself has
+func aiWaitTimeout(agentType string) time.Duration {
+	return time.Duration(max(len(GetConfig().Providers), 1))*aiTimeout(agentType) + aiWaitSlack
+}
+
+// aiWaitSlack is how much longer than the providers' timeouts aiWaitTimeout waits
+const aiWaitSlack = 2 * time.Second
+
+// callAPI makes a generic API call to any OpenAI-compatible endpoint, giving up after timeout
+func (c *AIClient) callAPI(ctx context.Context, baseURL, apiKey, model, agentType string, timeout time.Duration, messages []Message) (string, error) {
 	reqBody := OpenAIRequest{
 		Model:     model,
 		Messages:  messages,
@@ -145,7 +223,7 @@ func (c *AIClient) callAPI(baseURL, apiKey, model, agentType string, messages []
 		return "", err
 	}
 	
-	req, err := http.NewRequest("POST", baseURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", baseURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		c.logRequestResponse(agentType, messages, "", err)
 		return "", err
@@ -154,7 +232,7 @@ func (c *AIClient) callAPI(baseURL, apiKey, model, agentType string, messages []
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+apiKey)
 	
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		c.logRequestResponse(agentType, messages, "", err)
@@ -191,22 +269,48 @@ func (c *AIClient) callAPI(baseURL, apiKey, model, agentType string, messages []
 	return response, nil
 }
 
-// isInsufficientQuotaError checks if the error is due to insufficient quota
-func (c *AIClient) isInsufficientQuotaError(err error) bool {
-	if err == nil {
-		return false
+// Probe checks that at least one configured provider is reachable by listing its models (no tokens are spent)
+func (c *AIClient) Probe() error {
+	if c.Offline {
+		return nil // Nothing to reach; the fallbacks are always available
 	}
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "insufficient_quota") || strings.Contains(errStr, "quota")
+	client := &http.Client{Timeout: 3 * time.Second}
+	var lastErr error
+	for _, provider := range c.Providers {
+		if provider.APIKey == "" {
+			continue
+		}
+		modelsURL := strings.TrimSuffix(provider.BaseURL, "/chat/completions") + "/models"
+		req, err := http.NewRequest("GET", modelsURL, nil)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		req.Header.Set("Authorization", "Bearer "+provider.APIKey)
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %v", provider.Name, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		lastErr = fmt.Errorf("%s: status %d", provider.Name, resp.StatusCode)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no AI provider has an API key configured")
+	}
+	return lastErr
 }
 
 // GenerateTrickeryOffer generates a tricky offer using AI
-func (c *AIClient) GenerateTrickeryOffer(gameState *GameState) (*Offer, error) {
+func (c *AIClient) GenerateTrickeryOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
 	prompt := fmt.Sprintf(`You are a financial trickery agent. Create a deceptive offer that seems like a good deal but is actually a scam or bad investment.
 
 Current game state:
 - Player money: $%.2f
-- Day: %d
+- Day: %s
 - Current investments: %d stocks, %d crypto
 
 Create a tricky offer that:
@@ -225,11 +329,14 @@ Respond in JSON format:
 }`, gameState.Money, gameState.CurrentDate.Format("2006-01-02"), len(gameState.Stocks), len(gameState.Crypto))
 	
 	messages := []Message{
-		{Role: "system", Content: "You are a financial trickery expert. Generate deceptive offers that test financial literacy."},
+		{Role: "system", Content: "You are a financial trickery expert. Generate deceptive offers that test financial literacy." + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
-	response, err := c.CallOpenAIWithAgent("trickery_offer", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "trickery_offer", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		// Fallback to simple offer if API fails
 		return c.generateFallbackTrickeryOffer(gameState), nil
@@ -249,21 +356,22 @@ Respond in JSON format:
 		Price:         getFloat(offerData, "price", 1000),
 		OriginalPrice: getFloat(offerData, "original_price", 1500),
 		Discount:      getFloat(offerData, "discount", 30),
-		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		ExpiresAt:     gameState.CurrentDate.Add(24 * time.Hour),
 		IsTrickery:    true,
 		Reason:        getString(offerData, "reason", "Hidden fees and risks"),
+		Source:        OfferSourceAI,
 	}
 	
 	return offer, nil
 }
 
 // GenerateGoodOffer generates a legitimate good offer using AI
-func (c *AIClient) GenerateGoodOffer(gameState *GameState) (*Offer, error) {
+func (c *AIClient) GenerateGoodOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
 	prompt := fmt.Sprintf(`You are a financial advisor agent. Create a legitimate, good-value offer that helps the player.
 
 Current game state:
 - Player money: $%.2f
-- Day: %d
+- Day: %s
 - Current investments: %d stocks, %d crypto
 
 Create a good offer that:
@@ -283,11 +391,14 @@ Respond in JSON format:
 }`, gameState.Money, gameState.CurrentDate.Format("2006-01-02"), len(gameState.Stocks), len(gameState.Crypto))
 	
 	messages := []Message{
-		{Role: "system", Content: "You are a helpful financial advisor. Generate legitimate, valuable offers."},
+		{Role: "system", Content: "You are a helpful financial advisor. Generate legitimate, valuable offers." + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
-	response, err := c.CallOpenAIWithAgent("good_offer", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "good_offer", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		return c.generateFallbackGoodOffer(gameState), nil
 	}
@@ -305,18 +416,19 @@ Respond in JSON format:
 		Price:         getFloat(offerData, "price", 800),
 		OriginalPrice: getFloat(offerData, "original_price", 1200),
 		Discount:      getFloat(offerData, "discount", 25),
-		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		ExpiresAt:     gameState.CurrentDate.Add(24 * time.Hour),
 		IsTrickery:    false,
 		Reason:        getString(offerData, "reason", "Genuine value and discount"),
+		Source:        OfferSourceAI,
 	}
 	
 	return offer, nil
 }
 
 // GenerateStockOffer generates a stock offer using AI (safe or unsafe)
-func (c *AIClient) GenerateStockOffer(gameState *GameState) (*StockOffer, error) {
-	// Randomly decide if it's safe or unsafe (50/50)
-	isSafe := rand.Float64() < 0.5
+func (c *AIClient) GenerateStockOffer(ctx context.Context, gameState *GameState) (*StockOffer, error) {
+	// Randomly decide if it's safe or unsafe (odds set by the game's difficulty)
+	isSafe := gameState.rng.Float64() >= gameState.trickeryChance(gameState.difficulty().UnsafeStockChance)
 	
 	prompt := fmt.Sprintf(`You are a %s stock analyst. Create a stock investment opportunity that %s.
 
@@ -330,9 +442,10 @@ Create a stock offer that:
 2. Has a description of the company and why it's an investment opportunity
 3. Current stock price (€10-€500 per share)
 4. Is either SAFE (reliable, established company) or UNSAFE (risky, speculative, potential scam)
-5. Failure chance (0-100%): For safe stocks, 5-20% failure chance. For unsafe stocks, 30-80% failure chance.
+5. Failure chance (0-100%%): For safe stocks, 5-20%% failure chance. For unsafe stocks, 30-80%% failure chance.
 6. Reliability rating: "high" (safe), "medium" (moderate risk), or "low" (high risk/unsafe)
 7. Reason: Explain why this stock is safe/unsafe, what makes it reliable or risky
+8. Beta (0.2-3.0): how strongly the stock follows the overall market. Safe, defensive stocks 0.5-1.0; risky, speculative stocks 1.3-3.0.
 
 IMPORTANT:
 - Safe stocks: Established companies, good fundamentals, low volatility, reliable dividends
@@ -347,7 +460,8 @@ Respond in JSON format:
   "is_safe": true,
   "failure_chance": 15.0,
   "reliability": "high",
-  "reason": "Why this stock is safe/unsafe"
+  "reason": "Why this stock is safe/unsafe",
+  "beta": 0.8
 }`, 
 		map[bool]string{true: "conservative", false: "aggressive"}[isSafe],
 		map[bool]string{true: "is safe and reliable", false: "is risky or potentially unsafe"}[isSafe],
@@ -362,11 +476,14 @@ Respond in JSON format:
 	}[isSafe]
 	
 	messages := []Message{
-		{Role: "system", Content: systemMsg},
+		{Role: "system", Content: systemMsg + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
-	response, err := c.CallOpenAIWithAgent("stock_offer", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "stock_offer", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		return c.generateFallbackStockOffer(gameState, isSafe), nil
 	}
@@ -394,10 +511,20 @@ Respond in JSON format:
 	
 	// Adjust failure chance based on is_safe
 	if isSafe && failureChance > 20 {
-		failureChance = 15 + rand.Float64()*5 // 15-20% for safe stocks
+		failureChance = 15 + gameState.rng.Float64()*5 // 15-20% for safe stocks
 	}
 	if !isSafe && failureChance < 30 {
-		failureChance = 30 + rand.Float64()*50 // 30-80% for unsafe stocks
+		failureChance = 30 + gameState.rng.Float64()*50 // 30-80% for unsafe stocks
+	}
+	
+	// Keep beta in a sensible range; risky stocks swing at least as hard as the market
+	defaultBeta := map[bool]float64{true: 0.8, false: 1.6}[isSafe]
+	beta := getFloat(offerData, "beta", defaultBeta)
+	if beta < 0.2 || beta > 3 {
+		beta = defaultBeta
+	}
+	if !isSafe && beta < 1 {
+		beta = defaultBeta
 	}
 	
 	reliability := getString(offerData, "reliability", "medium")
@@ -416,9 +543,11 @@ Respond in JSON format:
 		CurrentPrice:  price,
 		IsSafe:        isSafe,
 		FailureChance: failureChance,
+		Beta:          beta,
 		Reliability:   reliability,
 		Reason:        getString(offerData, "reason", ""),
-		ExpiresAt:     gameState.CurrentDate.Add(7 * 24 * time.Hour), // Expires in 7 days
+		ExpiresAt:     GetConfig().Offers.Stocks.expiresAt(gameState.CurrentDate),
+		Source:        OfferSourceAI,
 	}
 	
 	return offer, nil
@@ -430,31 +559,35 @@ func (c *AIClient) generateFallbackStockOffer(gameState *GameState, isSafe bool)
 			ID:            generateID(),
 			Symbol:        "SAFE",
 			CompanyName:   "SafeCorp Industries",
-			Description:   "Established company with strong fundamentals and reliable dividends.",
+			Description:   localize(gameState.Language, "Established company with strong fundamentals and reliable dividends."),
 			CurrentPrice:  150.0,
 			IsSafe:        true,
 			FailureChance: 15.0,
+			Beta:          0.8,
 			Reliability:   "high",
-			Reason:        "Established company with good financials and stable growth",
-			ExpiresAt:     gameState.CurrentDate.Add(7 * 24 * time.Hour),
+			Reason:        localize(gameState.Language, "Established company with good financials and stable growth"),
+			ExpiresAt:     GetConfig().Offers.Stocks.expiresAt(gameState.CurrentDate),
+			Source:        OfferSourceFallback,
 		}
 	}
 	return &StockOffer{
 		ID:            generateID(),
 		Symbol:        "RISK",
 		CompanyName:   "Risky Ventures Inc.",
-		Description:   "New startup with high growth potential but significant risk.",
+		Description:   localize(gameState.Language, "New startup with high growth potential but significant risk."),
 		CurrentPrice:  50.0,
 		IsSafe:        false,
 		FailureChance: 60.0,
+		Beta:          1.8,
 		Reliability:   "low",
-		Reason:        "New company, high volatility, speculative investment",
-		ExpiresAt:     gameState.CurrentDate.Add(7 * 24 * time.Hour),
+		Reason:        localize(gameState.Language, "New company, high volatility, speculative investment"),
+		ExpiresAt:     GetConfig().Offers.Stocks.expiresAt(gameState.CurrentDate),
+		Source:        OfferSourceFallback,
 	}
 }
 
 // GenerateOtherOffer generates a random "other" offer (scams, charity, etc.) using AI
-func (c *AIClient) GenerateOtherOffer(gameState *GameState) (*Offer, error) {
+func (c *AIClient) GenerateOtherOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
 	// Provide examples to the AI, but let it be creative
 	examples := []string{
 		"African Prince / Nigerian prince scam - promises large money for small processing fee",
@@ -465,10 +598,10 @@ func (c *AIClient) GenerateOtherOffer(gameState *GameState) (*Offer, error) {
 	}
 	
 	// Randomly select an example category to guide the AI
-	exampleCategory := examples[rand.Intn(len(examples))]
+	exampleCategory := examples[gameState.rng.Intn(len(examples))]
 	
-	// Determine if it should be trickery (70% chance for scams, 30% for legitimate)
-	isTrickery := rand.Float64() < 0.5
+	// Determine if it should be trickery (odds set by the game's difficulty, adapted to the player)
+	isTrickery := gameState.rng.Float64() < gameState.trickeryChance(gameState.difficulty().OtherTrickeryChance)
 	
 	systemMsg := "You are a creative offer generator. Create interesting, realistic offers that test financial literacy and decision-making."
 	
@@ -492,6 +625,15 @@ The offer should:
    - reputation_change: reputation gained/lost (can be negative)
    - money_change: additional money change beyond the price (can be positive or negative)
    - For recurring agreements, these effects apply periodically (daily/weekly/monthly)
+   - For one-time purchases (category "purchase") the effects belong to the item: set effect_frequency
+     to "on_use" (they apply once when the player uses it, e.g. an energy drink) or "daily" (they apply
+     every day the player owns it, e.g. a good mattress)
+8. Have exactly one category:
+   - "scam": fraud that takes the player's money or data
+   - "charity": a donation or good cause
+   - "purchase": buying an item or one-off service
+   - "subscription": a recurring membership, service or plan
+   - "ethical_dilemma": quick gain at a moral or legal cost (e.g. selling documents)
 
 Current game state:
 - Player money: €%.2f
@@ -517,6 +659,8 @@ Respond in JSON format:
   "reputation_change": 0,
   "is_recurring": false,
   "recurrence_type": "monthly",
+  "category": "scam",
+  "effect_frequency": "on_use",
   "reason": "Why this is %s (explain the consequences)",
   "is_trickery": true
 }`, 
@@ -529,11 +673,14 @@ Respond in JSON format:
 		map[bool]string{true: "a scam/trickery", false: "legitimate"}[isTrickery])
 	
 	messages := []Message{
-		{Role: "system", Content: systemMsg},
+		{Role: "system", Content: systemMsg + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
-	response, err := c.CallOpenAIWithAgent("other_offer", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "other_offer", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		// Use fallback with the example category
 		return c.generateFallbackOtherOffer(gameState, exampleCategory, isTrickery), nil
@@ -595,7 +742,7 @@ Respond in JSON format:
 		Title:            getString(offerData, "title", "Special Offer"),
 		Description:      getString(offerData, "description", "An interesting offer"),
 		Price:            price,
-		ExpiresAt:        gameState.CurrentDate.Add(3 * 24 * time.Hour), // Expires in 3 days
+		ExpiresAt:        GetConfig().Offers.Other.expiresAt(gameState.CurrentDate),
 		IsTrickery:       aiIsTrickery,
 		Reason:           getString(offerData, "reason", ""),
 		HealthChange:     healthChange,
@@ -604,6 +751,11 @@ Respond in JSON format:
 		MoneyChange:      moneyChange,
 		IsRecurring:      isRecurring,
 		RecurrenceType:   recurrenceType,
+		Category:         normalizeOfferCategory(getString(offerData, "category", ""), aiIsTrickery, isRecurring),
+		Source:           OfferSourceAI,
+	}
+	if offer.Category == CategoryPurchase && !isRecurring {
+		offer.EffectFrequency = normalizeEffectFrequency(getString(offerData, "effect_frequency", ""))
 	}
 	
 	return offer, nil
@@ -611,7 +763,7 @@ Respond in JSON format:
 
 func (c *AIClient) generateFallbackOtherOffer(gameState *GameState, exampleCategory string, isTrickery bool) *Offer {
 	// Parse example category to determine type
-	var title, description, reason string
+	var title, description, reason, category string
 	var price float64
 	var healthChange, energyChange, reputationChange int
 	var moneyChange float64
@@ -622,12 +774,14 @@ func (c *AIClient) generateFallbackOtherOffer(gameState *GameState, exampleCateg
 		description = "I am Prince Abubakar from Nigeria. I need your help to transfer €500,000. Send €2,000 processing fee to receive your share!"
 		price = 2000
 		reason = "Classic advance fee fraud scam - you'll never see the money"
+		category = CategoryScam
 		moneyChange = -2000
 	} else if strings.Contains(strings.ToLower(exampleCategory), "charity") || strings.Contains(strings.ToLower(exampleCategory), "donation") {
 		title = "Charity Donation - Official Partners"
 		description = "Donate to help children in need. Official partners: UNICEF, Red Cross. Your donation makes a difference!"
 		price = 100
 		reason = "Legitimate charity donation that helps others"
+		category = CategoryCharity
 		moneyChange = -100
 		reputationChange = 5
 	} else if strings.Contains(strings.ToLower(exampleCategory), "ring") || strings.Contains(strings.ToLower(exampleCategory), "gypsy") || strings.Contains(strings.ToLower(exampleCategory), "traveler") {
@@ -647,12 +801,14 @@ func (c *AIClient) generateFallbackOtherOffer(gameState *GameState, exampleCateg
 		description = "A shady character offers €3,000 for your passport. Quick cash, but is it worth it?"
 		price = 0
 		reason = "Illegal and dangerous - selling identity documents"
+		category = CategoryEthicalDilemma
 		moneyChange = 3000
 		reputationChange = -10
 	} else if strings.Contains(strings.ToLower(exampleCategory), "car") || strings.Contains(strings.ToLower(exampleCategory), "friend") {
 		title = "Buy Car from Friend"
 		description = "Your friend offers to sell you their old car for €1,500. It's a good deal, but the car might have issues."
 		price = 1500
+		category = CategoryPurchase
 		if isTrickery {
 			reason = "Car breaks down immediately - hidden problems"
 			moneyChange = -1500
@@ -673,23 +829,89 @@ func (c *AIClient) generateFallbackOtherOffer(gameState *GameState, exampleCateg
 	return &Offer{
 		ID:               generateID(),
 		Type:             "other",
-		Title:            title,
-		Description:      description,
+		Title:            localize(gameState.Language, title),
+		Description:      localize(gameState.Language, description),
 		Price:            price,
-		ExpiresAt:        gameState.CurrentDate.Add(3 * 24 * time.Hour),
+		ExpiresAt:        GetConfig().Offers.Other.expiresAt(gameState.CurrentDate),
 		IsTrickery:       isTrickery,
-		Reason:           reason,
+		Reason:           localize(gameState.Language, reason),
 		HealthChange:     healthChange,
 		EnergyChange:     energyChange,
 		ReputationChange: reputationChange,
 		MoneyChange:      moneyChange,
+		Category:         normalizeOfferCategory(category, isTrickery, false),
 		IsRecurring:      false, // Fallback offers are one-time by default
 		RecurrenceType:   "",
+		Source:           OfferSourceFallback,
+	}
+}
+
+// ExplainTrickery generates a short lesson on the red flags the player missed after accepting a trickery offer
+func (c *AIClient) ExplainTrickery(ctx context.Context, gameState *GameState, offer *Offer) (string, error) {
+	prompt := fmt.Sprintf(`The player just accepted an offer that was a scam/trickery. Explain which red flags they missed.
+
+OFFER THE PLAYER ACCEPTED:
+- Title: %s
+- Description: %s
+- Price: €%.2f
+- Original price: €%.2f
+- Discount: %.0f%%
+- Recurring: %v (%s)
+- Effects: health %+d, energy %+d, reputation %+d, money %+.2f€
+- Why it was trickery: %s
+
+PLAYER SITUATION:
+- Money after accepting: €%.2f
+- Current date: %s
+
+YOUR TASK:
+Write a short lesson (3-4 sentences, plain text, no JSON, no markdown) that:
+1. Points at the specific fields of this offer that were red flags (price vs original price, discount, effects, wording of the description)
+2. Names the general scam tactic being used
+3. Gives one concrete rule the player can apply next time`,
+		offer.Title,
+		offer.Description,
+		offer.Price,
+		offer.OriginalPrice,
+		offer.Discount,
+		offer.IsRecurring,
+		offer.RecurrenceType,
+		offer.HealthChange,
+		offer.EnergyChange,
+		offer.ReputationChange,
+		offer.MoneyChange,
+		offer.Reason,
+		gameState.Money,
+		gameState.CurrentDate.Format("2006-01-02"))
+	
+	messages := []Message{
+		{Role: "system", Content: "You are a patient financial literacy teacher. Explain scams clearly and briefly without shaming the player." + languageInstruction(gameState)},
+		{Role: "user", Content: prompt},
+	}
+	
+	response, err := c.CallOpenAIWithAgent(ctx, "trickery_explainer", messages)
+	if err != nil || strings.TrimSpace(response) == "" {
+		return c.generateFallbackTrickeryLesson(offer), err
+	}
+	
+	return strings.TrimSpace(response), nil
+}
+
+// generateFallbackTrickeryLesson builds a lesson from the offer's static reason when the AI is unavailable
+func (c *AIClient) generateFallbackTrickeryLesson(offer *Offer) string {
+	lesson := fmt.Sprintf("\"%s\" was a trickery offer.", offer.Title)
+	if offer.Reason != "" {
+		lesson += " " + offer.Reason + "."
+	}
+	if offer.OriginalPrice > offer.Price && offer.Discount > 0 {
+		lesson += fmt.Sprintf(" A %.0f%% discount from €%.2f is a classic way to create false urgency.", offer.Discount, offer.OriginalPrice)
 	}
+	lesson += " Next time, ask yourself who benefits if you say yes right now."
+	return lesson
 }
 
 // ChatWithGuide asks the guide agent for advice
-func (c *AIClient) ChatWithGuide(gameState *GameState, userMessage string, context string) (*ChatResponse, error) {
+func (c *AIClient) ChatWithGuide(ctx context.Context, gameState *GameState, userMessage string, chatContext string) (*ChatResponse, error) {
 	// Build comprehensive work context
 	workContext := c.buildWorkContext(gameState)
 	
@@ -711,6 +933,9 @@ CURRENT GAME STATE:
 - Current apartment: %s
 - Investments: %d stocks, %d crypto, %d items
 
+FINANCIAL SUMMARY:
+%s
+
 DETAILED WORK CONTEXT:
 %s
 
@@ -765,10 +990,11 @@ Respond in JSON format:
 		len(gameState.Stocks),
 		len(gameState.Crypto),
 		len(gameState.Inventory),
+		gameState.Summary().summaryContext(),
 		workContext,
 		apartmentContext,
 		recentEvents,
-		context,
+		chatContext,
 		userMessage)
 	
 	systemPrompt := `You are a patient financial educator and career advisor. Your role is to guide players through their work and financial decisions by asking thoughtful, guiding questions rather than giving direct answers. 
@@ -801,14 +1027,19 @@ EXAMPLE OF BAD RESPONSE (DO NOT DO THIS):
 You MUST respond in valid JSON format only, no markdown, no code blocks, just pure JSON.`
 
 	messages := []Message{
-		{Role: "system", Content: systemPrompt},
+		{Role: "system", Content: systemPrompt + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
-	response, err := c.CallOpenAIWithAgent("guide_chat", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "guide_chat", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		// Log the error but provide a context-aware fallback
-		log.Printf("Error calling OpenAI for guide chat: %v", err)
+		if !errors.Is(err, errAIOffline) {
+			logErrorf("Error calling OpenAI for guide chat: %v", err)
+		}
 		return c.generateContextAwareFallback(gameState, userMessage), nil
 	}
 	
@@ -827,7 +1058,7 @@ You MUST respond in valid JSON format only, no markdown, no code blocks, just pu
 	var chatData map[string]interface{}
 	if err := json.Unmarshal([]byte(responseText), &chatData); err != nil {
 		// If JSON parsing fails, try to extract message and questions from plain text
-		log.Printf("Error parsing JSON response: %v. Response was: %s", err, response)
+		logErrorf("Error parsing JSON response: %v. Response was: %s", err, response)
 		return c.parseTextResponse(response, gameState, userMessage), nil
 	}
 	
@@ -858,7 +1089,7 @@ You MUST respond in valid JSON format only, no markdown, no code blocks, just pu
 }
 
 // ParseChatForOfferCreation parses a chat message to detect if player wants to create an offer/agreement/sell item
-func (c *AIClient) ParseChatForOfferCreation(gameState *GameState, userMessage string) (*ChatResponse, error) {
+func (c *AIClient) ParseChatForOfferCreation(ctx context.Context, gameState *GameState, userMessage string) (*ChatResponse, error) {
 	prompt := fmt.Sprintf(`Analyze the following player message to determine if they want to create an offer, agreement, or sell an item to other players.
 
 CURRENT GAME STATE:
@@ -889,6 +1120,7 @@ YOUR TASK:
    - For items: which item from inventory (if mentioned)
    - Stat effects: health_change, energy_change, reputation_change, money_change (if mentioned)
    - Is it a scam/trickery? (if they mention it's deceptive)
+   - Category: "scam", "charity", "purchase", "subscription" or "ethical_dilemma"
 
 3. If they're just asking a question, return null for all creation fields.
 
@@ -904,6 +1136,7 @@ Respond in JSON format:
   "reputation_change": 0,
   "money_change": 0.00,
   "is_trickery": false,
+  "category": "purchase",
   "item_id": "item_id_from_inventory" | null,
   "message": "Confirmation message or clarification question"
 }`, 
@@ -921,20 +1154,25 @@ Parse their natural language and extract structured information. If the message
 If they mention selling an item, try to match it to their inventory.`
 
 	messages := []Message{
-		{Role: "system", Content: systemMsg},
+		{Role: "system", Content: systemMsg + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 
-	response, err := c.CallOpenAIWithAgent("chat_offer_parser", messages)
+	response, err := c.CallOpenAIWithAgent(ctx, "chat_offer_parser", messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
-		log.Printf("[PARSE_OFFER] ERROR calling AI for player %s: %v", gameState.PlayerID, err)
+		if !errors.Is(err, errAIOffline) {
+			logErrorf("[PARSE_OFFER] Error calling AI for player %s: %v", gameState.PlayerID, err)
+		}
 		return &ChatResponse{
 			Agent:   AgentGuide,
 			Message: "I couldn't understand your request. Could you clarify what you'd like to create?",
 		}, nil
 	}
 
-	log.Printf("[PARSE_OFFER] Raw AI response for player %s: %s", gameState.PlayerID, response)
+	logDebugf("[PARSE_OFFER] Raw AI response for player %s: %s", gameState.PlayerID, response)
 
 	// Try to extract JSON
 	responseText := response
@@ -945,11 +1183,11 @@ If they mention selling an item, try to match it to their inventory.`
 		}
 	}
 
-	log.Printf("[PARSE_OFFER] Extracted JSON for player %s: %s", gameState.PlayerID, responseText)
+	logDebugf("[PARSE_OFFER] Extracted JSON for player %s: %s", gameState.PlayerID, responseText)
 
 	var parsedData map[string]interface{}
 	if err := json.Unmarshal([]byte(responseText), &parsedData); err != nil {
-		log.Printf("[PARSE_OFFER] ERROR parsing JSON for player %s: %v. Response text: %s", gameState.PlayerID, err, responseText)
+		logErrorf("[PARSE_OFFER] Error parsing JSON for player %s: %v. Response text: %s", gameState.PlayerID, err, responseText)
 		return &ChatResponse{
 			Agent:   AgentGuide,
 			Message: "I couldn't parse your request. Please try again with more details.",
@@ -957,10 +1195,10 @@ If they mention selling an item, try to match it to their inventory.`
 	}
 
 	intent := getString(parsedData, "intent", "question")
-	log.Printf("[PARSE_OFFER] Parsed intent for player %s: %s", gameState.PlayerID, intent)
+	logDebugf("[PARSE_OFFER] Parsed intent for player %s: %s", gameState.PlayerID, intent)
 	
 	if intent == "question" {
-		log.Printf("[PARSE_OFFER] No creation intent for player %s, returning nil", gameState.PlayerID)
+		logDebugf("[PARSE_OFFER] No creation intent for player %s, returning nil", gameState.PlayerID)
 		return nil, nil // No creation intent, return nil to indicate normal chat flow
 	}
 
@@ -982,12 +1220,12 @@ If they mention selling an item, try to match it to their inventory.`
 		Created: true,
 	}
 	
-	log.Printf("[PARSE_OFFER] Created ChatResponse for player %s: intent=%s, title=%s, Created=true", 
+	logDebugf("[PARSE_OFFER] Created ChatResponse for player %s: intent=%s, title=%s, Created=true", 
 		gameState.PlayerID, intent, title)
 
 	switch intent {
 	case "offer":
-		log.Printf("[PARSE_OFFER] Processing offer creation for player %s", gameState.PlayerID)
+		logDebugf("[PARSE_OFFER] Processing offer creation for player %s", gameState.PlayerID)
 		healthChange := 0
 		if val, ok := parsedData["health_change"]; ok {
 			if f, ok := val.(float64); ok {
@@ -1022,12 +1260,13 @@ If they mention selling an item, try to match it to their inventory.`
 			IsRecurring:     false,
 			CreatedBy:       gameState.PlayerID,
 		}
+		offer.Category = normalizeOfferCategory(getString(parsedData, "category", ""), offer.IsTrickery, false)
 		chatResponse.Offer = offer
-		log.Printf("[PARSE_OFFER] Created offer for player %s: ID=%s, Title=%s, Price=%.2f", 
+		logInfof("[PARSE_OFFER] Created offer for player %s: ID=%s, Title=%s, Price=%.2f", 
 			gameState.PlayerID, offer.ID, offer.Title, offer.Price)
 
 	case "agreement":
-		log.Printf("[PARSE_OFFER] Processing agreement creation for player %s", gameState.PlayerID)
+		logDebugf("[PARSE_OFFER] Processing agreement creation for player %s", gameState.PlayerID)
 		recurrenceType := getString(parsedData, "recurrence_type", "monthly")
 		if recurrenceType != "daily" && recurrenceType != "weekly" && recurrenceType != "monthly" {
 			recurrenceType = "monthly"
@@ -1076,12 +1315,14 @@ If they mention selling an item, try to match it to their inventory.`
 			IsTrickery:      getBool(parsedData, "is_trickery", false),
 			Price:           agreementPrice, // Store the price for offer creation
 		}
+		agreement.Category = normalizeOfferCategory(getString(parsedData, "category", ""), agreement.IsTrickery, true)
+		agreement.normalizeMoneyChange()
 		chatResponse.Agreement = agreement
-		log.Printf("[PARSE_OFFER] Created agreement for player %s: ID=%s, Title=%s, RecurrenceType=%s", 
+		logInfof("[PARSE_OFFER] Created agreement for player %s: ID=%s, Title=%s, RecurrenceType=%s", 
 			gameState.PlayerID, agreement.ID, agreement.Title, agreement.RecurrenceType)
 
 	case "item":
-		log.Printf("[PARSE_OFFER] Processing item sale for player %s", gameState.PlayerID)
+		logDebugf("[PARSE_OFFER] Processing item sale for player %s", gameState.PlayerID)
 		itemID := getString(parsedData, "item_id", "")
 		// Find item in inventory
 		var item *Item
@@ -1117,6 +1358,7 @@ If they mention selling an item, try to match it to their inventory.`
 			IsTrickery:  getBool(parsedData, "is_trickery", false),
 			CreatedBy:   gameState.PlayerID,
 		}
+		offer.Category = normalizeOfferCategory(getString(parsedData, "category", ""), offer.IsTrickery, false)
 		chatResponse.Offer = offer
 		chatResponse.Item = item
 	}
@@ -1359,6 +1601,13 @@ func (c *AIClient) buildApartmentContext(gameState *GameState) string {
 		context += fmt.Sprintf("- Health Gain: +%d/hour (when not working)\n", apt.HealthGain)
 		context += fmt.Sprintf("- Energy Gain: +%d/hour (when not working)\n", apt.EnergyGain)
 	}
+	for _, property := range gameState.Properties {
+		if property.RentedOut {
+			context += fmt.Sprintf("- Also rents %s for €%.2f/month and sublets it for €%.2f/month\n", property.Title, property.Rent, property.SubletRent)
+		} else {
+			context += fmt.Sprintf("- Also rents %s for €%.2f/month, empty (could be rented out)\n", property.Title, property.Rent)
+		}
+	}
 	
 	context += fmt.Sprintf("\nCURRENT HEALTH & ENERGY:\n")
 	context += fmt.Sprintf("- Health: %d/100", gameState.Health)
@@ -1377,7 +1626,7 @@ func (c *AIClient) buildApartmentContext(gameState *GameState) string {
 
 // getRecentWorkEvents gets recent work-related events from history
 func (c *AIClient) getRecentWorkEvents(gameState *GameState) string {
-	if len(gameState.History) == 0 {
+	if gameState.History.Len() == 0 {
 		return "No recent events."
 	}
 	
@@ -1397,13 +1646,9 @@ func (c *AIClient) getRecentWorkEvents(gameState *GameState) string {
 	
 	recentEvents := []string{}
 	// Get last 10 events, filter for work-related ones
-	startIdx := len(gameState.History) - 10
-	if startIdx < 0 {
-		startIdx = 0
-	}
-	
-	for i := len(gameState.History) - 1; i >= startIdx && len(recentEvents) < 5; i-- {
-		event := gameState.History[i]
+	lastEvents := gameState.History.Last(10)
+	for i := len(lastEvents) - 1; i >= 0 && len(recentEvents) < 5; i-- {
+		event := lastEvents[i]
 		if workEventTypes[event.Type] {
 			recentEvents = append(recentEvents, fmt.Sprintf("- %s: %s (Date: %s)", 
 				event.Type, event.Message, event.Timestamp.Format("2006-01-02 15:04")))
@@ -1426,14 +1671,15 @@ func (c *AIClient) generateFallbackTrickeryOffer(gameState *GameState) *Offer {
 	return &Offer{
 		ID:            generateID(),
 		Type:          AgentTrickery,
-		Title:         "Limited Time: Get Rich Quick Scheme",
-		Description:   "Invest now and double your money in 7 days! No risk! (Warning: This is a scam)",
+		Title:         localize(gameState.Language, "Limited Time: Get Rich Quick Scheme"),
+		Description:   localize(gameState.Language, "Invest now and double your money in 7 days! No risk! (Warning: This is a scam)"),
 		Price:         gameState.Money * 0.3,
 		OriginalPrice: gameState.Money * 0.5,
 		Discount:      40,
-		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		ExpiresAt:     gameState.CurrentDate.Add(24 * time.Hour),
 		IsTrickery:    true,
-		Reason:        "Get-rich-quick schemes are always scams. Real investments take time and have risks.",
+		Reason:        localize(gameState.Language, "Get-rich-quick schemes are always scams. Real investments take time and have risks."),
+		Source:        OfferSourceFallback,
 	}
 }
 
@@ -1441,14 +1687,15 @@ func (c *AIClient) generateFallbackGoodOffer(gameState *GameState) *Offer {
 	return &Offer{
 		ID:            generateID(),
 		Type:          AgentOffers,
-		Title:         "Diversified Investment Package",
-		Description:   "A balanced mix of stocks and bonds with proven track record. 15% annual return expected.",
+		Title:         localize(gameState.Language, "Diversified Investment Package"),
+		Description:   localize(gameState.Language, "A balanced mix of stocks and bonds with proven track record. 15% annual return expected."),
 		Price:         gameState.Money * 0.2,
 		OriginalPrice: gameState.Money * 0.3,
-		Discount:      25,
-		ExpiresAt:     time.Now().Add(24 * time.Hour),
+		Discount:      33,
+		ExpiresAt:     gameState.CurrentDate.Add(24 * time.Hour),
 		IsTrickery:    false,
-		Reason:        "Diversified portfolio reduces risk while maintaining growth potential.",
+		Reason:        localize(gameState.Language, "Diversified portfolio reduces risk while maintaining growth potential."),
+		Source:        OfferSourceFallback,
 	}
 }
 
@@ -1490,16 +1737,26 @@ func getJobTitle(gs *GameState) string {
 }
 
 // GenerateJobOffer generates a job offer using AI (good or trickery)
-func (c *AIClient) GenerateJobOffer(gameState *GameState, offerType string) (*JobOffer, error) {
+func (c *AIClient) GenerateJobOffer(ctx context.Context, gameState *GameState, offerType string) (*JobOffer, error) {
+	// A poor reputation leaves mostly shady employers willing to hire
+	if offerType != "trickery" && gameState.lowReputation() && gameState.rng.Float64() < 0.5 {
+		offerType = "trickery"
+	}
 	isTrickery := offerType == "trickery"
 	
+	// A strong reputation opens doors to better paid jobs
+	salaryRange, maxSalary := "€2000-€8000", 10000.0
+	if !isTrickery && gameState.highReputation() {
+		salaryRange, maxSalary = "€5000-€12000", 14000.0
+	}
+	
 	// Randomly choose work type (50/50 chance)
 	var workType string
 	var workStart, workEnd string
-	if rand.Float64() < 0.5 {
+	if gameState.rng.Float64() < 0.5 {
 		workType = "fixed_time"
 		// Generate random work hours (e.g., 09:00-17:00, 08:00-16:00, etc.)
-		startHour := 8 + rand.Intn(3) // 8, 9, or 10
+		startHour := 8 + gameState.rng.Intn(3) // 8, 9, or 10
 		endHour := startHour + 8 // 8 hours later
 		workStart = fmt.Sprintf("%02d:00", startHour)
 		workEnd = fmt.Sprintf("%02d:00", endHour)
@@ -1516,13 +1773,14 @@ Current game state:
 
 Create a job offer that:
 1. Has a title and description
-2. Monthly salary (reasonable range: €2000-€8000)
+2. Monthly salary (reasonable range: %s)
 3. Hours per day (4-10 hours)
 4. Work type: %s%s
 5. Health loss per hour (0.5-3.0) - how much health is lost per hour of work. Physical jobs lose more, desk jobs lose less.
 6. Energy loss per hour (1.0-5.0) - how much energy is lost per hour of work. Demanding jobs lose more energy.
-7. Upfront cost (0-€2000) - for %s jobs, this should be 0. For trickery/scam jobs, this can be €100-€2000 (training fees, materials, "registration fees", etc.). This is a red flag!
+7. Upfront cost (0-€2000) - for legitimate jobs, this should be 0. For trickery/scam jobs, this can be €100-€2000 (training fees, materials, "registration fees", etc.). This is a red flag!
 8. %s
+9. Pay frequency: "weekly", "biweekly" or "monthly" (the salary above is still the monthly amount)
 
 IMPORTANT: Health and energy loss should reflect the job's physical/mental demands:
 - Physical jobs (construction, delivery): higher health loss (2.0-3.0), high energy loss (3.0-5.0)
@@ -1543,6 +1801,7 @@ Respond in JSON format:
   "health_loss_per_hour": 1.5,
   "energy_loss_per_hour": 3.0,
   "upfront_cost": 0.00,
+  "pay_frequency": "monthly",
   "reason": "Why this is %s"
 }`, 
 		map[bool]string{true: "trickery", false: "good"}[isTrickery],
@@ -1550,6 +1809,7 @@ Respond in JSON format:
 		gameState.Money,
 		gameState.CurrentDate.Format("2006-01-02"),
 		getJobTitle(gameState),
+		salaryRange,
 		workType,
 		map[bool]string{true: fmt.Sprintf(" (Fixed schedule: %s-%s)", workStart, workEnd), false: ""}[workType == "fixed_time"],
 		map[bool]string{true: "Uses common job scam tactics (pyramid scheme, unpaid training, commission-only, etc.)", false: "Is transparent and fair"}[isTrickery],
@@ -1561,7 +1821,7 @@ Respond in JSON format:
 	}[isTrickery]
 	
 	messages := []Message{
-		{Role: "system", Content: systemMsg},
+		{Role: "system", Content: systemMsg + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
@@ -1569,7 +1829,10 @@ Respond in JSON format:
 	if isTrickery {
 		agentType = "job_offer_trickery"
 	}
-	response, err := c.CallOpenAIWithAgent(agentType, messages)
+	response, err := c.CallOpenAIWithAgent(ctx, agentType, messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		return c.generateFallbackJobOffer(gameState, isTrickery), nil
 	}
@@ -1583,8 +1846,8 @@ Respond in JSON format:
 	if salary < 1000 {
 		salary = 1000
 	}
-	if salary > 10000 {
-		salary = 10000
+	if salary > maxSalary {
+		salary = maxSalary
 	}
 	
 	hours := int(getFloat(offerData, "hours_per_day", 8))
@@ -1639,16 +1902,18 @@ Respond in JSON format:
 		HealthLossPerHour: healthLossPerHour,
 		EnergyLossPerHour: energyLossPerHour,
 		UpfrontCost:       upfrontCost,
-		ExpiresAt:         gameState.CurrentDate.Add(7 * 24 * time.Hour), // Expires in 7 days
+		PayFrequency:      normalizePayFrequency(getString(offerData, "pay_frequency", "")),
+		ExpiresAt:         GetConfig().Offers.Jobs.expiresAt(gameState.CurrentDate),
 		IsTrickery:        isTrickery,
 		Reason:            getString(offerData, "reason", ""),
+		Source:            OfferSourceAI,
 	}
 	
 	return offer, nil
 }
 
 // GenerateApartmentOffer generates an apartment offer using AI (good or trickery)
-func (c *AIClient) GenerateApartmentOffer(gameState *GameState, offerType string) (*ApartmentOffer, error) {
+func (c *AIClient) GenerateApartmentOffer(ctx context.Context, gameState *GameState, offerType string) (*ApartmentOffer, error) {
 	isTrickery := offerType == "trickery"
 	
 	prompt := fmt.Sprintf(`You are a %s apartment rental agent. Create an apartment rental offer that %s.
@@ -1664,6 +1929,7 @@ Create an apartment offer that:
 3. Health gain per hour (1-5 for good, 0-2 for trickery)
 4. Energy gain per hour (2-8 for good, 0-3 for trickery)
 5. %s
+6. Rent frequency: "weekly", "biweekly" or "monthly" (the rent above is still the monthly amount)
 
 Respond in JSON format:
 {
@@ -1672,6 +1938,7 @@ Respond in JSON format:
   "rent": 800.00,
   "health_gain": 3,
   "energy_gain": 5,
+  "rent_frequency": "monthly",
   "reason": "Why this is %s"
 }`, 
 		map[bool]string{true: "trickery", false: "good"}[isTrickery],
@@ -1693,7 +1960,7 @@ Respond in JSON format:
 	}[isTrickery]
 	
 	messages := []Message{
-		{Role: "system", Content: systemMsg},
+		{Role: "system", Content: systemMsg + languageInstruction(gameState)},
 		{Role: "user", Content: prompt},
 	}
 	
@@ -1701,7 +1968,10 @@ Respond in JSON format:
 	if isTrickery {
 		agentType = "apartment_offer_trickery"
 	}
-	response, err := c.CallOpenAIWithAgent(agentType, messages)
+	response, err := c.CallOpenAIWithAgent(ctx, agentType, messages)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
 	if err != nil {
 		return c.generateFallbackApartmentOffer(gameState, isTrickery), nil
 	}
@@ -1743,9 +2013,11 @@ Respond in JSON format:
 		Rent:        rent,
 		HealthGain:  healthGain,
 		EnergyGain:  energyGain,
-		ExpiresAt:   gameState.CurrentDate.Add(7 * 24 * time.Hour), // Expires in 7 days
+		RentFrequency: normalizePayFrequency(getString(offerData, "rent_frequency", "")),
+		ExpiresAt:   GetConfig().Offers.Apartments.expiresAt(gameState.CurrentDate),
 		IsTrickery:  isTrickery,
 		Reason:      getString(offerData, "reason", ""),
+		Source:      OfferSourceAI,
 	}
 	
 	return offer, nil
@@ -1771,14 +2043,15 @@ func (c *AIClient) generateFallbackApartmentOffer(gameState *GameState, isTricke
 	return &ApartmentOffer{
 		ID:          generateID(),
 		Type:        map[bool]string{true: "trickery", false: "good"}[isTrickery],
-		Title:       title,
-		Description: description,
+		Title:       localize(gameState.Language, title),
+		Description: localize(gameState.Language, description),
 		Rent:        rent,
 		HealthGain:  healthGain,
 		EnergyGain:  energyGain,
-		ExpiresAt:   gameState.CurrentDate.Add(7 * 24 * time.Hour),
+		ExpiresAt:   GetConfig().Offers.Apartments.expiresAt(gameState.CurrentDate),
 		IsTrickery:  isTrickery,
-		Reason:      reason,
+		Reason:      localize(gameState.Language, reason),
+		Source:      OfferSourceFallback,
 	}
 }
 
@@ -1787,9 +2060,9 @@ func (c *AIClient) generateFallbackJobOffer(gameState *GameState, isTrickery boo
 	workType := "hourly"
 	workStart := ""
 	workEnd := ""
-	if rand.Float64() < 0.5 {
+	if gameState.rng.Float64() < 0.5 {
 		workType = "fixed_time"
-		startHour := 8 + rand.Intn(3)
+		startHour := 8 + gameState.rng.Intn(3)
 		endHour := startHour + 8
 		workStart = fmt.Sprintf("%02d:00", startHour)
 		workEnd = fmt.Sprintf("%02d:00", endHour)
@@ -1799,8 +2072,8 @@ func (c *AIClient) generateFallbackJobOffer(gameState *GameState, isTrickery boo
 		return &JobOffer{
 			ID:                generateID(),
 			Type:              "trickery",
-			Title:             "Work from Home - Make €10,000/month!",
-			Description:       "No experience needed! Just pay €500 for training materials and start earning immediately! Commission-based only.",
+			Title:             localize(gameState.Language, "Work from Home - Make €10,000/month!"),
+			Description:       localize(gameState.Language, "No experience needed! Just pay €500 for training materials and start earning immediately! Commission-based only."),
 			Salary:            0, // Commission only
 			HoursPerDay:       10,
 			WorkType:          workType,
@@ -1809,17 +2082,22 @@ func (c *AIClient) generateFallbackJobOffer(gameState *GameState, isTrickery boo
 			HealthLossPerHour: 2.5, // High hidden cost
 			EnergyLossPerHour: 4.5, // Very draining
 			UpfrontCost:       500, // Training materials fee
-			ExpiresAt:         gameState.CurrentDate.Add(7 * 24 * time.Hour),
+			ExpiresAt:         GetConfig().Offers.Jobs.expiresAt(gameState.CurrentDate),
 			IsTrickery:        true,
-			Reason:            "This is a scam - requires upfront payment, commission-only (no guaranteed salary), unrealistic promises",
+			Reason:            localize(gameState.Language, "This is a scam - requires upfront payment, commission-only (no guaranteed salary), unrealistic promises"),
+			Source:            OfferSourceFallback,
 		}
 	}
+	salary := 5000.0
+	if gameState.highReputation() {
+		salary = 8000
+	}
 	return &JobOffer{
 		ID:                generateID(),
 		Type:              "good",
-		Title:             "Software Developer",
-		Description:       "Full-time position with benefits. Competitive salary and growth opportunities.",
-		Salary:            5000,
+		Title:             localize(gameState.Language, "Software Developer"),
+		Description:       localize(gameState.Language, "Full-time position with benefits. Competitive salary and growth opportunities."),
+		Salary:            salary,
 		HoursPerDay:       8,
 		WorkType:          workType,
 		WorkStart:         workStart,
@@ -1827,9 +2105,10 @@ func (c *AIClient) generateFallbackJobOffer(gameState *GameState, isTrickery boo
 		HealthLossPerHour: 1.0, // Low for desk job
 		EnergyLossPerHour: 2.0, // Moderate for office work
 		UpfrontCost:       0,   // No upfront cost for legitimate jobs
-		ExpiresAt:         gameState.CurrentDate.Add(7 * 24 * time.Hour),
+		ExpiresAt:         GetConfig().Offers.Jobs.expiresAt(gameState.CurrentDate),
 		IsTrickery:        false,
-		Reason:            "Fair salary, reasonable hours, legitimate opportunity",
+		Reason:            localize(gameState.Language, "Fair salary, reasonable hours, legitimate opportunity"),
+		Source:            OfferSourceFallback,
 	}
 }
 
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// AI is what the game asks of the AI agents. AIClient answers over the configured providers;
+// MockAI answers without a network, e.g. in tests.
+type AI interface {
+	GenerateJobOffer(ctx context.Context, gameState *GameState, offerType string) (*JobOffer, error)
+	GenerateApartmentOffer(ctx context.Context, gameState *GameState, offerType string) (*ApartmentOffer, error)
+	GenerateStockOffer(ctx context.Context, gameState *GameState) (*StockOffer, error)
+	GenerateOtherOffer(ctx context.Context, gameState *GameState) (*Offer, error)
+	GenerateGoodOffer(ctx context.Context, gameState *GameState) (*Offer, error)
+	GenerateTrickeryOffer(ctx context.Context, gameState *GameState) (*Offer, error)
+	ExplainTrickery(ctx context.Context, gameState *GameState, offer *Offer) (string, error)
+	ChatWithGuide(ctx context.Context, gameState *GameState, userMessage string, chatContext string) (*ChatResponse, error)
+	ParseChatForOfferCreation(ctx context.Context, gameState *GameState, userMessage string) (*ChatResponse, error)
+	CompareOffers(ctx context.Context, gameState *GameState, offers []OfferView) (*OfferComparison, error)
+	NarrateDigest(ctx context.Context, gameState *GameState, digest *DailyDigest) (string, error)
+	Probe() error
+}
+
+// MockAI is a deterministic AI for tests and demos. Every request is answered by the built-in
+// fallbacks, which draw from the game's own random source, so with game.seed set the same game
+// gets the same offers. Calls counts the requests by method.
+type MockAI struct {
+	fallback *AIClient
+	mu       sync.Mutex
+	calls    map[string]int
+}
+
+// NewMockAI returns a MockAI that has not been called yet
+func NewMockAI() *MockAI {
+	return &MockAI{fallback: &AIClient{Offline: true}, calls: make(map[string]int)}
+}
+
+// Calls returns how many times method was called
+func (m *MockAI) Calls(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// record counts a call to method
+func (m *MockAI) record(method string) {
+	m.mu.Lock()
+	m.calls[method]++
+	m.mu.Unlock()
+}
+
+func (m *MockAI) GenerateJobOffer(ctx context.Context, gameState *GameState, offerType string) (*JobOffer, error) {
+	m.record("GenerateJobOffer")
+	return m.fallback.GenerateJobOffer(ctx, gameState, offerType)
+}
+
+func (m *MockAI) GenerateApartmentOffer(ctx context.Context, gameState *GameState, offerType string) (*ApartmentOffer, error) {
+	m.record("GenerateApartmentOffer")
+	return m.fallback.GenerateApartmentOffer(ctx, gameState, offerType)
+}
+
+func (m *MockAI) GenerateStockOffer(ctx context.Context, gameState *GameState) (*StockOffer, error) {
+	m.record("GenerateStockOffer")
+	return m.fallback.GenerateStockOffer(ctx, gameState)
+}
+
+func (m *MockAI) GenerateOtherOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
+	m.record("GenerateOtherOffer")
+	return m.fallback.GenerateOtherOffer(ctx, gameState)
+}
+
+func (m *MockAI) GenerateGoodOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
+	m.record("GenerateGoodOffer")
+	return m.fallback.GenerateGoodOffer(ctx, gameState)
+}
+
+func (m *MockAI) GenerateTrickeryOffer(ctx context.Context, gameState *GameState) (*Offer, error) {
+	m.record("GenerateTrickeryOffer")
+	return m.fallback.GenerateTrickeryOffer(ctx, gameState)
+}
+
+func (m *MockAI) ExplainTrickery(ctx context.Context, gameState *GameState, offer *Offer) (string, error) {
+	m.record("ExplainTrickery")
+	return m.fallback.generateFallbackTrickeryLesson(offer), nil
+}
+
+func (m *MockAI) ChatWithGuide(ctx context.Context, gameState *GameState, userMessage string, chatContext string) (*ChatResponse, error) {
+	m.record("ChatWithGuide")
+	return m.fallback.generateContextAwareFallback(gameState, userMessage), nil
+}
+
+func (m *MockAI) ParseChatForOfferCreation(ctx context.Context, gameState *GameState, userMessage string) (*ChatResponse, error) {
+	m.record("ParseChatForOfferCreation")
+	return m.fallback.ParseChatForOfferCreation(ctx, gameState, userMessage)
+}
+
+func (m *MockAI) CompareOffers(ctx context.Context, gameState *GameState, offers []OfferView) (*OfferComparison, error) {
+	m.record("CompareOffers")
+	return m.fallback.generateFallbackComparison(gameState, offers), nil
+}
+
+func (m *MockAI) NarrateDigest(ctx context.Context, gameState *GameState, digest *DailyDigest) (string, error) {
+	m.record("NarrateDigest")
+	return "", nil
+}
+
+func (m *MockAI) Probe() error {
+	m.record("Probe")
+	return nil
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSessionTTL is how long a session token stays valid when auth.token_ttl_hours is not set
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// sessionAuth holds the session signing settings. It is set once at startup by SetupAuth.
+var sessionAuth struct {
+	devMode bool
+	secret  []byte
+	ttl     time.Duration
+}
+
+// sessionContextKey is the request context key for the authenticated player ID
+type sessionContextKey struct{}
+
+// sessionExpiryContextKey is the request context key for when the session token expires
+type sessionExpiryContextKey struct{}
+
+// SetupAuth prepares session signing. Without a configured secret a random one is generated,
+// so tokens stop working after a restart (as do the in-memory games they belong to).
+func SetupAuth(config *Config) error {
+	sessionAuth.devMode = config.Auth.DevMode
+	sessionAuth.ttl = time.Duration(config.Auth.TokenTTLHours) * time.Hour
+	if sessionAuth.ttl <= 0 {
+		sessionAuth.ttl = DefaultSessionTTL
+	}
+	
+	if config.Auth.Secret != "" {
+		sessionAuth.secret = []byte(config.Auth.Secret)
+	} else {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		sessionAuth.secret = secret
+		logInfof("[AUTH] No AUTH_SECRET configured, using a random secret; sessions end on restart")
+	}
+	
+	if sessionAuth.devMode {
+		logWarnf("[AUTH] Dev mode is on: player_id is trusted without a session token")
+	}
+	return nil
+}
+
+// issueSessionToken signs a token for playerID and returns it with its expiry
+func issueSessionToken(playerID string) (string, time.Time) {
+	expiresAt := time.Now().Add(sessionAuth.ttl)
+	payload := base64.RawURLEncoding.EncodeToString([]byte(playerID + "|" + strconv.FormatInt(expiresAt.Unix(), 10)))
+	return payload + "." + signSessionPayload(payload), expiresAt
+}
+
+// signSessionPayload returns the base64url HMAC-SHA256 of an encoded token payload
+func signSessionPayload(payload string) string {
+	mac := hmac.New(sha256.New, sessionAuth.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// verifySessionToken checks the signature and expiry of a token and returns its player ID
+func verifySessionToken(token string) (string, error) {
+	playerID, _, err := parseSessionToken(token)
+	return playerID, err
+}
+
+// parseSessionToken checks the signature and expiry of a token and returns its player ID and
+// when it expires
+func parseSessionToken(token string) (string, time.Time, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(signSessionPayload(payload))) {
+		return "", time.Time{}, errors.New("invalid session token")
+	}
+	
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", time.Time{}, errors.New("invalid session token")
+	}
+	// The player ID may itself contain "|", so the expiry is taken from the last separator
+	sep := strings.LastIndex(string(data), "|")
+	if sep < 0 {
+		return "", time.Time{}, errors.New("invalid session token")
+	}
+	expiresAt, err := strconv.ParseInt(string(data[sep+1:]), 10, 64)
+	if err != nil {
+		return "", time.Time{}, errors.New("invalid session token")
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", time.Time{}, errors.New("session token expired")
+	}
+	return string(data[:sep]), time.Unix(expiresAt, 0), nil
+}
+
+// requestSessionToken reads the token from the Authorization header, or from the token query
+// parameter for WebSocket upgrades, which cannot carry custom headers from the browser
+func requestSessionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return r.URL.Query().Get("token")
+}
+
+// sessionPlayerID returns the authenticated player ID injected by RequireSession
+func sessionPlayerID(r *http.Request) (string, bool) {
+	playerID, ok := r.Context().Value(sessionContextKey{}).(string)
+	return playerID, ok
+}
+
+// sessionExpiry returns when the session token checked by RequireSession expires; ok is false
+// in dev mode, where requests carry no token
+func sessionExpiry(r *http.Request) (time.Time, bool) {
+	expiresAt, ok := r.Context().Value(sessionExpiryContextKey{}).(time.Time)
+	return expiresAt, ok
+}
+
+// RequireSession rejects requests without a valid session token, and requests whose player_id
+// differs from the token's. When player_id is missing it is filled in from the token, so handlers
+// keep reading it from the query. In dev mode requests pass through unchecked.
+func (gm *GameManager) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sessionAuth.devMode {
+			next.ServeHTTP(w, r)
+			return
+		}
+	
+		playerID, expiresAt, err := parseSessionToken(requestSessionToken(r))
+		if err != nil {
+			logDebugf("[AUTH] Rejected %s %s: %v", r.Method, r.URL.Path, err)
+			http.Error(w, "Unauthorized: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+	
+		query := r.URL.Query()
+		if requested := query.Get("player_id"); requested != "" && requested != playerID {
+			logWarnf("[AUTH] Player %s tried to act as %s on %s", playerID, requested, r.URL.Path)
+			http.Error(w, "player_id does not match session", http.StatusForbidden)
+			return
+		}
+		query.Set("player_id", playerID)
+		query.Del("token")
+		r.URL.RawQuery = query.Encode()
+	
+		ctx := context.WithValue(r.Context(), sessionContextKey{}, playerID)
+		ctx = context.WithValue(ctx, sessionExpiryContextKey{}, expiresAt)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// HandleLogin issues a session token for a player ID. An ID that is unclaimed (no game and no
+// earlier login) is claimed by the caller; an ID that is already claimed only gets a fresh token
+// when the request carries a valid token for it, so nobody can take over another player's game.
+func (gm *GameManager) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		PlayerID string `json:"player_id"`
+	}
+	
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PlayerID == "" {
+		if requestTooLarge(w, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "player_id is required"})
+		return
+	}
+	
+	authenticated := false
+	if playerID, err := verifySessionToken(requestSessionToken(r)); err == nil && playerID == req.PlayerID {
+		authenticated = true
+	}
+	
+	_, gameExists := gm.getEntry(req.PlayerID)
+	gm.sessionClaimsMu.Lock()
+	claimed := gameExists || gm.sessionClaims[req.PlayerID]
+	if !claimed {
+		gm.sessionClaims[req.PlayerID] = true
+	}
+	gm.sessionClaimsMu.Unlock()
+	
+	if claimed && !authenticated && !sessionAuth.devMode {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": "player_id is already in use"})
+		return
+	}
+	
+	token, expiresAt := issueSessionToken(req.PlayerID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"player_id":  req.PlayerID,
+		"token":      token,
+		"expires_at": expiresAt,
+	})
+}
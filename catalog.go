@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Where a generated offer came from, in its Source field
+const (
+	OfferSourceAI       = "ai"       // Written by an AI provider
+	OfferSourceFallback = "fallback" // Built in, used when the AI is offline or fails
+	OfferSourceCatalog  = "catalog"  // Drawn from the catalog file (catalog.path)
+)
+
+// OfferCatalog holds curated offers the generators draw from alongside the AI, so a game gets
+// vetted content without an AI call. Each entry says itself whether it is trickery (is_safe for
+// stocks); IDs and expiry dates are set when an entry is drawn.
+type OfferCatalog struct {
+	Jobs       []JobOffer       `json:"jobs"`
+	Apartments []ApartmentOffer `json:"apartments"`
+	Stocks     []StockOffer     `json:"stocks"`
+	Other      []Offer          `json:"other"`
+}
+
+// loadOfferCatalog reads the catalog at path and drops entries whose labels contradict each
+// other, logging each. It returns nil if path is empty or the file cannot be read.
+func loadOfferCatalog(path string) *OfferCatalog {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logErrorf("Could not read the offer catalog %s: %v", path, err)
+		return nil
+	}
+	var catalog OfferCatalog
+	if err := json.Unmarshal(data, &catalog); err != nil {
+		logErrorf("Could not parse the offer catalog %s: %v", path, err)
+		return nil
+	}
+	
+	problems := catalog.normalize()
+	for _, problem := range problems {
+		logErrorf("Skipping offer catalog entry: %s", problem)
+	}
+	logInfof("Loaded offer catalog %s: %d jobs, %d apartments, %d stocks, %d other offers", path,
+		len(catalog.Jobs), len(catalog.Apartments), len(catalog.Stocks), len(catalog.Other))
+	return &catalog
+}
+
+// normalize fills in what the entries leave out and removes the ones that are mislabeled or
+// unusable, returning why each was removed
+func (c *OfferCatalog) normalize() []string {
+	var problems []string
+	label := map[bool]string{true: "trickery", false: "good"}
+	
+	jobs := c.Jobs[:0]
+	for _, job := range c.Jobs {
+		switch {
+		case job.Title == "":
+			problems = append(problems, "job without a title")
+		case job.Type != "" && job.Type != label[job.IsTrickery]:
+			problems = append(problems, fmt.Sprintf("job %q has type %q but is_trickery %v", job.Title, job.Type, job.IsTrickery))
+		case job.WorkType == "fixed_time" && (job.WorkStart == "" || job.WorkEnd == ""):
+			problems = append(problems, fmt.Sprintf("fixed_time job %q has no work_start or work_end", job.Title))
+		default:
+			job.Type = label[job.IsTrickery]
+			if job.WorkType != "fixed_time" {
+				job.WorkType = "hourly"
+			}
+			job.PayFrequency = normalizePayFrequency(job.PayFrequency)
+			jobs = append(jobs, job)
+		}
+	}
+	c.Jobs = jobs
+	
+	apartments := c.Apartments[:0]
+	for _, apartment := range c.Apartments {
+		switch {
+		case apartment.Title == "" || apartment.Rent <= 0:
+			problems = append(problems, fmt.Sprintf("apartment %q needs a title and a positive rent", apartment.Title))
+		case apartment.Type != "" && apartment.Type != label[apartment.IsTrickery]:
+			problems = append(problems, fmt.Sprintf("apartment %q has type %q but is_trickery %v", apartment.Title, apartment.Type, apartment.IsTrickery))
+		default:
+			apartment.Type = label[apartment.IsTrickery]
+			apartment.RentFrequency = normalizePayFrequency(apartment.RentFrequency)
+			apartments = append(apartments, apartment)
+		}
+	}
+	c.Apartments = apartments
+	
+	stocks := c.Stocks[:0]
+	for _, stock := range c.Stocks {
+		stock.Symbol = strings.ToUpper(strings.TrimSpace(stock.Symbol))
+		switch {
+		case stock.Symbol == "" || stock.CurrentPrice <= 0:
+			problems = append(problems, fmt.Sprintf("stock %q needs a symbol and a positive current_price", stock.CompanyName))
+		case stock.IsSafe && stock.Reliability == "low", !stock.IsSafe && stock.Reliability == "high":
+			problems = append(problems, fmt.Sprintf("stock %s has reliability %q but is_safe %v", stock.Symbol, stock.Reliability, stock.IsSafe))
+		default:
+			if stock.Reliability == "" {
+				stock.Reliability = map[bool]string{true: "high", false: "low"}[stock.IsSafe]
+			}
+			if stock.Beta <= 0 {
+				stock.Beta = map[bool]float64{true: 0.8, false: 1.6}[stock.IsSafe]
+			}
+			stocks = append(stocks, stock)
+		}
+	}
+	c.Stocks = stocks
+	
+	other := c.Other[:0]
+	for _, offer := range c.Other {
+		category := normalizeOfferCategory(offer.Category, offer.IsTrickery, offer.IsRecurring)
+		switch {
+		case offer.Title == "" || offer.Price < 0:
+			problems = append(problems, fmt.Sprintf("offer %q needs a title and a price that is not negative", offer.Title))
+		case category == CategoryScam && !offer.IsTrickery:
+			problems = append(problems, fmt.Sprintf("offer %q is a scam but not marked is_trickery", offer.Title))
+		default:
+			offer.Type = "other"
+			offer.Category = category
+			if offer.IsRecurring && offer.RecurrenceType == "" {
+				offer.RecurrenceType = "monthly"
+			}
+			if offer.Category == CategoryPurchase && !offer.IsRecurring {
+				offer.EffectFrequency = normalizeEffectFrequency(offer.EffectFrequency)
+			}
+			other = append(other, offer)
+		}
+	}
+	c.Other = other
+	
+	return problems
+}
+
+// draws reports whether the next offer should come from the catalog rather than the AI,
+// with catalog.share odds. A missing catalog never draws.
+func (c *OfferCatalog) draws(game *GameState) bool {
+	return c != nil && game.rng.Float64() < GetConfig().Catalog.Share
+}
+
+// catalogPick returns the index of a random one of n entries for which keep holds, or -1 if none does
+func catalogPick(game *GameState, n int, keep func(i int) bool) int {
+	var matching []int
+	for i := 0; i < n; i++ {
+		if keep(i) {
+			matching = append(matching, i)
+		}
+	}
+	if len(matching) == 0 {
+		return -1
+	}
+	return matching[game.rng.Intn(len(matching))]
+}
+
+// jobOffer draws a good or trickery job from the catalog for game, or returns nil if it has none
+func (c *OfferCatalog) jobOffer(game *GameState, isTrickery bool) *JobOffer {
+	i := catalogPick(game, len(c.Jobs), func(i int) bool { return c.Jobs[i].IsTrickery == isTrickery })
+	if i < 0 {
+		return nil
+	}
+	offer := c.Jobs[i]
+	offer.ID = generateID()
+	offer.ExpiresAt = GetConfig().Offers.Jobs.expiresAt(game.CurrentDate)
+	offer.Source = OfferSourceCatalog
+	offer.Messages, offer.Negotiation = nil, nil
+	return &offer
+}
+
+// apartmentOffer draws a good or trickery apartment from the catalog for game, or returns nil if it has none
+func (c *OfferCatalog) apartmentOffer(game *GameState, isTrickery bool) *ApartmentOffer {
+	i := catalogPick(game, len(c.Apartments), func(i int) bool { return c.Apartments[i].IsTrickery == isTrickery })
+	if i < 0 {
+		return nil
+	}
+	offer := c.Apartments[i]
+	offer.ID = generateID()
+	offer.ExpiresAt = GetConfig().Offers.Apartments.expiresAt(game.CurrentDate)
+	offer.Source = OfferSourceCatalog
+	offer.Messages, offer.Negotiation = nil, nil
+	return &offer
+}
+
+// stockOffer draws a stock from the catalog for game, safe or unsafe with the difficulty's odds
+// like the AI's, or returns nil if it has none of that kind
+func (c *OfferCatalog) stockOffer(game *GameState) *StockOffer {
+	isSafe := game.rng.Float64() >= game.trickeryChance(game.difficulty().UnsafeStockChance)
+	i := catalogPick(game, len(c.Stocks), func(i int) bool { return c.Stocks[i].IsSafe == isSafe })
+	if i < 0 {
+		return nil
+	}
+	offer := c.Stocks[i]
+	offer.ID = generateID()
+	offer.ExpiresAt = GetConfig().Offers.Stocks.expiresAt(game.CurrentDate)
+	offer.Source = OfferSourceCatalog
+	offer.Messages, offer.Negotiation = nil, nil
+	return &offer
+}
+
+// otherOffer draws an "other" offer from the catalog for game, trickery with the difficulty's
+// odds like the AI's, or returns nil if it has none of that kind
+func (c *OfferCatalog) otherOffer(game *GameState) *Offer {
+	isTrickery := game.rng.Float64() < game.trickeryChance(game.difficulty().OtherTrickeryChance)
+	i := catalogPick(game, len(c.Other), func(i int) bool { return c.Other[i].IsTrickery == isTrickery })
+	if i < 0 {
+		return nil
+	}
+	offer := c.Other[i]
+	offer.ID = generateID()
+	offer.ExpiresAt = GetConfig().Offers.Other.expiresAt(game.CurrentDate)
+	offer.Source = OfferSourceCatalog
+	offer.Messages, offer.Negotiation = nil, nil
+	return &offer
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the real (wall-clock) time. It drives real-time decisions such as offer
+// cooldowns, invite and idempotency expiry, cache timestamps and event timestamps. Game time is
+// GameState.CurrentDate, which only moves through AdvanceTime and never reads a Clock.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the Clock used outside tests
+type realClock struct{}
+
+// Now returns the current time
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// FakeClock is a Clock that only moves when told to, so tests can step past cooldowns and
+// expiries without sleeping
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock standing at now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// now returns the real time by the manager's clock
+func (gm *GameManager) now() time.Time {
+	return gm.clock.Now()
+}
+
+// since returns the real time elapsed since t by the manager's clock
+func (gm *GameManager) since(t time.Time) time.Duration {
+	return gm.clock.Now().Sub(t)
+}
+
+// now returns the real time by the game's clock, or the system time for a game created
+// outside a GameManager
+func (gs *GameState) now() time.Time {
+	if gs.clock == nil {
+		return time.Now()
+	}
+	return gs.clock.Now()
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxComparedOffers caps how many offers one comparison may include
+const maxComparedOffers = 5
+
+// ComparedOffer is the guide's take on one offer in a comparison
+type ComparedOffer struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Pros  []string `json:"pros"`
+	Cons  []string `json:"cons"`
+}
+
+// OfferComparison is the guide's side-by-side look at several offers. Like the guide's chat it
+// does not pick one for the player: the recommendation says what to weigh, and the questions
+// lead them to their own answer.
+type OfferComparison struct {
+	Agent          string          `json:"agent"`
+	Offers         []ComparedOffer `json:"offers"`
+	Recommendation string          `json:"recommendation"`
+	Questions      []string        `json:"questions"`
+}
+
+// findOffers returns the player's offers with the given IDs, in that order, or an error naming
+// the first ID the player does not have
+func (gs *GameState) findOffers(offerIDs []string) ([]OfferView, error) {
+	byID := make(map[string]OfferView)
+	for _, offer := range gs.allOffers() {
+		byID[offer.ID] = offer
+	}
+	offers := make([]OfferView, 0, len(offerIDs))
+	for _, id := range offerIDs {
+		offer, exists := byID[id]
+		if !exists {
+			return nil, &GameError{Message: "Offer not found or expired: " + id}
+		}
+		offers = append(offers, offer)
+	}
+	return offers, nil
+}
+
+// describeOfferForAI returns the offer's terms as JSON without the fields that tell whether it
+// is a scam, so the guide judges it from what the player sees
+func describeOfferForAI(offer OfferView) string {
+	var fields map[string]interface{}
+	data, _ := json.Marshal(offer.Offer)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return offer.Title
+	}
+	for _, hidden := range []string{"is_trickery", "is_safe", "reason", "category", "created_by", "messages"} {
+		delete(fields, hidden)
+	}
+	data, _ = json.Marshal(fields)
+	return string(data)
+}
+
+// CompareOffers asks the guide to weigh two or more offers against each other and the player's
+// situation. If the AI is unavailable or its answer can't be read, it returns a comparison built
+// from the offers' numbers together with the error.
+func (c *AIClient) CompareOffers(ctx context.Context, gameState *GameState, offers []OfferView) (*OfferComparison, error) {
+	var offerList strings.Builder
+	for i, offer := range offers {
+		fmt.Fprintf(&offerList, "%d. [%s] id=%s: %s\n", i+1, offer.OfferType, offer.ID, describeOfferForAI(offer))
+	}
+	jobStatus := "none"
+	if gameState.Job != nil {
+		jobStatus = fmt.Sprintf("%s (€%.2f/month)", gameState.Job.Title, gameState.Job.Salary)
+	}
+	apartmentStatus := "none"
+	if gameState.Apartment != nil {
+		apartmentStatus = fmt.Sprintf("%s (€%.2f/month rent)", gameState.Apartment.Title, gameState.Apartment.Rent)
+	}
+	if len(gameState.Properties) > 0 {
+		apartmentStatus += fmt.Sprintf(", plus %d other rented apartment(s)", len(gameState.Properties))
+	}
+
+	prompt := fmt.Sprintf(`The player wants to compare these offers:
+%s
+PLAYER SITUATION:
+- Money: €%.2f
+- Net worth: €%.2f
+- Health: %d, Energy: %d, Reputation: %d
+- Job: %s
+- Apartment: %s
+- Current date: %s
+
+YOUR TASK:
+Compare the offers for this player. For each offer list its concrete pros and cons (cost against their money, how long they are bound, effects on health/energy/reputation, anything that looks too good to be true). Do NOT tell the player which offer to take or whether any offer is a scam: the recommendation says what matters most in their situation, and 2-3 Socratic questions help them decide for themselves.
+
+Respond ONLY with valid JSON:
+{
+  "offers": [{"id": "offer id", "pros": ["..."], "cons": ["..."]}],
+  "recommendation": "What to weigh, without naming a winner",
+  "questions": ["Question 1?", "Question 2?"]
+}`,
+		offerList.String(),
+		gameState.Money,
+		gameState.NetWorth(),
+		gameState.Health,
+		gameState.Energy,
+		gameState.Reputation,
+		jobStatus,
+		apartmentStatus,
+		gameState.CurrentDate.Format("2006-01-02"))
+
+	messages := []Message{
+		{Role: "system", Content: "You are a Socratic financial literacy guide. You help players compare their options by pointing out trade-offs and asking questions, never by deciding for them." + languageInstruction(gameState)},
+		{Role: "user", Content: prompt},
+	}
+
+	response, err := c.CallOpenAIWithAgent(ctx, "offer_comparison", messages)
+	if err != nil {
+		return c.generateFallbackComparison(gameState, offers), err
+	}
+	if idx := findJSONInResponse(response); idx >= 0 {
+		response = response[idx:]
+		if endIdx := findJSONEnd(response); endIdx > 0 {
+			response = response[:endIdx+1]
+		}
+	}
+	var comparisonData struct {
+		Offers []struct {
+			ID   string   `json:"id"`
+			Pros []string `json:"pros"`
+			Cons []string `json:"cons"`
+		} `json:"offers"`
+		Recommendation string   `json:"recommendation"`
+		Questions      []string `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(response), &comparisonData); err != nil {
+		return c.generateFallbackComparison(gameState, offers), fmt.Errorf("parsing comparison: %w", err)
+	}
+
+	// Keep the player's order and titles, and only the offers they asked about
+	comparison := c.generateFallbackComparison(gameState, offers)
+	for i := range comparison.Offers {
+		for _, compared := range comparisonData.Offers {
+			if compared.ID == comparison.Offers[i].ID && len(compared.Pros)+len(compared.Cons) > 0 {
+				comparison.Offers[i].Pros = compared.Pros
+				comparison.Offers[i].Cons = compared.Cons
+			}
+		}
+	}
+	if comparisonData.Recommendation != "" {
+		comparison.Recommendation = comparisonData.Recommendation
+	}
+	if len(comparisonData.Questions) > 0 {
+		comparison.Questions = comparisonData.Questions
+	}
+	return comparison, nil
+}
+
+// generateFallbackComparison compares the offers' prices against the player's money when the AI
+// is unavailable
+func (c *AIClient) generateFallbackComparison(gameState *GameState, offers []OfferView) *OfferComparison {
+	comparison := &OfferComparison{
+		Agent:          AgentGuide,
+		Recommendation: localize(gameState.Language, "Compare what each offer costs or pays over a whole month against what you have now, and how long it binds you."),
+		Questions: []string{
+			localize(gameState.Language, "Which of these could you still afford if your income stopped next month?"),
+			localize(gameState.Language, "Does any offer promise much more than the others for the same price? Why might that be?"),
+		},
+	}
+	for _, offer := range offers {
+		compared := ComparedOffer{ID: offer.ID, Title: offer.Title, Pros: []string{}, Cons: []string{}}
+		switch offer.OfferType {
+		case "job":
+			compared.Pros = append(compared.Pros, fmt.Sprintf("Pays €%.2f a month", offer.Price))
+		case "apartment":
+			compared.Cons = append(compared.Cons, fmt.Sprintf("Costs €%.2f a month in rent", offer.Price))
+		default:
+			if offer.Price > gameState.Money {
+				compared.Cons = append(compared.Cons, fmt.Sprintf("Costs €%.2f, more than your €%.2f", offer.Price, gameState.Money))
+			} else {
+				compared.Pros = append(compared.Pros, fmt.Sprintf("Costs €%.2f, which you can afford", offer.Price))
+			}
+		}
+		compared.Cons = append(compared.Cons, "Expires "+offer.ExpiresAt.Format("2006-01-02 15:04"))
+		comparison.Offers = append(comparison.Offers, compared)
+	}
+	return comparison
+}
+
+// HandleCompareOffers compares two or more of the player's offers with the guide.
+// Body: {"offer_ids": ["...", "..."]}
+func (gm *GameManager) HandleCompareOffers(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+
+	var req struct {
+		OfferIDs []string `json:"offer_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	seen := make(map[string]bool)
+	for _, id := range req.OfferIDs {
+		seen[id] = true
+	}
+	if len(req.OfferIDs) < 2 || len(req.OfferIDs) > maxComparedOffers || len(seen) != len(req.OfferIDs) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("offer_ids must list 2 to %d different offers", maxComparedOffers)})
+		return
+	}
+
+	// Work on a snapshot so the game is not locked during the AI call
+	game, exists := gm.snapshotGame(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	offers, err := game.findOffers(req.OfferIDs)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+
+	comparison, err := gm.ai.CompareOffers(r.Context(), game, offers)
+	if err != nil {
+		logWarnf("[COMPARE] Comparison failed for player %s, using fallback: %v", playerID, err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
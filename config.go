@@ -2,10 +2,93 @@ package main
 
 import (
 	"encoding/json"
-	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// Scenario sets the starting conditions of a new game
+type Scenario struct {
+	InitialMoney float64 `json:"initial_money"`
+	StartDate    string  `json:"start_date"` // In-game start time, RFC3339
+	Difficulty   string  `json:"difficulty"` // Name in game.difficulties; normal if empty
+}
+
+// Difficulty tunes how often offers are scams, how many of each kind are open at once and how
+// long they stay open
+type Difficulty struct {
+	JobTrickeryChance       float64 `json:"job_trickery_chance"`       // 0-1
+	ApartmentTrickeryChance float64 `json:"apartment_trickery_chance"` // 0-1
+	OtherTrickeryChance     float64 `json:"other_trickery_chance"`     // 0-1
+	UnsafeStockChance       float64 `json:"unsafe_stock_chance"`       // 0-1
+	MaxJobOffers            int     `json:"max_job_offers"`
+	MaxApartmentOffers      int     `json:"max_apartment_offers"`
+	MaxStockOffers          int     `json:"max_stock_offers"`
+	MaxOtherOffers          int     `json:"max_other_offers"`
+	OfferLifetime           float64 `json:"offer_lifetime"` // Multiplier on how long offers stay open; below 1 is shorter
+	// Broker fee on every buy and sale of stocks, crypto and items: flat amount plus a share of the value
+	TradeFeeFlat float64 `json:"trade_fee_flat"` // € per trade
+	TradeFeeRate float64 `json:"trade_fee_rate"` // 0-1
+	// Hospital: admitted at or below the admit health, released at the release health; unset values take the normal preset's
+	HospitalAdmitHealth     int     `json:"hospital_admit_health"`
+	HospitalReleaseHealth   int     `json:"hospital_release_health"`
+	HospitalRecoveryPerHour int     `json:"hospital_recovery_per_hour"`
+	HospitalHourlyCost      float64 `json:"hospital_hourly_cost"` // Before insurance
+	// Victory goals; reaching either one wins the game, 0 disables it
+	GoalNetWorth float64 `json:"goal_net_worth"` // Net worth to reach
+	GoalDays     int     `json:"goal_days"`      // In-game days to survive, ending with positive money
+}
+
+// CryptoCoin is one coin of the crypto market
+type CryptoCoin struct {
+	Symbol     string  `json:"symbol"`
+	Name       string  `json:"name"`
+	BasePrice  float64 `json:"base_price"` // € price networks first see it near
+	Volatility float64 `json:"volatility"` // Size of the daily swing as a fraction, e.g. 0.1 moves up to ±5% a day
+}
+
+// CreditPoint is one point of the reputation to interest curve; reputations between points are
+// interpolated linearly and those beyond the ends take the nearest end's multiplier
+type CreditPoint struct {
+	Reputation int     `json:"reputation"`
+	Multiplier float64 `json:"multiplier"` // On the base interest rate; below 1 is cheaper credit
+}
+
+// OfferCadence sets how often one kind of offer is generated and how long new offers stay open
+type OfferCadence struct {
+	InitialDelaySeconds int     `json:"initial_delay_seconds"` // Wait after startup before the first round
+	MinIntervalSeconds  int     `json:"min_interval_seconds"`  // Real time between rounds is picked at random
+	MaxIntervalSeconds  int     `json:"max_interval_seconds"`  // within min-max
+	ExpiryHours         float64 `json:"expiry_hours"`          // In-game hours a new offer stays open, before the difficulty's offer_lifetime
+}
+
+// nextInterval picks the real time to wait before the next round of offers
+func (c OfferCadence) nextInterval(rng *gameRand) time.Duration {
+	seconds := c.MinIntervalSeconds
+	if c.MaxIntervalSeconds > c.MinIntervalSeconds {
+		seconds += rng.Intn(c.MaxIntervalSeconds - c.MinIntervalSeconds)
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// minGap returns the least real time between two rounds of this kind of offer for one network or
+// player, so extra rounds (such as when a player joins) do not pile up offers
+func (c OfferCadence) minGap() time.Duration {
+	return time.Duration(c.MinIntervalSeconds) * time.Second
+}
+
+// expiresAt returns when an offer created at the in-game time now expires
+func (c OfferCadence) expiresAt(now time.Time) time.Time {
+	return now.Add(time.Duration(c.ExpiryHours * float64(time.Hour)))
+}
+
+// valid reports whether the delay is not negative, the intervals are positive with min below max
+// and the expiry is positive
+func (c OfferCadence) valid() bool {
+	return c.InitialDelaySeconds >= 0 && c.MinIntervalSeconds > 0 && c.MinIntervalSeconds < c.MaxIntervalSeconds && c.ExpiryHours > 0
+}
+
 // Config holds all configuration values
 type Config struct {
 	OpenAI struct {
@@ -18,10 +101,141 @@ type Config struct {
 	} `json:"featherless"`
 	N8N struct {
 		WebhookURL string `json:"webhook_url"`
+		Secret     string `json:"secret"` // Shared secret for X-Signature on webhook requests and responses
+		EventsURL      string   `json:"events_url"`       // Receives significant game events as they happen; disabled if empty
+		EventTypes     []string `json:"event_types"`      // Event types sent to events_url; a built-in list if empty
+		EventQueueSize int      `json:"event_queue_size"` // Events buffered while the receiver is slow; the oldest are dropped beyond this
 	} `json:"n8n"`
 	Server struct {
-		Port string `json:"port"`
+		Port           string   `json:"port"`
+		AllowedOrigins []string `json:"allowed_origins"` // Origins allowed to open a WebSocket and call the API; same-origin only if empty, "*" allows any
+		CORSAllowedMethods []string `json:"cors_allowed_methods"` // Methods allowed origins may use in API requests
+		CORSAllowedHeaders []string `json:"cors_allowed_headers"` // Request headers allowed origins may send
+		WebSocketCompression bool `json:"websocket_compression"` // Offer permessage-deflate to WebSocket clients
+		MaxRequestBytes      int64 `json:"max_request_bytes"`       // Largest API request body; larger ones get 413
+		MaxWebSocketMessageBytes int64 `json:"max_websocket_message_bytes"` // Largest WebSocket message; a larger one closes the connection
 	} `json:"server"`
+	Logging struct {
+		Level  string `json:"level"`  // debug, info, warn or error
+		Format string `json:"format"` // text or json
+	} `json:"logging"`
+	Encryption struct {
+		Enabled bool     `json:"enabled"`  // Serve /api/encrypt and /api/decrypt (requires a key)
+		Key     string   `json:"key"`      // Primary key; new data is always encrypted with it
+		OldKeys []string `json:"old_keys"` // Previous keys, tried in order when decrypting
+	} `json:"encryption"`
+	Auth struct {
+		DevMode       bool   `json:"dev_mode"`        // Trust player_id without a session token (local testing only)
+		Secret        string `json:"secret"`          // HMAC key for session tokens; random per process if empty
+		TokenTTLHours int    `json:"token_ttl_hours"` // How long a session token stays valid
+	} `json:"auth"`
+	Admin struct {
+		Token string `json:"token"` // Enables the /api/admin endpoints for requests carrying it
+	} `json:"admin"`
+	Game struct {
+		Scenario  string              `json:"scenario"`  // Scenario for new games when the request does not pick one
+		Scenarios map[string]Scenario `json:"scenarios"` // Added to, or overriding, the built-in easy/normal/hard presets
+		Difficulties map[string]Difficulty `json:"difficulties"` // Added to, or overriding, the built-in easy/normal/hard tables
+		Seed      int64               `json:"seed"`      // Non-zero makes game randomness reproducible; each game's source is derived from it and the player ID
+		MaxAdvanceHours       float64 `json:"max_advance_hours"`        // Most in-game hours one advance_time action may move the clock
+		AdvanceHoursPerMinute float64 `json:"advance_hours_per_minute"` // In-game hours advance_time, rest and next_day may add per real minute, with up to one minute's worth saved up
+	} `json:"game"`
+	Invites struct {
+		ValidityHours int `json:"validity_hours"` // How long a newly issued invite code is accepted
+		MaxNetworkSize int `json:"max_network_size"` // Most players one invite network may hold; joins past it are rejected
+	} `json:"invites"`
+	History struct {
+		Capacity int    `json:"capacity"`  // Events kept in memory per player; the oldest are dropped
+		AuditLog string `json:"audit_log"` // Optional file that receives every event as JSON lines
+		NetWorthPoints int `json:"net_worth_points"` // Daily net worth samples kept per player; older ones are thinned out beyond this
+	} `json:"history"`
+	Metrics struct {
+		Enabled bool `json:"enabled"` // Expose Prometheus metrics on /metrics
+	} `json:"metrics"`
+	Features struct {
+		TrickeryExplainer bool `json:"trickery_explainer"` // AI lesson after accepting a trickery offer
+		ReadyAIProbe      bool `json:"ready_ai_probe"`     // /readyz also checks that an AI provider is reachable
+		DailyDigestNarration bool `json:"daily_digest_narration"` // The guide narrates the daily digest sent after next_day
+	} `json:"features"`
+	News struct {
+		Enabled     bool    `json:"enabled"`      // Company news moves held stocks
+		AverageDays float64 `json:"average_days"` // Mean in-game days between news about one company
+		MinShock    float64 `json:"min_shock"`    // Smallest price move caused by news, as a fraction
+		MaxShock    float64 `json:"max_shock"`    // Largest price move caused by news, as a fraction
+		HintCost    float64 `json:"hint_cost"`    // Price of a tip about the next news
+	} `json:"news"`
+	Work struct {
+		DaysOffPerMonth int     `json:"days_off_per_month"` // Days off a fixed-time job allows per in-game month
+		DayOffPayShare  float64 `json:"day_off_pay_share"`  // Share of a working day's salary docked for a day off, 0-1
+	} `json:"work"`
+	Catalog struct {
+		Path  string  `json:"path"`  // JSON file of curated offers (see OfferCatalog); empty for none
+		Share float64 `json:"share"` // Chance, 0-1, that a generated offer is drawn from the catalog instead of the AI
+	} `json:"catalog"`
+	Housing struct {
+		MaxApartments int     `json:"max_apartments"` // Apartments a player can rent at once, the one they live in included
+		SubletRate    float64 `json:"sublet_rate"`    // Rent a tenant pays, as a multiple of the rent the player pays for the apartment
+	} `json:"housing"`
+	Agreements struct {
+		SuspendAfterMissedPayments int `json:"suspend_after_missed_payments"` // Unaffordable charges in a row before an agreement is suspended, 0 for never
+	} `json:"agreements"`
+	Insurance struct {
+		MonthlyPremium float64 `json:"monthly_premium"` // Price of health insurance per month
+		Coverage       float64 `json:"coverage"`        // Share of hospital costs it covers, 0-1
+	} `json:"insurance"`
+	Reputation struct {
+		LegitJob      int           `json:"legit_job"`      // Change for accepting a legitimate job
+		ScamJob       int           `json:"scam_job"`       // Change for accepting a trickery job
+		ScamOffer     int           `json:"scam_offer"`     // Change for accepting a trickery apartment or other offer
+		RentPaid      int           `json:"rent_paid"`      // Change for paying rent on time
+		RentMissed    int           `json:"rent_missed"`    // Change for failing to pay rent
+		DayOff        int           `json:"day_off"`        // Change for taking a day off from a fixed-time job
+		QuitLegitJob  int           `json:"quit_legit_job"` // Change for quitting a legitimate job
+		ScamReported  int           `json:"scam_reported"`  // Change for correctly reporting a scam offer
+		FalseReport   int           `json:"false_report"`   // Change for reporting a legitimate offer as a scam
+		LowThreshold  int           `json:"low_threshold"`  // Below this, good job offers often turn out to be scams
+		HighThreshold int           `json:"high_threshold"` // From this on, better paid jobs are offered
+		CreditCurve   []CreditPoint `json:"credit_curve"`   // Interest multiplier by reputation, in ascending reputation order
+	} `json:"reputation"`
+	Reports struct {
+		ScamReward float64 `json:"scam_reward"` // Money for correctly reporting a scam, unless its hint was bought
+	} `json:"reports"`
+	Trickery struct {
+		Adaptive   bool    `json:"adaptive"`    // Adapt the difficulty's scam odds to how well the player spots scams
+		Window     int     `json:"window"`      // Latest scams (accepted or avoided) the odds adapt to
+		MinSamples int     `json:"min_samples"` // Scams a player must have seen before their odds adapt
+		MinFactor  float64 `json:"min_factor"`  // Multiplier on the odds for a player who accepted every recent scam
+		MaxFactor  float64 `json:"max_factor"`  // Multiplier on the odds for a player who avoided every recent scam
+	} `json:"trickery"`
+	OfferValue struct {
+		MinGoodDiscount       float64 `json:"min_good_discount"`        // Least real discount (percent) a good offer gives
+		MaxGoodDiscount       float64 `json:"max_good_discount"`        // Most real discount (percent) a good offer gives; more looks too good to be true
+		MaxGoodPriceShare     float64 `json:"max_good_price_share"`     // Most of the player's money a good offer may cost
+		MinTrickeryPriceShare float64 `json:"min_trickery_price_share"` // Least of the player's money a scam offer costs
+	} `json:"offer_value"`
+	Crypto struct {
+		Coins []CryptoCoin `json:"coins"` // Coins that can be traded; replaces the built-in list
+	} `json:"crypto"`
+	Items struct {
+		DepreciationPerMonth map[string]float64 `json:"depreciation_per_month"` // Share of its value an item loses per in-game month, by category; "other" covers the rest
+	} `json:"items"`
+	Offers struct {
+		Jobs       OfferCadence `json:"jobs"`
+		Apartments OfferCadence `json:"apartments"`
+		Stocks     OfferCadence `json:"stocks"`
+		Other      OfferCadence `json:"other"`
+		RefreshCooldownSeconds int `json:"refresh_cooldown_seconds"` // Real time between a player's manual offer refreshes
+		PlayerOfferCooldownSeconds int `json:"player_offer_cooldown_seconds"` // Real time between offers a player creates by chat
+		MaxPlayerOffers        int `json:"max_player_offers"`        // Offers a player created by chat that may be open at once
+	} `json:"offers"`
+	// Providers is the ordered list of AI endpoints tried on failure.
+	// If empty, it is built from the openai and featherless sections.
+	Providers []Provider `json:"providers"`
+	AI struct {
+		Offline             bool               `json:"offline"`               // Use only the built-in fallbacks; set automatically without providers or API keys
+		TimeoutSeconds      float64            `json:"timeout_seconds"`       // Time one provider gets to answer one AI request
+		AgentTimeoutSeconds map[string]float64 `json:"agent_timeout_seconds"` // Per agent type, e.g. "guide_chat", overriding timeout_seconds
+	} `json:"ai"`
 }
 
 var appConfig *Config
@@ -34,16 +248,84 @@ func LoadConfig() *Config {
 	config.OpenAI.BaseURL = "https://api.openai.com/v1/chat/completions"
 	config.Featherless.BaseURL = "https://api.featherless.ai/v1/chat/completions"
 	config.Server.Port = "8755"
+	config.Server.WebSocketCompression = true
+	config.Server.MaxRequestBytes = 1 << 20
+	config.Server.CORSAllowedMethods = []string{"GET", "POST", "DELETE", "OPTIONS"}
+	config.Server.CORSAllowedHeaders = []string{"Authorization", "Content-Type", "If-None-Match", "X-Admin-Token"}
+	config.Server.MaxWebSocketMessageBytes = 64 << 10
+	config.Logging.Level = "info"
+	config.Logging.Format = "text"
+	config.History.Capacity = DefaultHistoryCapacity
+	config.History.NetWorthPoints = DefaultNetWorthPoints
+	config.Invites.ValidityHours = 72
+	config.Invites.MaxNetworkSize = 50
+	config.N8N.EventQueueSize = DefaultEventQueueSize
+	config.Game.Scenario = "normal"
+	config.Game.Scenarios = defaultScenarios()
+	config.Game.Difficulties = defaultDifficulties()
+	config.Game.MaxAdvanceHours = 2
+	config.Game.AdvanceHoursPerMinute = 30
+	config.Encryption.Enabled = true
+	config.Auth.TokenTTLHours = int(DefaultSessionTTL / time.Hour)
+	config.Features.TrickeryExplainer = true
+	config.Features.DailyDigestNarration = true
+	config.AI.TimeoutSeconds = 30
+	config.AI.AgentTimeoutSeconds = map[string]float64{"chat_offer_parser": 20}
+	config.News.Enabled = true
+	config.News.AverageDays = 20
+	config.News.MinShock = 0.05
+	config.News.MaxShock = 0.25
+	config.News.HintCost = 25
+	config.Insurance.MonthlyPremium = 150
+	config.Insurance.Coverage = 0.8
+	config.Items.DepreciationPerMonth = defaultDepreciationRates()
+	config.Crypto.Coins = defaultCryptoCoins()
+	config.Reputation.LegitJob = 2
+	config.Reputation.ScamJob = -2
+	config.Reputation.ScamOffer = -1
+	config.Reputation.RentPaid = 1
+	config.Reputation.RentMissed = -3
+	config.Reputation.LowThreshold = -5
+	config.Reputation.HighThreshold = 10
+	config.Reputation.DayOff = -1
+	config.Reputation.QuitLegitJob = -1
+	config.Reputation.ScamReported = 2
+	config.Reputation.FalseReport = -3
+	config.Reports.ScamReward = 50
+	config.Reputation.CreditCurve = defaultCreditCurve()
+	config.Work.DaysOffPerMonth = 2
+	config.Work.DayOffPayShare = 0
+	config.Agreements.SuspendAfterMissedPayments = 3
+	config.Housing.MaxApartments = 3
+	config.Housing.SubletRate = 1.2
+	config.Catalog.Share = 0.5
+	config.Trickery.Adaptive = true
+	config.Trickery.Window = 10
+	config.Trickery.MinSamples = 3
+	config.Trickery.MinFactor = 0.5
+	config.Trickery.MaxFactor = 1.5
+	config.OfferValue.MinGoodDiscount = 10
+	config.OfferValue.MaxGoodDiscount = 50
+	config.OfferValue.MaxGoodPriceShare = 0.5
+	config.OfferValue.MinTrickeryPriceShare = 0.05
+	cadences := defaultOfferCadences()
+	config.Offers.Jobs = cadences["jobs"]
+	config.Offers.Apartments = cadences["apartments"]
+	config.Offers.Stocks = cadences["stocks"]
+	config.Offers.Other = cadences["other"]
+	config.Offers.RefreshCooldownSeconds = 120
+	config.Offers.PlayerOfferCooldownSeconds = 60
+	config.Offers.MaxPlayerOffers = 3
 	
 	// Try to load from config.json
 	if data, err := os.ReadFile("config.json"); err == nil {
 		if err := json.Unmarshal(data, config); err != nil {
-			log.Printf("Warning: Could not parse config.json: %v", err)
+			logErrorf("Could not parse config.json: %v", err)
 		} else {
-			log.Println("Loaded configuration from config.json")
+			logInfof("Loaded configuration from config.json")
 		}
 	} else {
-		log.Println("config.json not found, using defaults and environment variables")
+		logInfof("config.json not found, using defaults and environment variables")
 	}
 	
 	// Environment variables override config.json
@@ -62,14 +344,417 @@ func LoadConfig() *Config {
 	if n8nWebhookURL := os.Getenv("N8N_WEBHOOK_URL"); n8nWebhookURL != "" {
 		config.N8N.WebhookURL = n8nWebhookURL
 	}
+	if n8nSecret := os.Getenv("N8N_SECRET"); n8nSecret != "" {
+		config.N8N.Secret = n8nSecret
+	}
+	if eventsURL := os.Getenv("N8N_EVENTS_URL"); eventsURL != "" {
+		config.N8N.EventsURL = eventsURL
+	}
 	if port := os.Getenv("PORT"); port != "" {
 		config.Server.Port = port
 	}
+	if origins := os.Getenv("ALLOWED_ORIGINS"); origins != "" {
+		config.Server.AllowedOrigins = strings.Split(origins, ",")
+	}
+	if compression := os.Getenv("WS_COMPRESSION"); compression != "" {
+		if enabled, err := strconv.ParseBool(compression); err == nil {
+			config.Server.WebSocketCompression = enabled
+		}
+	}
+	if explainer := os.Getenv("TRICKERY_EXPLAINER"); explainer != "" {
+		if enabled, err := strconv.ParseBool(explainer); err == nil {
+			config.Features.TrickeryExplainer = enabled
+		}
+	}
+	if offline := os.Getenv("AI_OFFLINE"); offline != "" {
+		if enabled, err := strconv.ParseBool(offline); err == nil {
+			config.AI.Offline = enabled
+		}
+	}
+	if timeout := os.Getenv("AI_TIMEOUT_SECONDS"); timeout != "" {
+		if n, err := strconv.ParseFloat(timeout, 64); err == nil {
+			config.AI.TimeoutSeconds = n
+		}
+	}
+	if narration := os.Getenv("DAILY_DIGEST_NARRATION"); narration != "" {
+		if enabled, err := strconv.ParseBool(narration); err == nil {
+			config.Features.DailyDigestNarration = enabled
+		}
+	}
+	if news := os.Getenv("NEWS_ENABLED"); news != "" {
+		if enabled, err := strconv.ParseBool(news); err == nil {
+			config.News.Enabled = enabled
+		}
+	}
+	if level := os.Getenv("LOG_LEVEL"); level != "" {
+		config.Logging.Level = level
+	}
+	if format := os.Getenv("LOG_FORMAT"); format != "" {
+		config.Logging.Format = format
+	}
+	if key := os.Getenv("ENCRYPTION_KEY"); key != "" {
+		config.Encryption.Key = key
+	}
+	if oldKeys := os.Getenv("ENCRYPTION_OLD_KEYS"); oldKeys != "" {
+		config.Encryption.OldKeys = strings.Split(oldKeys, ",")
+	}
+	if encryption := os.Getenv("ENCRYPTION_ENABLED"); encryption != "" {
+		if enabled, err := strconv.ParseBool(encryption); err == nil {
+			config.Encryption.Enabled = enabled
+		}
+	}
+	if devMode := os.Getenv("AUTH_DEV_MODE"); devMode != "" {
+		if enabled, err := strconv.ParseBool(devMode); err == nil {
+			config.Auth.DevMode = enabled
+		}
+	}
+	if secret := os.Getenv("AUTH_SECRET"); secret != "" {
+		config.Auth.Secret = secret
+	}
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		config.Admin.Token = adminToken
+	}
+	if ttl := os.Getenv("AUTH_TOKEN_TTL_HOURS"); ttl != "" {
+		if n, err := strconv.Atoi(ttl); err == nil && n > 0 {
+			config.Auth.TokenTTLHours = n
+		}
+	}
+	if scenario := os.Getenv("GAME_SCENARIO"); scenario != "" {
+		config.Game.Scenario = scenario
+	}
+	if hours := os.Getenv("MAX_ADVANCE_HOURS"); hours != "" {
+		if n, err := strconv.ParseFloat(hours, 64); err == nil {
+			config.Game.MaxAdvanceHours = n
+		}
+	}
+	if hours := os.Getenv("ADVANCE_HOURS_PER_MINUTE"); hours != "" {
+		if n, err := strconv.ParseFloat(hours, 64); err == nil {
+			config.Game.AdvanceHoursPerMinute = n
+		}
+	}
+	if path := os.Getenv("OFFER_CATALOG"); path != "" {
+		config.Catalog.Path = path
+	}
+	if seed := os.Getenv("GAME_SEED"); seed != "" {
+		if n, err := strconv.ParseInt(seed, 10, 64); err == nil {
+			config.Game.Seed = n
+		}
+	}
+	if validity := os.Getenv("INVITE_VALIDITY_HOURS"); validity != "" {
+		if n, err := strconv.Atoi(validity); err == nil && n > 0 {
+			config.Invites.ValidityHours = n
+		}
+	}
+	if size := os.Getenv("MAX_NETWORK_SIZE"); size != "" {
+		if n, err := strconv.Atoi(size); err == nil {
+			config.Invites.MaxNetworkSize = n
+		}
+	}
+	if capacity := os.Getenv("HISTORY_CAPACITY"); capacity != "" {
+		if n, err := strconv.Atoi(capacity); err == nil && n > 0 {
+			config.History.Capacity = n
+		}
+	}
+	if auditLog := os.Getenv("HISTORY_AUDIT_LOG"); auditLog != "" {
+		config.History.AuditLog = auditLog
+	}
+	if metrics := os.Getenv("METRICS_ENABLED"); metrics != "" {
+		if enabled, err := strconv.ParseBool(metrics); err == nil {
+			config.Metrics.Enabled = enabled
+		}
+	}
+	if probe := os.Getenv("READY_AI_PROBE"); probe != "" {
+		if enabled, err := strconv.ParseBool(probe); err == nil {
+			config.Features.ReadyAIProbe = enabled
+		}
+	}
+	
+	if len(config.Providers) == 0 {
+		// Without any key the default providers cannot answer, so don't wait for them to fail
+		if config.OpenAI.APIKey == "" && config.Featherless.APIKey == "" && !config.AI.Offline {
+			logWarnf("No OpenAI or Featherless API key configured, running the AI offline")
+			config.AI.Offline = true
+		}
+		config.Providers = defaultProviders(config)
+	}
+	validateScenarios(config)
+	validateOfferCadences(config)
+	if config.Server.MaxRequestBytes <= 0 || config.Server.MaxWebSocketMessageBytes <= 0 {
+		logErrorf("Ignoring server.max_request_bytes and max_websocket_message_bytes: they must be positive")
+		config.Server.MaxRequestBytes = 1 << 20
+		config.Server.MaxWebSocketMessageBytes = 64 << 10
+	}
+	if config.AI.TimeoutSeconds <= 0 {
+		logErrorf("Ignoring ai.timeout_seconds: it must be positive")
+		config.AI.TimeoutSeconds = 30
+	}
+	for agentType, seconds := range config.AI.AgentTimeoutSeconds {
+		if seconds <= 0 {
+			logErrorf("Ignoring ai.agent_timeout_seconds for %s: it must be positive", agentType)
+			delete(config.AI.AgentTimeoutSeconds, agentType)
+		}
+	}
+	if config.Offers.PlayerOfferCooldownSeconds < 0 || config.Offers.MaxPlayerOffers < 1 {
+		logErrorf("Ignoring offers.player_offer_cooldown_seconds and offers.max_player_offers: the cooldown must not be negative and at least one offer must be allowed")
+		config.Offers.PlayerOfferCooldownSeconds = 60
+		config.Offers.MaxPlayerOffers = 3
+	}
+	if config.Insurance.Coverage < 0 || config.Insurance.Coverage > 1 || config.Insurance.MonthlyPremium < 0 {
+		logErrorf("Ignoring insurance settings: coverage must be within 0-1 and monthly_premium not negative")
+		config.Insurance.MonthlyPremium = 150
+		config.Insurance.Coverage = 0.8
+	}
+	if config.Game.MaxAdvanceHours <= 0 || config.Game.AdvanceHoursPerMinute < max(config.Game.MaxAdvanceHours, 24) {
+		logErrorf("Ignoring advance_time limits: max_advance_hours must be positive and advance_hours_per_minute at least as large, and at least 24 for next_day")
+		config.Game.MaxAdvanceHours = 2
+		config.Game.AdvanceHoursPerMinute = 30
+	}
+	if !validCryptoCoins(config.Crypto.Coins) {
+		logErrorf("Ignoring crypto.coins: every coin needs a unique symbol, a positive base_price and a volatility within 0-1")
+		config.Crypto.Coins = defaultCryptoCoins()
+	}
+	if !validDepreciationRates(config.Items.DepreciationPerMonth) {
+		logErrorf("Ignoring items.depreciation_per_month: every rate must be within 0-1")
+		config.Items.DepreciationPerMonth = defaultDepreciationRates()
+	}
+	if config.Invites.MaxNetworkSize < 1 {
+		logErrorf("Ignoring invites.max_network_size: a network must hold at least its first player")
+		config.Invites.MaxNetworkSize = 50
+	}
+	if config.History.NetWorthPoints < 2 {
+		logErrorf("Ignoring history.net_worth_points: at least 2 samples must be kept")
+		config.History.NetWorthPoints = DefaultNetWorthPoints
+	}
+	if config.Trickery.Window < 1 || config.Trickery.MinSamples < 1 || config.Trickery.MinSamples > config.Trickery.Window {
+		logErrorf("Ignoring trickery.window and min_samples: need 1 <= min_samples <= window")
+		config.Trickery.Window = 10
+		config.Trickery.MinSamples = 3
+	}
+	if config.Trickery.MinFactor < 0 || config.Trickery.MinFactor > 1 || config.Trickery.MaxFactor < 1 {
+		logErrorf("Ignoring trickery factors: need 0 <= min_factor <= 1 <= max_factor")
+		config.Trickery.MinFactor = 0.5
+		config.Trickery.MaxFactor = 1.5
+	}
+	if config.Work.DaysOffPerMonth < 0 || config.Work.DayOffPayShare < 0 || config.Work.DayOffPayShare > 1 {
+		logErrorf("Ignoring work settings: days_off_per_month must not be negative and day_off_pay_share must be within 0-1")
+		config.Work.DaysOffPerMonth = 2
+		config.Work.DayOffPayShare = 0
+	}
+	if config.Catalog.Share < 0 || config.Catalog.Share > 1 {
+		logErrorf("Ignoring catalog.share: must be within 0-1")
+		config.Catalog.Share = 0.5
+	}
+	if config.Housing.MaxApartments < 1 || config.Housing.SubletRate < 0 {
+		logErrorf("Ignoring housing settings: max_apartments must be at least 1 and sublet_rate must not be negative")
+		config.Housing.MaxApartments = 3
+		config.Housing.SubletRate = 1.2
+	}
+	if config.Agreements.SuspendAfterMissedPayments < 0 {
+		logErrorf("Ignoring agreements.suspend_after_missed_payments: must not be negative")
+		config.Agreements.SuspendAfterMissedPayments = 3
+	}
+	if ov := config.OfferValue; ov.MinGoodDiscount < 0 || ov.MinGoodDiscount > ov.MaxGoodDiscount || ov.MaxGoodDiscount >= 100 ||
+		ov.MaxGoodPriceShare <= 0 || ov.MaxGoodPriceShare > 1 || ov.MinTrickeryPriceShare < 0 || ov.MinTrickeryPriceShare > 1 {
+		logErrorf("Ignoring offer_value: need 0 <= min_good_discount <= max_good_discount < 100 and price shares within 0-1")
+		config.OfferValue.MinGoodDiscount = 10
+		config.OfferValue.MaxGoodDiscount = 50
+		config.OfferValue.MaxGoodPriceShare = 0.5
+		config.OfferValue.MinTrickeryPriceShare = 0.05
+	}
+	if config.Reputation.LowThreshold >= config.Reputation.HighThreshold {
+		logErrorf("Ignoring reputation thresholds: low_threshold must be below high_threshold")
+		config.Reputation.LowThreshold = -5
+		config.Reputation.HighThreshold = 10
+	}
+	if config.Reports.ScamReward < 0 {
+		logErrorf("Ignoring reports.scam_reward: must not be negative")
+		config.Reports.ScamReward = 50
+	}
+	if !validCreditCurve(config.Reputation.CreditCurve) {
+		logErrorf("Ignoring reputation.credit_curve: need at least one point, reputations in ascending order and positive multipliers")
+		config.Reputation.CreditCurve = defaultCreditCurve()
+	}
 	
 	appConfig = config
 	return config
 }
 
+// defaultProviders builds the OpenAI -> Featherless fallback chain from the legacy config sections
+func defaultProviders(config *Config) []Provider {
+	return []Provider{
+		{
+			Name:       "openai",
+			BaseURL:    config.OpenAI.BaseURL,
+			APIKey:     config.OpenAI.APIKey,
+			Model:      "gpt-3.5-turbo",
+			FailoverOn: []string{"insufficient_quota", "quota"},
+		},
+		{
+			Name:    "featherless",
+			BaseURL: config.Featherless.BaseURL,
+			APIKey:  config.Featherless.APIKey,
+			Model:   "meta-llama/Meta-Llama-3.1-8B-Instruct",
+		},
+	}
+}
+
+// validCryptoCoins reports whether the catalog is not empty and every coin has a unique symbol, a
+// positive base price and a volatility within 0-1
+func validCryptoCoins(coins []CryptoCoin) bool {
+	seen := map[string]bool{}
+	for _, coin := range coins {
+		if coin.Symbol == "" || seen[coin.Symbol] || !(coin.BasePrice > 0) || !(coin.Volatility >= 0 && coin.Volatility <= 1) {
+			return false
+		}
+		seen[coin.Symbol] = true
+	}
+	return len(coins) > 0
+}
+
+// validDepreciationRates reports whether every rate is within 0-1
+func validDepreciationRates(rates map[string]float64) bool {
+	for _, rate := range rates {
+		if !(rate >= 0 && rate <= 1) {
+			return false
+		}
+	}
+	return true
+}
+
+// defaultScenarios returns the built-in presets; "normal" matches the original game balance
+func defaultScenarios() map[string]Scenario {
+	return map[string]Scenario{
+		"easy":   {InitialMoney: 25000, StartDate: "2000-01-02T08:00:00Z", Difficulty: "easy"},
+		"normal": {InitialMoney: 10000, StartDate: "2000-01-02T08:00:00Z", Difficulty: "normal"},
+		"hard":   {InitialMoney: 3000, StartDate: "2000-01-02T08:00:00Z", Difficulty: "hard"},
+	}
+}
+
+// defaultDifficulties returns the built-in difficulty tables; "normal" matches the original game balance
+func defaultDifficulties() map[string]Difficulty {
+	return map[string]Difficulty{
+		"easy": {
+			JobTrickeryChance: 0.15, ApartmentTrickeryChance: 0.15, OtherTrickeryChance: 0.3, UnsafeStockChance: 0.3,
+			MaxJobOffers: 7, MaxApartmentOffers: 7, MaxStockOffers: 6, MaxOtherOffers: 5,
+			OfferLifetime: 1.5,
+			TradeFeeFlat: 0, TradeFeeRate: 0.005,
+			HospitalAdmitHealth: 0, HospitalReleaseHealth: 20, HospitalRecoveryPerHour: 2, HospitalHourlyCost: 75,
+			GoalNetWorth: 30000, GoalDays: 180,
+		},
+		"normal": {
+			JobTrickeryChance: 0.3, ApartmentTrickeryChance: 0.3, OtherTrickeryChance: 0.5, UnsafeStockChance: 0.5,
+			MaxJobOffers: 7, MaxApartmentOffers: 7, MaxStockOffers: 6, MaxOtherOffers: 5,
+			OfferLifetime: 1,
+			TradeFeeFlat: 1, TradeFeeRate: 0.01,
+			HospitalAdmitHealth: 0, HospitalReleaseHealth: 20, HospitalRecoveryPerHour: 1, HospitalHourlyCost: 100,
+			GoalNetWorth: 50000, GoalDays: 365,
+		},
+		"hard": {
+			JobTrickeryChance: 0.45, ApartmentTrickeryChance: 0.45, OtherTrickeryChance: 0.7, UnsafeStockChance: 0.65,
+			MaxJobOffers: 5, MaxApartmentOffers: 5, MaxStockOffers: 4, MaxOtherOffers: 4,
+			OfferLifetime: 0.5,
+			TradeFeeFlat: 2, TradeFeeRate: 0.02,
+			HospitalAdmitHealth: 0, HospitalReleaseHealth: 30, HospitalRecoveryPerHour: 1, HospitalHourlyCost: 150,
+			GoalNetWorth: 100000, GoalDays: 730,
+		},
+	}
+}
+
+// valid reports whether every chance and the fee rate are within 0-1, the caps and lifetime are
+// positive and the goals and flat fee are not negative
+func (d Difficulty) valid() bool {
+	for _, chance := range []float64{d.JobTrickeryChance, d.ApartmentTrickeryChance, d.OtherTrickeryChance, d.UnsafeStockChance, d.TradeFeeRate} {
+		if chance < 0 || chance > 1 {
+			return false
+		}
+	}
+	return d.MaxJobOffers > 0 && d.MaxApartmentOffers > 0 && d.MaxStockOffers > 0 && d.MaxOtherOffers > 0 && d.OfferLifetime > 0 &&
+		d.GoalNetWorth >= 0 && d.GoalDays >= 0 && d.TradeFeeFlat >= 0 &&
+		d.HospitalAdmitHealth >= 0 && d.HospitalReleaseHealth > d.HospitalAdmitHealth && d.HospitalReleaseHealth <= 100 &&
+		d.HospitalRecoveryPerHour > 0 && d.HospitalHourlyCost >= 0
+}
+
+// withHospitalDefaults fills the hospital settings a custom difficulty leaves unset from normal
+func (d Difficulty) withHospitalDefaults() Difficulty {
+	normal := defaultDifficulties()["normal"]
+	if d.HospitalReleaseHealth == 0 {
+		d.HospitalReleaseHealth = normal.HospitalReleaseHealth
+	}
+	if d.HospitalRecoveryPerHour == 0 {
+		d.HospitalRecoveryPerHour = normal.HospitalRecoveryPerHour
+	}
+	if d.HospitalHourlyCost == 0 {
+		d.HospitalHourlyCost = normal.HospitalHourlyCost
+	}
+	return d
+}
+
+// validateScenarios drops invalid difficulties and scenarios with an unparsable start date or
+// negative money, and makes sure the default scenario and the normal difficulty exist
+func validateScenarios(config *Config) {
+	for name, difficulty := range config.Game.Difficulties {
+		difficulty = difficulty.withHospitalDefaults()
+		if !difficulty.valid() {
+			logErrorf("Ignoring difficulty %q: chances and trade_fee_rate must be within 0-1, caps, offer_lifetime and hospital recovery positive, hospital release health above admit health", name)
+			delete(config.Game.Difficulties, name)
+			continue
+		}
+		config.Game.Difficulties[name] = difficulty
+	}
+	if _, exists := config.Game.Difficulties["normal"]; !exists {
+		config.Game.Difficulties["normal"] = defaultDifficulties()["normal"]
+	}
+	
+	for name, scenario := range config.Game.Scenarios {
+		if _, err := time.Parse(time.RFC3339, scenario.StartDate); err != nil || scenario.InitialMoney < 0 {
+			logErrorf("Ignoring scenario %q: start_date must be RFC3339 and initial_money non-negative", name)
+			delete(config.Game.Scenarios, name)
+			continue
+		}
+		if _, exists := config.Game.Difficulties[scenario.Difficulty]; !exists {
+			if scenario.Difficulty != "" {
+				logWarnf("Scenario %q uses unknown difficulty %q, using normal", name, scenario.Difficulty)
+			}
+			scenario.Difficulty = "normal"
+			config.Game.Scenarios[name] = scenario
+		}
+	}
+	if _, exists := config.Game.Scenarios[config.Game.Scenario]; !exists {
+		logWarnf("Unknown default scenario %q, using normal", config.Game.Scenario)
+		config.Game.Scenario = "normal"
+		if _, exists := config.Game.Scenarios["normal"]; !exists {
+			config.Game.Scenarios["normal"] = defaultScenarios()["normal"]
+		}
+	}
+}
+
+// defaultOfferCadences returns the built-in offer timings, keyed by their name under offers
+func defaultOfferCadences() map[string]OfferCadence {
+	return map[string]OfferCadence{
+		"jobs":       {InitialDelaySeconds: 0, MinIntervalSeconds: 30, MaxIntervalSeconds: 90, ExpiryHours: 7 * 24},
+		"apartments": {InitialDelaySeconds: 20, MinIntervalSeconds: 45, MaxIntervalSeconds: 120, ExpiryHours: 7 * 24},
+		"stocks":     {InitialDelaySeconds: 35, MinIntervalSeconds: 50, MaxIntervalSeconds: 130, ExpiryHours: 7 * 24},
+		"other":      {InitialDelaySeconds: 25, MinIntervalSeconds: 60, MaxIntervalSeconds: 150, ExpiryHours: 3 * 24},
+	}
+}
+
+// validateOfferCadences puts back the default timing of any offer kind whose settings are invalid
+func validateOfferCadences(config *Config) {
+	defaults := defaultOfferCadences()
+	cadences := map[string]*OfferCadence{
+		"jobs":       &config.Offers.Jobs,
+		"apartments": &config.Offers.Apartments,
+		"stocks":     &config.Offers.Stocks,
+		"other":      &config.Offers.Other,
+	}
+	for name, cadence := range cadences {
+		if !cadence.valid() {
+			logErrorf("Ignoring offers.%s: intervals must be positive with min below max, expiry_hours positive and initial_delay_seconds not negative", name)
+			*cadence = defaults[name]
+		}
+	}
+}
+
 // GetConfig returns the loaded configuration
 func GetConfig() *Config {
 	if appConfig == nil {
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// corsExposedHeaders are response headers browser clients on other origins may read
+const corsExposedHeaders = "ETag, X-History-Total, Content-Disposition"
+
+// originAllowed reports whether a browser page from origin may use the server: one of the
+// configured allowed origins, or only the server's own host when none are configured. "*" must
+// be listed explicitly to allow any origin.
+func originAllowed(origin string, host string) bool {
+	allowed := GetConfig().Server.AllowedOrigins
+	if len(allowed) == 0 {
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, host)
+	}
+	for _, candidate := range allowed {
+		candidate = strings.TrimSuffix(strings.TrimSpace(candidate), "/")
+		if candidate == "*" || strings.EqualFold(candidate, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowCORS lets browser pages from the allowed origins (the same list as WebSocket upgrades)
+// call the API. It answers preflight requests itself with server.cors_allowed_methods and
+// cors_allowed_headers, refusing them with 403 for other origins. Requests without an Origin
+// header, or from another origin, pass through without CORS headers.
+func AllowCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Origin")
+		if !originAllowed(origin, r.Host) {
+			if preflight {
+				logWarnf("[CORS] Rejected preflight from origin %s", origin)
+				http.Error(w, "Origin not allowed", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		if !preflight {
+			w.Header().Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+			return
+		}
+		server := GetConfig().Server
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(server.CORSAllowedMethods, ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(server.CORSAllowedHeaders, ", "))
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAllowCORS(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Server.AllowedOrigins = []string{"https://app.example.com"}
+		config.Server.CORSAllowedMethods = []string{"GET", "POST"}
+		config.Server.CORSAllowedHeaders = []string{"Authorization", "Content-Type"}
+	})
+	reached := false
+	handler := AllowCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	serve := func(method string, origin string, preflight bool) *httptest.ResponseRecorder {
+		reached = false
+		req := httptest.NewRequest(method, "http://game.example.com/api/state", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	
+	t.Run("preflight from an allowed origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://app.example.com", true)
+		if rec.Code != http.StatusNoContent || reached {
+			t.Fatalf("got status %d (handler reached: %v), want 204 answered by the middleware", rec.Code, reached)
+		}
+		want := map[string]string{
+			"Access-Control-Allow-Origin":  "https://app.example.com",
+			"Access-Control-Allow-Methods": "GET, POST",
+			"Access-Control-Allow-Headers": "Authorization, Content-Type",
+			"Vary":                         "Origin",
+		}
+		for header, value := range want {
+			if got := rec.Header().Get(header); got != value {
+				t.Errorf("%s: got %q, want %q", header, got, value)
+			}
+		}
+	})
+	
+	t.Run("preflight from another origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "https://evil.example.com", true)
+		if rec.Code != http.StatusForbidden || reached {
+			t.Fatalf("got status %d (handler reached: %v), want 403", rec.Code, reached)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin: got %q, want none", got)
+		}
+	})
+	
+	t.Run("request from an allowed origin", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://app.example.com", false)
+		if rec.Code != http.StatusOK || !reached {
+			t.Fatalf("got status %d (handler reached: %v), want the handler's 200", rec.Code, reached)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+			t.Errorf("Access-Control-Allow-Origin: got %q, want the origin", got)
+		}
+		if got := rec.Header().Get("Access-Control-Expose-Headers"); got != corsExposedHeaders {
+			t.Errorf("Access-Control-Expose-Headers: got %q, want %q", got, corsExposedHeaders)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Methods"); got != "" {
+			t.Errorf("Access-Control-Allow-Methods: got %q on a non-preflight response, want none", got)
+		}
+	})
+	
+	t.Run("request from another origin", func(t *testing.T) {
+		rec := serve(http.MethodGet, "https://evil.example.com", false)
+		if !reached {
+			t.Fatal("the handler was not reached; the browser, not the server, blocks the response")
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin: got %q, want none", got)
+		}
+	})
+	
+	t.Run("request without an origin", func(t *testing.T) {
+		rec := serve(http.MethodGet, "", false)
+		if !reached || rec.Header().Get("Access-Control-Allow-Origin") != "" || rec.Header().Get("Vary") != "" {
+			t.Errorf("got headers %v (handler reached: %v), want a plain pass-through", rec.Header(), reached)
+		}
+	})
+}
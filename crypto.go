@@ -10,25 +10,65 @@ import (
 	"io"
 )
 
-// Encryption key - in production, this should be stored securely
-// For this game, we use a constant key as requested
-const encryptionKey = "PlanC-Economic-Game-2025-Encryption-Key-32Bytes!"
+// encryptionKeys holds the derived AES keys: the primary key first, followed by the old keys
+// in the order Decrypt tries them. It is set once at startup by SetupEncryption.
+var encryptionKeys [][]byte
 
-// getKey derives a 32-byte key from the constant encryption key
-func getKey() []byte {
-	hash := sha256.Sum256([]byte(encryptionKey))
+// SetupEncryption derives the AES keys from the configured encryption key and old keys.
+// It fails if the encryption endpoints are enabled but no key is configured.
+func SetupEncryption(config *Config) error {
+	if !config.Encryption.Enabled {
+		return nil
+	}
+	if config.Encryption.Key == "" {
+		return errors.New("encryption is enabled but no key is configured (set ENCRYPTION_KEY, or ENCRYPTION_ENABLED=false to disable the endpoints)")
+	}
+
+	keys := [][]byte{deriveKey(config.Encryption.Key)}
+	for _, oldKey := range config.Encryption.OldKeys {
+		if oldKey != "" {
+			keys = append(keys, deriveKey(oldKey))
+		}
+	}
+	encryptionKeys = keys
+	return nil
+}
+
+// deriveKey derives a 32-byte AES-256 key from a configured key
+func deriveKey(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
 	return hash[:]
 }
 
-// Encrypt encrypts data using AES-256-GCM
-func Encrypt(plaintext string) (string, error) {
-	key := getKey()
+// newGCM creates an AES-256-GCM cipher for a derived key
+func newGCM(key []byte) (cipher.AEAD, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt encrypts data using AES-256-GCM with the primary key
+func Encrypt(plaintext string) (string, error) {
+	return encryptWithContext(plaintext, nil)
+}
+
+// Decrypt decrypts data using AES-256-GCM, trying the primary key first and then each old key,
+// so data encrypted before a key rotation still opens
+func Decrypt(ciphertext string) (string, error) {
+	return decryptWithContext(ciphertext, nil)
+}
+
+// encryptWithContext encrypts like Encrypt and binds the ciphertext to context (GCM additional
+// data), so it only opens with the same context. Save exports use this to keep blobs made with
+// the public /api/encrypt from passing as saves.
+func encryptWithContext(plaintext string, context []byte) (string, error) {
+	if len(encryptionKeys) == 0 {
+		return "", errors.New("encryption is not configured")
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	gcm, err := newGCM(encryptionKeys[0])
 	if err != nil {
 		return "", err
 	}
@@ -38,21 +78,14 @@ func Encrypt(plaintext string) (string, error) {
 		return "", err
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), context)
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts data using AES-256-GCM
-func Decrypt(ciphertext string) (string, error) {
-	key := getKey()
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", err
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
+// decryptWithContext decrypts data sealed by encryptWithContext with the same context
+func decryptWithContext(ciphertext string, context []byte) (string, error) {
+	if len(encryptionKeys) == 0 {
+		return "", errors.New("encryption is not configured")
 	}
 
 	data, err := base64.StdEncoding.DecodeString(ciphertext)
@@ -60,16 +93,21 @@ func Decrypt(ciphertext string) (string, error) {
 		return "", err
 	}
 
-	if len(data) < gcm.NonceSize() {
-		return "", errors.New("ciphertext too short")
-	}
+	for _, key := range encryptionKeys {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return "", err
+		}
 
-	nonce, ciphertextBytes := data[:gcm.NonceSize()], data[gcm.NonceSize():]
-	plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, nil)
-	if err != nil {
-		return "", err
+		if len(data) < gcm.NonceSize() {
+			return "", errors.New("ciphertext too short")
+		}
+
+		nonce, ciphertextBytes := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+		if plaintext, err := gcm.Open(nil, nonce, ciphertextBytes, context); err == nil {
+			return string(plaintext), nil
+		}
 	}
 
-	return string(plaintext), nil
+	return "", errors.New("ciphertext does not match any configured key")
 }
-
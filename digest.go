@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DailyDigest recaps one in-game day from the events added during it: money in and out per
+// event type, how money and net worth changed and how held stocks and coins moved
+type DailyDigest struct {
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	MoneyStart    float64            `json:"money_start"`
+	MoneyEnd      float64            `json:"money_end"`
+	NetWorthStart float64            `json:"net_worth_start"`
+	NetWorthEnd   float64            `json:"net_worth_end"`
+	Income        float64            `json:"income"`   // Sum of the day's positive event amounts
+	Spending      float64            `json:"spending"` // Sum of the day's negative event amounts, as a positive number
+	Totals        map[string]float64 `json:"totals"`   // Money moved per event type, e.g. "salary" or "rent"
+	Events        []Event            `json:"events"`
+	PriceMoves    []PriceMove        `json:"price_moves"`
+	Narrative     string             `json:"narrative,omitempty"` // The guide's recap, when daily_digest_narration is on
+}
+
+// PriceMove is how the price of a held stock or coin changed over the day
+type PriceMove struct {
+	Symbol        string  `json:"symbol"`
+	Kind          string  `json:"kind"` // "stock" or "crypto"
+	From          float64 `json:"from"`
+	To            float64 `json:"to"`
+	ChangePercent float64 `json:"change_percent"`
+}
+
+// digestStart is what a digest compares the end of the day with
+type digestStart struct {
+	from     time.Time
+	money    float64
+	netWorth float64
+	events   int // History.Total() at the start
+	stocks   map[string]float64
+	crypto   map[string]float64
+}
+
+// startDigest records the game before a day passes
+func (gs *GameState) startDigest() digestStart {
+	start := digestStart{
+		from:     gs.CurrentDate,
+		money:    gs.Money,
+		netWorth: gs.NetWorth(),
+		events:   gs.History.Total(),
+		stocks:   make(map[string]float64),
+		crypto:   make(map[string]float64),
+	}
+	for _, stock := range gs.Stocks {
+		start.stocks[stock.Symbol] = stock.CurrentPrice
+	}
+	for _, crypto := range gs.Crypto {
+		start.crypto[crypto.Symbol] = crypto.CurrentPrice
+	}
+	return start
+}
+
+// finishDigest builds the digest of everything that happened since start. Events the bounded
+// history already dropped are missing from it.
+func (gs *GameState) finishDigest(start digestStart) *DailyDigest {
+	digest := &DailyDigest{
+		From:          start.from,
+		To:            gs.CurrentDate,
+		MoneyStart:    start.money,
+		MoneyEnd:      gs.Money,
+		NetWorthStart: start.netWorth,
+		NetWorthEnd:   gs.NetWorth(),
+		Totals:        make(map[string]float64),
+		Events:        gs.History.Last(gs.History.Total() - start.events),
+		PriceMoves:    []PriceMove{},
+	}
+	for _, event := range digest.Events {
+		if event.Amount > 0 {
+			digest.Income += event.Amount
+		} else {
+			digest.Spending -= event.Amount
+		}
+		if event.Amount != 0 {
+			digest.Totals[event.Type] += event.Amount
+		}
+	}
+
+	addMove := func(symbol, kind string, from, to float64) {
+		move := PriceMove{Symbol: symbol, Kind: kind, From: from, To: to}
+		if from > 0 {
+			move.ChangePercent = (to - from) / from * 100
+		}
+		digest.PriceMoves = append(digest.PriceMoves, move)
+	}
+	for _, stock := range gs.Stocks {
+		if from, held := start.stocks[stock.Symbol]; held {
+			addMove(stock.Symbol, "stock", from, stock.CurrentPrice)
+			delete(start.stocks, stock.Symbol) // Once per symbol, however many positions
+		}
+	}
+	for _, crypto := range gs.Crypto {
+		if from, held := start.crypto[crypto.Symbol]; held {
+			addMove(crypto.Symbol, "crypto", from, crypto.CurrentPrice)
+			delete(start.crypto, crypto.Symbol)
+		}
+	}
+	return digest
+}
+
+// NarrateDigest asks the guide for a short recap of the day that points at one thing worth
+// thinking about
+func (c *AIClient) NarrateDigest(ctx context.Context, gameState *GameState, digest *DailyDigest) (string, error) {
+	types := make([]string, 0, len(digest.Totals))
+	for eventType := range digest.Totals {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	var totals strings.Builder
+	for _, eventType := range types {
+		fmt.Fprintf(&totals, "- %s: %+.2f€\n", eventType, digest.Totals[eventType])
+	}
+	var moves strings.Builder
+	for _, move := range digest.PriceMoves {
+		fmt.Fprintf(&moves, "- %s (%s): €%.2f -> €%.2f (%+.1f%%)\n", move.Symbol, move.Kind, move.From, move.To, move.ChangePercent)
+	}
+	var events strings.Builder
+	for _, event := range digest.Events {
+		fmt.Fprintf(&events, "- %s\n", event.Message)
+	}
+
+	prompt := fmt.Sprintf(`Recap the player's in-game day %s.
+
+MONEY: €%.2f -> €%.2f (income €%.2f, spending €%.2f)
+NET WORTH: €%.2f -> €%.2f
+
+MONEY BY EVENT TYPE:
+%s
+PRICE MOVES OF WHAT THEY HOLD:
+%s
+EVENTS:
+%s
+YOUR TASK:
+Write a short recap (2-3 sentences, plain text, no JSON, no markdown) of what happened to their money today, then ask one question that helps them think about a habit or risk the day shows. Don't tell them what to do.`,
+		digest.From.Format("2006-01-02"),
+		digest.MoneyStart, digest.MoneyEnd, digest.Income, digest.Spending,
+		digest.NetWorthStart, digest.NetWorthEnd,
+		totals.String(),
+		moves.String(),
+		events.String())
+
+	messages := []Message{
+		{Role: "system", Content: "You are a Socratic financial literacy guide who sums up the player's day briefly and kindly." + languageInstruction(gameState)},
+		{Role: "user", Content: prompt},
+	}
+
+	response, err := c.CallOpenAIWithAgent(ctx, "daily_digest", messages)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response), nil
+}
+
+// sendDailyDigest pushes a "daily_digest" message to the player's WebSocket, narrated by the
+// guide when daily_digest_narration is on. The AI call runs in the background; callers must not
+// hold any game lock.
+func (gm *GameManager) sendDailyDigest(playerID string, digest DailyDigest) {
+	if !GetConfig().Features.DailyDigestNarration {
+		gm.sendToPlayer(playerID, map[string]interface{}{"type": "daily_digest", "digest": digest})
+		return
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logErrorf("[PANIC] Panic in daily digest goroutine for player %s: %v", playerID, r)
+			}
+		}()
+
+		if game, exists := gm.snapshotGame(playerID); exists {
+			narrative, err := gm.ai.NarrateDigest(gm.ctx, game, &digest)
+			if err != nil {
+				logWarnf("[DIGEST] Narration failed for player %s, sending the digest without it: %v", playerID, err)
+			}
+			digest.Narrative = narrative
+		}
+		gm.sendToPlayer(playerID, map[string]interface{}{"type": "daily_digest", "digest": digest})
+	}()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Energy tuning
+const (
+	lowEnergyThreshold   = 30  // Below this the player is warned (the guide's prompt uses the same mark)
+	burnoutHealthPerHour = 2.0 // Extra health lost per hour worked with no energy left
+	restEnergyPerHour    = 5   // Energy regained per hour of rest, on top of any apartment gain
+	maxRestHours         = 12
+)
+
+// Rest spends game time recovering energy. It works without an apartment; with one, the
+// apartment's own recovery applies as well.
+func (gs *GameState) Rest(hours float64) error {
+	if err := gs.actionGuard("rest"); err != nil {
+		return err
+	}
+	if hours < 1 || hours > maxRestHours {
+		return &GameError{Message: fmt.Sprintf("You can rest between 1 and %d hours", maxRestHours)}
+	}
+
+	gs.AdvanceTime(time.Duration(hours * float64(time.Hour)))
+	energyGain := int(hours * restEnergyPerHour)
+	gs.Energy = min(gs.Energy+energyGain, 100)
+	gs.addEvent("rest", fmt.Sprintf("Rested for %.0f hours: +%d energy (Energy: %d/100)", hours, energyGain, gs.Energy), 0)
+	return nil
+}
+
+// burnout costs extra health for working the given hours with no energy left
+func (gs *GameState) burnout(hours float64) {
+	// Fractional losses from short ticks happen with matching probability, as with work losses
+	lossFloat := hours * burnoutHealthPerHour
+	healthLoss := int(lossFloat)
+	if gs.rng.Float64() < lossFloat-float64(healthLoss) {
+		healthLoss++
+	}
+	if healthLoss == 0 {
+		return
+	}
+	gs.Health = max(gs.Health-healthLoss, 0)
+	gs.addEvent("burnout", fmt.Sprintf("🥵 Burnout: working with no energy cost %d health. Rest before you collapse!", healthLoss), 0)
+}
+
+// warnEnergy tells the player when their energy has fallen below the warning mark or run out
+// since it was previous
+func (gs *GameState) warnEnergy(previous int) {
+	switch {
+	case gs.Energy == 0 && previous > 0:
+		gs.addEvent("energy_depleted", "⚠️ You are out of energy! Working now burns your health. Rest or go home to recover.", 0)
+	case gs.Energy < lowEnergyThreshold && previous >= lowEnergyThreshold:
+		gs.addEvent("energy_low", fmt.Sprintf("⚠️ Low energy (%d/100). You need to rest!", gs.Energy), 0)
+	}
+}
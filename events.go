@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultEventQueueSize is how many outbound events are buffered when n8n.event_queue_size is not set
+const DefaultEventQueueSize = 256
+
+// defaultOutboundEventTypes are the event types sent to n8n.events_url when n8n.event_types is empty
+var defaultOutboundEventTypes = []string{
+	"job_accepted", "job_quit",
+	"apartment_rented", "apartment_quit",
+	"scam_accepted",
+	"agreement_started", "agreement_cancelled", "agreement_penalty", "agreement_unaffordable", "agreement_suspended",
+	"hospital_admission",
+	"market_crash",
+	"achievement",
+	"game_over", "game_won",
+}
+
+// OutboundEvent is the payload posted to the events webhook
+type OutboundEvent struct {
+	PlayerID  string    `json:"player_id"`
+	EventID   string    `json:"event_id,omitempty"` // Empty for events that are not kept in the player's history
+	Type      string    `json:"type"`
+	Message   string    `json:"message"`
+	Amount    float64   `json:"amount"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// eventDispatcher posts game events to an external webhook in the background. Events wait in a
+// bounded queue; when the receiver falls behind and the queue is full, the oldest event is dropped
+// so gameplay never waits on the webhook.
+type eventDispatcher struct {
+	url      string
+	secret   string
+	types    map[string]bool
+	client   *http.Client
+	mu       sync.Mutex
+	queue    []OutboundEvent
+	capacity int
+	dropped  int           // Events dropped since the last warning
+	posting  bool          // An event has been taken off the queue and is being delivered
+	wake     chan struct{} // Signals the worker that the queue is not empty
+}
+
+// outboundEvents is the dispatcher game events are published to; nil when no events URL is configured
+var outboundEvents atomic.Pointer[eventDispatcher]
+
+// newEventDispatcher starts a dispatcher for the configured events URL, or returns nil if there is none
+func newEventDispatcher(config *Config) *eventDispatcher {
+	if config.N8N.EventsURL == "" {
+		return nil
+	}
+	
+	types := config.N8N.EventTypes
+	if len(types) == 0 {
+		types = defaultOutboundEventTypes
+	}
+	capacity := config.N8N.EventQueueSize
+	if capacity <= 0 {
+		capacity = DefaultEventQueueSize
+	}
+	
+	d := &eventDispatcher{
+		url:      config.N8N.EventsURL,
+		secret:   config.N8N.Secret,
+		types:    make(map[string]bool, len(types)),
+		client:   &http.Client{Timeout: 10 * time.Second},
+		capacity: capacity,
+		wake:     make(chan struct{}, 1),
+	}
+	for _, eventType := range types {
+		d.types[eventType] = true
+	}
+	go d.run()
+	logInfof("[EVENTS] Sending %d event types to %s", len(d.types), d.url)
+	return d
+}
+
+// enqueue queues an event if its type is wanted, dropping the oldest queued event when full
+func (d *eventDispatcher) enqueue(event OutboundEvent) {
+	if !d.types[event.Type] {
+		return
+	}
+	
+	d.mu.Lock()
+	if len(d.queue) >= d.capacity {
+		d.queue = d.queue[1:]
+		d.dropped++
+	}
+	d.queue = append(d.queue, event)
+	d.mu.Unlock()
+	
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued events one at a time, in order, for the lifetime of the process
+func (d *eventDispatcher) run() {
+	for range d.wake {
+		for {
+			d.mu.Lock()
+			if d.dropped > 0 {
+				logWarnf("[EVENTS] Queue full, dropped %d oldest events", d.dropped)
+				d.dropped = 0
+			}
+			if len(d.queue) == 0 {
+				d.mu.Unlock()
+				break
+			}
+			event := d.queue[0]
+			d.queue = d.queue[1:]
+			d.posting = true
+			d.mu.Unlock()
+			
+			if err := d.post(event); err != nil {
+				logWarnf("[EVENTS] Could not deliver %s event for player %s: %v", event.Type, event.PlayerID, err)
+			}
+			d.mu.Lock()
+			d.posting = false
+			d.mu.Unlock()
+		}
+	}
+}
+
+// flush waits until every queued event has been delivered (or given up on), or ctx is done
+func (d *eventDispatcher) flush(ctx context.Context) error {
+	for {
+		d.mu.Lock()
+		pending := len(d.queue)
+		if d.posting {
+			pending++
+		}
+		d.mu.Unlock()
+		if pending == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d events still queued: %w", pending, ctx.Err())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// post sends one event, signed like the offer message webhook when a secret is configured
+func (d *eventDispatcher) post(event OutboundEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	
+	req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.secret != "" {
+		req.Header.Set("X-Signature", signN8NPayload(d.secret, body))
+	}
+	
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// publishEvent hands an event to the outbound dispatcher, if one is running. It never blocks on
+// the network, so it is safe to call with a game lock held.
+func publishEvent(playerID string, event Event) {
+	if d := outboundEvents.Load(); d != nil {
+		d.enqueue(OutboundEvent{
+			PlayerID:  playerID,
+			EventID:   event.ID,
+			Type:      event.Type,
+			Message:   event.Message,
+			Amount:    event.Amount,
+			Timestamp: event.Timestamp,
+		})
+	}
+}
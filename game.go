@@ -1,9 +1,10 @@
 package main
 
 import (
+	"crypto/rand"
 	"fmt"
 	"math"
-	"math/rand"
+	"slices"
 	"strings"
 	"time"
 )
@@ -16,10 +17,8 @@ func abs(x float64) float64 {
 // Random seed is automatically initialized in Go 1.20+
 
 const (
-	InitialMoney = 10000.0
-	GameStartDate = "2000-01-02T08:00:00Z"
 	WorkDayDuration = 8 * time.Hour // 8 hours of work
-	SalaryPaymentDay = 1 // 1st of each month
+	SalaryPaymentDay = 1 // 1st of each month, for monthly salaries, rent and the insurance premium
 	NightStartHour = 0  // Night starts at 00:00
 	NightEndHour = 7    // Night ends at 07:00
 )
@@ -27,70 +26,147 @@ const (
 var (
 	availableJobs = []Job{
 		{ID: "job1", Title: "Junior Developer", Salary: 500, Description: "Entry-level programming job", HoursPerDay: 8},
-		{ID: "job2", Title: "Freelance Designer", Salary: 300, Description: "Flexible design work", HoursPerDay: 6},
-		{ID: "job3", Title: "Part-time Retail", Salary: 200, Description: "Retail store assistant", HoursPerDay: 4},
+		{ID: "job2", Title: "Freelance Designer", Salary: 300, Description: "Flexible design work", HoursPerDay: 6, PayFrequency: PayBiweekly},
+		{ID: "job3", Title: "Part-time Retail", Salary: 200, Description: "Retail store assistant", HoursPerDay: 4, PayFrequency: PayWeekly},
 		{ID: "job4", Title: "Tutor", Salary: 400, Description: "Teaching students", HoursPerDay: 5},
 	}
 	
 	marketItems = []Item{
-		{ID: "item1", Name: "Laptop", MarketPrice: 800, BuyPrice: 0},
-		{ID: "item2", Name: "Phone", MarketPrice: 500, BuyPrice: 0},
-		{ID: "item3", Name: "Watch", MarketPrice: 200, BuyPrice: 0},
-		{ID: "item4", Name: "Headphones", MarketPrice: 150, BuyPrice: 0},
-		{ID: "item5", Name: "Tablet", MarketPrice: 400, BuyPrice: 0},
+		{ID: "item1", Name: "Laptop", MarketPrice: 800, BuyPrice: 0, Category: ItemElectronics},
+		{ID: "item2", Name: "Phone", MarketPrice: 500, BuyPrice: 0, Category: ItemElectronics},
+		{ID: "item3", Name: "Watch", MarketPrice: 200, BuyPrice: 0, Category: ItemAccessories},
+		{ID: "item4", Name: "Headphones", MarketPrice: 150, BuyPrice: 0, Category: ItemElectronics},
+		{ID: "item5", Name: "Tablet", MarketPrice: 400, BuyPrice: 0, Category: ItemElectronics},
 	}
 	
 	stockSymbols = []string{"TECH", "FIN", "ENERGY", "HEALTH", "RETAIL"}
-	cryptoSymbols = []string{"BTC", "ETH", "SOL", "ADA", "DOT"}
 )
 
-// NewGame creates a new game state
-func NewGame(playerID string) *GameState {
-	startDate, _ := time.Parse(time.RFC3339, GameStartDate)
-	return &GameState{
+// resolveScenario returns the named scenario, or the configured default when the name is
+// empty or unknown
+func resolveScenario(name string) (string, Scenario) {
+	config := GetConfig()
+	if scenario, exists := config.Game.Scenarios[strings.ToLower(name)]; exists {
+		return strings.ToLower(name), scenario
+	}
+	if name != "" {
+		logDebugf("Unknown scenario %q, using %s", name, config.Game.Scenario)
+	}
+	return config.Game.Scenario, config.Game.Scenarios[config.Game.Scenario]
+}
+
+// difficulty returns the game's difficulty table, falling back to normal for unknown names
+func (gs *GameState) difficulty() Difficulty {
+	difficulties := GetConfig().Game.Difficulties
+	if difficulty, exists := difficulties[gs.Difficulty]; exists {
+		return difficulty
+	}
+	return difficulties["normal"]
+}
+
+// tradeFee returns the broker fee for buying or selling assets worth value
+func (gs *GameState) tradeFee(value float64) float64 {
+	difficulty := gs.difficulty()
+	if value <= 0 {
+		return 0
+	}
+	return difficulty.TradeFeeFlat + value*difficulty.TradeFeeRate
+}
+
+// chargeFee takes a broker fee from Money and records it as a transaction_fee event
+func (gs *GameState) chargeFee(fee float64, trade string) {
+	if fee <= 0 {
+		return
+	}
+	gs.Money -= fee
+	gs.addEvent("transaction_fee", "Paid a €"+formatMoney(fee)+" fee on "+trade, -fee)
+}
+
+// scaleOfferExpiry stretches or shortens how long an offer created at now stays open
+func (d Difficulty) scaleOfferExpiry(now, expiresAt time.Time) time.Time {
+	if d.OfferLifetime == 1 || !expiresAt.After(now) {
+		return expiresAt
+	}
+	return now.Add(time.Duration(float64(expiresAt.Sub(now)) * d.OfferLifetime))
+}
+
+// NetWorth returns cash plus the current value of stocks, crypto and inventory
+func (gs *GameState) NetWorth() float64 {
+	netWorth := gs.Money
+	for _, stock := range gs.Stocks {
+		netWorth += stock.Value()
+	}
+	for _, crypto := range gs.Crypto {
+		netWorth += crypto.CurrentPrice * crypto.Amount
+	}
+	for _, item := range gs.Inventory {
+		netWorth += item.MarketPrice
+	}
+	return netWorth
+}
+
+// UnrealizedGain returns what closing the position at the current price would gain (or lose if negative)
+func (s Stock) UnrealizedGain() float64 {
+	if s.Short {
+		return (s.BuyPrice - s.CurrentPrice) * float64(s.Shares)
+	}
+	return (s.CurrentPrice - s.BuyPrice) * float64(s.Shares)
+}
+
+// Value returns what the position is worth to the player: the shares for a long position, or the
+// reserved margin plus the unrealized gain for a short one
+func (s Stock) Value() float64 {
+	if s.Short {
+		return s.Margin + s.UnrealizedGain()
+	}
+	return s.CurrentPrice * float64(s.Shares)
+}
+
+// NewGame creates a new game state with the starting money and date of the given scenario
+func NewGame(playerID string, scenarioName string) *GameState {
+	scenarioName, scenario := resolveScenario(scenarioName)
+	startDate, _ := time.Parse(time.RFC3339, scenario.StartDate) // Validated by LoadConfig
+	gs := &GameState{
+		rng:           seededRand(playerID),
+		prices:        newNetworkMarket(seededRand("market:" + playerID)),
 		PlayerID:      playerID,
-		Money:         InitialMoney,
-		InitialMoney:  InitialMoney,
+		Scenario:      scenarioName,
+		Difficulty:    scenario.Difficulty,
+		Money:         scenario.InitialMoney,
+		InitialMoney:  scenario.InitialMoney,
 		Reputation:    0,
 		Health:        100, // Start with full health
 		Energy:        100, // Start with full energy
 		CurrentDate:   startDate,
+		StartDate:     startDate,
+		MarketIndex:   market.ValueOn(startDate),
 		Stocks:        []Stock{},
 		Crypto:        []Crypto{},
 		Inventory:     []Item{},
-		History:       []Event{},
+		History:       NewEventHistory(GetConfig().History.Capacity),
 		ActiveOffers:  []Offer{},
 		JobOffers:     []JobOffer{},
 		ApartmentOffers: []ApartmentOffer{},
 		StockOffers:   []StockOffer{},
 		StockHistory:  []StockHistory{},
+		NetWorthHistory: []NetWorthPoint{},
+		TrickeryFactor: 1,
 		Agreements:    []Agreement{},
+		Achievements:  []Achievement{},
 		IsWorking:     false,
 		IsInHospital:  false,
 		GameOver:      false,
 		IsFirstPlayer: false,
 		CreatedAt:     time.Now(),
 	}
+	gs.Hospital = gs.hospitalTerms()
+	return gs
 }
 
 // StartWork starts a work session (only for hourly jobs)
 func (gs *GameState) StartWork() error {
-	if gs.GameOver {
-		return &GameError{Message: "Game is over. You cannot perform actions."}
-	}
-	if gs.IsInHospital {
-		return &GameError{Message: "You are in the hospital and cannot work. You'll be released when your health reaches 20."}
-	}
-	if gs.Job == nil {
-		return &GameError{Message: "You don't have a job. Accept a job offer first!"}
-	}
-	
-	if gs.Job.WorkType == "fixed_time" {
-		return &GameError{Message: "This is a fixed-time job. Work starts and ends automatically at scheduled times."}
-	}
-	
-	if gs.IsWorking {
-		return &GameError{Message: "You are already working"}
+	if err := gs.actionGuard("start_work"); err != nil {
+		return err
 	}
 	
 	gs.IsWorking = true
@@ -98,22 +174,21 @@ func (gs *GameState) StartWork() error {
 	// For hourly jobs, work duration is based on hours per day
 	workDuration := time.Duration(gs.Job.HoursPerDay) * time.Hour
 	gs.WorkEndTime = gs.CurrentDate.Add(workDuration)
-	gs.addEvent("work_start", "Started working at "+gs.Job.Title+" (Time moves 10x faster while working)", 0)
+	eventMsg := "Started working at " + gs.Job.Title + " (Time moves 10x faster while working)"
+	if productivity := gs.workProductivity(); productivity < 1 {
+		eventMsg += fmt.Sprintf(". You are tired and will only get %.0f%% of the work done", productivity*100)
+	}
+	if gs.hoursWorkedOn(gs.CurrentDate) >= float64(gs.Job.HoursPerDay) {
+		eventMsg += fmt.Sprintf(". Overtime today is paid %gx", overtimeMultiplier)
+	}
+	gs.addEvent("work_start", eventMsg, 0)
 	return nil
 }
 
 // StopWork stops a work session (only for hourly jobs)
 func (gs *GameState) StopWork() error {
-	if gs.Job == nil {
-		return &GameError{Message: "You don't have a job"}
-	}
-	
-	if gs.Job.WorkType == "fixed_time" {
-		return &GameError{Message: "This is a fixed-time job. You cannot stop work manually."}
-	}
-	
-	if !gs.IsWorking {
-		return &GameError{Message: "You are not currently working"}
+	if err := gs.actionGuard("stop_work"); err != nil {
+		return err
 	}
 	
 	// Calculate hours worked
@@ -126,19 +201,20 @@ func (gs *GameState) StopWork() error {
 	gs.WorkStartTime = time.Time{}
 	gs.WorkEndTime = time.Time{}
 	
-	gs.addEvent("work_stop", fmt.Sprintf("Stopped working at %s (Worked %.2f hours)", gs.Job.Title, hoursWorked), 0)
+	gs.addEvent("work_stop", fmt.Sprintf("Stopped working at %s (Worked %.2f hours, %.1f since the last payday)", gs.Job.Title, hoursWorked, gs.HoursWorkedThisPeriod), 0)
 	return nil
 }
 
-// CheckWorkStatus checks if work is complete and processes salary
-func (gs *GameState) CheckWorkStatus() {
+// CheckWorkStatus checks if work is complete (salary is settled separately by processSalary).
+// workedHours are the hours logWork counted in the latest advance.
+func (gs *GameState) CheckWorkStatus(workedHours float64) {
 	if gs.Job == nil {
 		return
 	}
 	
 	// Handle fixed-time jobs
 	if gs.Job.WorkType == "fixed_time" {
-		gs.checkFixedTimeWork()
+		gs.checkFixedTimeWork(workedHours)
 		return
 	}
 	
@@ -152,66 +228,32 @@ func (gs *GameState) CheckWorkStatus() {
 		gs.IsWorking = false
 		gs.addEvent("work_end", "Finished working at "+gs.Job.Title, 0)
 	}
-	
-	// Process salary payment (same for both types)
-	gs.processSalary()
 }
 
-// checkFixedTimeWork handles fixed-time job work schedule
-func (gs *GameState) checkFixedTimeWork() {
+// checkFixedTimeWork sets whether the player is at work by the fixed-time job's schedule. The
+// hours worked, and their cost in health and energy, are counted by logWork and AdvanceTime,
+// including shifts an advance passed over entirely; workedHours is what it counted this time.
+func (gs *GameState) checkFixedTimeWork(workedHours float64) {
 	if gs.Job.WorkStart == "" || gs.Job.WorkEnd == "" {
 		return
 	}
 	
-	// Parse work hours (format: "HH:MM")
-	currentTime := gs.CurrentDate.Format("15:04")
-	
-	// Check if current time is within work hours
-	if currentTime >= gs.Job.WorkStart && currentTime < gs.Job.WorkEnd {
+	if gs.Job.onSchedule(gs.CurrentDate) && !gs.onDayOff(gs.CurrentDate) {
 		if !gs.IsWorking {
 			gs.IsWorking = true
 			gs.WorkStartTime = gs.CurrentDate
 			gs.addEvent("work_start", "Started working at "+gs.Job.Title+" (Fixed schedule: "+gs.Job.WorkStart+"-"+gs.Job.WorkEnd+")", 0)
 		}
-		// Lose health and energy while working
-		gs.loseHealthEnergyFromWork()
-	} else {
-		if gs.IsWorking {
-			gs.IsWorking = false
-			gs.WorkStartTime = time.Time{}
-			gs.WorkEndTime = time.Time{}
-			gs.addEvent("work_end", "Finished working at "+gs.Job.Title+" (Fixed schedule ended)", 0)
-		}
-	}
-}
-
-// loseHealthEnergyFromWork reduces health and energy while working (using AI-determined rates)
-func (gs *GameState) loseHealthEnergyFromWork() {
-	if !gs.IsWorking || gs.Job == nil {
 		return
 	}
-	
-	// Use AI-determined health and energy loss rates from the job
-	// This is called periodically, so we lose small amounts based on the job's characteristics
-	healthLoss := int(gs.Job.HealthLossPerHour)
-	energyLoss := int(gs.Job.EnergyLossPerHour)
-	
-	// Ensure at least 1 point is lost if the rate is very low
-	if healthLoss < 1 && gs.Job.HealthLossPerHour > 0 {
-		healthLoss = 1
-	}
-	if energyLoss < 1 && gs.Job.EnergyLossPerHour > 0 {
-		energyLoss = 1
-	}
-	
-	gs.Health -= healthLoss
-	if gs.Health < 0 {
-		gs.Health = 0
-	}
-	
-	gs.Energy -= energyLoss
-	if gs.Energy < 0 {
-		gs.Energy = 0
+	if gs.IsWorking {
+		gs.IsWorking = false
+		gs.WorkStartTime = time.Time{}
+		gs.WorkEndTime = time.Time{}
+		gs.addEvent("work_end", "Finished working at "+gs.Job.Title+" (Fixed schedule ended)", 0)
+	} else if workedHours > 0 {
+		// The advance passed over a whole shift
+		gs.addEvent("work_end", fmt.Sprintf("Worked %.1f hours at %s on its fixed schedule (%s-%s)", workedHours, gs.Job.Title, gs.Job.WorkStart, gs.Job.WorkEnd), 0)
 	}
 }
 
@@ -236,32 +278,39 @@ func (gs *GameState) gainHealthEnergyFromApartment() {
 	}
 }
 
-// processSalary processes monthly salary payment and rent
-func (gs *GameState) processSalary() {
-	// Check if it's salary payment day (1st of month)
-	if gs.CurrentDate.Day() == SalaryPaymentDay {
-		// Process salary
-		if gs.Job != nil {
-			// Check if we haven't paid this month yet
-			if gs.LastSalaryDate.IsZero() || 
-			   gs.LastSalaryDate.Year() != gs.CurrentDate.Year() || 
-			   gs.LastSalaryDate.Month() != gs.CurrentDate.Month() {
-				gs.Money += gs.Job.Salary
-				gs.LastSalaryDate = gs.CurrentDate
-				gs.addEvent("salary", "Received monthly salary: €"+formatMoney(gs.Job.Salary)+" from "+gs.Job.Title, gs.Job.Salary)
+// processSalary settles salary, rent and the insurance premium for every payment day crossed
+// between since and the current date, in date order, so a single large time jump pays each one
+// it skipped. Salary and rent follow the job's and apartment's own frequency; the premium is
+// paid monthly. Every rented apartment has its own rent, and a tenant's rent is received before
+// the rent of the apartment they live in is paid. LastSalaryDate records the last payment day a salary was paid for, which keeps
+// a payment from being made twice.
+func (gs *GameState) processSalary(since time.Time) {
+	var payments []*scheduledPayment
+	if gs.Job != nil {
+		payments = append(payments, &scheduledPayment{frequency: normalizePayFrequency(gs.Job.PayFrequency), settle: gs.paySalary})
+	}
+	for _, apartment := range gs.rentedApartments() {
+		apartment := apartment
+		payments = append(payments, &scheduledPayment{frequency: normalizePayFrequency(apartment.RentFrequency), settle: func(time.Time) { gs.payRent(apartment) }})
+	}
+	payments = append(payments, &scheduledPayment{frequency: PayMonthly, settle: func(time.Time) { gs.payInsurancePremium() }})
+	for _, payment := range payments {
+		payment.due = nextPayDay(payment.frequency, since)
+	}
+	
+	for {
+		// Settle the earliest payment due; on the same day salary comes before rent and the premium
+		var next *scheduledPayment
+		for _, payment := range payments {
+			if !payment.due.After(gs.CurrentDate) && (next == nil || payment.due.Before(next.due)) {
+				next = payment
 			}
 		}
-		
-		// Process rent payment
-		if gs.Apartment != nil {
-			if gs.Money >= gs.Apartment.Rent {
-				gs.Money -= gs.Apartment.Rent
-				gs.addEvent("rent_paid", "Paid monthly rent: €"+formatMoney(gs.Apartment.Rent)+" for "+gs.Apartment.Title, -gs.Apartment.Rent)
-			} else {
-				gs.addEvent("rent_failed", "Failed to pay rent: €"+formatMoney(gs.Apartment.Rent)+" for "+gs.Apartment.Title+" (Not enough money!)", 0)
-				// Could add logic to evict player if rent not paid
-			}
+		if next == nil {
+			return
 		}
+		next.settle(next.due)
+		next.due = nextPayDay(next.frequency, next.due)
 	}
 }
 
@@ -271,21 +320,24 @@ func (gs *GameState) IsNightTime() bool {
 	return hour >= NightStartHour && hour < NightEndHour
 }
 
+// checkOpenHours rejects an action during night hours. Deals that need someone on the other side
+// in business hours are closed at night: employers (job offers), landlords (apartment offers),
+// tenants (renting an apartment out), the stock exchange (buying, selling, shorting and covering
+// stocks) and the market (items). Crypto exchanges and the offers that reach the player online
+// (AcceptOffer) run around the clock, and so does anything that happens by itself, like rent,
+// salary or a margin call.
+func (gs *GameState) checkOpenHours(action string) error {
+	if gs.IsNightTime() {
+		return &GameError{Message: fmt.Sprintf("You cannot %s during night hours (%02d:00 - %02d:00). Please wait until morning.", action, NightStartHour, NightEndHour)}
+	}
+	return nil
+}
+
 // AcceptJobOffer accepts a job offer
 func (gs *GameState) AcceptJobOffer(offerID string) error {
-	if gs.GameOver {
-		return &GameError{Message: "Game is over. You cannot perform actions."}
-	}
-	if gs.IsInHospital {
-		return &GameError{Message: "You are in the hospital and cannot accept job offers. You'll be released when your health reaches 20."}
-	}
-	if gs.Job != nil {
-		return &GameError{Message: "You already have a job: " + gs.Job.Title + ". Quit first to accept a new one."}
-	}
-	
-	// Check if it's night time - cannot accept jobs during night
-	if gs.IsNightTime() {
-		return &GameError{Message: "You cannot accept job offers during night hours (00:00 - 07:00). Please wait until morning."}
+	// No job yet, and employers are closed at night
+	if err := gs.actionGuard("accept_job_offer"); err != nil {
+		return err
 	}
 	
 	offerIndex := -1
@@ -326,17 +378,26 @@ func (gs *GameState) AcceptJobOffer(offerID string) error {
 		WorkEnd:           offer.WorkEnd,
 		HealthLossPerHour: offer.HealthLossPerHour,
 		EnergyLossPerHour: offer.EnergyLossPerHour,
+		PayFrequency:      normalizePayFrequency(offer.PayFrequency),
 		IsTrickery:        offer.IsTrickery,
 		Reason:            offer.Reason,
 	}
 	
 	gs.JobOffers = append(gs.JobOffers[:offerIndex], gs.JobOffers[offerIndex+1:]...)
+	if offer.IsTrickery {
+		gs.noteScamAccepted("job", offer.Title)
+	}
 	
-	eventMsg := "Accepted job: " + offer.Title + " - Monthly salary: €" + formatMoney(offer.Salary)
+	eventMsg := "Accepted job: " + offer.Title + " - Salary: " + paymentTerms(offer.Salary, offer.PayFrequency)
 	if offer.UpfrontCost > 0 {
 		eventMsg += " - Paid upfront cost: €" + formatMoney(offer.UpfrontCost)
 	}
 	gs.addEvent("job_accepted", eventMsg, -offer.UpfrontCost)
+	if offer.IsTrickery {
+		gs.adjustReputation(GetConfig().Reputation.ScamJob, "fell for a scam job")
+	} else {
+		gs.adjustReputation(GetConfig().Reputation.LegitJob, "took a legitimate job")
+	}
 	return nil
 }
 
@@ -373,8 +434,8 @@ func (gs *GameState) ShowHint(offerID string) error {
 
 // AcceptApartmentOffer accepts an apartment offer
 func (gs *GameState) AcceptApartmentOffer(offerID string) error {
-	if gs.Apartment != nil {
-		return &GameError{Message: "You already have an apartment: " + gs.Apartment.Title + ". You can only have one apartment at a time."}
+	if err := gs.actionGuard("accept_apartment_offer"); err != nil {
+		return err
 	}
 	
 	offerIndex := -1
@@ -396,21 +457,36 @@ func (gs *GameState) AcceptApartmentOffer(offerID string) error {
 	}
 	
 	// Create apartment from offer
-	gs.Apartment = &Apartment{
+	apartment := Apartment{
 		ID:          offer.ID,
 		Title:       offer.Title,
 		Rent:        offer.Rent,
 		Description: offer.Description,
 		HealthGain:  offer.HealthGain,
 		EnergyGain:  offer.EnergyGain,
+		RentFrequency: normalizePayFrequency(offer.RentFrequency),
 		IsTrickery:  offer.IsTrickery,
 		Reason:      offer.Reason,
 	}
 	
+	// The first apartment is where the player lives, any others can be rented out
+	eventMsg := "Rented apartment: " + offer.Title + " - Rent: " + paymentTerms(offer.Rent, offer.RentFrequency)
+	if gs.Apartment == nil {
+		gs.Apartment = &apartment
+	} else {
+		gs.Properties = append(gs.Properties, apartment)
+		eventMsg += ". You still live in " + gs.Apartment.Title + " and can rent this one out."
+	}
+	
 	gs.ApartmentOffers = append(gs.ApartmentOffers[:offerIndex], gs.ApartmentOffers[offerIndex+1:]...)
+	if offer.IsTrickery {
+		gs.noteScamAccepted("apartment", offer.Title)
+	}
 	
-	eventMsg := "Rented apartment: " + offer.Title + " - Monthly rent: €" + formatMoney(offer.Rent)
 	gs.addEvent("apartment_rented", eventMsg, 0)
+	if offer.IsTrickery {
+		gs.adjustReputation(GetConfig().Reputation.ScamOffer, "rented from a scammer")
+	}
 	return nil
 }
 
@@ -444,22 +520,48 @@ func (gs *GameState) ShowApartmentHint(offerID string) error {
 	return nil
 }
 
-// QuitApartment quits the current apartment
-func (gs *GameState) QuitApartment() error {
-	if gs.Apartment == nil {
-		return &GameError{Message: "You don't have an apartment"}
+// QuitApartment gives up the apartment with the given ID, or the one the player lives in if the ID
+// is empty. Moving out of the residence moves the player into the first other apartment that isn't
+// rented out, if any.
+func (gs *GameState) QuitApartment(apartmentID string) error {
+	if err := gs.actionGuard("quit_apartment"); err != nil {
+		return err
+	}
+	
+	if gs.Apartment != nil && (apartmentID == "" || apartmentID == gs.Apartment.ID) {
+		apartmentTitle := gs.Apartment.Title
+		gs.Apartment = nil
+		message := "Moved out of apartment: " + apartmentTitle
+		if i := slices.IndexFunc(gs.Properties, func(a Apartment) bool { return !a.RentedOut }); i >= 0 {
+			home := gs.Properties[i]
+			gs.Apartment = &home
+			gs.Properties = slices.Delete(gs.Properties, i, i+1)
+			message += ". Moved into " + home.Title
+		}
+		gs.addEvent("apartment_quit", message, 0)
+		return nil
+	}
+	if apartmentID == "" {
+		return &GameError{Message: "You don't live in any apartment. Choose which of the others to give up."}
 	}
 	
-	apartmentTitle := gs.Apartment.Title
-	gs.Apartment = nil
-	gs.addEvent("apartment_quit", "Moved out of apartment: "+apartmentTitle, 0)
+	i := slices.IndexFunc(gs.Properties, func(a Apartment) bool { return a.ID == apartmentID })
+	if i < 0 {
+		return &GameError{Message: "Apartment not found"}
+	}
+	apartmentTitle := gs.Properties[i].Title
+	if gs.Properties[i].RentedOut {
+		apartmentTitle += " (the tenant moved out)"
+	}
+	gs.Properties = slices.Delete(gs.Properties, i, i+1)
+	gs.addEvent("apartment_quit", "Gave up apartment: "+apartmentTitle, 0)
 	return nil
 }
 
 // QuitJob quits the current job (Rage Quit - can quit even while working)
 func (gs *GameState) QuitJob() error {
-	if gs.Job == nil {
-		return &GameError{Message: "You don't have a job to quit"}
+	if err := gs.actionGuard("quit_job"); err != nil {
+		return err
 	}
 	
 	jobTitle := gs.Job.Title
@@ -472,13 +574,21 @@ func (gs *GameState) QuitJob() error {
 		gs.WorkEndTime = time.Time{}
 	}
 	
+	// Hours logged since the last payday are paid out on leaving
+	if gs.Job.isHourly() && gs.HoursWorkedThisPeriod > 0 {
+		gs.payWorkedHours("final pay")
+	}
+	gs.HoursWorkedThisPeriod, gs.PaidHoursThisPeriod = 0, 0
+	
 	gs.Job = nil
 	gs.LastSalaryDate = time.Time{}
+	gs.DayOffStart, gs.DayOffEnd = time.Time{}, time.Time{}
 	
 	// Apply reputation penalty if it was NOT a scam job
 	if !isTrickery {
-		gs.Reputation -= 1
-		gs.addEvent("job_quit", "Rage Quit job: "+jobTitle+" (Lost 1 Reputation - it was a legitimate job!)", 0)
+		penalty := GetConfig().Reputation.QuitLegitJob
+		gs.addEvent("job_quit", fmt.Sprintf("Rage Quit job: %s (Reputation %+d - it was a legitimate job!)", jobTitle, penalty), 0)
+		gs.adjustReputation(penalty, "quit a legitimate job")
 	} else {
 		gs.addEvent("job_quit", "Rage Quit job: "+jobTitle+" (No reputation loss - it was a scam!)", 0)
 	}
@@ -488,8 +598,8 @@ func (gs *GameState) QuitJob() error {
 
 // BuyStock purchases stock shares from a stock offer
 func (gs *GameState) BuyStock(offerID string, shares int) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("buy_stock"); err != nil {
+		return err
 	}
 	if shares <= 0 {
 		return &GameError{Message: "Invalid number of shares"}
@@ -514,11 +624,12 @@ func (gs *GameState) BuyStock(offerID string, shares int) error {
 		return &GameError{Message: "Stock offer has expired"}
 	}
 	
-	price := offer.CurrentPrice
+	price := gs.prices.stockPrice(offer.Symbol, gs.CurrentDate, offer.CurrentPrice, offer.Beta)
 	totalCost := price * float64(shares)
+	fee := gs.tradeFee(totalCost)
 	
-	if gs.Money < totalCost {
-		return &GameError{Message: "Not enough money. Need €" + formatMoney(totalCost)}
+	if gs.Money < totalCost+fee {
+		return &GameError{Message: "Not enough money. Need €" + formatMoney(totalCost+fee) + " including the €" + formatMoney(fee) + " fee"}
 	}
 	
 	gs.Money -= totalCost
@@ -528,11 +639,16 @@ func (gs *GameState) BuyStock(offerID string, shares int) error {
 		BuyPrice:     price,
 		CurrentPrice: price,
 		BoughtAt:     gs.CurrentDate,
+		Beta:         offer.Beta,
+		CompanyName:  offer.CompanyName,
+		FailureChance: offer.FailureChance,
+		FeesPaid:     fee,
 	}
+	gs.scheduleNews(&stock)
 	gs.Stocks = append(gs.Stocks, stock)
 	
 	// Add to stock history
-	gs.StockHistory = append(gs.StockHistory, StockHistory{
+	gs.addStockHistory(StockHistory{
 		Symbol: offer.Symbol,
 		Price:  price,
 		Date:   gs.CurrentDate,
@@ -540,22 +656,26 @@ func (gs *GameState) BuyStock(offerID string, shares int) error {
 	})
 	
 	gs.addEvent("stock_buy", fmt.Sprintf("Bought %d shares of %s (%s) at €%.2f", shares, offer.Symbol, offer.CompanyName, price), -totalCost)
+	gs.chargeFee(fee, offer.Symbol+" purchase")
+	if !offer.IsSafe {
+		gs.noteScamAccepted("stock", offer.CompanyName)
+	}
 	return nil
 }
 
 // SellStock sells stock shares
 func (gs *GameState) SellStock(symbol string, shares int) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("sell_stock"); err != nil {
+		return err
 	}
 	if shares <= 0 {
 		return &GameError{Message: "Invalid number of shares"}
 	}
 	
-	// Find stock
+	// Find stock (short positions are closed with CoverShort)
 	stockIndex := -1
 	for i, s := range gs.Stocks {
-		if s.Symbol == symbol {
+		if s.Symbol == symbol && !s.Short {
 			stockIndex = i
 			break
 		}
@@ -570,20 +690,24 @@ func (gs *GameState) SellStock(symbol string, shares int) error {
 		return &GameError{Message: "You only own " + formatInt(stock.Shares) + " shares"}
 	}
 	
-	// Update price with some volatility
-	change := (rand.Float64() - 0.5) * 0.2 // ±10% change
-	stock.CurrentPrice = stock.BuyPrice * (1 + change)
-	
+	// Sell at the network's price for the day; with the fees, buying and selling straight away
+	// always loses a little. The profit counts the sold shares' part of the purchase fee too.
+	stock.CurrentPrice = gs.stockPrice(*stock)
+	buyFee := stock.FeesPaid * float64(shares) / float64(stock.Shares)
+	cost := stock.BuyPrice*float64(shares) + buyFee
 	revenue := stock.CurrentPrice * float64(shares)
+	fee := gs.tradeFee(revenue)
 	gs.Money += revenue
 	stock.Shares -= shares
+	stock.FeesPaid -= buyFee
 	
 	if stock.Shares == 0 {
 		gs.Stocks = append(gs.Stocks[:stockIndex], gs.Stocks[stockIndex+1:]...)
 	}
 	
-	profit := revenue - (stock.BuyPrice * float64(shares))
+	profit := revenue - fee - cost
 	gs.addEvent("stock_sell", "Sold "+formatInt(shares)+" shares of "+symbol+" for €"+formatMoney(revenue), revenue)
+	gs.chargeFee(fee, symbol+" sale")
 	if profit > 0 {
 		gs.addEvent("profit", "Made a profit of €"+formatMoney(profit), profit)
 	} else {
@@ -592,6 +716,140 @@ func (gs *GameState) SellStock(symbol string, shares int) error {
 	return nil
 }
 
+// shortMarginRate is the share of a short position's value reserved from Money when it is opened.
+// A bad day or a market rally can wipe out a 4% margin.
+const shortMarginRate = 0.04
+
+// ShortSell opens a short position on a stock offer: the player borrows and sells shares at the
+// offer price, reserving a margin, and gains if the price falls before they cover
+func (gs *GameState) ShortSell(offerID string, shares int) error {
+	if err := gs.actionGuard("short_stock"); err != nil {
+		return err
+	}
+	if shares <= 0 {
+		return &GameError{Message: "Invalid number of shares"}
+	}
+	
+	offerIndex := -1
+	for i, offer := range gs.StockOffers {
+		if offer.ID == offerID {
+			offerIndex = i
+			break
+		}
+	}
+	
+	if offerIndex == -1 {
+		return &GameError{Message: "Stock offer not found or expired"}
+	}
+	
+	offer := gs.StockOffers[offerIndex]
+	if gs.CurrentDate.After(offer.ExpiresAt) {
+		gs.StockOffers = append(gs.StockOffers[:offerIndex], gs.StockOffers[offerIndex+1:]...)
+		return &GameError{Message: "Stock offer has expired"}
+	}
+	
+	price := gs.prices.stockPrice(offer.Symbol, gs.CurrentDate, offer.CurrentPrice, offer.Beta)
+	margin := price * float64(shares) * shortMarginRate
+	if gs.Money < margin {
+		return &GameError{Message: "Not enough money for the margin. Need €" + formatMoney(margin)}
+	}
+	
+	gs.Money -= margin
+	stock := Stock{
+		Symbol:       offer.Symbol,
+		Shares:       shares,
+		BuyPrice:     price,
+		CurrentPrice: price,
+		BoughtAt:     gs.CurrentDate,
+		Beta:         offer.Beta,
+		Short:        true,
+		Margin:       margin,
+		CompanyName:  offer.CompanyName,
+		FailureChance: offer.FailureChance,
+	}
+	gs.scheduleNews(&stock)
+	gs.Stocks = append(gs.Stocks, stock)
+	
+	gs.addStockHistory(StockHistory{
+		Symbol: offer.Symbol,
+		Price:  price,
+		Date:   gs.CurrentDate,
+		Event:  "short",
+	})
+	
+	gs.addEvent("short_open", fmt.Sprintf("Shorted %d shares of %s (%s) at €%.2f, reserving €%.2f margin", shares, offer.Symbol, offer.CompanyName, price, margin), -margin)
+	return nil
+}
+
+// CoverShort buys back shares of a short position, returning their share of the margin plus the
+// gain (or minus the loss) since the short was opened
+func (gs *GameState) CoverShort(symbol string, shares int) error {
+	if err := gs.actionGuard("cover_short"); err != nil {
+		return err
+	}
+	if shares <= 0 {
+		return &GameError{Message: "Invalid number of shares"}
+	}
+	
+	stockIndex := -1
+	for i, s := range gs.Stocks {
+		if s.Symbol == symbol && s.Short {
+			stockIndex = i
+			break
+		}
+	}
+	
+	if stockIndex == -1 {
+		return &GameError{Message: "You have no short position in " + symbol}
+	}
+	
+	stock := &gs.Stocks[stockIndex]
+	if stock.Shares < shares {
+		return &GameError{Message: "Your short position is only " + formatInt(stock.Shares) + " shares"}
+	}
+	
+	stock.CurrentPrice = gs.stockPrice(*stock)
+	gs.closeShort(stockIndex, shares, "short_cover", "Covered short of")
+	return nil
+}
+
+// closeShort buys back shares of the short position at stockIndex at its current price plus the
+// broker fee, settles the margin and records the result under eventType, with a message starting
+// with action. It removes the position once no shares are left.
+func (gs *GameState) closeShort(stockIndex int, shares int, eventType, action string) {
+	stock := &gs.Stocks[stockIndex]
+	symbol, price := stock.Symbol, stock.CurrentPrice
+	margin := stock.Margin * float64(shares) / float64(stock.Shares)
+	fee := gs.tradeFee(stock.CurrentPrice * float64(shares))
+	profit := (stock.BuyPrice-stock.CurrentPrice)*float64(shares) - fee
+	returned := margin + profit + fee // The fee is charged separately below
+	
+	gs.Money += returned
+	stock.Margin -= margin
+	stock.Shares -= shares
+	if stock.Shares == 0 {
+		gs.Stocks = append(gs.Stocks[:stockIndex], gs.Stocks[stockIndex+1:]...)
+	}
+	
+	gs.addEvent(eventType, fmt.Sprintf("%s %d shares of %s at €%.2f", action, shares, symbol, price), returned)
+	gs.chargeFee(fee, symbol+" buy-back")
+	if profit > 0 {
+		gs.addEvent("profit", "Made a profit of €"+formatMoney(profit), profit)
+	} else {
+		gs.addEvent("loss", "Lost €"+formatMoney(-profit), -profit)
+	}
+}
+
+// liquidateShorts forces a buy-in of every short position whose loss has used up its margin
+func (gs *GameState) liquidateShorts() {
+	for i := len(gs.Stocks) - 1; i >= 0; i-- {
+		stock := gs.Stocks[i]
+		if stock.Short && -stock.UnrealizedGain() >= stock.Margin {
+			gs.closeShort(i, stock.Shares, "short_liquidated", "Margin call! Forced buy-in of shorted")
+		}
+	}
+}
+
 // ShowStockHint shows a hint about a stock offer (costs 10 EUR)
 func (gs *GameState) ShowStockHint(offerID string) error {
 	const hintCost = 10.0
@@ -654,19 +912,23 @@ func (gs *GameState) ShowOtherOfferHint(offerID string) error {
 
 // BuyCrypto purchases cryptocurrency
 func (gs *GameState) BuyCrypto(symbol string, amount float64) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("buy_crypto"); err != nil {
+		return err
 	}
 	if amount <= 0 {
 		return &GameError{Message: "Invalid amount"}
 	}
+	if _, known := findCryptoCoin(symbol); !known {
+		return &GameError{Message: "Unknown coin: " + symbol}
+	}
 	
-	// Generate random price between 1000-5000
-	price := 1000.0 + rand.Float64()*4000.0
+	// Buy at the network's price for the coin (listed near its catalog base price the first time)
+	price := gs.prices.cryptoPrice(symbol, gs.CurrentDate)
 	totalCost := price * amount
 	
-	if gs.Money < totalCost {
-		return &GameError{Message: "Not enough money. Need €" + formatMoney(totalCost)}
+	fee := gs.tradeFee(totalCost)
+	if gs.Money < totalCost+fee {
+		return &GameError{Message: "Not enough money. Need €" + formatMoney(totalCost+fee) + " including the €" + formatMoney(fee) + " fee"}
 	}
 	
 	gs.Money -= totalCost
@@ -675,17 +937,19 @@ func (gs *GameState) BuyCrypto(symbol string, amount float64) error {
 		Amount:      amount,
 		BuyPrice:    price,
 		CurrentPrice: price,
-		BoughtAt:    time.Now(),
+		BoughtAt:    gs.CurrentDate,
+		FeesPaid:    fee,
 	}
 	gs.Crypto = append(gs.Crypto, crypto)
 	gs.addEvent("crypto_buy", "Bought "+formatFloat(amount)+" "+symbol+" at €"+formatMoney(price), -totalCost)
+	gs.chargeFee(fee, symbol+" purchase")
 	return nil
 }
 
 // SellCrypto sells cryptocurrency
 func (gs *GameState) SellCrypto(symbol string, amount float64) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("sell_crypto"); err != nil {
+		return err
 	}
 	if amount <= 0 {
 		return &GameError{Message: "Invalid amount"}
@@ -709,20 +973,24 @@ func (gs *GameState) SellCrypto(symbol string, amount float64) error {
 		return &GameError{Message: "You only own " + formatFloat(crypto.Amount) + " " + symbol}
 	}
 	
-	// Update price with volatility
-	change := (rand.Float64() - 0.5) * 0.3 // ±15% change
-	crypto.CurrentPrice = crypto.BuyPrice * (1 + change)
+	// Sell at the network's price for the day
+	crypto.CurrentPrice = gs.prices.cryptoPrice(symbol, gs.CurrentDate)
 	
+	buyFee := crypto.FeesPaid * amount / crypto.Amount
+	cost := crypto.BuyPrice*amount + buyFee
 	revenue := crypto.CurrentPrice * amount
+	fee := gs.tradeFee(revenue)
 	gs.Money += revenue
 	crypto.Amount -= amount
+	crypto.FeesPaid -= buyFee
 	
 	if crypto.Amount == 0 {
 		gs.Crypto = append(gs.Crypto[:cryptoIndex], gs.Crypto[cryptoIndex+1:]...)
 	}
 	
-	profit := revenue - (crypto.BuyPrice * amount)
+	profit := revenue - fee - cost
 	gs.addEvent("crypto_sell", "Sold "+formatFloat(amount)+" "+symbol+" for €"+formatMoney(revenue), revenue)
+	gs.chargeFee(fee, symbol+" sale")
 	if profit > 0 {
 		gs.addEvent("profit", "Made a profit of €"+formatMoney(profit), profit)
 	} else {
@@ -733,11 +1001,12 @@ func (gs *GameState) SellCrypto(symbol string, amount float64) error {
 
 // BuyItem purchases an item from market
 func (gs *GameState) BuyItem(itemID string, price float64) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("buy_item"); err != nil {
+		return err
 	}
-	if gs.Money < price {
-		return &GameError{Message: "Not enough money. Need €" + formatMoney(price)}
+	fee := gs.tradeFee(price)
+	if gs.Money < price+fee {
+		return &GameError{Message: "Not enough money. Need €" + formatMoney(price+fee) + " including the €" + formatMoney(fee) + " fee"}
 	}
 	
 	// Find item template
@@ -755,21 +1024,25 @@ func (gs *GameState) BuyItem(itemID string, price float64) error {
 	
 	gs.Money -= price
 	item := Item{
-		ID:          itemID,
-		Name:        itemTemplate.Name,
-		BuyPrice:    price,
-		MarketPrice: itemTemplate.MarketPrice,
-		BoughtAt:    time.Now(),
+		ID:            itemID,
+		Name:          itemTemplate.Name,
+		BuyPrice:      price,
+		MarketPrice:   itemTemplate.MarketPrice,
+		BoughtAt:      gs.CurrentDate,
+		FeesPaid:      fee,
+		Category:      itemTemplate.Category,
+		PurchaseValue: itemTemplate.MarketPrice,
 	}
 	gs.Inventory = append(gs.Inventory, item)
 	gs.addEvent("item_buy", "Bought "+item.Name+" for €"+formatMoney(price), -price)
+	gs.chargeFee(fee, item.Name)
 	return nil
 }
 
 // SellItem sells an item from inventory
 func (gs *GameState) SellItem(itemID string) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("sell_item"); err != nil {
+		return err
 	}
 	itemIndex := -1
 	for i, item := range gs.Inventory {
@@ -783,30 +1056,30 @@ func (gs *GameState) SellItem(itemID string) error {
 		return &GameError{Message: "Item not found in inventory"}
 	}
 	
+	gs.Inventory[itemIndex].depreciate(gs.CurrentDate)
 	item := gs.Inventory[itemIndex]
-	// Resale at market price (could be less than buy price)
+	// Resale at the depreciated market price (could be less than buy price)
 	revenue := item.MarketPrice
+	fee := gs.tradeFee(revenue)
 	gs.Money += revenue
 	gs.Inventory = append(gs.Inventory[:itemIndex], gs.Inventory[itemIndex+1:]...)
 	
-	profit := revenue - item.BuyPrice
+	profit := revenue - fee - item.BuyPrice - item.FeesPaid
 	gs.addEvent("item_sell", "Sold "+item.Name+" for €"+formatMoney(revenue), revenue)
+	gs.chargeFee(fee, item.Name)
 	if profit < 0 {
 		gs.addEvent("loss", "Lost €"+formatMoney(-profit)+" on resale", -profit)
 	}
 	return nil
 }
 
+// maxAcceptedOfferIDs is how many accepted offer IDs a game remembers
+const maxAcceptedOfferIDs = 50
+
 // AcceptOffer accepts an AI-generated offer
 func (gs *GameState) AcceptOffer(offerID string) error {
-	if gs.GameOver {
-		return &GameError{Message: "Game is over. You cannot perform actions."}
-	}
-	if !gs.CanPerformAction() {
-		if gs.IsInHospital {
-			return &GameError{Message: "You are in the hospital and cannot perform this action. You'll be released when your health reaches 20."}
-		}
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+	if err := gs.actionGuard("accept_offer"); err != nil {
+		return err
 	}
 	offerIndex := -1
 	for i, offer := range gs.ActiveOffers {
@@ -817,6 +1090,9 @@ func (gs *GameState) AcceptOffer(offerID string) error {
 	}
 	
 	if offerIndex == -1 {
+		if slices.Contains(gs.AcceptedOfferIDs, offerID) {
+			return &GameError{Message: "You already accepted this offer"}
+		}
 		return &GameError{Message: "Offer not found or expired"}
 	}
 	
@@ -833,45 +1109,18 @@ func (gs *GameState) AcceptOffer(offerID string) error {
 	// Deduct price
 	gs.Money -= offer.Price
 	
-	// Apply immediate stat effects
-	if offer.HealthChange != 0 {
-		gs.Health += offer.HealthChange
-		if gs.Health < 0 {
-			gs.Health = 0
-		}
-		if gs.Health > 100 {
-			gs.Health = 100
-		}
-	}
-	
-	if offer.EnergyChange != 0 {
-		gs.Energy += offer.EnergyChange
-		if gs.Energy < 0 {
-			gs.Energy = 0
-		}
-		if gs.Energy > 100 {
-			gs.Energy = 100
-		}
-	}
-	
-	if offer.ReputationChange != 0 {
-		gs.Reputation += offer.ReputationChange
-	}
-	
-	if offer.MoneyChange != 0 {
-		gs.Money += offer.MoneyChange
-	}
-	
-	// Determine if this is a recurring agreement or a one-time item
+	// A recurring offer becomes an agreement; its first period is charged now with the same
+	// money change as the agreement's later periods. Between players the price just paid, which
+	// the creator receives, is that first period's money.
+	var agreement Agreement
 	if offer.IsRecurring {
-		// Create an Agreement
 		recurrenceType := offer.RecurrenceType
 		if recurrenceType == "" {
 			recurrenceType = "monthly" // Default to monthly
 		}
 		
-		agreement := Agreement{
-			ID:              fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Intn(10000)),
+		agreement = Agreement{
+			ID:              generateID(),
 			Title:           offer.Title,
 			Description:     offer.Description,
 			RecurrenceType:  recurrenceType,
@@ -883,27 +1132,71 @@ func (gs *GameState) AcceptOffer(offerID string) error {
 			MoneyChange:     offer.MoneyChange,
 			IsTrickery:      offer.IsTrickery,
 			Reason:          offer.Reason,
+			Category:        offer.Category,
 			IsReciprocal:    false, // Buyer's agreement (not reciprocal)
 			OtherPartyID:    offer.CreatedBy, // Track the creator if it's a player-created offer
 			OriginalPrice:   offer.Price, // Store original price for penalty calculation
 		}
+		if offer.CreatedBy != "" {
+			agreement.LinkID = offer.ID // A player-created offer is sold only once
+		}
+		agreement.normalizeMoneyChange()
+		offer.MoneyChange = agreement.MoneyChange
+		if agreement.OtherPartyID != "" {
+			offer.MoneyChange = 0
+		}
+	}
+	
+	// A one-time purchase becomes an item whose effects apply when it is used (or while it is
+	// owned, see processItemEffects); anything else takes effect at once
+	itemEffects := !offer.IsRecurring && offer.Category == CategoryPurchase
+	var statChanges []string
+	if !itemEffects {
+		statChanges = gs.applyStatChanges(offer.Title, offer.HealthChange, offer.EnergyChange, offer.ReputationChange, offer.MoneyChange)
+	}
+	
+	// Determine if this is a recurring agreement or a one-time item
+	if offer.IsRecurring {
 		gs.Agreements = append(gs.Agreements, agreement)
 		
-		eventMsg := fmt.Sprintf("Started agreement: %s (Recurring: %s)", offer.Title, recurrenceType)
+		eventMsg := fmt.Sprintf("Started agreement: %s (Recurring: %s)", offer.Title, agreement.RecurrenceType)
 		gs.addEvent("agreement_started", eventMsg, -offer.Price+offer.MoneyChange)
-	} else {
-		// Create an Item
+	} else if itemEffects {
+		// Create an Item that carries the offer's effects
 		item := Item{
-			ID:              fmt.Sprintf("%d-%d", time.Now().UnixNano(), rand.Intn(10000)),
+			ID:              generateID(),
 			Name:            offer.Title,
 			BuyPrice:        offer.Price,
 			MarketPrice:     offer.Price * 0.7, // Resale value is 70% of buy price
 			BoughtAt:        gs.CurrentDate,
+			Category:        ItemOther,
+			PurchaseValue:   offer.Price * 0.7,
 			HealthChange:    offer.HealthChange,
 			EnergyChange:    offer.EnergyChange,
 			ReputationChange: offer.ReputationChange,
 			MoneyChange:     offer.MoneyChange,
-			EffectFrequency: "on_use", // Items typically have effects on use, but can be daily
+			EffectFrequency: normalizeEffectFrequency(offer.EffectFrequency),
+		}
+		item.Consumable = item.EffectFrequency == EffectOnUse
+		gs.Inventory = append(gs.Inventory, item)
+		
+		eventMsg := "Purchased item: " + offer.Title
+		if item.hasEffects() && item.EffectFrequency == EffectOnUse {
+			eventMsg += " (use it from your inventory for its effects)"
+		} else if item.hasEffects() {
+			eventMsg += " (its effects apply " + item.EffectFrequency + " while you own it)"
+		}
+		gs.addEvent("item_purchased", eventMsg, -offer.Price)
+	} else {
+		// Create an Item to show for it; its effects have already applied
+		item := Item{
+			ID:            generateID(),
+			Name:          offer.Title,
+			BuyPrice:      offer.Price,
+			MarketPrice:   offer.Price * 0.7, // Resale value is 70% of buy price
+			BoughtAt:      gs.CurrentDate,
+			Category:      ItemOther,
+			PurchaseValue: offer.Price * 0.7,
 		}
 		gs.Inventory = append(gs.Inventory, item)
 		
@@ -911,41 +1204,35 @@ func (gs *GameState) AcceptOffer(offerID string) error {
 		gs.addEvent("item_purchased", eventMsg, -offer.Price+offer.MoneyChange)
 	}
 	
-	// Remove offer
+	// Remove offer, remembering it so a repeated accept gets a clear answer
 	gs.ActiveOffers = append(gs.ActiveOffers[:offerIndex], gs.ActiveOffers[offerIndex+1:]...)
+	gs.AcceptedOfferIDs = append(gs.AcceptedOfferIDs, offerID)
+	if len(gs.AcceptedOfferIDs) > maxAcceptedOfferIDs {
+		gs.AcceptedOfferIDs = gs.AcceptedOfferIDs[len(gs.AcceptedOfferIDs)-maxAcceptedOfferIDs:]
+	}
+	recordOtherOfferAccepted(offer.Category, offer.IsTrickery)
 	
 	// Build event message for immediate effects
-	if offer.HealthChange != 0 || offer.EnergyChange != 0 || offer.ReputationChange != 0 || offer.MoneyChange != 0 {
-		var statChanges []string
-		if offer.HealthChange != 0 {
-			statChanges = append(statChanges, fmt.Sprintf("Health: %+d", offer.HealthChange))
-		}
-		if offer.EnergyChange != 0 {
-			statChanges = append(statChanges, fmt.Sprintf("Energy: %+d", offer.EnergyChange))
-		}
-		if offer.ReputationChange != 0 {
-			statChanges = append(statChanges, fmt.Sprintf("Reputation: %+d", offer.ReputationChange))
-		}
-		if offer.MoneyChange != 0 {
-			statChanges = append(statChanges, fmt.Sprintf("Money: %+.2f€", offer.MoneyChange))
-		}
-		if len(statChanges) > 0 {
-			eventMsg := "Immediate effects: " + strings.Join(statChanges, ", ")
-			gs.addEvent("offer_effects", eventMsg, 0)
-		}
+	if len(statChanges) > 0 {
+		eventMsg := "Immediate effects: " + strings.Join(statChanges, ", ")
+		gs.addEvent("offer_effects", eventMsg, 0)
 	}
 	
 	if offer.IsTrickery {
+		gs.noteScamAccepted("other", offer.Title)
 		gs.addEvent("trickery_warning", "⚠️ This was a trickery offer!", 0)
+		gs.adjustReputation(GetConfig().Reputation.ScamOffer, "fell for a scam offer")
 	}
 	
 	return nil
 }
 
-// NextDay advances the game to the next day
-func (gs *GameState) NextDay() {
+// NextDay advances the game to the next day and returns a digest of what happened during it
+func (gs *GameState) NextDay() *DailyDigest {
+	start := gs.startDigest()
 	gs.AdvanceTime(24 * time.Hour)
 	gs.addEvent("day_advanced", "Date: "+gs.CurrentDate.Format("2006-01-02"), 0)
+	return gs.finishDigest(start)
 }
 
 // AdvanceTime advances the game time by specified duration
@@ -955,16 +1242,24 @@ func (gs *GameState) AdvanceTime(duration time.Duration) {
 		return // Don't advance time if game is over
 	}
 	
+	previousDate := gs.CurrentDate
+	previousEnergy := gs.Energy
 	gs.CurrentDate = gs.CurrentDate.Add(duration)
+	gs.Hospital = gs.hospitalTerms()
+	
+	// Log work done in this time, then settle salary and rent for every payday crossed (also while in hospital)
+	workedHours := gs.logWork(previousDate)
+	gs.processSalary(previousDate)
 	
 	// Check for game over condition (negative money for > 1 month)
 	gs.checkGameOver()
 	if gs.GameOver {
 		return
 	}
+	gs.checkVictory()
 	
-	// Check if health dropped below 0 and admit to hospital if needed
-	if gs.Health < 0 && !gs.IsInHospital {
+	// Admit to hospital once health falls to the threshold (0 by default; health never goes below 0)
+	if gs.Health <= gs.Hospital.AdmitHealth && !gs.IsInHospital {
 		gs.admitToHospital()
 	}
 	
@@ -975,47 +1270,52 @@ func (gs *GameState) AdvanceTime(duration time.Duration) {
 	
 	// Check work status (can't work while in hospital)
 	if !gs.IsInHospital {
-		gs.CheckWorkStatus()
+		gs.CheckWorkStatus(workedHours)
 	}
 	
-	// Process agreements (recurring effects)
+	// Process agreements and owned items (recurring effects)
 	gs.processAgreements(duration)
+	gs.processItemEffects()
+	gs.depreciateItems()
 	
 	// Process health/energy changes based on time (only if not in hospital)
 	if !gs.IsInHospital {
 		hoursPassed := duration.Hours()
 		
-		// Check if it's night time (00:00 - 07:00)
-		isNightTime := gs.IsNightTime()
-		
-		// Lose health at night if no apartment (2 health per night, once per night period)
-		if isNightTime && gs.Apartment == nil {
-		// Check if we've already lost health this night period
-		// Lose health once per day when entering night (at 00:00)
-		currentDate := gs.CurrentDate.Format("2006-01-02")
-		lastLossDate := gs.LastNightHealthLossDate.Format("2006-01-02")
-		
-		// Check if we crossed into 00:00 (new day, new night period)
-		previousTime := gs.CurrentDate.Add(-duration)
-		previousHour := previousTime.Hour()
-		currentHour := gs.CurrentDate.Hour()
-		
-		// If we just crossed into 00:00 (entered night time) and haven't lost health today
-		if currentHour == 0 && previousHour >= 7 && currentDate != lastLossDate {
-			gs.Health -= 2
-			if gs.Health < 0 {
-				gs.Health = 0
+		// Lose health for every night spent without an apartment (2 health per night, charged when
+		// the night starts at 00:00). Count each midnight crossed, so large jumps don't skip nights.
+		if gs.Apartment == nil {
+			nights := 0
+			midnight := time.Date(previousDate.Year(), previousDate.Month(), previousDate.Day(), 0, 0, 0, 0, previousDate.Location()).AddDate(0, 0, 1)
+			for ; !midnight.After(gs.CurrentDate); midnight = midnight.AddDate(0, 0, 1) {
+				// Skip nights that were already charged
+				if midnight.Format("2006-01-02") == gs.LastNightHealthLossDate.Format("2006-01-02") {
+					continue
+				}
+				nights++
+				gs.LastNightHealthLossDate = midnight
+			}
+			gs.NightsHomeless += nights
+			
+			if nights > 0 {
+				healthLoss := 2 * nights
+				gs.Health -= healthLoss
+				if gs.Health < 0 {
+					gs.Health = 0
+				}
+				if nights == 1 {
+					gs.addEvent("health_lost_no_apartment", "Lost 2 health - You need an apartment! Sleeping on the street is dangerous.", 0)
+				} else {
+					gs.addEvent("health_lost_no_apartment", fmt.Sprintf("Lost %d health over %d nights - You need an apartment! Sleeping on the street is dangerous.", healthLoss, nights), 0)
+				}
 			}
-			gs.LastNightHealthLossDate = gs.CurrentDate
-			gs.addEvent("health_lost_no_apartment", "Lost 2 health - You need an apartment! Sleeping on the street is dangerous.", 0)
 		}
-	}
 	
-		// Lose health/energy while working (AI-determined rates per job)
-		if gs.IsWorking && gs.Job != nil && gs.Job.HealthLossPerHour > 0 && gs.Job.EnergyLossPerHour > 0 {
-			// Calculate loss based on hours passed
-			healthLossFloat := hoursPassed * gs.Job.HealthLossPerHour
-			energyLossFloat := hoursPassed * gs.Job.EnergyLossPerHour
+		// Lose health/energy for the hours worked (AI-determined rates per job)
+		if workedHours > 0 && gs.Job != nil && gs.Job.HealthLossPerHour > 0 && gs.Job.EnergyLossPerHour > 0 {
+			// Calculate loss based on hours worked
+			healthLossFloat := workedHours * gs.Job.HealthLossPerHour
+			energyLossFloat := workedHours * gs.Job.EnergyLossPerHour
 			
 			// For very small time increments, use probabilistic loss to ensure it happens
 			healthLoss := int(healthLossFloat)
@@ -1023,20 +1323,20 @@ func (gs *GameState) AdvanceTime(duration time.Duration) {
 			
 			// Handle fractional loss: if we have fractional part, lose 1 point probabilistically
 			healthFraction := healthLossFloat - float64(healthLoss)
-			if healthFraction > 0 && rand.Float64() < healthFraction {
+			if healthFraction > 0 && gs.rng.Float64() < healthFraction {
 				healthLoss++
 			}
 			// Always lose at least 1 point if we've worked at least 1/60 hour (1 minute) and rate > 0
-			if hoursPassed >= 1.0/60.0 && healthLoss == 0 && gs.Job.HealthLossPerHour > 0 {
+			if workedHours >= 1.0/60.0 && healthLoss == 0 && gs.Job.HealthLossPerHour > 0 {
 				healthLoss = 1
 			}
 			
 			energyFraction := energyLossFloat - float64(energyLoss)
-			if energyFraction > 0 && rand.Float64() < energyFraction {
+			if energyFraction > 0 && gs.rng.Float64() < energyFraction {
 				energyLoss++
 			}
 			// Always lose at least 1 point if we've worked at least 1/60 hour (1 minute) and rate > 0
-			if hoursPassed >= 1.0/60.0 && energyLoss == 0 && gs.Job.EnergyLossPerHour > 0 {
+			if workedHours >= 1.0/60.0 && energyLoss == 0 && gs.Job.EnergyLossPerHour > 0 {
 				energyLoss = 1
 			}
 			
@@ -1045,16 +1345,21 @@ func (gs *GameState) AdvanceTime(duration time.Duration) {
 				gs.Health = 0
 			}
 			
+			// Work that was already running on empty burns health instead
+			if gs.Energy == 0 {
+				gs.burnout(workedHours)
+			}
+			
 			gs.Energy -= energyLoss
 			if gs.Energy < 0 {
 				gs.Energy = 0
 			}
 		}
 		
-		// Gain health/energy while in apartment (if not working)
-		if gs.Apartment != nil && !gs.IsWorking {
-			healthGain := int(hoursPassed * float64(gs.Apartment.HealthGain))
-			energyGain := int(hoursPassed * float64(gs.Apartment.EnergyGain))
+		// Gain health/energy in the apartment for the hours not spent working
+		if restHours := hoursPassed - workedHours; gs.Apartment != nil && restHours > 0 {
+			healthGain := int(restHours * float64(gs.Apartment.HealthGain))
+			energyGain := int(restHours * float64(gs.Apartment.EnergyGain))
 			
 			gs.Health += healthGain
 			if gs.Health > 100 {
@@ -1074,43 +1379,61 @@ func (gs *GameState) AdvanceTime(duration time.Duration) {
 		
 		// If we crossed a day boundary, update prices
 		if currentDay != lastUpdateDay || duration >= 24*time.Hour {
+			gs.updateMarketIndex()
+			gs.applyDueNews()
+			held := map[string]bool{}
 			for i := range gs.Stocks {
-				change := (rand.Float64() - 0.5) * 0.1
 				oldPrice := gs.Stocks[i].CurrentPrice
-				gs.Stocks[i].CurrentPrice = gs.Stocks[i].BuyPrice * (1 + change)
+				gs.Stocks[i].CurrentPrice = gs.stockPrice(gs.Stocks[i])
 				
-				// Add to history if significant change (5% or more)
+				// Add the day's price to history, marking a significant change (5% or more)
+				event := ""
 				if abs(oldPrice - gs.Stocks[i].CurrentPrice) > oldPrice * 0.05 {
-					event := "surge"
+					event = "surge"
 					if gs.Stocks[i].CurrentPrice < oldPrice {
 						event = "crash"
 					}
-					gs.StockHistory = append(gs.StockHistory, StockHistory{
+				}
+				if !held[gs.Stocks[i].Symbol] || event != "" {
+					gs.addStockHistory(StockHistory{
 						Symbol: gs.Stocks[i].Symbol,
 						Price:  gs.Stocks[i].CurrentPrice,
 						Date:   gs.CurrentDate,
 						Event:  event,
 					})
 				}
+				held[gs.Stocks[i].Symbol] = true
 			}
 			for i := range gs.Crypto {
-				change := (rand.Float64() - 0.5) * 0.15
-				gs.Crypto[i].CurrentPrice = gs.Crypto[i].BuyPrice * (1 + change)
+				gs.Crypto[i].CurrentPrice = gs.prices.cryptoPrice(gs.Crypto[i].Symbol, gs.CurrentDate)
 			}
+			for i := range gs.StockOffers {
+				offer := &gs.StockOffers[i]
+				offer.CurrentPrice = gs.prices.stockPrice(offer.Symbol, gs.CurrentDate, offer.CurrentPrice, offer.Beta)
+			}
+			gs.sampleStockPrices(held)
+			gs.liquidateShorts()
 		}
 	} // End of "if !gs.IsInHospital" block
 	
+	gs.warnEnergy(previousEnergy)
+	
 	// Remove expired offers
 	gs.removeExpiredOffers()
+	
+	gs.recordNetWorth()
+	gs.checkAchievements()
 }
 
 // removeExpiredOffers removes expired job offers, apartment offers, stock offers, and regular offers
 func (gs *GameState) removeExpiredOffers() {
-	// Remove expired job offers
+	// Remove expired job offers; scams that expire unaccepted count as avoided
 	validJobOffers := []JobOffer{}
 	for _, offer := range gs.JobOffers {
 		if gs.CurrentDate.Before(offer.ExpiresAt) {
 			validJobOffers = append(validJobOffers, offer)
+		} else if offer.IsTrickery {
+			gs.noteScamAvoided()
 		}
 	}
 	gs.JobOffers = validJobOffers
@@ -1120,6 +1443,8 @@ func (gs *GameState) removeExpiredOffers() {
 	for _, offer := range gs.ApartmentOffers {
 		if gs.CurrentDate.Before(offer.ExpiresAt) {
 			validApartmentOffers = append(validApartmentOffers, offer)
+		} else if offer.IsTrickery {
+			gs.noteScamAvoided()
 		}
 	}
 	gs.ApartmentOffers = validApartmentOffers
@@ -1129,6 +1454,8 @@ func (gs *GameState) removeExpiredOffers() {
 	for _, offer := range gs.StockOffers {
 		if gs.CurrentDate.Before(offer.ExpiresAt) {
 			validStockOffers = append(validStockOffers, offer)
+		} else if !offer.IsSafe {
+			gs.noteScamAvoided()
 		}
 	}
 	gs.StockOffers = validStockOffers
@@ -1138,50 +1465,53 @@ func (gs *GameState) removeExpiredOffers() {
 	for _, offer := range gs.ActiveOffers {
 		if gs.CurrentDate.Before(offer.ExpiresAt) {
 			validOffers = append(validOffers, offer)
+		} else if offer.IsTrickery {
+			gs.noteScamAvoided()
 		}
 	}
 	gs.ActiveOffers = validOffers
 }
 
-// admitToHospital automatically admits player to hospital when health drops below 0
+// admitToHospital automatically admits player to hospital when health falls to the admission threshold
 func (gs *GameState) admitToHospital() {
 	gs.IsInHospital = true
 	gs.HospitalEntryTime = gs.CurrentDate
 	gs.IsWorking = false // Can't work while in hospital
-	gs.addEvent("hospital_admission", "⚠️ Health critical! Admitted to hospital. Cost: €100/hour. You'll be released when health reaches 20.", 0)
+	gs.addEvent("hospital_admission", fmt.Sprintf("⚠️ Health critical! Admitted to hospital. Cost: €%.0f/hour. ", gs.hospitalHourlyRate())+gs.hospitalReleaseMessage(), 0)
 }
 
 // processHospitalStay processes the hospital stay: recovers health, charges money, releases when health >= 20
 func (gs *GameState) processHospitalStay(duration time.Duration) {
 	hoursPassed := duration.Hours()
+	terms := gs.hospitalTerms()
 	
-	// Charge hospital fees: €100 per hour
-	totalCost := hoursPassed * 100.0
-	gs.Money -= totalCost
-	
-	// Recover health: +1 per hour
-	healthRecovery := int(hoursPassed)
-	gs.Health += healthRecovery
+	// Only the hours until health reaches the release threshold are spent in hospital
+	hoursInHospital := min(hoursPassed, float64(max(terms.ReleaseHealth-gs.Health, 0))/float64(terms.RecoveryPerHour))
 	
-	// Check if health reached 20 or above (release condition)
-	if gs.Health >= 20 {
-		gs.Health = 20 // Cap at 20 for release
-		daysInHospital := gs.CurrentDate.Sub(gs.HospitalEntryTime).Hours() / 24
-		totalHospitalCost := daysInHospital * 24 * 100.0
-		gs.addEvent("hospital_release", fmt.Sprintf("Released from hospital after %.1f days. Total cost: €%.2f", daysInHospital, totalHospitalCost), -totalHospitalCost)
+	// Charge hospital fees: €100 per hour, less what insurance covers
+	totalCost := hoursInHospital * gs.hospitalHourlyRate()
+	gs.Money -= totalCost
+	gs.HospitalBill += totalCost
+	
+	// Recover health, including the hours after release in the same tick
+	healthRecovery := int(hoursPassed * float64(terms.RecoveryPerHour))
+	gs.Health = min(gs.Health+healthRecovery, 100)
+	
+	// Check if health reached the release threshold
+	if gs.Health >= terms.ReleaseHealth {
+		releasedAt := gs.CurrentDate.Add(-time.Duration((hoursPassed - hoursInHospital) * float64(time.Hour)))
+		daysInHospital := releasedAt.Sub(gs.HospitalEntryTime).Hours() / 24
+		// The event carries only this tick's charge; earlier ones were logged as hospital_stay
+		gs.addEvent("hospital_release", fmt.Sprintf("Released from hospital after %.1f days. Total cost: €%.2f", daysInHospital, gs.HospitalBill), -totalCost)
 		gs.IsInHospital = false
 		gs.HospitalEntryTime = time.Time{}
+		gs.HospitalBill = 0
 	} else {
 		// Still in hospital - add event for hourly charges if significant time passed
 		if hoursPassed >= 1.0 {
 			gs.addEvent("hospital_stay", fmt.Sprintf("Hospital stay: +%d health, -€%.2f (Health: %d/100)", healthRecovery, totalCost, gs.Health), -totalCost)
 		}
 	}
-	
-	// Ensure health doesn't go above 100
-	if gs.Health > 100 {
-		gs.Health = 100
-	}
 }
 
 // checkGameOver checks if the game should end (negative money for > 1 month)
@@ -1209,132 +1539,190 @@ func (gs *GameState) checkGameOver() {
 	}
 }
 
+// checkVictory marks the game as won once the difficulty's goal is reached: the target net worth,
+// or surviving the target number of days with positive money. The game can continue afterwards.
+func (gs *GameState) checkVictory() {
+	if gs.GameWon || gs.GameOver {
+		return
+	}
+	
+	goal := gs.difficulty()
+	daysSurvived := int(gs.CurrentDate.Sub(gs.StartDate).Hours() / 24)
+	if netWorth := gs.NetWorth(); goal.GoalNetWorth > 0 && netWorth >= goal.GoalNetWorth {
+		gs.GameWonReason = fmt.Sprintf("Victory: You reached a net worth of €%.2f (goal €%.2f).", netWorth, goal.GoalNetWorth)
+	} else if goal.GoalDays > 0 && daysSurvived >= goal.GoalDays && gs.Money > 0 {
+		gs.GameWonReason = fmt.Sprintf("Victory: You survived %d days and kept your finances positive.", daysSurvived)
+	} else {
+		return
+	}
+	gs.GameWon = true
+	gs.addEvent("game_won", gs.GameWonReason, 0)
+}
+
 // processAgreements processes recurring agreements and applies their effects
 func (gs *GameState) processAgreements(duration time.Duration) {
 	now := gs.CurrentDate
 	
 	for i := range gs.Agreements {
 		agreement := &gs.Agreements[i]
-		shouldProcess := false
+		if agreement.Suspended {
+			continue
+		}
 		
 		switch agreement.RecurrenceType {
 		case "daily":
 			// Process once per day
 			daysSinceLastProcess := now.Sub(agreement.LastProcessedAt).Hours() / 24
 			if daysSinceLastProcess >= 1.0 {
-				shouldProcess = true
+				gs.applyAgreement(agreement)
+				agreement.LastProcessedAt = now
 			}
 		case "weekly":
 			// Process once per week
 			daysSinceLastProcess := now.Sub(agreement.LastProcessedAt).Hours() / 24
 			if daysSinceLastProcess >= 7.0 {
-				shouldProcess = true
-			}
-		case "monthly":
-			// Process once per month (on the same day of month)
-			lastDay := agreement.LastProcessedAt.Day()
-			currentDay := now.Day()
-			lastMonth := agreement.LastProcessedAt.Month()
-			currentMonth := now.Month()
-			lastYear := agreement.LastProcessedAt.Year()
-			currentYear := now.Year()
-			
-			// Check if we've crossed a month boundary and it's the same day or later
-			if (currentYear > lastYear) || 
-			   (currentYear == lastYear && currentMonth > lastMonth) ||
-			   (currentYear == lastYear && currentMonth == lastMonth && currentDay >= lastDay && now.Sub(agreement.LastProcessedAt).Hours() >= 24*24) {
-				shouldProcess = true
+				gs.applyAgreement(agreement)
+				agreement.LastProcessedAt = now
 			}
 		default:
-			// Default to monthly
-			lastDay := agreement.LastProcessedAt.Day()
-			currentDay := now.Day()
-			lastMonth := agreement.LastProcessedAt.Month()
-			currentMonth := now.Month()
-			lastYear := agreement.LastProcessedAt.Year()
-			currentYear := now.Year()
-			
-			if (currentYear > lastYear) || 
-			   (currentYear == lastYear && currentMonth > lastMonth) ||
-			   (currentYear == lastYear && currentMonth == lastMonth && currentDay >= lastDay && now.Sub(agreement.LastProcessedAt).Hours() >= 24*24) {
-				shouldProcess = true
+			// Monthly (also the default): process once for every calendar month that has elapsed,
+			// so a jump of several months settles each of them
+			for due := agreement.nextMonthlyDue(); !due.After(now) && !agreement.Suspended; due = agreement.nextMonthlyDue() {
+				gs.applyAgreement(agreement)
+				agreement.LastProcessedAt = due
 			}
 		}
-		
-		if shouldProcess {
-			// Apply agreement effects
-			gs.Health += agreement.HealthChange
-			if gs.Health > 100 {
-				gs.Health = 100
-			}
-			if gs.Health < 0 {
-				gs.Health = 0
-			}
-			
-			gs.Energy += agreement.EnergyChange
-			if gs.Energy > 100 {
-				gs.Energy = 100
-			}
-			if gs.Energy < 0 {
-				gs.Energy = 0
-			}
-			
-			gs.Reputation += agreement.ReputationChange
-			
-			gs.Money += agreement.MoneyChange
-			
-			// Build event message
-			var statChanges []string
-			if agreement.HealthChange != 0 {
-				statChanges = append(statChanges, fmt.Sprintf("Health: %+d", agreement.HealthChange))
-			}
-			if agreement.EnergyChange != 0 {
-				statChanges = append(statChanges, fmt.Sprintf("Energy: %+d", agreement.EnergyChange))
-			}
-			if agreement.ReputationChange != 0 {
-				statChanges = append(statChanges, fmt.Sprintf("Reputation: %+d", agreement.ReputationChange))
-			}
-			if agreement.MoneyChange != 0 {
-				statChanges = append(statChanges, fmt.Sprintf("Money: %+.2f€", agreement.MoneyChange))
-			}
-			
-			eventMsg := fmt.Sprintf("Agreement: %s (%s)", agreement.Title, agreement.RecurrenceType)
-			if len(statChanges) > 0 {
-				eventMsg += " - " + strings.Join(statChanges, ", ")
-			}
-			
-			gs.addEvent("agreement_processed", eventMsg, agreement.MoneyChange)
-			agreement.LastProcessedAt = now
+	}
+}
+
+// nextMonthlyDue returns when a monthly agreement is next due: the first monthly anniversary of its
+// start date after LastProcessedAt. Anniversaries on days a month doesn't have fall on that month's
+// last day (an agreement started on Jan 31 is due on Feb 28/29, then Mar 31).
+func (a *Agreement) nextMonthlyDue() time.Time {
+	anchor := a.StartedAt
+	if anchor.IsZero() {
+		anchor = a.LastProcessedAt
+	}
+	
+	months := (a.LastProcessedAt.Year()-anchor.Year())*12 + int(a.LastProcessedAt.Month()-anchor.Month())
+	due := addMonths(anchor, months)
+	for !due.After(a.LastProcessedAt) {
+		months++
+		due = addMonths(anchor, months)
+	}
+	return due
+}
+
+// addMonths adds calendar months to t, clamping the day to the end of shorter months
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	day := t.Day()
+	if lastDay := firstOfMonth.AddDate(0, 1, -1).Day(); day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// normalizeMoneyChange makes the sign of the agreement's per-period MoneyChange match which side
+// of it the player is on. The creator's reciprocal agreement always receives money, and the buyer of a
+// player-created offer (or the agreement a chat message describes, which has a Price) always pays;
+// between players both sides move the per-period price, so one pays exactly what the other receives.
+// A subscription or charity never pays out. Non-finite values count as no money change.
+func (a *Agreement) normalizeMoneyChange() {
+	if math.IsNaN(a.MoneyChange) || math.IsInf(a.MoneyChange, 0) {
+		a.MoneyChange = 0
+	}
+	
+	price := a.OriginalPrice
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		price = a.Price
+	}
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		price = 0
+	}
+	
+	switch {
+	case a.IsReciprocal:
+		if price > 0 {
+			a.MoneyChange = price
+		} else if a.MoneyChange < 0 {
+			a.MoneyChange = -a.MoneyChange
+		}
+	case a.OtherPartyID != "" && price > 0:
+		a.MoneyChange = -price
+	case a.OtherPartyID != "" || a.Price > 0 || a.Category == CategorySubscription || a.Category == CategoryCharity:
+		if a.MoneyChange > 0 {
+			a.MoneyChange = -a.MoneyChange
 		}
 	}
 }
 
-// QuitAgreement cancels an active agreement (may have early termination penalty)
-func (gs *GameState) QuitAgreement(agreementID string) error {
-	if !gs.CanPerformAction() {
-		return &GameError{Message: "You are currently working and cannot perform this action"}
+// matchesReciprocal reports whether reciprocal is the creator's side of a's agreement with buyerID.
+// Agreements made before link IDs existed have none, and are matched by the buyer alone.
+func (a *Agreement) matchesReciprocal(reciprocal *Agreement, buyerID string) bool {
+	return !a.IsReciprocal && reciprocal.IsReciprocal && reciprocal.OtherPartyID == buyerID && reciprocal.LinkID == a.LinkID
+}
+
+// isCounterpart reports whether other, one of the other party's agreements, is the other side of
+// a, one of playerID's
+func (a *Agreement) isCounterpart(other *Agreement, playerID string) bool {
+	if a.IsReciprocal {
+		return other.OtherPartyID == playerID && other.matchesReciprocal(a, a.OtherPartyID)
 	}
+	return a.matchesReciprocal(other, playerID)
+}
+
+// applyAgreement applies one period of an agreement's effects and records it in the history
+func (gs *GameState) applyAgreement(agreement *Agreement) {
+	// Apply agreement effects
+	statChanges := gs.applyStatChanges(agreement.Title, agreement.HealthChange, agreement.EnergyChange, agreement.ReputationChange, agreement.MoneyChange)
 	
-	agreementIndex := -1
-	for i, agreement := range gs.Agreements {
-		if agreement.ID == agreementID {
-			agreementIndex = i
-			break
-		}
+	eventMsg := fmt.Sprintf("Agreement: %s (%s)", agreement.Title, agreement.RecurrenceType)
+	if len(statChanges) > 0 {
+		eventMsg += " - " + strings.Join(statChanges, ", ")
 	}
 	
-	if agreementIndex == -1 {
-		return &GameError{Message: "Agreement not found"}
+	if agreement.MoneyChange >= 0 || gs.Money >= 0 {
+		agreement.MissedPayments = 0
+		gs.addEvent("agreement_processed", eventMsg, agreement.MoneyChange)
+		return
 	}
 	
-	agreement := gs.Agreements[agreementIndex]
-	
+	// The charge left the player in debt: say so, and stop the agreement once it keeps happening
+	agreement.MissedPayments++
+	limit := GetConfig().Agreements.SuspendAfterMissedPayments
+	// One side of an agreement with another player cannot stop while the other side goes on
+	agreement.Suspended = limit > 0 && agreement.MissedPayments >= limit && agreement.OtherPartyID == ""
+	eventMsg += fmt.Sprintf(" - you couldn't afford it and now have €%.2f", gs.Money)
+	gs.addEvent("agreement_unaffordable", eventMsg, agreement.MoneyChange)
+	if agreement.Suspended {
+		gs.addEvent("agreement_suspended", fmt.Sprintf("%s was suspended after %d missed payments", agreement.Title, agreement.MissedPayments), 0)
+	}
+	gs.newUnpaidCharges = append(gs.newUnpaidCharges, UnpaidCharge{
+		AgreementID:    agreement.ID,
+		Title:          agreement.Title,
+		Amount:         agreement.MoneyChange,
+		Money:          gs.Money,
+		MissedPayments: agreement.MissedPayments,
+		Suspended:      agreement.Suspended,
+	})
+}
+
+// takeUnpaidCharges returns the agreement charges the player could not afford since the last call and clears them
+func (gs *GameState) takeUnpaidCharges() []UnpaidCharge {
+	charges := gs.newUnpaidCharges
+	gs.newUnpaidCharges = nil
+	return charges
+}
+
+// earlyTerminationPenalty returns the fee for cancelling the agreement at the given time
+func (a *Agreement) earlyTerminationPenalty(now time.Time) float64 {
 	// Calculate early termination penalty based on how long the agreement has been active
-	daysActive := gs.CurrentDate.Sub(agreement.StartedAt).Hours() / 24
+	daysActive := now.Sub(a.StartedAt).Hours() / 24
 	penalty := 0.0
 	
 	// Penalty is higher if cancelled early (within first period)
-	switch agreement.RecurrenceType {
+	switch a.RecurrenceType {
 	case "daily":
 		if daysActive < 1 {
 			penalty = 50.0 // 50 EUR penalty for cancelling same day
@@ -1355,6 +1743,30 @@ func (gs *GameState) QuitAgreement(agreementID string) error {
 		}
 	}
 	
+	return penalty
+}
+
+// QuitAgreement cancels an active agreement (may have early termination penalty)
+func (gs *GameState) QuitAgreement(agreementID string) error {
+	if !gs.CanPerformAction() {
+		return &GameError{Message: "You are currently working and cannot perform this action"}
+	}
+	
+	agreementIndex := -1
+	for i, agreement := range gs.Agreements {
+		if agreement.ID == agreementID {
+			agreementIndex = i
+			break
+		}
+	}
+	
+	if agreementIndex == -1 {
+		return &GameError{Message: "Agreement not found"}
+	}
+	
+	agreement := gs.Agreements[agreementIndex]
+	penalty := agreement.earlyTerminationPenalty(gs.CurrentDate)
+	
 	// Apply penalty if any
 	if penalty > 0 {
 		if gs.Money < penalty {
@@ -1387,9 +1799,63 @@ func (gs *GameState) addEvent(eventType, message string, amount float64) {
 		Type:      eventType,
 		Message:   message,
 		Amount:    amount,
-		Timestamp: time.Now(),
+		Timestamp: gs.now(),
 	}
-	gs.History = append(gs.History, event)
+	gs.History.Add(event)
+	recordAuditEvent(gs.PlayerID, event)
+	publishEvent(gs.PlayerID, event)
+}
+
+// noteScamAvoided counts a scam offer that expired unaccepted or was reported
+func (gs *GameState) noteScamAvoided() {
+	gs.ScamsAvoided++
+	gs.recordScamOutcome(false)
+}
+
+// noteScamAccepted counts an accepted scam offer and reports it to the events webhook. It is
+// not added to the history, so accepting a scam does not give it away in the player's log.
+func (gs *GameState) noteScamAccepted(offerType, title string) {
+	gs.ScamsAccepted++
+	gs.recordScamOutcome(true)
+	publishEvent(gs.PlayerID, Event{
+		Type:      "scam_accepted",
+		Message:   fmt.Sprintf("Accepted a scam %s offer: %s", offerType, title),
+		Timestamp: gs.now(),
+	})
+}
+
+// snapshot returns a copy of the game state that does not share slices or pointers with gs,
+// so it can be read (e.g. by an AI call) after the game's lock has been released. Only the
+// random source is shared, so offers generated from the snapshot stay on the game's sequence.
+func (gs *GameState) snapshot() *GameState {
+	cp := *gs
+	if gs.Job != nil {
+		job := *gs.Job
+		cp.Job = &job
+	}
+	if gs.Apartment != nil {
+		apartment := *gs.Apartment
+		cp.Apartment = &apartment
+	}
+	cp.Properties = slices.Clone(gs.Properties)
+	cp.Stocks = slices.Clone(gs.Stocks)
+	cp.Crypto = slices.Clone(gs.Crypto)
+	cp.Inventory = slices.Clone(gs.Inventory)
+	cp.History = gs.History.Clone()
+	cp.ActiveOffers = slices.Clone(gs.ActiveOffers)
+	cp.JobOffers = slices.Clone(gs.JobOffers)
+	cp.ApartmentOffers = slices.Clone(gs.ApartmentOffers)
+	cp.StockOffers = slices.Clone(gs.StockOffers)
+	cp.StockHistory = slices.Clone(gs.StockHistory)
+	cp.NetWorthHistory = slices.Clone(gs.NetWorthHistory)
+	cp.Agreements = slices.Clone(gs.Agreements)
+	cp.Achievements = slices.Clone(gs.Achievements)
+	cp.AcceptedOfferIDs = slices.Clone(gs.AcceptedOfferIDs)
+	cp.RecentScams = slices.Clone(gs.RecentScams)
+	cp.newAchievements = nil
+	cp.newNews = nil
+	cp.newUnpaidCharges = nil
+	return &cp
 }
 
 // GameError represents a game error
@@ -1448,7 +1914,17 @@ func formatIntWithPadding(i int, width int) string {
 	return str
 }
 
+// generateID returns a random (version 4) UUID. IDs come from crypto/rand so that offers, agreements
+// and events created concurrently by the background generators and player actions never collide.
 func generateID() string {
-	return time.Now().Format("20060102150405") + formatInt(rand.Intn(10000))
+	var b [16]byte
+	if idRand != nil {
+		idRand.Read(b[:])
+	} else if _, err := rand.Read(b[:]); err != nil {
+		panic("generateID: crypto/rand failed: " + err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
 
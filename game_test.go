@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// paidTestJob is a monthly fixed-time job that costs no health or energy
+func paidTestJob() *Job {
+	return &Job{Title: "Clerk", Salary: 3000, HoursPerDay: 8, WorkType: "fixed_time", WorkStart: "09:00", WorkEnd: "17:00", PayFrequency: PayMonthly}
+}
+
+func TestProcessSalaryAcrossMonths(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps int
+		step  time.Duration
+	}{
+		{"one 90-day jump", 1, 90 * 24 * time.Hour},
+		{"daily advances", 90, 24 * time.Hour},
+		{"three 30-day jumps", 3, 30 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestState(t, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+			game.Money = 50000
+			game.Job = paidTestJob()
+			game.Apartment = &Apartment{ID: "home", Title: "Flat", Rent: 600, HealthGain: 5, EnergyGain: 5}
+			for i := 0; i < tt.steps; i++ {
+				game.AdvanceTime(tt.step)
+			}
+			
+			// Jan 2 + 90 days is Apr 2: the 1sts of February, March and April were crossed
+			if salaries := eventsOfType(game, "salary"); len(salaries) != 3 {
+				t.Errorf("got %d salary payments, want 3", len(salaries))
+			}
+			rents := eventsOfType(game, "rent_paid")
+			if len(rents) != 3 {
+				t.Fatalf("got %d rent payments, want 3", len(rents))
+			}
+			for _, rent := range rents {
+				if rent.Amount != -600 {
+					t.Errorf("got a rent payment of %.2f, want -600", rent.Amount)
+				}
+			}
+			if !game.LastSalaryDate.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("LastSalaryDate is %v, want April 1st", game.LastSalaryDate)
+			}
+		})
+	}
+}
+
+func TestProcessSalaryPaysEachPeriodOnce(t *testing.T) {
+	game := newTestState(t, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+	game.Job = paidTestJob()
+	game.AdvanceTime(40 * 24 * time.Hour)
+	
+	// Settling the same period again, as an overlapping advance would, pays nothing new
+	game.processSalary(time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC))
+	if salaries := eventsOfType(game, "salary"); len(salaries) != 1 {
+		t.Errorf("got %d salary payments, want 1", len(salaries))
+	}
+}
+
+func TestProcessAgreementsMonthly(t *testing.T) {
+	tests := []struct {
+		name    string
+		started time.Time
+		now     time.Time
+		want    int
+		next    time.Time
+	}{
+		{"Feb to Mar", time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC), 1, time.Date(2025, 4, 10, 9, 0, 0, 0, time.UTC)},
+		{"short of a month after Feb", time.Date(2025, 2, 10, 9, 0, 0, 0, time.UTC), time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC), 0, time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC)},
+		{"Jan 31 to Feb 28", time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2025, 2, 28, 9, 0, 0, 0, time.UTC), 1, time.Date(2025, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"Jan 31 to Feb 29 in a leap year", time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC), 1, time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC)},
+		{"six-month jump", time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2025, 7, 31, 9, 0, 0, 0, time.UTC), 6, time.Date(2025, 8, 31, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestState(t, tt.started)
+			game.Money = 1000
+			game.Agreements = []Agreement{{ID: "gym", Title: "Gym", RecurrenceType: "monthly", StartedAt: tt.started, LastProcessedAt: tt.started, MoneyChange: -40}}
+			game.CurrentDate = tt.now
+			game.processAgreements(tt.now.Sub(tt.started))
+			
+			if processed := eventsOfType(game, "agreement_processed"); len(processed) != tt.want {
+				t.Errorf("got %d charges, want %d", len(processed), tt.want)
+			}
+			if want := 1000 - 40*float64(tt.want); game.Money != want {
+				t.Errorf("money is %.2f, want %.2f", game.Money, want)
+			}
+			if next := game.Agreements[0].nextMonthlyDue(); !next.Equal(tt.next) {
+				t.Errorf("next charge on %v, want %v", next, tt.next)
+			}
+		})
+	}
+}
+
+// Advancing a day at a time charges a monthly agreement as often as one jump over the same months
+func TestProcessAgreementsMonthlyDailyAdvances(t *testing.T) {
+	started := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+	game := newTestState(t, started)
+	game.Agreements = []Agreement{{ID: "gym", Title: "Gym", RecurrenceType: "monthly", StartedAt: started, LastProcessedAt: started, MoneyChange: -40}}
+	for game.CurrentDate.Before(time.Date(2025, 7, 31, 9, 0, 0, 0, time.UTC)) {
+		game.CurrentDate = game.CurrentDate.Add(24 * time.Hour)
+		game.processAgreements(24 * time.Hour)
+	}
+	if processed := eventsOfType(game, "agreement_processed"); len(processed) != 6 {
+		t.Errorf("got %d charges, want 6", len(processed))
+	}
+}
+
+func TestNightHealthLossOverThreeDays(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps int
+		step  time.Duration
+	}{
+		{"one 3-day advance", 1, 72 * time.Hour},
+		{"hourly advances", 72, time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A player with a home that gives nothing back loses health like the homeless one
+			// except for the nights
+			homeless := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			housed := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			housed.Apartment = &Apartment{ID: "home", Title: "Flat"}
+			for i := 0; i < tt.steps; i++ {
+				homeless.AdvanceTime(tt.step)
+				housed.AdvanceTime(tt.step)
+			}
+			
+			if homeless.NightsHomeless != 3 {
+				t.Errorf("got %d homeless nights, want 3", homeless.NightsHomeless)
+			}
+			if loss := housed.Health - homeless.Health; loss != 6 {
+				t.Errorf("lost %d health to the nights, want 6 (2 for each of 3 nights)", loss)
+			}
+			if !homeless.LastNightHealthLossDate.Equal(time.Date(2025, 1, 9, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("LastNightHealthLossDate is %v, want the third midnight", homeless.LastNightHealthLossDate)
+			}
+		})
+	}
+}
+
+// IDs made at the same moment by many goroutines, as the offer generators and concurrent actions
+// do, are all different
+func TestGenerateIDUniqueUnderConcurrency(t *testing.T) {
+	const goroutines, perGoroutine = 50, 200
+	ids := make(chan string, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				ids <- generateID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	for id := range ids {
+		if seen[id] {
+			t.Fatalf("generateID returned %s twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("got %d IDs, want %d", len(seen), goroutines*perGoroutine)
+	}
+}
+
+// Selling straight after buying uses the same day's price, so the fees make every flip a loss
+func TestBuyThenSellStockNeverProfits(t *testing.T) {
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	game.Money = 1e6
+	for trial := 0; trial < 200; trial++ {
+		symbol := fmt.Sprintf("FLIP%d", trial)
+		game.CurrentDate = game.CurrentDate.Add(24 * time.Hour)
+		game.StockOffers = []StockOffer{{ID: symbol, Symbol: symbol, CompanyName: "Flip Co", CurrentPrice: 100, Beta: 2, ExpiresAt: game.CurrentDate.Add(time.Hour)}}
+		before := game.Money
+		if err := game.BuyStock(symbol, 10); err != nil {
+			t.Fatalf("trial %d: buying failed: %v", trial, err)
+		}
+		if err := game.SellStock(symbol, 10); err != nil {
+			t.Fatalf("trial %d: selling failed: %v", trial, err)
+		}
+		if game.Money >= before {
+			t.Fatalf("trial %d: went from €%.2f to €%.2f by buying and selling at once", trial, before, game.Money)
+		}
+	}
+}
@@ -3,15 +3,17 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
+	"crypto/hmac"
 	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log"
 	"math/rand"
 	"net/http"
-	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -19,42 +21,23 @@ import (
 	"github.com/gorilla/websocket"
 )
 
-// Logging helpers for resource tracking
-func logLockAcquire(lockType, playerID string) {
-	_, file, line, _ := runtime.Caller(1)
-	log.Printf("[LOCK_ACQUIRE] %s for player %s at %s:%d", lockType, playerID, file, line)
-}
-
-func logLockRelease(lockType, playerID string) {
-	_, file, line, _ := runtime.Caller(1)
-	log.Printf("[LOCK_RELEASE] %s for player %s at %s:%d", lockType, playerID, file, line)
-}
-
-func logGoroutineStart(name, playerID string) {
-	log.Printf("[GOROUTINE_START] %s for player %s (goroutines: %d)", name, playerID, runtime.NumGoroutine())
-}
-
-func logGoroutineEnd(name, playerID string) {
-	log.Printf("[GOROUTINE_END] %s for player %s (goroutines: %d)", name, playerID, runtime.NumGoroutine())
-}
-
-func logChannelOp(op, playerID string, channelLen, channelCap int) {
-	log.Printf("[CHAN_%s] Player %s (len=%d, cap=%d)", op, playerID, channelLen, channelCap)
-}
-
-func logHTTPRequest(method, path, playerID string) {
-	log.Printf("[HTTP_REQUEST] %s %s for player %s", method, path, playerID)
-}
-
-func logHTTPResponse(method, path, playerID string, statusCode int) {
-	log.Printf("[HTTP_RESPONSE] %s %s for player %s - Status: %d", method, path, playerID, statusCode)
-}
-
-// GameManager manages game sessions
+// GameManager manages game sessions.
+//
+// Each game is guarded by its own lock (gameEntry.mu); gm.mu only guards membership of
+// the games map and is never held while a game lock is acquired. Lock ordering, when more
+// than one lock is needed:
+//
+//	offer generation mutexes -> wsConnectionsMu -> game locks -> gm.mu -> invite codes,
+//	first player, shared job offers, network index, state cache, session claims
+//
+// Hold at most one game lock at a time, except through withGames, which takes them in
+// ascending player ID order. Never take wsConnectionsMu while holding a game lock; release
+// it first and use notifyPlayers to push state afterwards.
 type GameManager struct {
-	games                    map[string]*GameState
-	ai                       *AIClient
-	mu                       sync.RWMutex
+	games                    map[string]*gameEntry
+	ai                       AI // AIClient, or MockAI in tests
+	catalog                  *OfferCatalog // Curated offers drawn instead of the AI's (nil without catalog.path)
+	mu                       sync.RWMutex // Guards the games map only, not the games themselves
 	lastJobOfferGen          map[string]time.Time
 	jobOfferGenMu            sync.Mutex
 	lastApartmentOfferGen    map[string]time.Time
@@ -63,27 +46,101 @@ type GameManager struct {
 	otherOfferGenMu          sync.Mutex
 	lastStockOfferGen        map[string]time.Time
 	stockOfferGenMu          sync.Mutex
-	// Invite code tracking: invite code -> player ID
-	inviteCodes              map[string]string
+	// Manual offer refreshes: playerID -> last refresh, for the cooldown (taken under a game lock)
+	lastOfferRefresh         map[string]time.Time
+	offerRefreshMu           sync.Mutex
+	// Offers created by chat: playerID -> when they last created one, for the cooldown (leaf lock)
+	lastPlayerOffer          map[string]time.Time
+	playerOfferMu            sync.Mutex
+	// Player-created offers being accepted or withdrawn: offer ID -> how (see claimOfferClose, leaf lock)
+	closingOffers            map[string]string
+	closingOffersMu          sync.Mutex
+	advanceBudgets           map[string]*advanceBudget // playerID -> allowance for moving the clock, see claimAdvance
+	advanceBudgetsMu         sync.Mutex
+	// Players' last HTTP requests, for networkIsLive (leaf lock)
+	lastActive               map[string]time.Time
+	lastActiveMu             sync.Mutex
+	// Offer message threads: offer ID -> conversation shared by all copies of the offer (leaf lock)
+	offerThreads             map[string]*offerThread
+	offerThreadsMu           sync.Mutex
+	// Invite code tracking: invite code -> who issued it and until when it is valid
+	inviteCodes              map[string]*inviteRecord
 	inviteCodesMu            sync.RWMutex
 	firstPlayerID            string // Track the first player
 	firstPlayerMu            sync.Mutex
+	// Stock and crypto prices: network root player ID -> the network's market (see networkMarket)
+	markets                  map[string]*networkMarket
+	marketsMu                sync.Mutex
 	// Shared job offers: job offer ID -> network root player ID
 	sharedJobOffers          map[string]string // Maps job offer ID to the network root player ID
 	sharedJobOffersMu        sync.RWMutex
+	// Network membership, derived from the games' invite links (see networkIndex; nil until rebuilt)
+	network                  *networkIndex
+	networkMu                sync.Mutex
 	// Caching
 	stateCache               map[string]*cachedState // playerID -> cached state
 	stateCacheMu             sync.RWMutex
+	// Session claims: player IDs that have logged in, so a second login cannot take them over
+	sessionClaims            map[string]bool
+	sessionClaimsMu          sync.Mutex
 	// JSON encoder pool for better performance
 	jsonEncoderPool          sync.Pool
-	// Encryption key cache
-	encryptionKey            []byte
-	encryptionKeyOnce        sync.Once
 	// WebSocket connections
 	wsConnections            map[string]*wsConnection // playerID -> connection
-	wsConnectionsMu          sync.RWMutex
+	spectators               map[string]map[*wsConnection]bool // playerID -> read-only connections watching them, see spectate.go
+	wsConnectionsMu          sync.RWMutex // Guards wsConnections and spectators
+	// Real time for cooldowns, expiries and timestamps (see Clock)
+	clock                    Clock
+	// Health/readiness
+	startedAt                time.Time
+	aiProbeAt                time.Time // When the last AI connectivity probe ran
+	aiProbeErr               error     // Result of the last AI connectivity probe
+	aiProbeMu                sync.Mutex
+	// Outbound game events (nil when n8n.events_url is not set)
+	events                   *eventDispatcher
+	// Shutdown: ctx is cancelled to stop the background loops, which background tracks
+	ctx                      context.Context
+	stop                     context.CancelFunc
+	background               sync.WaitGroup
+	// Randomness of the offer generators' timing (seeded by Config.Game.Seed, otherwise nil)
+	rng                      *gameRand
 }
 
+// gameEntry holds a player's game together with the lock that guards it.
+// InvitedBy and IsFirstPlayer only change when an inviter's game is removed, under both the
+// game's lock and gm.mu (see removeGame), so the network helpers read them under gm.mu alone.
+type gameEntry struct {
+	mu   sync.RWMutex
+	game *GameState
+}
+
+// inviteRecord is an issued invite code. Codes are valid until ExpiresAt (real time, not game
+// time) unless the owner revokes them first or they run out of uses. The default code is the
+// player's GameState.InviteCode, which has no use limit.
+type inviteRecord struct {
+	PlayerID  string
+	ExpiresAt time.Time
+	Revoked   bool
+	MaxUses   int // 0 means unlimited
+	Uses      int
+	Default   bool
+}
+
+// maxActiveInvites caps how many usable codes a player can hold at once
+const maxActiveInvites = 20
+
+// usable reports whether the code can still be used to join at now
+func (invite *inviteRecord) usable(now time.Time) bool {
+	return !invite.Revoked && now.Before(invite.ExpiresAt) && (invite.MaxUses == 0 || invite.Uses < invite.MaxUses)
+}
+
+// inviteSweepInterval is how often expired invite codes are dropped, and inviteRetention is how
+// long after expiry they are kept so that late joiners get "expired" rather than "invalid"
+const (
+	inviteSweepInterval = time.Hour
+	inviteRetention     = 24 * time.Hour
+)
+
 // wsConnection represents a WebSocket connection for a player
 type wsConnection struct {
 	conn     *websocket.Conn
@@ -91,17 +148,54 @@ type wsConnection struct {
 	send     chan []byte
 	manager  *GameManager
 	mu       sync.Mutex
+	closed   bool          // Set by close(); send must not be used after it, guarded by mu
+	done     chan struct{} // Closed when the server shuts down; the writePump then says goodbye
+	doneOnce sync.Once
+	ctx      context.Context // Cancelled when the connection closes, abandoning the player's AI calls
+	cancel   context.CancelFunc
+	recentKeys map[string]*seenAction // Idempotency keys of recent actions, see claimIdempotencyKey
+	// The latest game state not yet written; newer states replace it instead of queueing behind it
+	pendingState []byte
+	stateMu      sync.Mutex
+	stateReady   chan struct{}
+	// Fires when the session token the socket was opened with expires; nil in dev mode
+	sessionTimer *time.Timer
+	spectator    bool // Read-only: receives the player's state and chat but cannot act
 }
 
 // WebSocket upgrader
 var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Allow all origins in development
-	},
+	CheckOrigin:     checkWebSocketOrigin,
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
 }
 
+// wsCompressionThreshold is the smallest frame worth compressing, as for gzipped HTTP responses
+const wsCompressionThreshold = 1024
+
+// SetupWebSocket applies the WebSocket settings; permessage-deflate is only used when the client offers it too
+func SetupWebSocket(config *Config) {
+	upgrader.EnableCompression = config.Server.WebSocketCompression
+	if upgrader.EnableCompression {
+		logInfof("[WS] permessage-deflate enabled for frames over %d bytes", wsCompressionThreshold)
+	}
+}
+
+// checkWebSocketOrigin accepts upgrades from the allowed origins (see originAllowed).
+// Requests without an Origin header do not come from a browser page and are allowed.
+func checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	
+	if !originAllowed(origin, r.Host) {
+		logWarnf("[WS] Rejected upgrade from origin %s (host %s), not in allowed origins", origin, r.Host)
+		return false
+	}
+	return true
+}
+
 // cachedState stores cached game state with ETag
 type cachedState struct {
 	state     *GameState
@@ -112,18 +206,43 @@ type cachedState struct {
 
 // NewGameManager creates a new game manager
 func NewGameManager() *GameManager {
+	return NewGameManagerWithClock(realClock{})
+}
+
+// NewGameManagerWithClock creates a GameManager that takes real time from clock, e.g. a
+// FakeClock in tests
+func NewGameManagerWithClock(clock Clock) *GameManager {
+	return NewGameManagerWithAI(NewAIClient(), clock)
+}
+
+// NewGameManagerWithAI creates a GameManager that asks ai for offers, chat and lessons, e.g. a
+// MockAI in tests, and takes real time from clock
+func NewGameManagerWithAI(ai AI, clock Clock) *GameManager {
 	gm := &GameManager{
-		games:                 make(map[string]*GameState),
-		ai:                    NewAIClient(),
+		games:                 make(map[string]*gameEntry),
+		ai:                    ai,
+		catalog:               loadOfferCatalog(GetConfig().Catalog.Path),
 		lastJobOfferGen:       make(map[string]time.Time),
 		lastApartmentOfferGen: make(map[string]time.Time),
 		lastOtherOfferGen:     make(map[string]time.Time),
 		lastStockOfferGen:     make(map[string]time.Time),
-		inviteCodes:           make(map[string]string),
+		lastOfferRefresh:      make(map[string]time.Time),
+		lastPlayerOffer:       make(map[string]time.Time),
+		closingOffers:         make(map[string]string),
+		advanceBudgets:        make(map[string]*advanceBudget),
+		lastActive:            make(map[string]time.Time),
+		offerThreads:          make(map[string]*offerThread),
+		inviteCodes:           make(map[string]*inviteRecord),
 		firstPlayerID:         "",
 		sharedJobOffers:       make(map[string]string),
+		markets:               make(map[string]*networkMarket),
 		stateCache:            make(map[string]*cachedState),
+		sessionClaims:         make(map[string]bool),
 		wsConnections:         make(map[string]*wsConnection),
+		spectators:            make(map[string]map[*wsConnection]bool),
+		clock:                 clock,
+		startedAt:             clock.Now(),
+		rng:                   seededRand(""),
 		jsonEncoderPool: sync.Pool{
 			New: func() interface{} {
 				return new(bytes.Buffer)
@@ -131,43 +250,72 @@ func NewGameManager() *GameManager {
 		},
 	}
 	
+	gm.ctx, gm.stop = context.WithCancel(context.Background())
+	
 	// Start background job offer generator
-	go gm.autoGenerateJobOffers()
+	gm.runInBackground(gm.autoGenerateJobOffers)
 	
 	// Start background apartment offer generator
-	go gm.autoGenerateApartmentOffers()
+	gm.runInBackground(gm.autoGenerateApartmentOffers)
 	
 	// Start background other offers generator
-	go gm.autoGenerateOtherOffers()
+	gm.runInBackground(gm.autoGenerateOtherOffers)
 	
 	// Start background stock offers generator
-	go gm.autoGenerateStockOffers()
+	gm.runInBackground(gm.autoGenerateStockOffers)
+	
+	// Start background sweep of expired invite codes
+	gm.runInBackground(gm.autoSweepInviteCodes)
+	
+	// Start sending game events to the events webhook, if configured
+	gm.events = newEventDispatcher(GetConfig())
+	outboundEvents.Store(gm.events)
 	
 	return gm
 }
 
+// autoSweepInviteCodes periodically drops invite codes that expired more than inviteRetention ago
+func (gm *GameManager) autoSweepInviteCodes() {
+	for gm.sleep(inviteSweepInterval) {
+		gm.sweepInviteCodes(gm.now())
+		gm.sweepOfferThreads(gm.now())
+	}
+}
+
+// sweepInviteCodes drops invite codes that expired before now minus inviteRetention
+func (gm *GameManager) sweepInviteCodes(now time.Time) {
+	gm.inviteCodesMu.Lock()
+	defer gm.inviteCodesMu.Unlock()
+	
+	dropped := 0
+	for code, invite := range gm.inviteCodes {
+		if now.Sub(invite.ExpiresAt) > inviteRetention {
+			delete(gm.inviteCodes, code)
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		logDebugf("[INVITE] Swept %d expired invite codes", dropped)
+	}
+}
+
 // autoGenerateJobOffers periodically generates job offers
 func (gm *GameManager) autoGenerateJobOffers() {
-	// Generate initial offers immediately
+	// Generate initial offers right away (offers.jobs.initial_delay_seconds, 0 by default)
+	if !gm.sleep(time.Duration(GetConfig().Offers.Jobs.InitialDelaySeconds) * time.Second) {
+		return
+	}
 	gm.generateJobOffersForAllGames()
 	
-	// Generate more frequently: every 30-90 seconds (randomized for variability)
-	for {
-		// Random interval between 30-90 seconds
-		interval := 30 + rand.Intn(60) // 30-90 seconds
-		time.Sleep(time.Duration(interval) * time.Second)
+	// Then every offers.jobs min-max interval (30-90 seconds by default), read each round
+	for gm.sleep(GetConfig().Offers.Jobs.nextInterval(gm.rng)) {
 		gm.generateJobOffersForAllGames()
 	}
 }
 
 // generateJobOffersForAllGames generates job offers for all active games
 func (gm *GameManager) generateJobOffersForAllGames() {
-	gm.mu.RLock()
-	playerIDs := make([]string, 0, len(gm.games))
-	for playerID := range gm.games {
-		playerIDs = append(playerIDs, playerID)
-	}
-	gm.mu.RUnlock()
+	playerIDs := gm.playerIDs()
 	
 	// Track which networks we've already generated offers for
 	networksProcessed := make(map[string]bool)
@@ -179,205 +327,214 @@ func (gm *GameManager) generateJobOffersForAllGames() {
 			continue // Skip if we already processed this network
 		}
 		networksProcessed[networkRoot] = true
+		if !gm.networkIsLive(playerID) {
+			continue // Nobody in the network is playing
+		}
 		
-		game, err := gm.GetGame(playerID)
-		if err != nil {
+		game, exists := gm.snapshotGame(playerID)
+		if !exists {
 			continue
 		}
 		
-		// Check if we should generate offers for this network (use network root's timing)
+		// Check if we should generate offers for this network (use network root's timing, at most
+		// once per offers.jobs.min_interval_seconds)
 		gm.jobOfferGenMu.Lock()
 		lastGen, exists := gm.lastJobOfferGen[networkRoot]
-		shouldGen := !exists || time.Since(lastGen) >= 2*time.Minute
+		shouldGen := !exists || gm.since(lastGen) >= GetConfig().Offers.Jobs.minGap()
 		gm.jobOfferGenMu.Unlock()
 		
 		if shouldGen {
 			// Check total offers in network (use network root's count)
-			gm.mu.RLock()
-			networkGame, networkExists := gm.games[networkRoot]
 			currentOffers := 0
-			if networkExists {
+			gm.readGame(networkRoot, func(networkGame *GameState) {
 				currentOffers = len(networkGame.JobOffers)
-			}
-			gm.mu.RUnlock()
+			})
 			
-			if currentOffers < 7 {
-				// Randomly decide if it's a good or trickery offer
-				offerType := "good"
-				if rand.Float64() < 0.3 { // 30% chance of trickery
-					offerType = "trickery"
-				}
-				
-				jobOffer, err := gm.ai.GenerateJobOffer(game, offerType)
-				if err == nil && jobOffer != nil {
-					// Share the job offer with all players in the network
-					gm.shareJobOfferWithNetwork(playerID, *jobOffer)
-					
-					// Notify all network players via WebSocket
-					networkPlayers := gm.getNetworkPlayers(playerID)
-					gm.wsConnectionsMu.RLock()
-					for _, pid := range networkPlayers {
-						if wsConn, exists := gm.wsConnections[pid]; exists {
-							gm.mu.RLock()
-							if g, exists := gm.games[pid]; exists {
-								wsConn.sendGameState(g)
-							}
-							gm.mu.RUnlock()
-						}
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					gm.jobOfferGenMu.Lock()
-					gm.lastJobOfferGen[networkRoot] = time.Now()
-					gm.jobOfferGenMu.Unlock()
-				}
+			if currentOffers < game.difficulty().MaxJobOffers && gm.generateJobOffer(playerID, game) {
+				gm.jobOfferGenMu.Lock()
+				gm.lastJobOfferGen[networkRoot] = gm.now()
+				gm.jobOfferGenMu.Unlock()
 			}
 		}
 	}
 }
 
+// generateJobOffer draws one job offer from the catalog (with catalog.share odds) or asks the AI,
+// good or trickery with the player's odds, shares it with the player's network and notifies them.
+// game is a snapshot of the player's game.
+func (gm *GameManager) generateJobOffer(playerID string, game *GameState) bool {
+	difficulty := game.difficulty()
+	offerType := "good"
+	if game.rng.Float64() < game.trickeryChance(difficulty.JobTrickeryChance) {
+		offerType = "trickery"
+	}
+	
+	var jobOffer *JobOffer
+	var err error
+	if gm.catalog.draws(game) {
+		jobOffer = gm.catalog.jobOffer(game, offerType == "trickery")
+	}
+	if jobOffer == nil {
+		jobOffer, err = gm.ai.GenerateJobOffer(gm.ctx, game, offerType)
+	}
+	if err != nil || jobOffer == nil {
+		return false
+	}
+	jobOffer.ExpiresAt = difficulty.scaleOfferExpiry(game.CurrentDate, jobOffer.ExpiresAt)
+	// Share the job offer with all players in the network
+	gm.shareJobOfferWithNetwork(playerID, *jobOffer)
+	recordOfferGenerated("job", jobOffer.IsTrickery)
+	
+	// Notify all network players via WebSocket
+	gm.notifyPlayers(gm.getNetworkPlayers(playerID)...)
+	return true
+}
+
 // autoGenerateApartmentOffers periodically generates apartment offers (3-6 per week)
 func (gm *GameManager) autoGenerateApartmentOffers() {
 	// Generate initial offers after a short delay
-	time.Sleep(20 * time.Second)
+	if !gm.sleep(time.Duration(GetConfig().Offers.Apartments.InitialDelaySeconds) * time.Second) {
+		return
+	}
 	gm.generateApartmentOffersForAllGames()
 	
-	// Generate more frequently: every 45-120 seconds (randomized for variability)
-	for {
-		// Random interval between 45-120 seconds
-		interval := 45 + rand.Intn(75) // 45-120 seconds
-		time.Sleep(time.Duration(interval) * time.Second)
+	// Then every offers.apartments min-max interval (45-120 seconds by default), read each round
+	for gm.sleep(GetConfig().Offers.Apartments.nextInterval(gm.rng)) {
 		gm.generateApartmentOffersForAllGames()
 	}
 }
 
 // generateApartmentOffersForAllGames generates apartment offers for all active games
 func (gm *GameManager) generateApartmentOffersForAllGames() {
-	gm.mu.RLock()
-	gameList := make(map[string]*GameState)
-	for k, v := range gm.games {
-		gameList[k] = v
-	}
-	gm.mu.RUnlock()
+	playerIDs := gm.playerIDs()
 	
 	gm.apartmentOfferGenMu.Lock()
 	defer gm.apartmentOfferGenMu.Unlock()
 	
-	for playerID, game := range gameList {
-		// Check if enough time has passed (30 seconds real time minimum)
+	for _, playerID := range playerIDs {
+		if !gm.networkIsLive(playerID) {
+			continue // Nobody in the network is playing
+		}
+		
+		// Check if enough time has passed (offers.apartments.min_interval_seconds real time)
 		lastGen, exists := gm.lastApartmentOfferGen[playerID]
-		if !exists || time.Since(lastGen) >= 30*time.Second {
-			// Limit to max 7 apartment offers (increased from 6)
-			gm.mu.RLock()
-			currentOffers := 0
-			if g, exists := gm.games[playerID]; exists {
-				currentOffers = len(g.ApartmentOffers)
+		if !exists || gm.since(lastGen) >= GetConfig().Offers.Apartments.minGap() {
+			// Limit open apartment offers to the difficulty's cap
+			game, exists := gm.snapshotGame(playerID)
+			if !exists {
+				continue
 			}
-			gm.mu.RUnlock()
-			
-			if currentOffers < 7 {
-				// Generate a random apartment offer (70% good, 30% trickery)
-				offerType := "good"
-				if rand.Float64() < 0.3 {
-					offerType = "trickery"
-				}
-				
-				apartmentOffer, err := gm.ai.GenerateApartmentOffer(game, offerType)
-				if err == nil && apartmentOffer != nil {
-					gm.mu.Lock()
-					if g, exists := gm.games[playerID]; exists {
-						g.ApartmentOffers = append(g.ApartmentOffers, *apartmentOffer)
-					}
-					gm.mu.Unlock()
-					
-					// Notify player via WebSocket if connected
-					gm.wsConnectionsMu.RLock()
-					if wsConn, exists := gm.wsConnections[playerID]; exists {
-						gm.mu.RLock()
-						if g, exists := gm.games[playerID]; exists {
-							wsConn.sendGameState(g)
-						}
-						gm.mu.RUnlock()
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					gm.lastApartmentOfferGen[playerID] = time.Now()
-				}
+			if len(game.ApartmentOffers) < game.difficulty().MaxApartmentOffers && gm.generateApartmentOffer(playerID, game) {
+				gm.lastApartmentOfferGen[playerID] = gm.now()
 			}
 		}
 	}
 }
 
+// generateApartmentOffer draws one apartment offer from the catalog (with catalog.share odds) or
+// asks the AI, trickery with the player's odds, adds it to the player's game and notifies them. game is a snapshot of the player's game.
+func (gm *GameManager) generateApartmentOffer(playerID string, game *GameState) bool {
+	difficulty := game.difficulty()
+	offerType := "good"
+	if game.rng.Float64() < game.trickeryChance(difficulty.ApartmentTrickeryChance) {
+		offerType = "trickery"
+	}
+	
+	var apartmentOffer *ApartmentOffer
+	var err error
+	if gm.catalog.draws(game) {
+		apartmentOffer = gm.catalog.apartmentOffer(game, offerType == "trickery")
+	}
+	if apartmentOffer == nil {
+		apartmentOffer, err = gm.ai.GenerateApartmentOffer(gm.ctx, game, offerType)
+	}
+	if err != nil || apartmentOffer == nil {
+		return false
+	}
+	apartmentOffer.ExpiresAt = difficulty.scaleOfferExpiry(game.CurrentDate, apartmentOffer.ExpiresAt)
+	gm.withGame(playerID, func(g *GameState) {
+		g.ApartmentOffers = append(g.ApartmentOffers, *apartmentOffer)
+	})
+	recordOfferGenerated("apartment", apartmentOffer.IsTrickery)
+	
+	// Notify player via WebSocket if connected
+	gm.notifyPlayers(playerID)
+	return true
+}
+
 // autoGenerateOtherOffers periodically generates random "other" offers
 func (gm *GameManager) autoGenerateOtherOffers() {
 	// Generate initial offers after a short delay
-	time.Sleep(25 * time.Second)
+	if !gm.sleep(time.Duration(GetConfig().Offers.Other.InitialDelaySeconds) * time.Second) {
+		return
+	}
 	gm.generateOtherOffersForAllGames()
 	
-	// Generate more frequently: every 60-150 seconds (randomized for variability)
-	for {
-		// Random interval between 60-150 seconds
-		interval := 60 + rand.Intn(90) // 60-150 seconds
-		time.Sleep(time.Duration(interval) * time.Second)
+	// Then every offers.other min-max interval (60-150 seconds by default), read each round
+	for gm.sleep(GetConfig().Offers.Other.nextInterval(gm.rng)) {
 		gm.generateOtherOffersForAllGames()
 	}
 }
 
 // generateOtherOffersForAllGames generates other offers for all active games
 func (gm *GameManager) generateOtherOffersForAllGames() {
-	gm.mu.RLock()
-	gameList := make(map[string]*GameState)
-	for k, v := range gm.games {
-		gameList[k] = v
-	}
-	gm.mu.RUnlock()
+	playerIDs := gm.playerIDs()
 	
 	gm.otherOfferGenMu.Lock()
 	defer gm.otherOfferGenMu.Unlock()
 	
-	for playerID, game := range gameList {
-		// Check if enough time has passed (30 seconds real time minimum)
+	for _, playerID := range playerIDs {
+		if !gm.networkIsLive(playerID) {
+			continue // Nobody in the network is playing
+		}
+		
+		// Check if enough time has passed (offers.other.min_interval_seconds real time)
 		lastGen, exists := gm.lastOtherOfferGen[playerID]
-		if !exists || time.Since(lastGen) >= 30*time.Second {
-			// Limit to max 5 other offers (increased from 3)
-			gm.mu.RLock()
+		if !exists || gm.since(lastGen) >= GetConfig().Offers.Other.minGap() {
+			// Limit open other offers to the difficulty's cap
 			currentOffers := 0
-			if g, exists := gm.games[playerID]; exists {
-				for _, offer := range g.ActiveOffers {
-					if offer.Type == "other" {
-						currentOffers++
-					}
+			game, exists := gm.snapshotGame(playerID)
+			if !exists {
+				continue
+			}
+			for _, offer := range game.ActiveOffers {
+				if offer.Type == "other" {
+					currentOffers++
 				}
 			}
-			gm.mu.RUnlock()
 			
-			if currentOffers < 5 {
-				otherOffer, err := gm.ai.GenerateOtherOffer(game)
-				if err == nil && otherOffer != nil {
-					gm.mu.Lock()
-					if g, exists := gm.games[playerID]; exists {
-						g.ActiveOffers = append(g.ActiveOffers, *otherOffer)
-					}
-					gm.mu.Unlock()
-					
-					// Notify player via WebSocket if connected
-					gm.wsConnectionsMu.RLock()
-					if wsConn, exists := gm.wsConnections[playerID]; exists {
-						gm.mu.RLock()
-						if g, exists := gm.games[playerID]; exists {
-							wsConn.sendGameState(g)
-						}
-						gm.mu.RUnlock()
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					gm.lastOtherOfferGen[playerID] = time.Now()
-				}
+			if currentOffers < game.difficulty().MaxOtherOffers && gm.generateOtherOffer(playerID, game) {
+				gm.lastOtherOfferGen[playerID] = gm.now()
 			}
 		}
 	}
 }
 
+// generateOtherOffer draws one "other" offer from the catalog (with catalog.share odds) or asks
+// the AI, adds it to the player's game and notifies them. game is a snapshot of the player's game.
+func (gm *GameManager) generateOtherOffer(playerID string, game *GameState) bool {
+	var otherOffer *Offer
+	var err error
+	if gm.catalog.draws(game) {
+		otherOffer = gm.catalog.otherOffer(game)
+	}
+	if otherOffer == nil {
+		otherOffer, err = gm.ai.GenerateOtherOffer(gm.ctx, game)
+	}
+	if err != nil || otherOffer == nil {
+		return false
+	}
+	otherOffer.ExpiresAt = game.difficulty().scaleOfferExpiry(game.CurrentDate, otherOffer.ExpiresAt)
+	gm.withGame(playerID, func(g *GameState) {
+		g.ActiveOffers = append(g.ActiveOffers, *otherOffer)
+	})
+	recordOfferGenerated("other", otherOffer.IsTrickery)
+	
+	// Notify player via WebSocket if connected
+	gm.notifyPlayers(playerID)
+	return true
+}
+
 // generateInviteCode generates a unique invite code
 func (gm *GameManager) generateInviteCode() string {
 	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // Excluding confusing chars
@@ -388,210 +545,326 @@ func (gm *GameManager) generateInviteCode() string {
 	return string(b)
 }
 
-// GetOrCreateGame gets or creates a game for a player
-func (gm *GameManager) GetOrCreateGame(playerID string) *GameState {
-	gm.mu.Lock()
-	defer gm.mu.Unlock()
+// issueInviteCode gives the game a new unique invite code, valid for the configured window.
+// The caller must hold the game's lock, or own the game before it is published.
+func (gm *GameManager) issueInviteCode(game *GameState) {
+	expiresAt := gm.now().Add(time.Duration(GetConfig().Invites.ValidityHours) * time.Hour)
+	
+	gm.inviteCodesMu.Lock()
+	inviteCode := strings.ToUpper(gm.generateInviteCode())
+	// Ensure uniqueness
+	for {
+		if _, exists := gm.inviteCodes[inviteCode]; !exists {
+			break
+		}
+		inviteCode = strings.ToUpper(gm.generateInviteCode())
+	}
+	gm.inviteCodes[inviteCode] = &inviteRecord{PlayerID: game.PlayerID, ExpiresAt: expiresAt, Default: true}
+	gm.inviteCodesMu.Unlock()
 	
-	if game, exists := gm.games[playerID]; exists {
-		return game
+	game.InviteCode = inviteCode
+	game.InviteExpiresAt = expiresAt
+}
+
+// GenerateInvite mints an extra invite code for playerID that can be used maxUses times
+// (at least once). It does not change the player's default InviteCode.
+func (gm *GameManager) GenerateInvite(playerID string, maxUses int) (string, error) {
+	if _, exists := gm.getEntry(playerID); !exists {
+		return "", errors.New("game not found")
 	}
+	if maxUses < 1 {
+		maxUses = 1
+	}
+	now := gm.now()
+	expiresAt := now.Add(time.Duration(GetConfig().Invites.ValidityHours) * time.Hour)
 	
-	game := NewGame(playerID)
+	gm.inviteCodesMu.Lock()
+	defer gm.inviteCodesMu.Unlock()
 	
-	// Check if this is the first player
-	gm.firstPlayerMu.Lock()
-	if gm.firstPlayerID == "" {
-		gm.firstPlayerID = playerID
-		game.IsFirstPlayer = true
+	active := 0
+	for _, invite := range gm.inviteCodes {
+		if invite.PlayerID == playerID && !invite.Default && invite.usable(now) {
+			active++
+		}
+	}
+	if active >= maxActiveInvites {
+		return "", fmt.Errorf("you already have %d active invite codes", active)
 	}
-	gm.firstPlayerMu.Unlock()
 	
-	// Generate invite code for this player (always uppercase)
-	gm.inviteCodesMu.Lock()
 	inviteCode := strings.ToUpper(gm.generateInviteCode())
-	// Ensure uniqueness
 	for {
 		if _, exists := gm.inviteCodes[inviteCode]; !exists {
 			break
 		}
 		inviteCode = strings.ToUpper(gm.generateInviteCode())
 	}
-	gm.inviteCodes[inviteCode] = playerID
-	game.InviteCode = inviteCode
-	gm.inviteCodesMu.Unlock()
-	
-	gm.games[playerID] = game
+	gm.inviteCodes[inviteCode] = &inviteRecord{PlayerID: playerID, ExpiresAt: expiresAt, MaxUses: maxUses}
+	return inviteCode, nil
+}
+
+// listInvites returns the player's usable invite codes, the default code first
+func (gm *GameManager) listInvites(playerID string) []map[string]interface{} {
+	now := gm.now()
+	gm.inviteCodesMu.RLock()
+	defer gm.inviteCodesMu.RUnlock()
 	
-	// Sync time with network if this player was invited
-	if game.InvitedBy != "" {
-		if inviter, exists := gm.games[game.InvitedBy]; exists {
-			game.CurrentDate = inviter.CurrentDate
+	invites := []map[string]interface{}{}
+	for code, invite := range gm.inviteCodes {
+		if invite.PlayerID != playerID || !invite.usable(now) {
+			continue
+		}
+		remaining := -1 // Unlimited
+		if invite.MaxUses > 0 {
+			remaining = invite.MaxUses - invite.Uses
 		}
+		invites = append(invites, map[string]interface{}{
+			"code":           code,
+			"default":        invite.Default,
+			"uses":           invite.Uses,
+			"max_uses":       invite.MaxUses,
+			"remaining_uses": remaining,
+			"expires_at":     invite.ExpiresAt,
+		})
 	}
+	slices.SortFunc(invites, func(a, b map[string]interface{}) int {
+		if a["default"] != b["default"] {
+			if a["default"].(bool) {
+				return -1
+			}
+			return 1
+		}
+		return a["expires_at"].(time.Time).Compare(b["expires_at"].(time.Time))
+	})
+	return invites
+}
+
+// useInviteCode validates an invite code and counts one use of it, returning the inviter
+func (gm *GameManager) useInviteCode(inviteCode string) (string, error) {
+	gm.inviteCodesMu.Lock()
+	defer gm.inviteCodesMu.Unlock()
 	
-	// Trigger job offer, apartment offer, stock offer, and other offer generation for new game
-	go func() {
-		time.Sleep(3 * time.Second) // Wait 3 seconds before generating first offers (faster)
-		gm.generateJobOffersForAllGames()
-		gm.generateApartmentOffersForAllGames()
-		gm.generateStockOffersForAllGames()
-		// Other offers will be generated after 25 seconds (handled by autoGenerateOtherOffers)
-	}()
+	invite, exists := gm.inviteCodes[inviteCode]
+	if !exists {
+		return "", errors.New("invalid invite code")
+	}
+	if invite.Revoked {
+		return "", errors.New("invite code has been revoked")
+	}
+	if !gm.now().Before(invite.ExpiresAt) {
+		return "", errors.New("invite code has expired")
+	}
+	if invite.MaxUses > 0 && invite.Uses >= invite.MaxUses {
+		return "", errors.New("invite code has already been used")
+	}
+	invite.Uses++
+	return invite.PlayerID, nil
+}
+
+// releaseInviteUse gives back a use taken by useInviteCode when joining fails afterwards
+func (gm *GameManager) releaseInviteUse(inviteCode string) {
+	gm.inviteCodesMu.Lock()
+	defer gm.inviteCodesMu.Unlock()
+	
+	if invite, exists := gm.inviteCodes[inviteCode]; exists && invite.Uses > 0 {
+		invite.Uses--
+	}
+}
+
+// revokeInvite revokes the game's current invite code and issues a fresh one, so a leaked code
+// stops working while the owner can keep inviting. The caller must hold the game's lock.
+func (gm *GameManager) revokeInvite(game *GameState) {
+	gm.inviteCodesMu.Lock()
+	invite, exists := gm.inviteCodes[game.InviteCode]
+	if exists && invite.PlayerID == game.PlayerID {
+		invite.Revoked = true
+	}
+	gm.inviteCodesMu.Unlock()
 	
+	oldCode := game.InviteCode
+	gm.issueInviteCode(game)
+	game.addEvent("invite_revoked", fmt.Sprintf("Revoked invite code %s, new code is %s", oldCode, game.InviteCode), 0)
+}
+
+// newGame creates a game that takes real time from the manager's clock
+func (gm *GameManager) newGame(playerID string, scenario string) *GameState {
+	game := NewGame(playerID, scenario)
+	game.clock = gm.clock
+	game.CreatedAt = gm.now()
 	return game
 }
 
-// CreateGameWithInvite creates a new game with an invite code
-func (gm *GameManager) CreateGameWithInvite(playerID string, inviteCode string) (*GameState, error) {
+// GetOrCreateGame gets or creates a game entry for a player; language and scenario are only applied to newly created games.
+// The returned game must be accessed under the entry's lock.
+func (gm *GameManager) GetOrCreateGame(playerID string, language string, scenario string) *gameEntry {
 	gm.mu.Lock()
 	defer gm.mu.Unlock()
 	
+	if entry, exists := gm.games[playerID]; exists {
+		return entry
+	}
+	
+	game := gm.newGame(playerID, scenario)
+	game.Language = normalizeLanguage(language)
+	game.prices = gm.networkMarket(playerID) // A new player roots their own network
+	
+	// Check if this is the first player (again, when they reset their game)
+	gm.firstPlayerMu.Lock()
+	if gm.firstPlayerID == "" || gm.firstPlayerID == playerID {
+		gm.firstPlayerID = playerID
+		game.IsFirstPlayer = true
+	}
+	gm.firstPlayerMu.Unlock()
+	
+	// Generate invite code for this player (always uppercase)
+	gm.issueInviteCode(game)
+	
+	entry := &gameEntry{game: game}
+	gm.games[playerID] = entry
+	gm.indexNewGameUnlocked(playerID, "")
+	metricsActiveGames.Set(float64(len(gm.games)))
+	
+	// Trigger job offer, apartment offer and stock offer generation for new game
+	gm.generateOffersSoon()
+	
+	return entry
+}
+
+// CreateGameWithInvite creates a new game with an invite code.
+// The returned game must be accessed under its entry's lock (see readGame).
+func (gm *GameManager) CreateGameWithInvite(playerID string, inviteCode string, language string) (*GameState, error) {
 	// Check if player already exists
-	if _, exists := gm.games[playerID]; exists {
+	if _, exists := gm.getEntry(playerID); exists {
 		return nil, errors.New("player already exists")
 	}
 	
 	// Validate invite code (case-insensitive lookup - convert to uppercase)
 	inviteCodeUpper := strings.ToUpper(inviteCode)
-	gm.inviteCodesMu.RLock()
-	inviterID, exists := gm.inviteCodes[inviteCodeUpper]
-	gm.inviteCodesMu.RUnlock()
-	
-	if !exists {
-		return nil, errors.New("invalid invite code")
+	inviterID, err := gm.useInviteCode(inviteCodeUpper)
+	if err != nil {
+		return nil, err
+	}
+	if inviterID == playerID {
+		gm.releaseInviteUse(inviteCodeUpper)
+		return nil, errors.New("you cannot use your own invite code")
 	}
 	
-	// Check if inviter exists
-	inviter, inviterExists := gm.games[inviterID]
+	// Create the game in the inviter's scenario so the network starts on equal terms, sync time
+	// with the network, inherit their language when none was given, and share the network's
+	// job offers (they are the same for every network member)
+	var game *GameState
+	inviterExists := gm.readGame(inviterID, func(inviter *GameState) {
+		game = gm.newGame(playerID, inviter.Scenario)
+		game.CurrentDate = inviter.CurrentDate
+		game.StartDate = inviter.CurrentDate
+		game.MarketIndex = inviter.MarketIndex
+		game.prices = inviter.prices
+		if language == "" {
+			language = inviter.Language
+		}
+		game.JobOffers = append(game.JobOffers, inviter.JobOffers...)
+	})
 	if !inviterExists {
+		gm.releaseInviteUse(inviteCodeUpper)
 		return nil, errors.New("inviter not found")
 	}
-	
-	// Create new game
-	game := NewGame(playerID)
 	game.InvitedBy = inviterID
+	game.Language = normalizeLanguage(language)
 	
-	// Deduct 500 from new player's initial money (they start with less)
+	// Deduct 500 from new player's initial money (they start with less); a player who can't
+	// afford it pays everything they have
 	inviteFee := 500.0
-	if game.Money >= inviteFee {
-		game.Money -= inviteFee
-		game.InitialMoney = game.Money
-		game.addEvent("invite_fee", fmt.Sprintf("Paid €%.2f invite fee to %s", inviteFee, inviterID), -inviteFee)
+	paid := max(min(game.Money, inviteFee), 0)
+	game.Money -= paid
+	game.InitialMoney = game.Money
+	if paid == inviteFee {
+		game.addEvent("invite_fee", fmt.Sprintf("Paid €%.2f invite fee to %s", paid, inviterID), -paid)
 	} else {
-		// If they don't have enough, set money to 0
-		game.Money = 0
-		game.InitialMoney = 0
-		game.addEvent("invite_fee", fmt.Sprintf("Paid all money (€%.2f) as invite fee to %s", game.Money+inviteFee, inviterID), -(game.Money + inviteFee))
+		game.addEvent("invite_fee", fmt.Sprintf("Paid all money (€%.2f) as invite fee to %s", paid, inviterID), -paid)
 	}
 	
-	// Give 500 to inviter
-	inviter.Money += inviteFee
-	inviter.addEvent("invite_reward", fmt.Sprintf("Received €%.2f from %s (invite reward)", inviteFee, playerID), inviteFee)
-	
-	// Generate invite code for new player (always uppercase)
-	gm.inviteCodesMu.Lock()
-	newInviteCode := strings.ToUpper(gm.generateInviteCode())
-	for {
-		if _, exists := gm.inviteCodes[newInviteCode]; !exists {
-			break
-		}
-		newInviteCode = strings.ToUpper(gm.generateInviteCode())
+	gm.mu.Lock()
+	// Re-check under the write lock in case the player was created concurrently
+	if _, exists := gm.games[playerID]; exists {
+		gm.mu.Unlock()
+		gm.releaseInviteUse(inviteCodeUpper)
+		return nil, errors.New("player already exists")
 	}
-	gm.inviteCodes[newInviteCode] = playerID
-	game.InviteCode = newInviteCode
-	gm.inviteCodesMu.Unlock()
-	
-	gm.games[playerID] = game
-	
-	// Sync time with inviter's network
-	if inviter, exists := gm.games[inviterID]; exists {
-		game.CurrentDate = inviter.CurrentDate
+	// The new player must not already appear in the inviter's chain, or the network would loop
+	if gm.isInviteAncestorUnlocked(playerID, inviterID) {
+		gm.mu.Unlock()
+		gm.releaseInviteUse(inviteCodeUpper)
+		return nil, errors.New("invite would create a cycle in the invite chain")
 	}
-	
-	// Share existing job offers from the network with the new player
-	// We already hold the lock, so use the unlocked version
-	networkRoot := gm.getNetworkRootUnlocked(playerID)
-	networkPlayers := []string{networkRoot}
-	
-	// Find all players who were invited by anyone in the network
-	visited := make(map[string]bool)
-	visited[networkRoot] = true
-	
-	// Recursively find all players in the network
-	var findNetwork func(rootID string)
-	findNetwork = func(rootID string) {
-		for pid, g := range gm.games {
-			if !visited[pid] && g.InvitedBy == rootID {
-				networkPlayers = append(networkPlayers, pid)
-				visited[pid] = true
-				findNetwork(pid) // Recursively find players invited by this one
-			}
-		}
+	// Every member gets a copy of each shared offer, so networks are capped
+	if maxSize := GetConfig().Invites.MaxNetworkSize; len(gm.getNetworkPlayersUnlocked(inviterID)) >= maxSize {
+		gm.mu.Unlock()
+		gm.releaseInviteUse(inviteCodeUpper)
+		return nil, fmt.Errorf("this invite network is full (%d players)", maxSize)
 	}
 	
-	findNetwork(networkRoot)
+	// Generate invite code for new player (always uppercase)
+	gm.issueInviteCode(game)
 	
-	// Copy job offers from network (we already hold the lock)
-	for _, pid := range networkPlayers {
-		if pid != playerID {
-			if networkGame, exists := gm.games[pid]; exists {
-				// Copy job offers from network
-				for _, offer := range networkGame.JobOffers {
-					// Check if offer already exists
-					exists := false
-					for _, existingOffer := range game.JobOffers {
-						if existingOffer.ID == offer.ID {
-							exists = true
-							break
-						}
-					}
-					if !exists {
-						game.JobOffers = append(game.JobOffers, offer)
-					}
-				}
-				break // Only need to copy from one player in network
-			}
-		}
-	}
+	gm.games[playerID] = &gameEntry{game: game}
+	gm.indexNewGameUnlocked(playerID, inviterID)
+	metricsActiveGames.Set(float64(len(gm.games)))
+	gm.mu.Unlock()
+	
+	// Credit the inviter with what was actually paid
+	gm.withGame(inviterID, func(inviter *GameState) {
+		inviter.Money += paid
+		inviter.addEvent("invite_reward", fmt.Sprintf("Received €%.2f from %s (invite reward)", paid, playerID), paid)
+	})
 	
 	// Trigger offer generation
-	go func() {
-		time.Sleep(3 * time.Second)
+	gm.generateOffersSoon()
+	
+	return game, nil
+}
+
+// generateOffersSoon runs the job, apartment and stock offer generators shortly after a player
+// joins or reconnects, instead of waiting for their next round. Other offers follow with
+// autoGenerateOtherOffers. Like the generator loops it stops on shutdown.
+func (gm *GameManager) generateOffersSoon() {
+	gm.runInBackground(func() {
+		if !gm.sleep(3 * time.Second) { // Wait 3 seconds before generating first offers (faster)
+			return
+		}
 		gm.generateJobOffersForAllGames()
 		gm.generateApartmentOffersForAllGames()
 		gm.generateStockOffersForAllGames()
-	}()
-	
-	return game, nil
+	})
 }
 
 // getNetworkRoot finds the root player (first player) in the network
-// Note: This function assumes the caller already holds the appropriate lock
+// Note: This function assumes the caller already holds gm.mu (no game locks are needed)
 func (gm *GameManager) getNetworkRootUnlocked(playerID string) string {
-	visited := make(map[string]bool)
-	currentID := playerID
+	gm.networkMu.Lock()
+	defer gm.networkMu.Unlock()
 	
-	// Traverse up the invite chain to find the root
-	for {
-		if visited[currentID] {
-			break // Prevent infinite loops
+	if root, indexed := gm.networkIndexUnlocked().roots[playerID]; indexed {
+		return root
+	}
+	return playerID // A player without a game roots their own network
+}
+
+// isInviteAncestorUnlocked reports whether ancestorID is playerID itself or appears anywhere up
+// playerID's invite chain, including dangling InvitedBy references. Caller must hold gm.mu.
+func (gm *GameManager) isInviteAncestorUnlocked(ancestorID string, playerID string) bool {
+	visited := make(map[string]bool)
+	for currentID := playerID; currentID != "" && !visited[currentID]; {
+		if currentID == ancestorID {
+			return true
 		}
 		visited[currentID] = true
 		
-		game, exists := gm.games[currentID]
+		entry, exists := gm.games[currentID]
 		if !exists {
 			break
 		}
-		
-		if game.IsFirstPlayer || game.InvitedBy == "" {
-			return currentID
-		}
-		
-		currentID = game.InvitedBy
+		currentID = entry.game.InvitedBy
 	}
-	
-	return playerID // Fallback to current player if no root found
+	return false
 }
 
 // getNetworkRoot finds the root player (first player) in the network
@@ -605,67 +878,256 @@ func (gm *GameManager) getNetworkRoot(playerID string) string {
 func (gm *GameManager) getNetworkPlayers(playerID string) []string {
 	gm.mu.RLock()
 	defer gm.mu.RUnlock()
+	return gm.getNetworkPlayersUnlocked(playerID)
+}
+
+// getNetworkPlayersUnlocked is getNetworkPlayers for callers that already hold gm.mu. The
+// network's root comes first.
+func (gm *GameManager) getNetworkPlayersUnlocked(playerID string) []string {
+	gm.networkMu.Lock()
+	defer gm.networkMu.Unlock()
 	
+	index := gm.networkIndexUnlocked()
+	root, indexed := index.roots[playerID]
+	if !indexed {
+		return []string{playerID}
+	}
+	return slices.Clone(index.members[root])
+}
+
+// advanceNetworkTime moves the network's time forward by delta after playerID's own game moved
+// forward by it. The network root's CurrentDate is the network's clock: delta is added to the
+// root (unless the root is the player, whose clock already moved) and every member behind the
+// root is brought up to it. Members are never moved backwards, so concurrent advances add up
+// instead of rewinding each other. All network players are notified via WebSocket.
+func (gm *GameManager) advanceNetworkTime(playerID string, delta time.Duration) {
+	if delta <= 0 {
+		return // Network time only moves forward
+	}
+	gm.mu.RLock()
 	networkRoot := gm.getNetworkRootUnlocked(playerID)
-	networkPlayers := []string{networkRoot}
+	networkPlayers := gm.getNetworkPlayersUnlocked(playerID)
+	gm.mu.RUnlock()
 	
-	// Find all players who were invited by anyone in the network
-	visited := make(map[string]bool)
-	visited[networkRoot] = true
-	
-	// Recursively find all players in the network
-	var findNetwork func(rootID string)
-	findNetwork = func(rootID string) {
-		for pid, game := range gm.games {
-			if !visited[pid] && game.InvitedBy == rootID {
-				networkPlayers = append(networkPlayers, pid)
-				visited[pid] = true
-				findNetwork(pid) // Recursively find players invited by this one
+	gm.withGames(networkPlayers, func(games map[string]*GameState) {
+		root, exists := games[networkRoot]
+		if !exists {
+			return
+		}
+		if networkRoot != playerID {
+			root.CurrentDate = root.CurrentDate.Add(delta)
+			root.updateMarketIndex()
+			root.depreciateItems()
+		}
+		for _, game := range games {
+			if game.CurrentDate.Before(root.CurrentDate) {
+				game.CurrentDate = root.CurrentDate
+				game.updateMarketIndex()
+				game.depreciateItems()
+			}
+		}
+	})
+	
+	// Notify all network players via WebSocket
+	gm.notifyPlayers(networkPlayers...)
+}
+
+// notifyPlayers pushes the current game state to each listed player that has a WebSocket open.
+// Callers must not hold any game lock (see lock ordering on GameManager).
+func (gm *GameManager) notifyPlayers(playerIDs ...string) {
+	gm.wsConnectionsMu.RLock()
+	defer gm.wsConnectionsMu.RUnlock()
+	
+	for _, pid := range playerIDs {
+		// The player's own connection copies the state to their spectators as it writes it,
+		// so they are only sent it here while the player is away
+		wsConn, exists := gm.wsConnections[pid]
+		if !exists {
+			for spectator := range gm.spectators[pid] {
+				gm.readGame(pid, spectator.sendGameState)
 			}
+			continue
 		}
+		gm.readGame(pid, wsConn.sendGameState)
+	}
+}
+
+// quitAgreement cancels one of the buyer's agreements. The caller must hold the buyer's game lock.
+// It returns the cancelled agreement and the early termination penalty, which the caller passes to
+// payAgreementPenalty once the lock has been released.
+func (gm *GameManager) quitAgreement(game *GameState, agreementID string) (Agreement, float64, error) {
+	// Copy the agreement before QuitAgreement removes it
+	var agreementCopy Agreement
+	agreementFound := false
+	for _, a := range game.Agreements {
+		if a.ID == agreementID {
+			agreementCopy = a
+			agreementFound = true
+			break
+		}
+	}
+	if !agreementFound {
+		return Agreement{}, 0, &GameError{Message: "Agreement not found"}
 	}
 	
-	findNetwork(networkRoot)
+	// Calculate penalty BEFORE calling QuitAgreement (which deducts it from the buyer)
+	penalty := agreementCopy.earlyTerminationPenalty(game.CurrentDate)
 	
-	return networkPlayers
+	if err := game.QuitAgreement(agreementID); err != nil {
+		return Agreement{}, 0, err
+	}
+	return agreementCopy, penalty, nil
 }
 
-// syncTimeAcrossNetwork syncs game time across all players in the network and notifies via WebSocket
-func (gm *GameManager) syncTimeAcrossNetwork(playerID string, newTime time.Time) {
-	networkPlayers := gm.getNetworkPlayers(playerID)
+// payAgreementPenalty settles a cancelled agreement that was bought from another player:
+// the creator's reciprocal agreement (the one sharing its LinkID) is removed and the creator
+// receives any early termination penalty. Must be called without holding any game lock.
+func (gm *GameManager) payAgreementPenalty(buyerID string, agreement Agreement, penalty float64) {
+	if agreement.OtherPartyID == "" || agreement.IsReciprocal {
+		return
+	}
 	
-	gm.mu.Lock()
-	for _, pid := range networkPlayers {
-		if game, exists := gm.games[pid]; exists {
-			game.CurrentDate = newTime
+	// This is a buyer canceling - the creator gets the penalty payment
+	logInfof("[QUIT_AGREEMENT] Buyer %s canceled agreement %s, penalty €%.2f should go to creator %s", 
+		buyerID, agreement.ID, penalty, agreement.OtherPartyID)
+	
+	creatorNotified := false
+	creatorExists := gm.withGame(agreement.OtherPartyID, func(creator *GameState) {
+		// Find and remove the reciprocal agreement from creator
+		reciprocalFound := false
+		for idx, creatorAgreement := range creator.Agreements {
+			if agreement.matchesReciprocal(&creatorAgreement, buyerID) {
+				creator.Agreements = append(creator.Agreements[:idx], creator.Agreements[idx+1:]...)
+				reciprocalFound = true
+				logInfof("[QUIT_AGREEMENT] Found and removed reciprocal agreement %s from creator %s", 
+					creatorAgreement.ID, agreement.OtherPartyID)
+				break
+			}
+		}
+		
+		if reciprocalFound && penalty <= 0 {
+			creator.addEvent("agreement_cancelled", fmt.Sprintf("%s canceled %s", buyerID, agreement.Title), 0)
+			creatorNotified = true
+		} else if reciprocalFound {
+			creator.Money += penalty
+			creator.addEvent("agreement_cancelled_penalty", 
+				fmt.Sprintf("Received €%.2f early termination penalty from %s canceling %s", 
+					penalty, buyerID, agreement.Title), penalty)
+			logInfof("[QUIT_AGREEMENT] Creator %s received penalty €%.2f from buyer %s. Remaining agreements: %d", 
+				agreement.OtherPartyID, penalty, buyerID, len(creator.Agreements))
+			creatorNotified = true
+		} else {
+			logWarnf("[QUIT_AGREEMENT] Reciprocal agreement not found for creator %s, buyer %s. Creator has %d agreements", 
+				agreement.OtherPartyID, buyerID, len(creator.Agreements))
+			for _, ag := range creator.Agreements {
+				logDebugf("[QUIT_AGREEMENT] Creator agreement: ID=%s, IsReciprocal=%v, OtherPartyID=%s, Title=%s", 
+					ag.ID, ag.IsReciprocal, ag.OtherPartyID, ag.Title)
+			}
 		}
+	})
+	if !creatorExists {
+		logWarnf("[QUIT_AGREEMENT] Creator %s not found", agreement.OtherPartyID)
 	}
-	gm.mu.Unlock()
 	
-	// Notify all network players via WebSocket
-	gm.wsConnectionsMu.RLock()
-	for _, pid := range networkPlayers {
-		if wsConn, exists := gm.wsConnections[pid]; exists {
-			// Get fresh game state
-			gm.mu.RLock()
-			if game, exists := gm.games[pid]; exists {
-				wsConn.sendGameState(game)
+	if creatorNotified {
+		gm.notifyPlayers(agreement.OtherPartyID)
+	}
+}
+
+// endedLinkedAgreements returns the agreements with other players in before that are not in after
+func endedLinkedAgreements(before []Agreement, after []Agreement) []Agreement {
+	var ended []Agreement
+	for _, agreement := range before {
+		if agreement.OtherPartyID == "" {
+			continue
+		}
+		if !slices.ContainsFunc(after, func(a Agreement) bool { return a.ID == agreement.ID }) {
+			ended = append(ended, agreement)
+		}
+	}
+	return ended
+}
+
+// endLinkedAgreements ends the other party's side of agreements playerID no longer has, so a
+// creator is not paid on for an agreement its buyer lost, nor a buyer charged on for one its
+// creator lost. Must be called without holding any game lock.
+func (gm *GameManager) endLinkedAgreements(playerID string, ended []Agreement) {
+	for _, agreement := range ended {
+		agreement := agreement
+		removed := false
+		gm.withGame(agreement.OtherPartyID, func(other *GameState) {
+			i := slices.IndexFunc(other.Agreements, func(a Agreement) bool { return agreement.isCounterpart(&a, playerID) })
+			if i < 0 {
+				return
 			}
-			gm.mu.RUnlock()
+			title := other.Agreements[i].Title
+			other.Agreements = slices.Delete(other.Agreements, i, i+1)
+			other.addEvent("agreement_cancelled", fmt.Sprintf("%s ended %s", playerID, title), 0)
+			logInfof("[AGREEMENT] Ended %s's side of agreement %s with %s", agreement.OtherPartyID, agreement.LinkID, playerID)
+			removed = true
+		})
+		if removed {
+			gm.notifyPlayers(agreement.OtherPartyID)
 		}
 	}
-	gm.wsConnectionsMu.RUnlock()
+}
+
+// settleOfferSale pays the creator of a player-created offer that buyerID accepted and, for
+// recurring offers, gives the creator a reciprocal agreement. Must be called without holding
+// any game lock.
+func (gm *GameManager) settleOfferSale(buyerID string, offer Offer) {
+	gm.withGame(offer.CreatedBy, func(creator *GameState) {
+		// Transfer money to creator
+		creator.Money += offer.Price
+		creator.addEvent("offer_sold", fmt.Sprintf("Sold %s to %s for €%.2f", offer.Title, buyerID, offer.Price), offer.Price)
+		
+		// If it's a recurring offer, creator should also get an agreement
+		if offer.IsRecurring {
+			recurrenceType := offer.RecurrenceType
+			if recurrenceType == "" {
+				recurrenceType = "monthly"
+			}
+			
+			// Create reciprocal agreement for creator (they provide the service)
+			// The creator gets money periodically (positive MoneyChange)
+			creatorAgreement := Agreement{
+				ID:              generateID(),
+				Title:           fmt.Sprintf("Providing %s to %s", offer.Title, buyerID),
+				Description:     fmt.Sprintf("You are providing %s to %s. You receive €%.2f per %s.", offer.Description, buyerID, offer.Price, recurrenceType),
+				RecurrenceType:  recurrenceType,
+				StartedAt:       creator.CurrentDate,
+				LastProcessedAt: creator.CurrentDate,
+				HealthChange:    0, // Creator doesn't lose health/energy from providing service
+				EnergyChange:    0,
+				ReputationChange: 0,
+				MoneyChange:     offer.Price, // Creator receives money periodically
+				IsTrickery:      offer.IsTrickery,
+				Reason:          fmt.Sprintf("Reciprocal agreement from selling %s", offer.Title),
+				Category:        offer.Category,
+				IsReciprocal:    true,  // Mark as reciprocal
+				OtherPartyID:    buyerID, // Track who the buyer is
+				OriginalPrice:   offer.Price, // Store original price for penalty calculation
+				LinkID:          offer.ID, // Same as the buyer's agreement
+			}
+			creatorAgreement.normalizeMoneyChange()
+			creator.Agreements = append(creator.Agreements, creatorAgreement)
+			creator.addEvent("agreement_started", fmt.Sprintf("Started providing %s to %s (€%.2f per %s)", offer.Title, buyerID, offer.Price, recurrenceType), 0)
+			logInfof("[ACCEPT_OFFER] Created reciprocal agreement for creator %s: ID=%s, Title=%s, OtherParty=%s", 
+				offer.CreatedBy, creatorAgreement.ID, creatorAgreement.Title, buyerID)
+		} else {
+			// For one-time offers, creator just gets the money (already done above)
+			logInfof("[ACCEPT_OFFER] One-time offer accepted, creator %s received €%.2f", offer.CreatedBy, offer.Price)
+		}
+	})
+	
+	// Notify creator via WebSocket if connected
+	gm.notifyPlayers(offer.CreatedBy)
 }
 
 // removeJobOfferFromNetwork removes a job offer from all players in the network
 func (gm *GameManager) removeJobOfferFromNetwork(playerID string, offerID string) {
-	networkPlayers := gm.getNetworkPlayers(playerID)
-	
-	gm.mu.Lock()
-	defer gm.mu.Unlock()
-	
-	for _, pid := range networkPlayers {
-		if game, exists := gm.games[pid]; exists {
+	gm.withGames(gm.getNetworkPlayers(playerID), func(games map[string]*GameState) {
+		for _, game := range games {
 			// Remove the job offer
 			for i, offer := range game.JobOffers {
 				if offer.ID == offerID {
@@ -674,7 +1136,7 @@ func (gm *GameManager) removeJobOfferFromNetwork(playerID string, offerID string
 				}
 			}
 		}
-	}
+	})
 	
 	// Remove from shared job offers tracking
 	gm.sharedJobOffersMu.Lock()
@@ -684,13 +1146,8 @@ func (gm *GameManager) removeJobOfferFromNetwork(playerID string, offerID string
 
 // removeOfferFromNetwork removes an offer from all players in the network
 func (gm *GameManager) removeOfferFromNetwork(playerID string, offerID string) {
-	networkPlayers := gm.getNetworkPlayers(playerID)
-	
-	gm.mu.Lock()
-	defer gm.mu.Unlock()
-	
-	for _, pid := range networkPlayers {
-		if game, exists := gm.games[pid]; exists {
+	gm.withGames(gm.getNetworkPlayers(playerID), func(games map[string]*GameState) {
+		for _, game := range games {
 			// Remove the offer
 			for i, offer := range game.ActiveOffers {
 				if offer.ID == offerID {
@@ -699,7 +1156,54 @@ func (gm *GameManager) removeOfferFromNetwork(playerID string, offerID string) {
 				}
 			}
 		}
+	})
+}
+
+// sendTrickeryLesson asks the explainer agent about an accepted trickery offer and pushes the lesson via WebSocket
+func (gm *GameManager) sendTrickeryLesson(playerID string, offer Offer) {
+	if !offer.IsTrickery || !GetConfig().Features.TrickeryExplainer {
+		return
 	}
+	
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logErrorf("[PANIC] Panic in trickery lesson goroutine for player %s: %v", playerID, r)
+			}
+		}()
+		
+		game, exists := gm.snapshotGame(playerID)
+		if !exists {
+			return
+		}
+		
+		lesson, err := gm.ai.ExplainTrickery(gm.ctx, game, &offer)
+		if err != nil {
+			logWarnf("[LESSON] Explainer failed for player %s, using fallback: %v", playerID, err)
+		}
+		
+		data, err := json.Marshal(map[string]interface{}{
+			"type":     "lesson",
+			"offer_id": offer.ID,
+			"title":    offer.Title,
+			"message":  lesson,
+		})
+		if err != nil {
+			logErrorf("[LESSON] Error marshaling lesson for player %s: %v", playerID, err)
+			return
+		}
+		
+		gm.wsConnectionsMu.RLock()
+		wsConn, exists := gm.wsConnections[playerID]
+		gm.wsConnectionsMu.RUnlock()
+		if !exists {
+			return
+		}
+		
+		if !wsConn.trySend(data) {
+			logWarnf("[LESSON] WebSocket send channel full or closed for player %s", playerID)
+		}
+	}()
 }
 
 // shareJobOfferWithNetwork adds a job offer to all players in the network
@@ -707,17 +1211,14 @@ func (gm *GameManager) shareJobOfferWithNetwork(playerID string, offer JobOffer)
 	networkPlayers := gm.getNetworkPlayers(playerID)
 	networkRoot := gm.getNetworkRoot(playerID)
 	
-	gm.mu.Lock()
-	defer gm.mu.Unlock()
-	
 	// Track this offer as shared
 	gm.sharedJobOffersMu.Lock()
 	gm.sharedJobOffers[offer.ID] = networkRoot
 	gm.sharedJobOffersMu.Unlock()
 	
 	// Add offer to all players in network
-	for _, pid := range networkPlayers {
-		if game, exists := gm.games[pid]; exists {
+	gm.withGames(networkPlayers, func(games map[string]*GameState) {
+		for _, game := range games {
 			// Check if offer already exists
 			exists := false
 			for _, existingOffer := range game.JobOffers {
@@ -730,101 +1231,182 @@ func (gm *GameManager) shareJobOfferWithNetwork(playerID string, offer JobOffer)
 				game.JobOffers = append(game.JobOffers, offer)
 			}
 		}
-	}
+	})
 }
 
 // autoGenerateStockOffers periodically generates stock offers
 func (gm *GameManager) autoGenerateStockOffers() {
 	// Generate initial offers after a short delay
-	time.Sleep(35 * time.Second)
+	if !gm.sleep(time.Duration(GetConfig().Offers.Stocks.InitialDelaySeconds) * time.Second) {
+		return
+	}
 	gm.generateStockOffersForAllGames()
 	
-	// Generate more frequently: every 50-130 seconds (randomized for variability)
-	for {
-		// Random interval between 50-130 seconds
-		interval := 50 + rand.Intn(80) // 50-130 seconds
-		time.Sleep(time.Duration(interval) * time.Second)
+	// Then every offers.stocks min-max interval (50-130 seconds by default), read each round
+	for gm.sleep(GetConfig().Offers.Stocks.nextInterval(gm.rng)) {
 		gm.generateStockOffersForAllGames()
 	}
 }
 
 // generateStockOffersForAllGames generates stock offers for all active games
 func (gm *GameManager) generateStockOffersForAllGames() {
-	gm.mu.RLock()
-	gameList := make(map[string]*GameState)
-	for k, v := range gm.games {
-		gameList[k] = v
-	}
-	gm.mu.RUnlock()
+	playerIDs := gm.playerIDs()
 	
 	gm.stockOfferGenMu.Lock()
 	defer gm.stockOfferGenMu.Unlock()
 	
-	for playerID, game := range gameList {
-		// Check if enough time has passed (30 seconds real time minimum)
+	for _, playerID := range playerIDs {
+		if !gm.networkIsLive(playerID) {
+			continue // Nobody in the network is playing
+		}
+		
+		// Check if enough time has passed (offers.stocks.min_interval_seconds real time)
 		lastGen, exists := gm.lastStockOfferGen[playerID]
-		if !exists || time.Since(lastGen) >= 30*time.Second {
-			// Limit to max 6 stock offers (increased from 5)
-			gm.mu.RLock()
-			currentOffers := 0
-			if g, exists := gm.games[playerID]; exists {
-				currentOffers = len(g.StockOffers)
+		if !exists || gm.since(lastGen) >= GetConfig().Offers.Stocks.minGap() {
+			// Limit open stock offers to the difficulty's cap
+			game, exists := gm.snapshotGame(playerID)
+			if !exists {
+				continue
 			}
-			gm.mu.RUnlock()
-			
-			if currentOffers < 6 {
-				stockOffer, err := gm.ai.GenerateStockOffer(game)
-				if err == nil && stockOffer != nil {
-					gm.mu.Lock()
-					if g, exists := gm.games[playerID]; exists {
-						g.StockOffers = append(g.StockOffers, *stockOffer)
-					}
-					gm.mu.Unlock()
-					
-					// Notify player via WebSocket if connected
-					gm.wsConnectionsMu.RLock()
-					if wsConn, exists := gm.wsConnections[playerID]; exists {
-						gm.mu.RLock()
-						if g, exists := gm.games[playerID]; exists {
-							wsConn.sendGameState(g)
-						}
-						gm.mu.RUnlock()
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					gm.lastStockOfferGen[playerID] = time.Now()
-				}
+			if len(game.StockOffers) < game.difficulty().MaxStockOffers && gm.generateStockOffer(playerID, game) {
+				gm.lastStockOfferGen[playerID] = gm.now()
 			}
 		}
 	}
 }
 
-// GetGame gets a game for a player
-func (gm *GameManager) GetGame(playerID string) (*GameState, error) {
-	gm.mu.RLock()
-	defer gm.mu.RUnlock()
-	
-	game, exists := gm.games[playerID]
-	if !exists {
-		return nil, &GameError{Message: "Game not found"}
-	}
-	return game, nil
+// generateStockOffer draws one stock offer from the catalog (with catalog.share odds) or asks the
+// AI, adds it to the player's game and notifies them. game is a snapshot of the player's game.
+func (gm *GameManager) generateStockOffer(playerID string, game *GameState) bool {
+	var stockOffer *StockOffer
+	var err error
+	if gm.catalog.draws(game) {
+		stockOffer = gm.catalog.stockOffer(game)
+	}
+	if stockOffer == nil {
+		stockOffer, err = gm.ai.GenerateStockOffer(gm.ctx, game)
+	}
+	if err != nil || stockOffer == nil {
+		return false
+	}
+	stockOffer.ExpiresAt = game.difficulty().scaleOfferExpiry(game.CurrentDate, stockOffer.ExpiresAt)
+	// Offer a symbol the network already trades at its price, and list new ones at the offer's
+	stockOffer.CurrentPrice = game.prices.stockPrice(stockOffer.Symbol, game.CurrentDate, stockOffer.CurrentPrice, stockOffer.Beta)
+	gm.withGame(playerID, func(g *GameState) {
+		g.StockOffers = append(g.StockOffers, *stockOffer)
+	})
+	recordOfferGenerated("stock", !stockOffer.IsSafe)
+	
+	// Notify player via WebSocket if connected
+	gm.notifyPlayers(playerID)
+	return true
+}
+
+// getEntry looks up a player's game entry; gm.mu is only held for the map lookup
+func (gm *GameManager) getEntry(playerID string) (*gameEntry, bool) {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	
+	entry, exists := gm.games[playerID]
+	return entry, exists
+}
+
+// playerIDs returns the IDs of all active games
+func (gm *GameManager) playerIDs() []string {
+	gm.mu.RLock()
+	defer gm.mu.RUnlock()
+	
+	ids := make([]string, 0, len(gm.games))
+	for playerID := range gm.games {
+		ids = append(ids, playerID)
+	}
+	return ids
+}
+
+// withGame runs fn while holding the player's game write lock, then drops the player's cached
+// state. Returns false if the player has no game.
+func (gm *GameManager) withGame(playerID string, fn func(game *GameState)) bool {
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		return false
+	}
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	fn(entry.game)
+	gm.invalidateCache(playerID)
+	return true
+}
+
+// readGame runs fn while holding the player's game read lock.
+// Returns false if the player has no game.
+func (gm *GameManager) readGame(playerID string, fn func(game *GameState)) bool {
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		return false
+	}
+	entry.mu.RLock()
+	defer entry.mu.RUnlock()
+	fn(entry.game)
+	return true
+}
+
+// withGames runs fn while holding the write locks of several games at once, then drops
+// their cached states. Locks are taken in ascending player ID order so concurrent callers
+// cannot deadlock; players without a game are left out of the map passed to fn.
+func (gm *GameManager) withGames(playerIDs []string, fn func(games map[string]*GameState)) {
+	ids := slices.Clone(playerIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+	
+	entries := make([]*gameEntry, 0, len(ids))
+	games := make(map[string]*GameState, len(ids))
+	gm.mu.RLock()
+	for _, pid := range ids {
+		if entry, exists := gm.games[pid]; exists {
+			entries = append(entries, entry)
+			games[pid] = entry.game
+		}
+	}
+	gm.mu.RUnlock()
+	
+	for _, entry := range entries {
+		entry.mu.Lock()
+	}
+	defer func() {
+		for i := len(entries) - 1; i >= 0; i-- {
+			entries[i].mu.Unlock()
+		}
+	}()
+	fn(games)
+	for pid := range games {
+		gm.invalidateCache(pid)
+	}
+}
+
+// invalidateCache drops the player's cached /api/state response. Call it after every change
+// to the player's game, while still holding the game's write lock, so HandleGetState cannot
+// cache state from before the change after it was dropped.
+func (gm *GameManager) invalidateCache(playerID string) {
+	gm.stateCacheMu.Lock()
+	delete(gm.stateCache, playerID)
+	gm.stateCacheMu.Unlock()
+}
+
+// snapshotGame returns a copy of the player's game that can be used without holding
+// any lock, e.g. as context for a slow AI call
+func (gm *GameManager) snapshotGame(playerID string) (*GameState, bool) {
+	var snapshot *GameState
+	exists := gm.readGame(playerID, func(game *GameState) {
+		snapshot = game.snapshot()
+	})
+	return snapshot, exists
 }
 
-// getETag generates an ETag for a game state
-func (gm *GameManager) getETag(game *GameState) string {
-	// Use a hash of key fields that change frequently
-	hash := sha256.New()
-	hash.Write([]byte(fmt.Sprintf("%s-%.2f-%d-%d-%d-%v-%v",
-		game.PlayerID,
-		game.Money,
-		game.Health,
-		game.Energy,
-		len(game.History),
-		game.CurrentDate.Unix(),
-		game.IsWorking,
-	)))
-	return fmt.Sprintf(`"%x"`, hash.Sum(nil)[:8])
+// stateETag generates an ETag from the serialized game state, so any change to what the
+// client receives (inventory, agreements, offers, ...) produces a new ETag
+func stateETag(data []byte) string {
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf(`"%x"`, hash[:16])
 }
 
 // encodeJSON encodes game state to JSON with compression support
@@ -838,9 +1420,7 @@ func (gm *GameManager) encodeJSON(game *GameState) ([]byte, string, error) {
 	
 	// Limit history size for performance (keep last 50 events)
 	limitedGame := *game
-	if len(limitedGame.History) > 50 {
-		limitedGame.History = limitedGame.History[len(limitedGame.History)-50:]
-	}
+	limitedGame.History = game.History.Tail(maxHistoryInState)
 	
 	encoder := json.NewEncoder(buf)
 	encoder.SetEscapeHTML(false)
@@ -848,13 +1428,14 @@ func (gm *GameManager) encodeJSON(game *GameState) ([]byte, string, error) {
 		return nil, "", err
 	}
 	
-	data := buf.Bytes()
+	// Copy out of the pooled buffer, which is reused once this returns
+	data := bytes.Clone(buf.Bytes())
 	// Remove trailing newline from json.Encoder
 	if len(data) > 0 && data[len(data)-1] == '\n' {
 		data = data[:len(data)-1]
 	}
 	
-	etag := gm.getETag(game)
+	etag := stateETag(data)
 	return data, etag, nil
 }
 
@@ -868,7 +1449,7 @@ func (gm *GameManager) writeJSONResponse(w http.ResponseWriter, r *http.Request,
 	
 	// Set caching headers
 	w.Header().Set("ETag", etag)
-	w.Header().Set("Cache-Control", "private, max-age=5") // Cache for 5 seconds
+	w.Header().Set("Cache-Control", "private, no-cache") // Revalidate every time; unchanged state is a cheap 304
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	
 	// Check if client accepts gzip
@@ -891,7 +1472,30 @@ func (gm *GameManager) HandleGetState(w http.ResponseWriter, r *http.Request) {
 		playerID = "default"
 	}
 	
-	game := gm.GetOrCreateGame(playerID)
+	gm.markActive(playerID)
+	entry := gm.GetOrCreateGame(playerID, r.URL.Query().Get("lang"), r.URL.Query().Get("scenario"))
+	
+	// A category filter trims the "other" offers, so that response is encoded fresh and not cached
+	if r.URL.Query().Get("category") != "" {
+		category, err := parseOfferCategory(r.URL.Query())
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		entry.mu.RLock()
+		filtered := *entry.game
+		filtered.ActiveOffers = filterOffersByCategory(entry.game.ActiveOffers, category)
+		data, etag, err := gm.encodeJSON(&filtered)
+		entry.mu.RUnlock()
+		if err != nil {
+			http.Error(w, "Failed to encode game state", http.StatusInternalServerError)
+			return
+		}
+		gm.writeJSONResponse(w, r, data, etag)
+		return
+	}
 	
 	// Check cache first
 	gm.stateCacheMu.RLock()
@@ -902,27 +1506,31 @@ func (gm *GameManager) HandleGetState(w http.ResponseWriter, r *http.Request) {
 	var etag string
 	var err error
 	
-	if exists && time.Since(cached.timestamp) < 2*time.Second {
-		// Use cached data if fresh (< 2 seconds old)
+	if exists {
+		// Every change to the game drops its cache entry (see invalidateCache), so a cached
+		// entry always matches the current state
 		data = cached.jsonData
 		etag = cached.etag
 	} else {
-		// Encode fresh
-		data, etag, err = gm.encodeJSON(game)
+		// Encode fresh and cache it before releasing the read lock, so a change made after
+		// the encoding cannot have its invalidation overwritten by this older data
+		entry.mu.RLock()
+		data, etag, err = gm.encodeJSON(entry.game)
+		if err == nil {
+			gm.stateCacheMu.Lock()
+			gm.stateCache[playerID] = &cachedState{
+				state:     entry.game,
+				etag:      etag,
+				timestamp: gm.now(),
+				jsonData:  data,
+			}
+			gm.stateCacheMu.Unlock()
+		}
+		entry.mu.RUnlock()
 		if err != nil {
 			http.Error(w, "Failed to encode game state", http.StatusInternalServerError)
 			return
 		}
-		
-		// Update cache
-		gm.stateCacheMu.Lock()
-		gm.stateCache[playerID] = &cachedState{
-			state:     game,
-			etag:      etag,
-			timestamp: time.Now(),
-			jsonData:  data,
-		}
-		gm.stateCacheMu.Unlock()
 	}
 	
 	gm.writeJSONResponse(w, r, data, etag)
@@ -935,31 +1543,138 @@ func (gm *GameManager) HandleAction(w http.ResponseWriter, r *http.Request) {
 		playerID = "default"
 	}
 	
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
+	gm.markActive(playerID)
 	
 	var actionReq ActionRequest
 	if err := json.NewDecoder(r.Body).Decode(&actionReq); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 	
+	actionStart := time.Now()
+	defer func() {
+		recordActionDuration(actionReq.Action, time.Since(actionStart))
+	}()
+	
+	entry.mu.Lock()
+	game := entry.game
+	wasWon := game.GameWon
+	result, followUps := gm.dispatchAction(playerID, game, actionReq.Action, actionReq.Data)
+	followUps = append(followUps, gm.finishActions(playerID, game, wasWon)...)
+	gm.invalidateCache(playerID)
+	entry.mu.Unlock()
+	
+	for _, followUp := range followUps {
+		followUp()
+	}
+	
+	gm.writeActionResponse(w, r, entry, result)
+}
+
+// maxBatchActions limits how many actions one /api/actions/batch request may carry
+const maxBatchActions = 50
+
+// HandleBatchActions runs an ordered list of actions under a single lock acquisition, so no
+// other request can see or change the game halfway through (e.g. between quitting a job and
+// accepting a new one). It stops at the first failed action unless continue_on_error is set,
+// and returns every executed action's result plus the final game state.
+func (gm *GameManager) HandleBatchActions(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	if playerID == "" {
+		playerID = "default"
+	}
+	
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	gm.markActive(playerID)
+	
+	var batchReq struct {
+		Actions         []ActionRequest `json:"actions"`
+		ContinueOnError bool            `json:"continue_on_error"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&batchReq); err != nil || len(batchReq.Actions) == 0 {
+		if requestTooLarge(w, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "actions must be a non-empty array"})
+		return
+	}
+	if len(batchReq.Actions) > maxBatchActions {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("at most %d actions per batch", maxBatchActions)})
+		return
+	}
+	
+	results := make([]map[string]interface{}, 0, len(batchReq.Actions))
+	var followUps []func()
+	allSucceeded := true
+	
+	entry.mu.Lock()
+	game := entry.game
+	wasWon := game.GameWon
+	for _, actionReq := range batchReq.Actions {
+		actionStart := time.Now()
+		result, actionFollowUps := gm.dispatchAction(playerID, game, actionReq.Action, actionReq.Data)
+		recordActionDuration(actionReq.Action, time.Since(actionStart))
+		
+		result["action"] = actionReq.Action
+		results = append(results, result)
+		followUps = append(followUps, actionFollowUps...)
+		if success, _ := result["success"].(bool); !success {
+			allSucceeded = false
+			if !batchReq.ContinueOnError {
+				break
+			}
+		}
+	}
+	followUps = append(followUps, gm.finishActions(playerID, game, wasWon)...)
+	gm.invalidateCache(playerID)
+	entry.mu.Unlock()
+	
+	for _, followUp := range followUps {
+		followUp()
+	}
+	
+	gm.writeActionResponse(w, r, entry, map[string]interface{}{
+		"success": allSucceeded,
+		"results": results,
+	})
+}
+
+// dispatchAction performs one player action on game and returns its result, plus work that
+// touches other players' games and must run after this player's lock is released. It is the
+// single implementation of every action shared by HTTP (HandleAction, HandleBatchActions) and
+// WebSocket (processWebSocketAction). The caller must hold the game's write lock.
+func (gm *GameManager) dispatchAction(playerID string, game *GameState, action string, data map[string]interface{}) (map[string]interface{}, []func()) {
 	var result map[string]interface{}
+	var err error
+	var followUps []func()
 	
-	switch actionReq.Action {
+	switch action {
 	case "start_work":
 		err = game.StartWork()
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "accept_job_offer":
-		offerID := getString(actionReq.Data, "offer_id", "")
+		offerID := getString(data, "offer_id", "")
 		err = game.AcceptJobOffer(offerID)
 		if err == nil {
 			// Remove job offer from all players in the network
-			gm.removeJobOfferFromNetwork(playerID, offerID)
+			followUps = append(followUps, func() { gm.removeJobOfferFromNetwork(playerID, offerID) })
 		}
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
@@ -967,278 +1682,292 @@ func (gm *GameManager) HandleAction(w http.ResponseWriter, r *http.Request) {
 		err = game.QuitJob()
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
+	case "take_day_off":
+		err = game.TakeDayOff()
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
 	case "stop_work":
 		err = game.StopWork()
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "show_hint":
-		offerID := getString(actionReq.Data, "offer_id", "")
+		offerID := getString(data, "offer_id", "")
 		err = game.ShowHint(offerID)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "accept_apartment_offer":
-		offerID := getString(actionReq.Data, "offer_id", "")
+		offerID := getString(data, "offer_id", "")
 		err = game.AcceptApartmentOffer(offerID)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "show_apartment_hint":
-		offerID := getString(actionReq.Data, "offer_id", "")
+		offerID := getString(data, "offer_id", "")
 		err = game.ShowApartmentHint(offerID)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
-	case "quit_apartment":
-		err = game.QuitApartment()
+	case "show_stock_hint":
+		offerID := getString(data, "offer_id", "")
+		err = game.ShowStockHint(offerID)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
-	case "quit_agreement":
-		agreementID := getString(actionReq.Data, "agreement_id", "")
-		// Find the agreement before quitting to check if it's reciprocal
-		// Copy all necessary data before QuitAgreement modifies/removes it
-		var agreementCopy Agreement
-		var agreementFound bool
-		for _, a := range game.Agreements {
-			if a.ID == agreementID {
-				agreementCopy = a // Copy the agreement data
-				agreementFound = true
-				break
-			}
-		}
+	case "show_news_hint":
+		symbol := getString(data, "symbol", "")
+		err = game.ShowNewsHint(symbol)
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "show_other_offer_hint":
+		offerID := getString(data, "offer_id", "")
+		err = game.ShowOtherOfferHint(offerID)
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "buy_insurance":
+		err = game.BuyInsurance()
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
-		if !agreementFound {
-			result = map[string]interface{}{"success": false, "message": "Agreement not found"}
+	case "cancel_insurance":
+		err = game.CancelInsurance()
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "report_offer":
+		offerType := getString(data, "offer_type", "")
+		offerID := getString(data, "offer_id", "")
+		var isScam bool
+		var reward float64
+		isScam, reward, err = game.ReportOffer(offerType, offerID)
+		message := getMessage(err)
+		if err == nil && isScam && reward > 0 {
+			message = "Good catch! That was a scam. You earned €" + formatMoney(reward) + " and reputation."
+		} else if err == nil && isScam {
+			message = "Good catch! That was a scam. You earned reputation; there is no reward for a scam you bought the hint for."
+		} else if err == nil {
+			message = "That offer was legitimate. False reports cost you reputation."
+		}
+		result = map[string]interface{}{"success": err == nil, "message": message, "correct": isScam}
+		
+	case "refresh_offers":
+		var refresh func()
+		refresh, err = gm.refreshOffers(playerID, game, getString(data, "offer_type", ""))
+		if err != nil {
+			result = map[string]interface{}{"success": false, "message": getMessage(err)}
 			break
 		}
+		followUps = append(followUps, refresh)
+		result = map[string]interface{}{"success": true, "message": "Looking for a new offer, it will appear shortly"}
 		
-		// Calculate penalty BEFORE calling QuitAgreement (which might modify CurrentDate)
-		penalty := 0.0
-		daysActive := game.CurrentDate.Sub(agreementCopy.StartedAt).Hours() / 24
-		switch agreementCopy.RecurrenceType {
-		case "daily":
-			if daysActive < 1 {
-				penalty = 50.0
-			} else if daysActive < 7 {
-				penalty = 25.0
-			}
-		case "weekly":
-			if daysActive < 7 {
-				penalty = 100.0
-			} else if daysActive < 30 {
-				penalty = 50.0
-			}
-		case "monthly":
-			if daysActive < 30 {
-				penalty = 200.0
-			} else if daysActive < 90 {
-				penalty = 100.0
-			}
-		}
+	case "quit_apartment":
+		err = game.QuitApartment(getString(data, "apartment_id", ""))
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
-		err = game.QuitAgreement(agreementID)
+	case "rent_out":
+		err = game.RentOut(getString(data, "apartment_id", ""))
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
-		if err == nil && agreementCopy.OtherPartyID != "" && !agreementCopy.IsReciprocal && penalty > 0 {
-			// This is a buyer canceling - creator should get penalty payment
-			// The penalty was already deducted from the buyer in QuitAgreement
-			// Now we need to give it to the creator
-			log.Printf("[QUIT_AGREEMENT] Buyer %s canceled agreement %s, penalty €%.2f should go to creator %s", 
-				playerID, agreementCopy.ID, penalty, agreementCopy.OtherPartyID)
-			
-			gm.mu.Lock()
-			if creator, exists := gm.games[agreementCopy.OtherPartyID]; exists {
-				// Find and remove the reciprocal agreement from creator
-				reciprocalFound := false
-				for idx, creatorAgreement := range creator.Agreements {
-					if creatorAgreement.IsReciprocal && creatorAgreement.OtherPartyID == playerID {
-						creator.Agreements = append(creator.Agreements[:idx], creator.Agreements[idx+1:]...)
-						reciprocalFound = true
-						log.Printf("[QUIT_AGREEMENT] Found and removed reciprocal agreement %s from creator %s", 
-							creatorAgreement.ID, agreementCopy.OtherPartyID)
-						break
-					}
-				}
-				
-				if reciprocalFound {
-					creator.Money += penalty
-					creator.addEvent("agreement_cancelled_penalty", 
-						fmt.Sprintf("Received €%.2f early termination penalty from %s canceling %s", 
-							penalty, playerID, agreementCopy.Title), penalty)
-					log.Printf("[QUIT_AGREEMENT] Creator %s received penalty €%.2f from buyer %s", 
-						agreementCopy.OtherPartyID, penalty, playerID)
-					
-					// Get fresh creator state after modifications
-					creatorStateForSend := creator
-					
-					gm.mu.Unlock() // Release lock before WebSocket operations
-					
-					// Notify creator via WebSocket
-					gm.wsConnectionsMu.RLock()
-					if creatorWs, exists := gm.wsConnections[agreementCopy.OtherPartyID]; exists {
-						// Re-acquire read lock to get fresh state
-						gm.mu.RLock()
-						if freshCreator, exists := gm.games[agreementCopy.OtherPartyID]; exists {
-							creatorStateForSend = freshCreator
-						}
-						gm.mu.RUnlock()
-						
-						creatorWs.sendGameState(creatorStateForSend)
-						log.Printf("[QUIT_AGREEMENT] Notified creator %s via WebSocket with %d agreements", 
-							agreementCopy.OtherPartyID, len(creatorStateForSend.Agreements))
-					} else {
-						log.Printf("[QUIT_AGREEMENT] Creator %s not connected via WebSocket", agreementCopy.OtherPartyID)
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					// Re-acquire lock for result
-					gm.mu.Lock()
-				} else {
-					log.Printf("[QUIT_AGREEMENT] WARNING: Reciprocal agreement not found for creator %s, buyer %s", 
-						agreementCopy.OtherPartyID, playerID)
-					gm.mu.Unlock()
-				}
-			} else {
-				log.Printf("[QUIT_AGREEMENT] WARNING: Creator %s not found", agreementCopy.OtherPartyID)
-				gm.mu.Unlock()
-			}
+	case "stop_renting_out":
+		err = game.StopRentingOut(getString(data, "apartment_id", ""))
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "revoke_invite":
+		gm.revokeInvite(game)
+		result = map[string]interface{}{"success": true, "message": "Invite code revoked, new code is " + game.InviteCode}
+		
+	case "quit_agreement":
+		agreementID := getString(data, "agreement_id", "")
+		var agreement Agreement
+		var penalty float64
+		agreement, penalty, err = gm.quitAgreement(game, agreementID)
+		if err == nil && agreement.IsReciprocal {
+			// A creator quitting their side ends the buyer's side too
+			followUps = append(followUps, func() { gm.endLinkedAgreements(playerID, []Agreement{agreement}) })
+		} else if err == nil {
+			followUps = append(followUps, func() { gm.payAgreementPenalty(playerID, agreement, penalty) })
 		}
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "advance_time":
-		hours := getFloat(actionReq.Data, "hours", 0.0)
-		if hours > 0 {
+		hours := getFloat(data, "hours", 0.0)
+		if err = gm.claimAdvance(playerID, hours, GetConfig().Game.MaxAdvanceHours); err == nil {
 			oldTime := game.CurrentDate
 			game.AdvanceTime(time.Duration(hours * float64(time.Hour)))
-			newTime := game.CurrentDate
 			
-			// Sync time across all players in the network
-			if !oldTime.Equal(newTime) {
-				gm.syncTimeAcrossNetwork(playerID, newTime)
+			// Move the rest of the network forward by the same amount
+			if delta := game.CurrentDate.Sub(oldTime); delta > 0 {
+				followUps = append(followUps, func() { gm.advanceNetworkTime(playerID, delta) })
 			}
 			
 			result = map[string]interface{}{"success": true, "message": "Time advanced"}
 		} else {
-			result = map[string]interface{}{"success": false, "message": "Invalid time duration"}
+			result = map[string]interface{}{"success": false, "message": getMessage(err)}
+		}
+		
+	case "rest":
+		hours := getFloat(data, "hours", 8.0)
+		oldTime := game.CurrentDate
+		if err = gm.claimAdvance(playerID, hours, maxRestHours); err == nil {
+			if err = game.Rest(hours); err != nil {
+				gm.releaseAdvance(playerID, hours)
+			}
+		}
+		if delta := game.CurrentDate.Sub(oldTime); delta > 0 {
+			followUps = append(followUps, func() { gm.advanceNetworkTime(playerID, delta) })
 		}
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "buy_stock":
-		offerID := getString(actionReq.Data, "offer_id", "")
-		shares := getInt(actionReq.Data, "shares")
+		offerID := getString(data, "offer_id", "")
+		shares := getInt(data, "shares")
 		err = game.BuyStock(offerID, shares)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "sell_stock":
-		symbol := getString(actionReq.Data, "symbol", "")
-		shares := getInt(actionReq.Data, "shares")
+		symbol := getString(data, "symbol", "")
+		shares := getInt(data, "shares")
 		err = game.SellStock(symbol, shares)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
+	case "short_stock":
+		offerID := getString(data, "offer_id", "")
+		shares := getInt(data, "shares")
+		err = game.ShortSell(offerID, shares)
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "cover_short":
+		symbol := getString(data, "symbol", "")
+		shares := getInt(data, "shares")
+		err = game.CoverShort(symbol, shares)
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
 	case "buy_crypto":
-		symbol := getString(actionReq.Data, "symbol", "")
-		amount := getFloat(actionReq.Data, "amount", 0.0)
+		symbol := getString(data, "symbol", "")
+		amount := getFloat(data, "amount", 0.0)
 		err = game.BuyCrypto(symbol, amount)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "sell_crypto":
-		symbol := getString(actionReq.Data, "symbol", "")
-		amount := getFloat(actionReq.Data, "amount", 0.0)
+		symbol := getString(data, "symbol", "")
+		amount := getFloat(data, "amount", 0.0)
 		err = game.SellCrypto(symbol, amount)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "buy_item":
-		itemID := getString(actionReq.Data, "item_id", "")
-		price := getFloat(actionReq.Data, "price", 0.0)
+		itemID := getString(data, "item_id", "")
+		price := getFloat(data, "price", 0.0)
 		err = game.BuyItem(itemID, price)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "sell_item":
-		itemID := getString(actionReq.Data, "item_id", "")
+		itemID := getString(data, "item_id", "")
 		err = game.SellItem(itemID)
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
+	case "use_item":
+		itemID := getString(data, "item_id", "")
+		err = game.UseItem(itemID)
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
 	case "accept_offer":
-		offerID := getString(actionReq.Data, "offer_id", "")
+		offerID := getString(data, "offer_id", "")
 		
 		// Find the offer before accepting to check if it's player-created
+		// (copy it, since AcceptOffer removes it from ActiveOffers)
 		var offer *Offer
-		for i, o := range game.ActiveOffers {
+		for _, o := range game.ActiveOffers {
 			if o.ID == offerID {
-				offer = &game.ActiveOffers[i]
+				offerCopy := o
+				offer = &offerCopy
 				break
 			}
 		}
 		
-		err = game.AcceptOffer(offerID)
-		if err == nil && offer != nil && offer.CreatedBy != "" && offer.CreatedBy != playerID {
-			// Transfer money to the creator
-			gm.mu.Lock()
-			if creator, exists := gm.games[offer.CreatedBy]; exists {
-				// Transfer money to creator
-				creator.Money += offer.Price
-				creator.addEvent("offer_sold", fmt.Sprintf("Sold %s to %s for €%.2f", offer.Title, playerID, offer.Price), offer.Price)
-				
-				// If it's a recurring offer, creator should also get an agreement
-				if offer.IsRecurring {
-					recurrenceType := offer.RecurrenceType
-					if recurrenceType == "" {
-						recurrenceType = "monthly"
-					}
-					
-					// Create reciprocal agreement for creator (they provide the service)
-					// The creator gets money periodically (positive MoneyChange)
-					creatorAgreement := Agreement{
-						ID:              generateID(),
-						Title:           fmt.Sprintf("Providing %s to %s", offer.Title, playerID),
-						Description:     fmt.Sprintf("You are providing %s to %s. You receive €%.2f per %s.", offer.Description, playerID, offer.Price, recurrenceType),
-						RecurrenceType:  recurrenceType,
-						StartedAt:       creator.CurrentDate,
-						LastProcessedAt: creator.CurrentDate,
-						HealthChange:    0, // Creator doesn't lose health/energy from providing service
-						EnergyChange:    0,
-						ReputationChange: 0,
-						MoneyChange:     offer.Price, // Creator receives money periodically
-						IsTrickery:      offer.IsTrickery,
-						Reason:          fmt.Sprintf("Reciprocal agreement from selling %s", offer.Title),
-						IsReciprocal:    true,  // Mark as reciprocal
-						OtherPartyID:    playerID, // Track who the buyer is
-						OriginalPrice:   offer.Price, // Store original price for penalty calculation
-					}
-					creator.Agreements = append(creator.Agreements, creatorAgreement)
-					creator.addEvent("agreement_started", fmt.Sprintf("Started providing %s to %s (€%.2f per %s)", offer.Title, playerID, offer.Price, recurrenceType), 0)
-					log.Printf("[ACCEPT_OFFER] Created reciprocal agreement for creator %s: ID=%s, Title=%s, OtherParty=%s", 
-						offer.CreatedBy, creatorAgreement.ID, creatorAgreement.Title, playerID)
-				} else {
-					// For one-time offers, creator just gets the money (already done above)
-					log.Printf("[ACCEPT_OFFER] One-time offer accepted, creator %s received €%.2f", offer.CreatedBy, offer.Price)
+		// A player-created offer is sold at most once, and not after its creator withdrew it
+		playerCreated := offer != nil && offer.CreatedBy != "" && offer.CreatedBy != playerID
+		if playerCreated {
+			if how, ok := gm.claimOfferClose(offerID, "accepted"); !ok {
+				err = &GameError{Message: "This offer was just " + how + " and is no longer available"}
+			}
+		}
+		if err == nil {
+			err = game.AcceptOffer(offerID)
+			if err != nil && playerCreated {
+				gm.releaseOfferClose(offerID)
+			}
+		}
+		if err == nil && offer != nil {
+			followUps = append(followUps, func() {
+				if playerCreated {
+					// Transfer money to the creator
+					gm.settleOfferSale(playerID, *offer)
 				}
-				
-				// Notify creator via WebSocket if connected
-				gm.wsConnectionsMu.RLock()
-				if creatorWs, exists := gm.wsConnections[offer.CreatedBy]; exists {
-					creatorWs.sendGameState(creator)
-					log.Printf("[ACCEPT_OFFER] Notified creator %s via WebSocket", offer.CreatedBy)
+				// Remove offer from all players in the network
+				gm.removeOfferFromNetwork(playerID, offerID)
+				if playerCreated {
+					gm.releaseOfferClose(offerID)
 				}
-				gm.wsConnectionsMu.RUnlock()
-			}
-			gm.mu.Unlock()
-			
-			// Remove offer from all players in the network
-			gm.removeOfferFromNetwork(playerID, offerID)
-		} else if err == nil && offer != nil {
-			// Remove offer from all players in the network even if not player-created
-			gm.removeOfferFromNetwork(playerID, offerID)
+				gm.sendTrickeryLesson(playerID, *offer)
+			})
+		}
+		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		
+	case "withdraw_offer":
+		offerID := getString(data, "offer_id", "")
+		if err = gm.withdrawOffer(game, offerID); err == nil {
+			followUps = append(followUps, func() {
+				gm.removeOfferFromNetwork(playerID, offerID)
+				gm.releaseOfferClose(offerID)
+				gm.notifyPlayers(gm.getNetworkPlayers(playerID)...)
+			})
 		}
 		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
 		
 	case "next_day":
-		game.NextDay()
-		result = map[string]interface{}{"success": true, "message": "Day advanced"}
+		if err = gm.claimAdvance(playerID, 24, 24); err == nil {
+			oldTime := game.CurrentDate
+			digest := game.NextDay()
+			followUps = append(followUps, func() { gm.sendDailyDigest(playerID, *digest) })
+			if delta := game.CurrentDate.Sub(oldTime); delta > 0 {
+				followUps = append(followUps, func() { gm.advanceNetworkTime(playerID, delta) })
+			}
+			result = map[string]interface{}{"success": true, "message": "Day advanced", "digest": digest}
+		} else {
+			result = map[string]interface{}{"success": false, "message": getMessage(err)}
+		}
 		
 	default:
 		result = map[string]interface{}{"success": false, "message": "Unknown action"}
 	}
-	
-	// Invalidate cache for this player
-	gm.stateCacheMu.Lock()
-	delete(gm.stateCache, playerID)
-	gm.stateCacheMu.Unlock()
+	return result, followUps
+}
+
+// finishActions checks for achievements and victory after one or more actions and returns the
+// notifications to send once the game's lock is released. The caller must hold the write lock.
+func (gm *GameManager) finishActions(playerID string, game *GameState, wasWon bool) []func() {
+	var followUps []func()
+	game.checkAchievements()
+	if unlocked := game.takeNewAchievements(); len(unlocked) > 0 {
+		followUps = append(followUps, func() { gm.sendAchievements(playerID, unlocked) })
+	}
+	if news := game.takeNews(); len(news) > 0 {
+		followUps = append(followUps, func() { gm.sendNews(playerID, news) })
+	}
+	if charges := game.takeUnpaidCharges(); len(charges) > 0 {
+		followUps = append(followUps, func() {
+			for _, charge := range charges {
+				gm.sendToPlayer(playerID, map[string]interface{}{"type": "agreement_unaffordable", "charge": charge})
+			}
+		})
+	}
+	if game.GameWon && !wasWon {
+		reason := game.GameWonReason
+		followUps = append(followUps, func() {
+			gm.sendToPlayer(playerID, map[string]interface{}{"type": "game_won", "message": reason})
+		})
+	}
+	return followUps
+}
+
+// writeActionResponse writes an action result together with the current game state,
+// gzip-compressed when the client accepts it and the response is large enough
+func (gm *GameManager) writeActionResponse(w http.ResponseWriter, r *http.Request, entry *gameEntry, result map[string]interface{}) {
+	game := entry.game
 	
 	// Encode result with optimized JSON
 	buf := gm.jsonEncoderPool.Get().(*bytes.Buffer)
@@ -1248,15 +1977,16 @@ func (gm *GameManager) HandleAction(w http.ResponseWriter, r *http.Request) {
 	}()
 	
 	// Limit history size
+	entry.mu.RLock()
 	limitedGame := *game
-	if len(limitedGame.History) > 50 {
-		limitedGame.History = limitedGame.History[len(limitedGame.History)-50:]
-	}
+	limitedGame.History = game.History.Tail(maxHistoryInState)
 	result["game_state"] = &limitedGame
 	
 	encoder := json.NewEncoder(buf)
 	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(result); err != nil {
+	err := encoder.Encode(result)
+	entry.mu.RUnlock()
+	if err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
@@ -1287,34 +2017,39 @@ func (gm *GameManager) HandleGenerateOffer(w http.ResponseWriter, r *http.Reques
 	
 	offerType := r.URL.Query().Get("type") // "trickery" or "good"
 	
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	// Work on a snapshot so the game is not locked during the AI call
+	game, exists := gm.snapshotGame(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 	
 	var offer *Offer
+	var err error
 	if offerType == "trickery" {
-		offer, err = gm.ai.GenerateTrickeryOffer(game)
+		offer, err = gm.ai.GenerateTrickeryOffer(r.Context(), game)
 	} else {
-		offer, err = gm.ai.GenerateGoodOffer(game)
+		offer, err = gm.ai.GenerateGoodOffer(r.Context(), game)
 	}
 	
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	game.checkOfferValue(offer)
 	
 	// Add offer to game
-	gm.mu.Lock()
-	game.ActiveOffers = append(game.ActiveOffers, *offer)
-	gm.mu.Unlock()
+	gm.withGame(playerID, func(game *GameState) {
+		game.ActiveOffers = append(game.ActiveOffers, *offer)
+	})
 	
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"offer":   offer,
-		"game_state": game,
+	gm.readGame(playerID, func(game *GameState) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"offer":   offer,
+			"game_state": game,
+		})
 	})
 }
 
@@ -1330,28 +2065,32 @@ func (gm *GameManager) HandleGenerateJobOffer(w http.ResponseWriter, r *http.Req
 		offerType = "good"
 	}
 	
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	// Work on a snapshot so the game is not locked during the AI call
+	game, exists := gm.snapshotGame(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 	
-	jobOffer, err := gm.ai.GenerateJobOffer(game, offerType)
+	jobOffer, err := gm.ai.GenerateJobOffer(r.Context(), game, offerType)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	jobOffer.ExpiresAt = game.difficulty().scaleOfferExpiry(game.CurrentDate, jobOffer.ExpiresAt)
 	
 	// Add job offer to game
-	gm.mu.Lock()
-	game.JobOffers = append(game.JobOffers, *jobOffer)
-	gm.mu.Unlock()
+	gm.withGame(playerID, func(game *GameState) {
+		game.JobOffers = append(game.JobOffers, *jobOffer)
+	})
 	
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"job_offer": jobOffer,
-		"game_state": game,
+	gm.readGame(playerID, func(game *GameState) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"job_offer": jobOffer,
+			"game_state": game,
+		})
 	})
 }
 
@@ -1362,6 +2101,10 @@ type N8NWebhookResponse struct {
 	JobOffer     *JobOffer              `json:"job_offer,omitempty"`
 	ApartmentOffer *ApartmentOffer      `json:"apartment_offer,omitempty"`
 	StockOffer   *StockOffer            `json:"stock_offer,omitempty"`
+	// Negotiation: the price the other side proposes (salary for jobs, rent for apartments),
+	// either outright or as a change to the current one; counter_price wins if both are set
+	CounterPrice *float64               `json:"counter_price,omitempty"`
+	PriceDelta   *float64               `json:"price_delta,omitempty"`
 }
 
 // callN8NWebhook calls the n8n webhook with offer details and message
@@ -1419,6 +2162,9 @@ func (gm *GameManager) callN8NWebhook(offerType string, offerID string, offerDat
 		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	if config.N8N.Secret != "" {
+		req.Header.Set("X-Signature", signN8NPayload(config.N8N.Secret, payloadJSON))
+	}
 	
 	// Make request with timeout
 	client := &http.Client{
@@ -1450,9 +2196,30 @@ func (gm *GameManager) callN8NWebhook(offerType string, offerID string, offerDat
 		webhookResp.Message = &messageStr
 	}
 	
+	// With a shared secret, offer updates are only trusted from a signed response; the message is still shown
+	if config.N8N.Secret != "" && !hmac.Equal([]byte(resp.Header.Get("X-Signature")), []byte(signN8NPayload(config.N8N.Secret, body))) {
+		if webhookResp.Offer != nil || webhookResp.JobOffer != nil || webhookResp.ApartmentOffer != nil || webhookResp.StockOffer != nil || webhookResp.CounterPrice != nil || webhookResp.PriceDelta != nil {
+			logWarnf("[N8N] Ignoring offer update for %s: response signature missing or invalid", offerID)
+		}
+		webhookResp.Offer = nil
+		webhookResp.JobOffer = nil
+		webhookResp.ApartmentOffer = nil
+		webhookResp.StockOffer = nil
+		webhookResp.CounterPrice = nil
+		webhookResp.PriceDelta = nil
+	}
+	
 	return &webhookResp, nil
 }
 
+// signN8NPayload returns the X-Signature value for a webhook body: "sha256=" followed by the
+// lowercase hex HMAC-SHA256 of the raw body bytes exactly as sent, keyed with the shared secret
+func signN8NPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
 // HandleOfferMessage handles sending a message to an offer via n8n webhook
 // Supports all offer types: job, apartment, stock, and other offers
 func (gm *GameManager) HandleOfferMessage(w http.ResponseWriter, r *http.Request) {
@@ -1469,6 +2236,9 @@ func (gm *GameManager) HandleOfferMessage(w http.ResponseWriter, r *http.Request
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&requestData); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -1484,167 +2254,51 @@ func (gm *GameManager) HandleOfferMessage(w http.ResponseWriter, r *http.Request
 	}
 	
 	// Get game
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 	
-	// Search for offer in all offer types
-	var foundOfferType string
-	var foundOfferIndex int = -1
-	var foundOfferData interface{}
-	
-	gm.mu.Lock()
-	// Search in ActiveOffers (other offers)
-	for i := range game.ActiveOffers {
-		if game.ActiveOffers[i].ID == requestData.OfferID {
-			foundOfferType = "other"
-			foundOfferIndex = i
-			foundOfferData = &game.ActiveOffers[i]
-			break
-		}
-	}
-	
-	// Search in JobOffers
-	if foundOfferData == nil {
-		for i := range game.JobOffers {
-			if game.JobOffers[i].ID == requestData.OfferID {
-				foundOfferType = "job"
-				foundOfferIndex = i
-				foundOfferData = &game.JobOffers[i]
-				break
-			}
-		}
-	}
-	
-	// Search in ApartmentOffers
-	if foundOfferData == nil {
-		for i := range game.ApartmentOffers {
-			if game.ApartmentOffers[i].ID == requestData.OfferID {
-				foundOfferType = "apartment"
-				foundOfferIndex = i
-				foundOfferData = &game.ApartmentOffers[i]
-				break
-			}
-		}
-	}
-	
-	// Search in StockOffers
-	if foundOfferData == nil {
-		for i := range game.StockOffers {
-			if game.StockOffers[i].ID == requestData.OfferID {
-				foundOfferType = "stock"
-				foundOfferIndex = i
-				foundOfferData = &game.StockOffers[i]
-				break
-			}
-		}
-	}
-	gm.mu.Unlock()
+	// Copy the offer so the webhook call can read it without holding the game lock
+	entry.mu.RLock()
+	foundOfferType, foundOfferData := entry.game.findMessageableOffer(requestData.OfferID)
+	negotiationClosed := foundOfferData != nil && entry.game.negotiationClosed(foundOfferType, requestData.OfferID)
+	entry.mu.RUnlock()
 	
 	if foundOfferData == nil {
 		http.Error(w, "Offer not found", http.StatusNotFound)
 		return
 	}
+	if negotiationClosed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("Negotiation is over: the %d rounds for this offer are used up", maxNegotiationRounds)})
+		return
+	}
 	
-	// Call n8n webhook
-	webhookResp, err := gm.callN8NWebhook(foundOfferType, requestData.OfferID, foundOfferData, requestData.Message, playerID)
-	if err != nil {
-		log.Printf("Error calling n8n webhook: %v", err)
-		http.Error(w, fmt.Sprintf("Failed to send message: %v", err), http.StatusInternalServerError)
-		return
-	}
-	
-	// Update offer based on response
-	gm.mu.Lock()
-	offerUpdated := false
-	
-	// Add message to offer's message history and update if needed
-	switch foundOfferType {
-	case "other":
-		if foundOfferIndex >= 0 && foundOfferIndex < len(game.ActiveOffers) {
-			if game.ActiveOffers[foundOfferIndex].Messages == nil {
-				game.ActiveOffers[foundOfferIndex].Messages = []string{}
-			}
-			game.ActiveOffers[foundOfferIndex].Messages = append(game.ActiveOffers[foundOfferIndex].Messages, requestData.Message)
-			
-			if webhookResp != nil && webhookResp.Message != nil {
-				game.ActiveOffers[foundOfferIndex].Messages = append(game.ActiveOffers[foundOfferIndex].Messages, *webhookResp.Message)
-			}
-			
-			if webhookResp != nil && webhookResp.Offer != nil {
-				updatedOffer := webhookResp.Offer
-				updatedOffer.ID = game.ActiveOffers[foundOfferIndex].ID
-				updatedOffer.Messages = game.ActiveOffers[foundOfferIndex].Messages
-				game.ActiveOffers[foundOfferIndex] = *updatedOffer
-				offerUpdated = true
-			}
-		}
-	case "job":
-		if foundOfferIndex >= 0 && foundOfferIndex < len(game.JobOffers) {
-			if game.JobOffers[foundOfferIndex].Messages == nil {
-				game.JobOffers[foundOfferIndex].Messages = []string{}
-			}
-			game.JobOffers[foundOfferIndex].Messages = append(game.JobOffers[foundOfferIndex].Messages, requestData.Message)
-			
-			if webhookResp != nil && webhookResp.Message != nil {
-				game.JobOffers[foundOfferIndex].Messages = append(game.JobOffers[foundOfferIndex].Messages, *webhookResp.Message)
-			}
-			
-			if webhookResp != nil && webhookResp.JobOffer != nil {
-				updatedOffer := webhookResp.JobOffer
-				updatedOffer.ID = game.JobOffers[foundOfferIndex].ID
-				updatedOffer.Messages = game.JobOffers[foundOfferIndex].Messages
-				game.JobOffers[foundOfferIndex] = *updatedOffer
-				offerUpdated = true
-			}
-		}
-	case "apartment":
-		if foundOfferIndex >= 0 && foundOfferIndex < len(game.ApartmentOffers) {
-			if game.ApartmentOffers[foundOfferIndex].Messages == nil {
-				game.ApartmentOffers[foundOfferIndex].Messages = []string{}
-			}
-			game.ApartmentOffers[foundOfferIndex].Messages = append(game.ApartmentOffers[foundOfferIndex].Messages, requestData.Message)
-			
-			if webhookResp != nil && webhookResp.Message != nil {
-				game.ApartmentOffers[foundOfferIndex].Messages = append(game.ApartmentOffers[foundOfferIndex].Messages, *webhookResp.Message)
-			}
-			
-			if webhookResp != nil && webhookResp.ApartmentOffer != nil {
-				updatedOffer := webhookResp.ApartmentOffer
-				updatedOffer.ID = game.ApartmentOffers[foundOfferIndex].ID
-				updatedOffer.Messages = game.ApartmentOffers[foundOfferIndex].Messages
-				game.ApartmentOffers[foundOfferIndex] = *updatedOffer
-				offerUpdated = true
-			}
-		}
-	case "stock":
-		if foundOfferIndex >= 0 && foundOfferIndex < len(game.StockOffers) {
-			if game.StockOffers[foundOfferIndex].Messages == nil {
-				game.StockOffers[foundOfferIndex].Messages = []string{}
-			}
-			game.StockOffers[foundOfferIndex].Messages = append(game.StockOffers[foundOfferIndex].Messages, requestData.Message)
-			
-			if webhookResp != nil && webhookResp.Message != nil {
-				game.StockOffers[foundOfferIndex].Messages = append(game.StockOffers[foundOfferIndex].Messages, *webhookResp.Message)
-			}
-			
-			if webhookResp != nil && webhookResp.StockOffer != nil {
-				updatedOffer := webhookResp.StockOffer
-				updatedOffer.ID = game.StockOffers[foundOfferIndex].ID
-				updatedOffer.Messages = game.StockOffers[foundOfferIndex].Messages
-				game.StockOffers[foundOfferIndex] = *updatedOffer
-				offerUpdated = true
-			}
-		}
-	}
-	gm.mu.Unlock()
-	
-	// Invalidate cache
-	gm.stateCacheMu.Lock()
-	delete(gm.stateCache, playerID)
-	gm.stateCacheMu.Unlock()
+	// Call n8n webhook
+	webhookResp, err := gm.callN8NWebhook(foundOfferType, requestData.OfferID, foundOfferData, requestData.Message, playerID)
+	if err != nil {
+		logErrorf("Error calling n8n webhook: %v", err)
+		http.Error(w, fmt.Sprintf("Failed to send message: %v", err), http.StatusInternalServerError)
+		return
+	}
+	
+	// Update the offer, unless it was accepted or expired during the webhook call
+	entry.mu.Lock()
+	game := entry.game
+	found, offerUpdated := game.applyOfferMessage(foundOfferType, requestData.OfferID, requestData.Message, webhookResp)
+	if !found {
+		entry.mu.Unlock()
+		http.Error(w, "Offer is no longer available", http.StatusGone)
+		return
+	}
+	round := game.negotiate(foundOfferType, requestData.OfferID, requestData.Message, webhookResp)
+	thread := gm.recordOfferMessage(requestData.OfferID, playerID, requestData.Message, webhookResp)
+	gm.invalidateCache(playerID)
+	entry.mu.Unlock()
+	gm.syncOfferMessages(playerID, requestData.OfferID, thread)
 	
 	// Return response
 	response := map[string]interface{}{
@@ -1652,6 +2306,9 @@ func (gm *GameManager) HandleOfferMessage(w http.ResponseWriter, r *http.Request
 		"message": "Message sent successfully",
 		"offer_type": foundOfferType,
 	}
+	if round != nil {
+		response["negotiation_round"] = round
+	}
 	
 	if webhookResp != nil {
 		if webhookResp.Message != nil {
@@ -1673,46 +2330,30 @@ func (gm *GameManager) HandleChat(w http.ResponseWriter, r *http.Request) {
 		playerID = "default"
 	}
 	
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+	// Work on a snapshot so the game is not locked during the AI calls
+	game, exists := gm.snapshotGame(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
 		return
 	}
 	
 	var chatReq ChatMessage
 	if err := json.NewDecoder(r.Body).Decode(&chatReq); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 	
 	// First, check if the message is trying to create an offer/agreement/item
-	creationResponse, err := gm.ai.ParseChatForOfferCreation(game, chatReq.Message)
+	creationResponse, err := gm.ai.ParseChatForOfferCreation(r.Context(), game, chatReq.Message)
 	if err == nil && creationResponse != nil && creationResponse.Created {
 		// Player wants to create something
-		gm.mu.Lock()
-		
+		var shareErr error
 		if creationResponse.Offer != nil {
 			// Add offer to game and share with network
-			game.ActiveOffers = append(game.ActiveOffers, *creationResponse.Offer)
-			// Share with network
-			networkPlayers := gm.getNetworkPlayers(playerID)
-			for _, pid := range networkPlayers {
-				if pid != playerID {
-					if networkGame, exists := gm.games[pid]; exists {
-						networkGame.ActiveOffers = append(networkGame.ActiveOffers, *creationResponse.Offer)
-					}
-				}
-			}
-			// Notify all network players via WebSocket
-			gm.wsConnectionsMu.RLock()
-			for _, pid := range networkPlayers {
-				if wsConn, exists := gm.wsConnections[pid]; exists {
-					if g, exists := gm.games[pid]; exists {
-						wsConn.sendGameState(g)
-					}
-				}
-			}
-			gm.wsConnectionsMu.RUnlock()
+			shareErr = gm.sharePlayerOffer(playerID, *creationResponse.Offer)
 			creationResponse.Message = fmt.Sprintf("✅ Created offer: %s (€%.2f). It's now available to other players in your network!", creationResponse.Offer.Title, creationResponse.Offer.Price)
 		} else if creationResponse.Agreement != nil {
 			// For agreements, we create an offer that becomes an agreement when accepted
@@ -1741,33 +2382,24 @@ func (gm *GameManager) HandleChat(w http.ResponseWriter, r *http.Request) {
 				MoneyChange:     creationResponse.Agreement.MoneyChange,
 				IsRecurring:     true,
 				RecurrenceType:  creationResponse.Agreement.RecurrenceType,
+				Category:        creationResponse.Agreement.Category,
 				CreatedBy:       playerID,
 			}
-			game.ActiveOffers = append(game.ActiveOffers, *offer)
-			// Share with network
-			networkPlayers := gm.getNetworkPlayers(playerID)
-			for _, pid := range networkPlayers {
-				if pid != playerID {
-					if networkGame, exists := gm.games[pid]; exists {
-						networkGame.ActiveOffers = append(networkGame.ActiveOffers, *offer)
-					}
-				}
-			}
-			// Notify all network players via WebSocket
-			gm.wsConnectionsMu.RLock()
-			for _, pid := range networkPlayers {
-				if wsConn, exists := gm.wsConnections[pid]; exists {
-					if g, exists := gm.games[pid]; exists {
-						wsConn.sendGameState(g)
-					}
-				}
-			}
-			gm.wsConnectionsMu.RUnlock()
+			// Add offer to game and share with network
+			shareErr = gm.sharePlayerOffer(playerID, *offer)
 			creationResponse.Offer = offer
 			creationResponse.Message = fmt.Sprintf("✅ Created agreement offer: %s (€%.2f/%s). It's now available to other players in your network!", offer.Title, offer.Price, creationResponse.Agreement.RecurrenceType)
 		}
 		
-		gm.mu.Unlock()
+		if shareErr != nil {
+			// Over the creation limits: tell the player instead of creating it
+			creationResponse.Created = false
+			creationResponse.Offer = nil
+			creationResponse.Message = getMessage(shareErr)
+		} else {
+			// Notify all network players via WebSocket
+			gm.notifyPlayers(gm.getNetworkPlayers(playerID)...)
+		}
 		
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(creationResponse)
@@ -1775,7 +2407,7 @@ func (gm *GameManager) HandleChat(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Normal chat flow
-	response, err := gm.ai.ChatWithGuide(game, chatReq.Message, chatReq.Context)
+	response, err := gm.ai.ChatWithGuide(r.Context(), game, chatReq.Message, chatReq.Context)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -1784,7 +2416,7 @@ func (gm *GameManager) HandleChat(w http.ResponseWriter, r *http.Request) {
 	// Add hint about creating offers if it's a general question
 	if strings.Contains(strings.ToLower(chatReq.Message), "how") || strings.Contains(strings.ToLower(chatReq.Message), "can i") || strings.Contains(strings.ToLower(chatReq.Message), "create") {
 		if !strings.Contains(response.Message, "create") && !strings.Contains(response.Message, "offer") {
-			response.Message += "\n\n💡 Tip: You can create offers, agreements, or sell items to other players by describing what you want to offer in the chat! For example: 'I want to sell my laptop for €500' or 'I'm offering a monthly subscription service for €50/month'."
+			response.Message += localize(game.Language, createOfferTip)
 		}
 	}
 	
@@ -1804,10 +2436,10 @@ func (gm *GameManager) HandleGetStockSymbols(w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(stockSymbols)
 }
 
-// HandleGetCryptoSymbols returns available crypto symbols
+// HandleGetCryptoSymbols returns the crypto catalog: each coin's symbol, name, base price and volatility
 func (gm *GameManager) HandleGetCryptoSymbols(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cryptoSymbols)
+	json.NewEncoder(w).Encode(GetConfig().Crypto.Coins)
 }
 
 // HandleCreateWithInvite creates a new game with an invite code
@@ -1815,9 +2447,13 @@ func (gm *GameManager) HandleCreateWithInvite(w http.ResponseWriter, r *http.Req
 	var req struct {
 		PlayerID   string `json:"player_id"`
 		InviteCode string `json:"invite_code"`
+		Language   string `json:"language"`
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request"})
@@ -1831,10 +2467,62 @@ func (gm *GameManager) HandleCreateWithInvite(w http.ResponseWriter, r *http.Req
 		return
 	}
 	
+	if sessionID, ok := sessionPlayerID(r); ok && sessionID != req.PlayerID {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]string{"error": "player_id does not match session"})
+		return
+	}
+	
 	// Convert invite code to uppercase for case-insensitive lookup
 	req.InviteCode = strings.ToUpper(req.InviteCode)
 	
-	game, err := gm.CreateGameWithInvite(req.PlayerID, req.InviteCode)
+	game, err := gm.CreateGameWithInvite(req.PlayerID, req.InviteCode, req.Language)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	gm.readGame(game.PlayerID, func(game *GameState) {
+		json.NewEncoder(w).Encode(game)
+	})
+}
+
+// HandleListInvites lists the player's usable invite codes with their remaining uses
+func (gm *GameManager) HandleListInvites(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	if _, exists := gm.getEntry(playerID); !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"invites": gm.listInvites(playerID)})
+}
+
+// HandleCreateInvite mints a new invite code for the player; max_uses defaults to 1 (single use)
+func (gm *GameManager) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	var req struct {
+		MaxUses int `json:"max_uses"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			if requestTooLarge(w, err) {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request"})
+			return
+		}
+	}
+	
+	code, err := gm.GenerateInvite(playerID, req.MaxUses)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -1843,7 +2531,7 @@ func (gm *GameManager) HandleCreateWithInvite(w http.ResponseWriter, r *http.Req
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(game)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": code, "invites": gm.listInvites(playerID)})
 }
 
 // HandleEncrypt encrypts game state data (optimized with goroutine for large data)
@@ -1853,6 +2541,9 @@ func (gm *GameManager) HandleEncrypt(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
@@ -1905,6 +2596,9 @@ func (gm *GameManager) HandleDecrypt(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
@@ -1926,101 +2620,175 @@ func (gm *GameManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "player_id required", http.StatusBadRequest)
 		return
 	}
+	// A spectator watches an existing game; it must not start one
+	spectator := isSpectator(r)
+	if _, exists := gm.getEntry(playerID); spectator && !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
 
-	log.Printf("[WS_OPEN] Opening WebSocket connection for player %s", playerID)
+	logDebugf("[WS_OPEN] Opening WebSocket connection for player %s (spectator: %v)", playerID, spectator)
 
 	// Upgrade connection to WebSocket
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("[WS_ERROR] WebSocket upgrade error for player %s: %v", playerID, err)
+		logErrorf("[WS_ERROR] WebSocket upgrade error for player %s: %v", playerID, err)
 		return
 	}
 
-	log.Printf("[WS_OPEN] WebSocket connection upgraded for player %s", playerID)
+	logInfof("[WS_OPEN] WebSocket connection upgraded for player %s", playerID)
 
 	// Create connection handler
 	sendChan := make(chan []byte, 256)
-	log.Printf("[CHAN_OPEN] Created send channel for player %s (capacity: 256)", playerID)
+	logDebugf("[CHAN_OPEN] Created send channel for player %s (capacity: 256)", playerID)
 	
 	wsConn := &wsConnection{
 		conn:     conn,
 		playerID: playerID,
 		send:     sendChan,
 		manager:  gm,
+		done:     make(chan struct{}),
+		stateReady: make(chan struct{}, 1),
+		spectator:  spectator,
+	}
+	wsConn.ctx, wsConn.cancel = context.WithCancel(gm.ctx)
+	if expiresAt, ok := sessionExpiry(r); ok {
+		wsConn.sessionTimer = time.NewTimer(time.Until(expiresAt))
 	}
 
 	// Register connection
-	log.Printf("[LOCK_ACQUIRE] Acquiring wsConnectionsMu write lock for player %s", playerID)
+	logDebugf("[LOCK_ACQUIRE] Acquiring wsConnectionsMu write lock for player %s", playerID)
 	gm.wsConnectionsMu.Lock()
-	// Close existing connection if any
-	if oldConn, exists := gm.wsConnections[playerID]; exists {
-		log.Printf("[WS_CLOSE] Closing existing WebSocket connection for player %s", playerID)
-		oldConn.close()
+	if spectator {
+		// Spectators sit beside the player's own connection, so neither evicts the other
+		gm.addSpectatorUnlocked(wsConn)
+	} else {
+		// Close existing connection if any
+		if oldConn, exists := gm.wsConnections[playerID]; exists {
+			logDebugf("[WS_CLOSE] Closing existing WebSocket connection for player %s", playerID)
+			oldConn.close()
+		}
+		gm.wsConnections[playerID] = wsConn
+		metricsWebSocketConnections.Set(float64(len(gm.wsConnections)))
 	}
-	gm.wsConnections[playerID] = wsConn
-	log.Printf("[LOCK_RELEASE] Releasing wsConnectionsMu write lock for player %s", playerID)
+	logDebugf("[LOCK_RELEASE] Releasing wsConnectionsMu write lock for player %s", playerID)
 	gm.wsConnectionsMu.Unlock()
 
 	// Start goroutines
-	log.Printf("[GOROUTINE_START] Starting writePump goroutine for player %s", playerID)
+	logDebugf("[GOROUTINE_START] Starting writePump goroutine for player %s", playerID)
 	go wsConn.writePump()
-	log.Printf("[GOROUTINE_START] Starting readPump goroutine for player %s", playerID)
+	logDebugf("[GOROUTINE_START] Starting readPump goroutine for player %s", playerID)
 	go wsConn.readPump()
 
-	// Send initial game state
-	game := gm.GetOrCreateGame(playerID)
-	wsConn.sendGameState(game)
+	if spectator {
+		gm.readGame(playerID, wsConn.sendGameState)
+		return
+	}
+
+	// Send initial game state. A returning player's network may have been skipped by the offer
+	// generators while nobody was connected, so generate for it now (new games do this themselves).
+	_, existed := gm.getEntry(playerID)
+	entry := gm.GetOrCreateGame(playerID, r.URL.Query().Get("lang"), r.URL.Query().Get("scenario"))
+	entry.mu.RLock()
+	wsConn.sendGameState(entry.game)
+	entry.mu.RUnlock()
+	if existed {
+		gm.generateOffersSoon()
+	}
 }
 
-// sendGameState sends game state to the WebSocket connection
+// sendGameState sends game state to the WebSocket connection; the caller must hold the game's read lock
 func (c *wsConnection) sendGameState(game *GameState) {
-	log.Printf("[SEND_STATE_START] Starting sendGameState for player %s", c.playerID)
+	logDebugf("[SEND_STATE_START] Starting sendGameState for player %s", c.playerID)
 	// Limit history for performance
 	limitedGame := *game
-	if len(limitedGame.History) > 50 {
-		limitedGame.History = limitedGame.History[len(limitedGame.History)-50:]
-	}
+	limitedGame.History = game.History.Tail(maxHistoryInState)
 
 	msg := map[string]interface{}{
 		"type":       "state",
 		"game_state": &limitedGame,
 	}
 
-	log.Printf("[SEND_STATE_MARSHAL] Marshaling game state for player %s", c.playerID)
+	logDebugf("[SEND_STATE_MARSHAL] Marshaling game state for player %s", c.playerID)
 	data, err := json.Marshal(msg)
 	if err != nil {
-		log.Printf("[SEND_STATE_ERROR] Error marshaling game state for player %s: %v", c.playerID, err)
+		logErrorf("[SEND_STATE_ERROR] Error marshaling game state for player %s: %v", c.playerID, err)
 		return
 	}
-	log.Printf("[SEND_STATE_MARSHALED] Marshaled game state for player %s (size: %d bytes)", c.playerID, len(data))
+	logDebugf("[SEND_STATE_MARSHALED] Marshaled game state for player %s (size: %d bytes)", c.playerID, len(data))
+
+	c.queueState(data)
+	logDebugf("[SEND_STATE_END] Finished sendGameState for player %s", c.playerID)
+}
 
+// queueState hands a marshaled state message to the writePump. Only the latest state matters:
+// it replaces one that the writePump hasn't sent yet.
+func (c *wsConnection) queueState(data []byte) {
+	c.stateMu.Lock()
+	replaced := c.pendingState != nil
+	c.pendingState = data
+	c.stateMu.Unlock()
+	if replaced {
+		logDebugf("[SEND_STATE_COALESCED] Replaced unsent game state for player %s", c.playerID)
+	}
 	select {
-	case c.send <- data:
-		logChannelOp("SEND", c.playerID+"_state", len(c.send), cap(c.send))
-		log.Printf("[SEND_STATE_SUCCESS] Successfully queued game state for player %s", c.playerID)
+	case c.stateReady <- struct{}{}:
 	default:
-		log.Printf("[SEND_STATE_FULL] Channel full for player %s, dropping game state", c.playerID)
-		// Channel full, connection might be slow
 	}
-	log.Printf("[SEND_STATE_END] Finished sendGameState for player %s", c.playerID)
+}
+
+// takePendingState returns the state waiting to be sent, if any, and clears it
+func (c *wsConnection) takePendingState() []byte {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	data := c.pendingState
+	c.pendingState = nil
+	return data
+}
+
+// writeFrame sends one message as its own text frame, and copies it to the player's spectators
+// when this is the player's own connection
+func (c *wsConnection) writeFrame(message []byte) error {
+	if !c.spectator {
+		c.manager.mirrorToSpectators(c.playerID, message)
+	}
+	// Small frames aren't worth the CPU; this is a no-op if compression wasn't negotiated
+	c.conn.EnableWriteCompression(len(message) > wsCompressionThreshold)
+	return c.conn.WriteMessage(websocket.TextMessage, message)
+}
+
+// writePendingState sends the pending state, if any, so it goes out before messages queued after it
+func (c *wsConnection) writePendingState() error {
+	if data := c.takePendingState(); data != nil {
+		return c.writeFrame(data)
+	}
+	return nil
 }
 
 // readPump reads messages from the WebSocket connection
 func (c *wsConnection) readPump() {
-	log.Printf("[GOROUTINE_START] readPump started for player %s", c.playerID)
+	logDebugf("[GOROUTINE_START] readPump started for player %s", c.playerID)
 	defer func() {
-		log.Printf("[GOROUTINE_END] readPump ending for player %s", c.playerID)
-		log.Printf("[LOCK_ACQUIRE] Acquiring wsConnectionsMu write lock to unregister player %s", c.playerID)
+		logDebugf("[GOROUTINE_END] readPump ending for player %s", c.playerID)
+		c.cancel()
+		logDebugf("[LOCK_ACQUIRE] Acquiring wsConnectionsMu write lock to unregister player %s", c.playerID)
 		c.manager.wsConnectionsMu.Lock()
-		delete(c.manager.wsConnections, c.playerID)
-		log.Printf("[WS_UNREGISTER] Unregistered WebSocket connection for player %s", c.playerID)
-		log.Printf("[LOCK_RELEASE] Releasing wsConnectionsMu write lock for player %s", c.playerID)
+		if c.spectator {
+			c.manager.removeSpectatorUnlocked(c)
+		} else if c.manager.wsConnections[c.playerID] == c {
+			delete(c.manager.wsConnections, c.playerID)
+		}
+		metricsWebSocketConnections.Set(float64(len(c.manager.wsConnections)))
+		logInfof("[WS_UNREGISTER] Unregistered WebSocket connection for player %s", c.playerID)
+		logDebugf("[LOCK_RELEASE] Releasing wsConnectionsMu write lock for player %s", c.playerID)
 		c.manager.wsConnectionsMu.Unlock()
-		log.Printf("[WS_CLOSE] Closing WebSocket connection (readPump) for player %s", c.playerID)
+		logDebugf("[WS_CLOSE] Closing WebSocket connection (readPump) for player %s", c.playerID)
 		c.conn.Close()
-		log.Printf("[WS_CLOSED] WebSocket connection closed (readPump) for player %s", c.playerID)
+		logDebugf("[WS_CLOSED] WebSocket connection closed (readPump) for player %s", c.playerID)
 	}()
 
+	// A larger message fails the read and closes the connection with 1009 (message too big)
+	c.conn.SetReadLimit(GetConfig().Server.MaxWebSocketMessageBytes)
 	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -2030,8 +2798,10 @@ func (c *wsConnection) readPump() {
 	for {
 		_, message, err := c.conn.ReadMessage()
 		if err != nil {
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("WebSocket error: %v", err)
+			if errors.Is(err, websocket.ErrReadLimit) {
+				logWarnf("[WS] Closing connection of player %s: message over %d bytes", c.playerID, GetConfig().Server.MaxWebSocketMessageBytes)
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				logWarnf("WebSocket error: %v", err)
 			}
 			break
 		}
@@ -2046,398 +2816,164 @@ func (c *wsConnection) readPump() {
 		if !ok {
 			continue
 		}
+		if c.spectator {
+			c.sendError("Spectators can only watch this game")
+			continue
+		}
 
-		// Process action
-		c.manager.processWebSocketAction(c.playerID, action, actionMsg["data"], c)
+		// Process action; a repeated idempotency key is answered without running it again
+		idempotencyKey, _ := actionMsg["idempotency_key"].(string)
+		c.manager.processWebSocketAction(c.playerID, action, actionMsg["data"], idempotencyKey, c)
 	}
 }
 
 // writePump writes messages to the WebSocket connection
 func (c *wsConnection) writePump() {
-	log.Printf("[GOROUTINE_START] writePump started for player %s", c.playerID)
+	logDebugf("[GOROUTINE_START] writePump started for player %s", c.playerID)
 	ticker := time.NewTicker(54 * time.Second)
-	defer func() {
-		log.Printf("[GOROUTINE_END] writePump ending for player %s", c.playerID)
-		ticker.Stop()
-		log.Printf("[WS_CLOSE] Closing WebSocket connection (writePump) for player %s", c.playerID)
-		c.conn.Close()
-		log.Printf("[WS_CLOSED] WebSocket connection closed (writePump) for player %s", c.playerID)
-	}()
-
-	for {
-		select {
-		case message, ok := <-c.send:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if !ok {
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
-				return
-			}
-
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			// Send queued messages
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
-			}
-
-			if err := w.Close(); err != nil {
-				return
-			}
-		case <-ticker.C:
-			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				return
-			}
-		}
+	var sessionExpired <-chan time.Time
+	if c.sessionTimer != nil {
+		sessionExpired = c.sessionTimer.C
 	}
-}
-
-// close closes the WebSocket connection
-func (c *wsConnection) close() {
-	log.Printf("[LOCK_ACQUIRE] Acquiring wsConnection.mu lock to close connection for player %s", c.playerID)
-	c.mu.Lock()
 	defer func() {
-		log.Printf("[LOCK_RELEASE] Releasing wsConnection.mu lock for player %s", c.playerID)
-		c.mu.Unlock()
-	}()
-	log.Printf("[CHAN_CLOSE] Closing send channel for player %s", c.playerID)
-	close(c.send)
-	log.Printf("[CHAN_CLOSED] Send channel closed for player %s", c.playerID)
-	log.Printf("[WS_CLOSE] Closing WebSocket connection (close method) for player %s", c.playerID)
-	c.conn.Close()
-	log.Printf("[WS_CLOSED] WebSocket connection closed (close method) for player %s", c.playerID)
-}
-
-// processWebSocketAction processes an action from WebSocket
-func (gm *GameManager) processWebSocketAction(playerID string, action string, data interface{}, wsConn *wsConnection) {
-	game, err := gm.GetGame(playerID)
-	if err != nil {
-		wsConn.sendError("Game not found")
-		return
-	}
-
-	var result map[string]interface{}
-
-	// Convert data to map
-	dataMap, ok := data.(map[string]interface{})
-	if !ok {
-		dataMap = make(map[string]interface{})
-	}
-
-	switch action {
-	case "advance_time":
-		hours := getFloat(dataMap, "hours", 0.0)
-		if hours > 0 {
-			oldTime := game.CurrentDate
-			game.AdvanceTime(time.Duration(hours * float64(time.Hour)))
-			newTime := game.CurrentDate
-			
-			if !oldTime.Equal(newTime) {
-				gm.syncTimeAcrossNetwork(playerID, newTime)
-			}
-			// For advance_time, we don't need to send state immediately
-			// The frontend updates time locally, and state will be synced via periodic updates
-			// This reduces WebSocket traffic significantly
-			result = map[string]interface{}{"success": true, "message": "Time advanced", "skip_state": true}
-		} else {
-			result = map[string]interface{}{"success": false, "message": "Invalid time duration"}
-		}
-
-	case "start_work":
-		err = game.StartWork()
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "stop_work":
-		err = game.StopWork()
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "quit_job":
-		err = game.QuitJob()
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "accept_job_offer":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.AcceptJobOffer(offerID)
-		if err == nil {
-			gm.removeJobOfferFromNetwork(playerID, offerID)
-		}
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "accept_apartment_offer":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.AcceptApartmentOffer(offerID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "quit_apartment":
-		err = game.QuitApartment()
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "accept_offer":
-		offerID := getString(dataMap, "offer_id", "")
-		var offer *Offer
-		for i, o := range game.ActiveOffers {
-			if o.ID == offerID {
-				offer = &game.ActiveOffers[i]
-				break
-			}
-		}
-		err = game.AcceptOffer(offerID)
-		if err == nil && offer != nil && offer.CreatedBy != "" && offer.CreatedBy != playerID {
-			gm.mu.Lock()
-			if creator, exists := gm.games[offer.CreatedBy]; exists {
-				// Transfer money to creator
-				creator.Money += offer.Price
-				creator.addEvent("offer_sold", fmt.Sprintf("Sold %s to %s for €%.2f", offer.Title, playerID, offer.Price), offer.Price)
-				
-				// If it's a recurring offer, creator should also get an agreement
-				if offer.IsRecurring {
-					recurrenceType := offer.RecurrenceType
-					if recurrenceType == "" {
-						recurrenceType = "monthly"
-					}
-					
-					// Create reciprocal agreement for creator (they provide the service)
-					// The creator gets money periodically (positive MoneyChange)
-					creatorAgreement := Agreement{
-						ID:              generateID(),
-						Title:           fmt.Sprintf("Providing %s to %s", offer.Title, playerID),
-						Description:     fmt.Sprintf("You are providing %s to %s. You receive €%.2f per %s.", offer.Description, playerID, offer.Price, recurrenceType),
-						RecurrenceType:  recurrenceType,
-						StartedAt:       creator.CurrentDate,
-						LastProcessedAt: creator.CurrentDate,
-						HealthChange:    0, // Creator doesn't lose health/energy from providing service (or could be negative if it's work)
-						EnergyChange:    0,
-						ReputationChange: 0,
-						MoneyChange:     offer.Price, // Creator receives money periodically
-						IsTrickery:      offer.IsTrickery,
-						Reason:          fmt.Sprintf("Reciprocal agreement from selling %s", offer.Title),
-						IsReciprocal:    true,  // Mark as reciprocal
-						OtherPartyID:    playerID, // Track who the buyer is
-						OriginalPrice:   offer.Price, // Store original price for penalty calculation
-					}
-					creator.Agreements = append(creator.Agreements, creatorAgreement)
-					creator.addEvent("agreement_started", fmt.Sprintf("Started providing %s to %s (€%.2f per %s)", offer.Title, playerID, offer.Price, recurrenceType), 0)
-					log.Printf("[ACCEPT_OFFER] Created reciprocal agreement for creator %s: ID=%s, Title=%s, OtherParty=%s, IsReciprocal=%v", 
-						offer.CreatedBy, creatorAgreement.ID, creatorAgreement.Title, playerID, creatorAgreement.IsReciprocal)
-				} else {
-					// For one-time offers, creator just gets the money (already done above)
-					log.Printf("[ACCEPT_OFFER] One-time offer accepted, creator %s received €%.2f", offer.CreatedBy, offer.Price)
-				}
-				
-				// Notify creator via WebSocket if connected
-				creatorWsConn := (*wsConnection)(nil)
-				gm.wsConnectionsMu.RLock()
-				if ws, exists := gm.wsConnections[offer.CreatedBy]; exists {
-					creatorWsConn = ws
-				}
-				gm.wsConnectionsMu.RUnlock()
-				
-				if creatorWsConn != nil {
-					creatorWsConn.sendGameState(creator)
-					log.Printf("[ACCEPT_OFFER] Notified creator %s via WebSocket", offer.CreatedBy)
-				}
-			}
-			gm.mu.Unlock()
-			gm.removeOfferFromNetwork(playerID, offerID)
-		} else if err == nil && offer != nil {
-			gm.removeOfferFromNetwork(playerID, offerID)
+		logDebugf("[GOROUTINE_END] writePump ending for player %s", c.playerID)
+		ticker.Stop()
+		if c.sessionTimer != nil {
+			c.sessionTimer.Stop()
 		}
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "buy_stock":
-		offerID := getString(dataMap, "offer_id", "")
-		shares := getInt(dataMap, "shares")
-		err = game.BuyStock(offerID, shares)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "sell_stock":
-		symbol := getString(dataMap, "symbol", "")
-		shares := getInt(dataMap, "shares")
-		err = game.SellStock(symbol, shares)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "buy_crypto":
-		symbol := getString(dataMap, "symbol", "")
-		amount := getFloat(dataMap, "amount", 0.0)
-		err = game.BuyCrypto(symbol, amount)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "sell_crypto":
-		symbol := getString(dataMap, "symbol", "")
-		amount := getFloat(dataMap, "amount", 0.0)
-		err = game.SellCrypto(symbol, amount)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "buy_item":
-		itemID := getString(dataMap, "item_id", "")
-		price := getFloat(dataMap, "price", 0.0)
-		err = game.BuyItem(itemID, price)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "sell_item":
-		itemID := getString(dataMap, "item_id", "")
-		err = game.SellItem(itemID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "show_hint":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.ShowHint(offerID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "show_apartment_hint":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.ShowApartmentHint(offerID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
-
-	case "show_stock_hint":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.ShowStockHint(offerID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+		logDebugf("[WS_CLOSE] Closing WebSocket connection (writePump) for player %s", c.playerID)
+		c.conn.Close()
+		logDebugf("[WS_CLOSED] WebSocket connection closed (writePump) for player %s", c.playerID)
+	}()
 
-	case "show_other_offer_hint":
-		offerID := getString(dataMap, "offer_id", "")
-		err = game.ShowOtherOfferHint(offerID)
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
 
-	case "quit_agreement":
-		agreementID := getString(dataMap, "agreement_id", "")
-		// Find the agreement before quitting to check if it's reciprocal
-		// Copy all necessary data before QuitAgreement modifies/removes it
-		var agreementCopy Agreement
-		var agreementFound bool
-		for _, a := range game.Agreements {
-			if a.ID == agreementID {
-				agreementCopy = a // Copy the agreement data
-				agreementFound = true
-				break
+			// One message per frame, so the client never has to split them
+			if err := c.writePendingState(); err != nil {
+				return
 			}
-		}
-		
-		if !agreementFound {
-			result = map[string]interface{}{"success": false, "message": "Agreement not found"}
-			break
-		}
-		
-		// Calculate penalty BEFORE calling QuitAgreement (which might modify CurrentDate)
-		penalty := 0.0
-		daysActive := game.CurrentDate.Sub(agreementCopy.StartedAt).Hours() / 24
-		switch agreementCopy.RecurrenceType {
-		case "daily":
-			if daysActive < 1 {
-				penalty = 50.0
-			} else if daysActive < 7 {
-				penalty = 25.0
+			if err := c.writeFrame(message); err != nil {
+				return
 			}
-		case "weekly":
-			if daysActive < 7 {
-				penalty = 100.0
-			} else if daysActive < 30 {
-				penalty = 50.0
+		case <-c.stateReady:
+			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := c.writePendingState(); err != nil {
+				return
 			}
-		case "monthly":
-			if daysActive < 30 {
-				penalty = 200.0
-			} else if daysActive < 90 {
-				penalty = 100.0
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
 			}
-		}
-		
-		err = game.QuitAgreement(agreementID)
-		
-		// Always send updated state to the buyer (person who canceled)
-		// Get fresh game state after QuitAgreement
-		var buyerStateForSend *GameState
-		gm.mu.RLock()
-		if g, exists := gm.games[playerID]; exists {
-			buyerStateForSend = g
-		}
-		gm.mu.RUnlock()
-		
-		if err == nil && agreementCopy.OtherPartyID != "" && !agreementCopy.IsReciprocal && penalty > 0 {
-			// This is a buyer canceling - creator should get penalty payment
-			// The penalty was already deducted from the buyer in QuitAgreement
-			// Now we need to give it to the creator
-			log.Printf("[QUIT_AGREEMENT] Buyer %s canceled agreement %s, penalty €%.2f should go to creator %s", 
-				playerID, agreementCopy.ID, penalty, agreementCopy.OtherPartyID)
-			
-			gm.mu.Lock()
-			if creator, exists := gm.games[agreementCopy.OtherPartyID]; exists {
-				// Find and remove the reciprocal agreement from creator
-				reciprocalFound := false
-				for idx, creatorAgreement := range creator.Agreements {
-					if creatorAgreement.IsReciprocal && creatorAgreement.OtherPartyID == playerID {
-						creator.Agreements = append(creator.Agreements[:idx], creator.Agreements[idx+1:]...)
-						reciprocalFound = true
-						log.Printf("[QUIT_AGREEMENT] Found and removed reciprocal agreement %s from creator %s", 
-							creatorAgreement.ID, agreementCopy.OtherPartyID)
-						break
-					}
+		case <-sessionExpired:
+			// The token no longer proves who is on the other end: ask the client to log in again
+			logInfof("[WS_CLOSE] Session expired for player %s, closing WebSocket", c.playerID)
+			c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			reauth, _ := json.Marshal(map[string]string{"type": "reauth", "message": "Your session has expired. Please log in again."})
+			if err := c.writeFrame(reauth); err != nil {
+				return
+			}
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "Session expired"))
+			return
+		case <-c.done:
+			// Deliver what is still queued, then tell the client we are going away
+			c.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+			if err := c.writePendingState(); err != nil {
+				return
+			}
+			for n := len(c.send); n > 0; n-- {
+				message, ok := <-c.send
+				if !ok {
+					break
 				}
-				
-				if reciprocalFound {
-					creator.Money += penalty
-					creator.addEvent("agreement_cancelled_penalty", 
-						fmt.Sprintf("Received €%.2f early termination penalty from %s canceling %s", 
-							penalty, playerID, agreementCopy.Title), penalty)
-					log.Printf("[QUIT_AGREEMENT] Creator %s received penalty €%.2f from buyer %s. Remaining agreements: %d", 
-						agreementCopy.OtherPartyID, penalty, playerID, len(creator.Agreements))
-					
-					// Get fresh creator state after modifications
-					creatorStateForSend := creator
-					
-					gm.mu.Unlock() // Release lock before WebSocket operations
-					
-					// Notify creator via WebSocket
-					gm.wsConnectionsMu.RLock()
-					if creatorWs, exists := gm.wsConnections[agreementCopy.OtherPartyID]; exists {
-						// Re-acquire read lock to get fresh state
-						gm.mu.RLock()
-						if freshCreator, exists := gm.games[agreementCopy.OtherPartyID]; exists {
-							creatorStateForSend = freshCreator
-						}
-						gm.mu.RUnlock()
-						
-						creatorWs.sendGameState(creatorStateForSend)
-						log.Printf("[QUIT_AGREEMENT] Notified creator %s via WebSocket with %d agreements", 
-							agreementCopy.OtherPartyID, len(creatorStateForSend.Agreements))
-					} else {
-						log.Printf("[QUIT_AGREEMENT] Creator %s not connected via WebSocket", agreementCopy.OtherPartyID)
-					}
-					gm.wsConnectionsMu.RUnlock()
-					
-					// Lock already released above, no need to re-acquire
-					// All modifications to creator are complete
-				} else {
-					log.Printf("[QUIT_AGREEMENT] WARNING: Reciprocal agreement not found for creator %s, buyer %s. Creator has %d agreements", 
-						agreementCopy.OtherPartyID, playerID, len(creator.Agreements))
-					// Log all creator's agreements for debugging
-					for _, ag := range creator.Agreements {
-						log.Printf("[QUIT_AGREEMENT] Creator agreement: ID=%s, IsReciprocal=%v, OtherPartyID=%s, Title=%s", 
-							ag.ID, ag.IsReciprocal, ag.OtherPartyID, ag.Title)
-					}
-					gm.mu.Unlock()
+				if err := c.writeFrame(message); err != nil {
+					return
 				}
-			} else {
-				log.Printf("[QUIT_AGREEMENT] WARNING: Creator %s not found", agreementCopy.OtherPartyID)
-				gm.mu.Unlock()
 			}
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "Server shutting down"))
+			return
 		}
-		
-		// Send updated state to buyer (person who canceled)
-		if buyerStateForSend != nil {
-			wsConn.sendGameState(buyerStateForSend)
-			log.Printf("[QUIT_AGREEMENT] Sent updated state to buyer %s with %d agreements", 
-				playerID, len(buyerStateForSend.Agreements))
+	}
+}
+
+// close closes the WebSocket connection
+func (c *wsConnection) close() {
+	logDebugf("[LOCK_ACQUIRE] Acquiring wsConnection.mu lock to close connection for player %s", c.playerID)
+	c.mu.Lock()
+	defer func() {
+		logDebugf("[LOCK_RELEASE] Releasing wsConnection.mu lock for player %s", c.playerID)
+		c.mu.Unlock()
+	}()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	logDebugf("[CHAN_CLOSE] Closing send channel for player %s", c.playerID)
+	close(c.send)
+	logDebugf("[CHAN_CLOSED] Send channel closed for player %s", c.playerID)
+	logDebugf("[WS_CLOSE] Closing WebSocket connection (close method) for player %s", c.playerID)
+	c.conn.Close()
+	logDebugf("[WS_CLOSED] WebSocket connection closed (close method) for player %s", c.playerID)
+}
+
+// trySend queues a message for the writePump without blocking. It returns false and drops the
+// message when the send buffer is full or the connection has been closed, which goroutines that
+// outlive the connection (chat, lessons) run into.
+func (c *wsConnection) trySend(data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return false
+	}
+	select {
+	case c.send <- data:
+		return true
+	default:
+		return false
+	}
+}
+
+// processWebSocketAction processes an action from WebSocket
+func (gm *GameManager) processWebSocketAction(playerID string, action string, data interface{}, idempotencyKey string, wsConn *wsConnection) {
+	if idempotencyKey != "" {
+		if seen := wsConn.claimIdempotencyKey(idempotencyKey, action, gm.now()); seen != nil {
+			logInfof("[PROCESS_ACTION] Ignoring repeated %s action for player %s (key %s)", action, playerID, idempotencyKey)
+			wsConn.sendDuplicateResult(action, idempotencyKey, seen)
+			return
 		}
-		
-		result = map[string]interface{}{"success": err == nil, "message": getMessage(err)}
+	}
+	
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		wsConn.sendError("Game not found")
+		return
+	}
+
+	var result map[string]interface{}
+	// Work that touches other players' games runs after this player's lock is released
+	var followUps []func()
+	if idempotencyKey != "" {
+		defer func() { wsConn.rememberResult(idempotencyKey, result) }()
+	}
+
+	// Convert data to map
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		dataMap = make(map[string]interface{})
+	}
 
+	entry.mu.Lock()
+	game := entry.game
+	wasWon := game.GameWon
+	switch action {
+	// Actions that only exist over WebSocket; everything else is shared with HTTP
 	case "offer_message":
 		// Handle sending message to offer via n8n webhook (supports all offer types)
 		offerID := getString(dataMap, "offer_id", "")
@@ -2453,149 +2989,32 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 			break
 		}
 		
-		// Search for offer in all offer types
-		var foundOfferType string
-		var foundOfferIndex int = -1
-		var foundOfferData interface{}
-		
-		// Search in ActiveOffers (other offers)
-		for i := range game.ActiveOffers {
-			if game.ActiveOffers[i].ID == offerID {
-				foundOfferType = "other"
-				foundOfferIndex = i
-				foundOfferData = &game.ActiveOffers[i]
-				break
-			}
-		}
-		
-		// Search in JobOffers
-		if foundOfferData == nil {
-			for i := range game.JobOffers {
-				if game.JobOffers[i].ID == offerID {
-					foundOfferType = "job"
-					foundOfferIndex = i
-					foundOfferData = &game.JobOffers[i]
-					break
-				}
-			}
-		}
-		
-		// Search in ApartmentOffers
-		if foundOfferData == nil {
-			for i := range game.ApartmentOffers {
-				if game.ApartmentOffers[i].ID == offerID {
-					foundOfferType = "apartment"
-					foundOfferIndex = i
-					foundOfferData = &game.ApartmentOffers[i]
-					break
-				}
-			}
-		}
-		
-		// Search in StockOffers
-		if foundOfferData == nil {
-			for i := range game.StockOffers {
-				if game.StockOffers[i].ID == offerID {
-					foundOfferType = "stock"
-					foundOfferIndex = i
-					foundOfferData = &game.StockOffers[i]
-					break
-				}
-			}
-		}
-		
+		foundOfferType, foundOfferData := game.findMessageableOffer(offerID)
 		if foundOfferData == nil {
 			result = map[string]interface{}{"success": false, "message": "Offer not found"}
 			break
 		}
+		if game.negotiationClosed(foundOfferType, offerID) {
+			result = map[string]interface{}{"success": false, "message": fmt.Sprintf("Negotiation is over: the %d rounds for this offer are used up", maxNegotiationRounds)}
+			break
+		}
 		
-		// Call n8n webhook
+		// Call n8n webhook without holding the game lock (foundOfferData is a copy)
+		entry.mu.Unlock()
 		webhookResp, err := gm.callN8NWebhook(foundOfferType, offerID, foundOfferData, message, playerID)
+		entry.mu.Lock()
+		game = entry.game
 		if err != nil {
-			log.Printf("Error calling n8n webhook: %v", err)
+			logErrorf("Error calling n8n webhook: %v", err)
 			result = map[string]interface{}{"success": false, "message": fmt.Sprintf("Failed to send message: %v", err)}
 			break
 		}
 		
-		// Update offer based on response
-		offerUpdated := false
-		switch foundOfferType {
-		case "other":
-			if foundOfferIndex >= 0 && foundOfferIndex < len(game.ActiveOffers) {
-				if game.ActiveOffers[foundOfferIndex].Messages == nil {
-					game.ActiveOffers[foundOfferIndex].Messages = []string{}
-				}
-				game.ActiveOffers[foundOfferIndex].Messages = append(game.ActiveOffers[foundOfferIndex].Messages, message)
-				
-				if webhookResp != nil && webhookResp.Message != nil {
-					game.ActiveOffers[foundOfferIndex].Messages = append(game.ActiveOffers[foundOfferIndex].Messages, *webhookResp.Message)
-				}
-				
-				if webhookResp != nil && webhookResp.Offer != nil {
-					updatedOffer := webhookResp.Offer
-					updatedOffer.ID = game.ActiveOffers[foundOfferIndex].ID
-					updatedOffer.Messages = game.ActiveOffers[foundOfferIndex].Messages
-					game.ActiveOffers[foundOfferIndex] = *updatedOffer
-					offerUpdated = true
-				}
-			}
-		case "job":
-			if foundOfferIndex >= 0 && foundOfferIndex < len(game.JobOffers) {
-				if game.JobOffers[foundOfferIndex].Messages == nil {
-					game.JobOffers[foundOfferIndex].Messages = []string{}
-				}
-				game.JobOffers[foundOfferIndex].Messages = append(game.JobOffers[foundOfferIndex].Messages, message)
-				
-				if webhookResp != nil && webhookResp.Message != nil {
-					game.JobOffers[foundOfferIndex].Messages = append(game.JobOffers[foundOfferIndex].Messages, *webhookResp.Message)
-				}
-				
-				if webhookResp != nil && webhookResp.JobOffer != nil {
-					updatedOffer := webhookResp.JobOffer
-					updatedOffer.ID = game.JobOffers[foundOfferIndex].ID
-					updatedOffer.Messages = game.JobOffers[foundOfferIndex].Messages
-					game.JobOffers[foundOfferIndex] = *updatedOffer
-					offerUpdated = true
-				}
-			}
-		case "apartment":
-			if foundOfferIndex >= 0 && foundOfferIndex < len(game.ApartmentOffers) {
-				if game.ApartmentOffers[foundOfferIndex].Messages == nil {
-					game.ApartmentOffers[foundOfferIndex].Messages = []string{}
-				}
-				game.ApartmentOffers[foundOfferIndex].Messages = append(game.ApartmentOffers[foundOfferIndex].Messages, message)
-				
-				if webhookResp != nil && webhookResp.Message != nil {
-					game.ApartmentOffers[foundOfferIndex].Messages = append(game.ApartmentOffers[foundOfferIndex].Messages, *webhookResp.Message)
-				}
-				
-				if webhookResp != nil && webhookResp.ApartmentOffer != nil {
-					updatedOffer := webhookResp.ApartmentOffer
-					updatedOffer.ID = game.ApartmentOffers[foundOfferIndex].ID
-					updatedOffer.Messages = game.ApartmentOffers[foundOfferIndex].Messages
-					game.ApartmentOffers[foundOfferIndex] = *updatedOffer
-					offerUpdated = true
-				}
-			}
-		case "stock":
-			if foundOfferIndex >= 0 && foundOfferIndex < len(game.StockOffers) {
-				if game.StockOffers[foundOfferIndex].Messages == nil {
-					game.StockOffers[foundOfferIndex].Messages = []string{}
-				}
-				game.StockOffers[foundOfferIndex].Messages = append(game.StockOffers[foundOfferIndex].Messages, message)
-				
-				if webhookResp != nil && webhookResp.Message != nil {
-					game.StockOffers[foundOfferIndex].Messages = append(game.StockOffers[foundOfferIndex].Messages, *webhookResp.Message)
-				}
-				
-				if webhookResp != nil && webhookResp.StockOffer != nil {
-					updatedOffer := webhookResp.StockOffer
-					updatedOffer.ID = game.StockOffers[foundOfferIndex].ID
-					updatedOffer.Messages = game.StockOffers[foundOfferIndex].Messages
-					game.StockOffers[foundOfferIndex] = *updatedOffer
-					offerUpdated = true
-				}
-			}
+		// Update the offer, unless it was accepted or expired during the webhook call
+		found, offerUpdated := game.applyOfferMessage(foundOfferType, offerID, message, webhookResp)
+		if !found {
+			result = map[string]interface{}{"success": false, "message": "Offer is no longer available"}
+			break
 		}
 		
 		responseMsg := "Message sent successfully"
@@ -2603,11 +3022,21 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 			responseMsg = *webhookResp.Message
 		}
 		
+		round := game.negotiate(foundOfferType, offerID, message, webhookResp)
+		thread := gm.recordOfferMessage(offerID, playerID, message, webhookResp)
+		followUps = append(followUps, func() {
+			gm.syncOfferMessages(playerID, offerID, thread)
+		})
+		if round != nil && round.NewPrice != round.OldPrice {
+			responseMsg += fmt.Sprintf(" (Price now €%.2f)", round.NewPrice)
+		}
+		
 		result = map[string]interface{}{
 			"success": true,
 			"message": responseMsg,
 			"offer_type": foundOfferType,
 			"offer_updated": offerUpdated,
+			"negotiation_round": round,
 		}
 
 	case "chat":
@@ -2620,54 +3049,55 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 		} else {
 			// Process chat in a goroutine to avoid blocking
 			// Chat handles its own state updates, so we'll skip the default state send at the end
-			log.Printf("[GOROUTINE_START] Starting chat processing goroutine for player %s", playerID)
+			logDebugf("[GOROUTINE_START] Starting chat processing goroutine for player %s", playerID)
 			go func() {
+				// Let the UI show that the guide is typing until the answer (or an error) is out
+				chatStarted := time.Now()
+				typingData, _ := json.Marshal(map[string]interface{}{"type": "chat_typing"})
+				wsConn.trySend(typingData)
+				
 				defer func() {
-					log.Printf("[GOROUTINE_END] Chat processing goroutine ending for player %s", playerID)
+					logDebugf("[GOROUTINE_END] Chat processing goroutine ending for player %s", playerID)
 					if r := recover(); r != nil {
-						log.Printf("[PANIC] PANIC in chat goroutine for player %s: %v", playerID, r)
+						logErrorf("[PANIC] Panic in chat goroutine for player %s: %v", playerID, r)
 						errorMsg := map[string]interface{}{
 							"type":    "chat_response",
 							"success": false,
 							"message": "An error occurred processing your chat. Please try again.",
 						}
 						errorData, _ := json.Marshal(errorMsg)
-						select {
-						case wsConn.send <- errorData:
-						default:
-						}
+						wsConn.trySend(errorData)
 					}
+					
+					typingEndData, _ := json.Marshal(map[string]interface{}{
+						"type":       "chat_typing_end",
+						"elapsed_ms": time.Since(chatStarted).Milliseconds(),
+					})
+					wsConn.trySend(typingEndData)
 				}()
 				
-				log.Printf("[CHAT] Player %s sent message: %s", playerID, message)
+				logDebugf("[CHAT] Player %s sent message: %s", playerID, message)
 				
-				// Get fresh game state
-				log.Printf("[LOCK_ACQUIRE] Acquiring gm.mu read lock for chat (player %s)", playerID)
-				gm.mu.RLock()
-				currentGame, exists := gm.games[playerID]
+				// Get a fresh snapshot of the game state, so no lock is held during the AI calls
+				currentGame, exists := gm.snapshotGame(playerID)
 				if !exists {
-					log.Printf("[LOCK_RELEASE] Releasing gm.mu read lock (game not found, player %s)", playerID)
-					gm.mu.RUnlock()
-					log.Printf("[CHAT] ERROR: Game not found for player %s", playerID)
+					logErrorf("[CHAT] Game not found for player %s", playerID)
 					errorMsg := map[string]interface{}{
 						"type":    "chat_response",
 						"success": false,
 						"message": "Game not found",
 					}
 					errorData, _ := json.Marshal(errorMsg)
-					select {
-					case wsConn.send <- errorData:
-					default:
-					}
+					wsConn.trySend(errorData)
 					return
 				}
-				logLockRelease("gm.mu.RUnlock", playerID)
-				gm.mu.RUnlock()
 				
 				// First, check if the message is trying to create an offer/agreement/item
-				log.Printf("[CHAT] Parsing offer creation for player %s", playerID)
+				logDebugf("[CHAT] Parsing offer creation for player %s", playerID)
 				
-				// Call ParseChatForOfferCreation with timeout protection
+				// Call ParseChatForOfferCreation with timeout protection; the wait covers the AI
+				// request's own timeouts, so it only runs out if the parser hangs elsewhere
+				parseTimeout := aiWaitTimeout("chat_offer_parser")
 				parseResponseChan := make(chan *ChatResponse, 1)
 				parseErrorChan := make(chan error, 1)
 				doneChan := make(chan bool, 1) // Signal when goroutine completes
@@ -2683,145 +3113,92 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 						case doneChan <- true:
 							logChannelOp("SEND", playerID+"_parse_done", len(doneChan), cap(doneChan))
 						default:
-							log.Printf("[CHAN_FULL] doneChan full for player %s", playerID)
+							logWarnf("[CHAN_FULL] doneChan full for player %s", playerID)
 						}
 						if r := recover(); r != nil {
-							log.Printf("[PANIC] PANIC in ParseChatForOfferCreation goroutine for player %s: %v", playerID, r)
+							logErrorf("[PANIC] Panic in ParseChatForOfferCreation goroutine for player %s: %v", playerID, r)
 							select {
 							case parseErrorChan <- fmt.Errorf("panic: %v", r):
 								logChannelOp("SEND", playerID+"_parse_err", len(parseErrorChan), cap(parseErrorChan))
 							default:
-								log.Printf("[CHAN_FULL] parseErrorChan full for player %s", playerID)
+								logWarnf("[CHAN_FULL] parseErrorChan full for player %s", playerID)
 							}
 						}
 					}()
 					
-					log.Printf("[AI_CALL_START] ParseChatForOfferCreation for player %s", playerID)
-					response, parseErr := gm.ai.ParseChatForOfferCreation(currentGame, message)
-					log.Printf("[AI_CALL_END] ParseChatForOfferCreation for player %s (error: %v)", playerID, parseErr != nil)
+					logDebugf("[AI_CALL_START] ParseChatForOfferCreation for player %s", playerID)
+					response, parseErr := gm.ai.ParseChatForOfferCreation(wsConn.ctx, currentGame, message)
+					logDebugf("[AI_CALL_END] ParseChatForOfferCreation for player %s (error: %v)", playerID, parseErr != nil)
 					if parseErr != nil {
 						select {
 						case parseErrorChan <- parseErr:
 							logChannelOp("SEND", playerID+"_parse_err", len(parseErrorChan), cap(parseErrorChan))
 						case <-time.After(1 * time.Second):
-							log.Printf("[CHAN_TIMEOUT] Could not send parse error for player %s (channel timeout)", playerID)
+							logWarnf("[CHAN_TIMEOUT] Could not send parse error for player %s (channel timeout)", playerID)
 						}
 					} else {
 						select {
 						case parseResponseChan <- response:
 							logChannelOp("SEND", playerID+"_parse_resp", len(parseResponseChan), cap(parseResponseChan))
 						case <-time.After(1 * time.Second):
-							log.Printf("[CHAN_TIMEOUT] Could not send parse response for player %s (channel timeout)", playerID)
+							logWarnf("[CHAN_TIMEOUT] Could not send parse response for player %s (channel timeout)", playerID)
 						}
 					}
 				}()
 				
 				var creationResponse *ChatResponse
 				var parseErr error
-				log.Printf("[SELECT_START] Waiting for ParseChatForOfferCreation response for player %s", playerID)
+				logDebugf("[SELECT_START] Waiting for ParseChatForOfferCreation response for player %s", playerID)
 				select {
 				case creationResponse = <-parseResponseChan:
 					logChannelOp("RECV", playerID+"_parse_resp", len(parseResponseChan), cap(parseResponseChan))
-					log.Printf("[CHAT] Received parse response for player %s", playerID)
+					logDebugf("[CHAT] Received parse response for player %s", playerID)
 				case parseErr = <-parseErrorChan:
 					logChannelOp("RECV", playerID+"_parse_err", len(parseErrorChan), cap(parseErrorChan))
-					log.Printf("[CHAT] Received parse error for player %s: %v", playerID, parseErr)
-				case <-time.After(20 * time.Second):
-					log.Printf("[TIMEOUT] ParseChatForOfferCreation timeout for player %s", playerID)
-					parseErr = fmt.Errorf("Offer parsing timed out after 20 seconds")
+					logDebugf("[CHAT] Received parse error for player %s: %v", playerID, parseErr)
+				case <-time.After(parseTimeout):
+					logWarnf("[TIMEOUT] ParseChatForOfferCreation timeout for player %s", playerID)
+					parseErr = fmt.Errorf("Offer parsing timed out after %s", parseTimeout)
 					// Wait a bit for goroutine to finish (non-blocking)
 					select {
 					case <-doneChan:
 						logChannelOp("RECV", playerID+"_parse_done", len(doneChan), cap(doneChan))
-						log.Printf("[CHAT] ParseChatForOfferCreation goroutine completed after timeout for player %s", playerID)
+						logDebugf("[CHAT] ParseChatForOfferCreation goroutine completed after timeout for player %s", playerID)
 					case <-time.After(2 * time.Second):
-						log.Printf("[WARNING] ParseChatForOfferCreation goroutine still running after timeout for player %s", playerID)
+						logWarnf("[TIMEOUT] ParseChatForOfferCreation goroutine still running after timeout for player %s", playerID)
 					}
 				}
-				log.Printf("[SELECT_END] ParseChatForOfferCreation select completed for player %s", playerID)
+				logDebugf("[SELECT_END] ParseChatForOfferCreation select completed for player %s", playerID)
+				if wsConn.ctx.Err() != nil {
+					logInfof("[CHAT] Player %s disconnected, dropping their chat", playerID)
+					return
+				}
 				
 				if parseErr != nil {
-					log.Printf("[CHAT] ERROR parsing offer creation for player %s: %v", playerID, parseErr)
+					logErrorf("[CHAT] Error parsing offer creation for player %s: %v", playerID, parseErr)
 					// Continue with normal chat flow if parsing fails
 					creationResponse = nil
 				}
 				
 				if creationResponse != nil {
-					log.Printf("[CHAT] Creation response for player %s: Created=%v, Offer=%v, Agreement=%v", 
+					logDebugf("[CHAT] Creation response for player %s: Created=%v, Offer=%v, Agreement=%v", 
 						playerID, creationResponse.Created, creationResponse.Offer != nil, creationResponse.Agreement != nil)
 				}
 				
 				if parseErr == nil && creationResponse != nil && creationResponse.Created {
-					log.Printf("[CHAT] Processing offer/agreement creation for player %s", playerID)
+					logDebugf("[CHAT] Processing offer/agreement creation for player %s", playerID)
 					
 					// Prepare network players list and offer/agreement data
-					var networkPlayersCopy []string
+					networkPlayers := gm.getNetworkPlayers(playerID)
 					var responseMessage string
-					
-					// Lock to modify game state
-					logLockAcquire("gm.mu.Lock", playerID)
-					gm.mu.Lock()
-					// Get fresh game state again (in case it changed)
-					if g, exists := gm.games[playerID]; exists {
-						currentGame = g
-					} else {
-						logLockRelease("gm.mu.Unlock", playerID)
-						gm.mu.Unlock()
-						log.Printf("[CHAT] ERROR: Game not found for player %s during offer creation", playerID)
-						errorMsg := map[string]interface{}{
-							"type":    "chat_response",
-							"success": false,
-							"message": "Game not found",
-						}
-						errorData, _ := json.Marshal(errorMsg)
-						select {
-						case wsConn.send <- errorData:
-						default:
-						}
-						return
-					}
+					var shareErr error
 					
 					if creationResponse.Offer != nil {
-						log.Printf("[CHAT] Creating offer for player %s: %s (€%.2f)", playerID, creationResponse.Offer.Title, creationResponse.Offer.Price)
-						// Add offer to game and share with network
-						currentGame.ActiveOffers = append(currentGame.ActiveOffers, *creationResponse.Offer)
-						log.Printf("[CHAT] Added offer to player %s's game. Total offers: %d", playerID, len(currentGame.ActiveOffers))
-						
-						// Share with network (we already hold the lock, so use unlocked version)
-						networkRoot := gm.getNetworkRootUnlocked(playerID)
-						networkPlayers := []string{networkRoot}
-						
-						// Find all players who were invited by anyone in the network
-						visited := make(map[string]bool)
-						visited[networkRoot] = true
-						
-						// Recursively find all players in the network
-						var findNetwork func(rootID string)
-						findNetwork = func(rootID string) {
-							for pid, g := range gm.games {
-								if !visited[pid] && g.InvitedBy == rootID {
-									networkPlayers = append(networkPlayers, pid)
-									visited[pid] = true
-									findNetwork(pid) // Recursively find players invited by this one
-								}
-							}
-						}
-						findNetwork(networkRoot)
-						
-						log.Printf("[CHAT] Sharing offer with network. Network players: %v", networkPlayers)
-						
-						for _, pid := range networkPlayers {
-							if pid != playerID {
-								if networkGame, exists := gm.games[pid]; exists {
-									networkGame.ActiveOffers = append(networkGame.ActiveOffers, *creationResponse.Offer)
-									log.Printf("[CHAT] Added offer to network player %s", pid)
-								}
-							}
-						}
+						logInfof("[CHAT] Creating offer for player %s: %s (€%.2f)", playerID, creationResponse.Offer.Title, creationResponse.Offer.Price)
+						logDebugf("[CHAT] Sharing offer with network. Network players: %v", networkPlayers)
 						
-						// Store network players for use after releasing lock
-						networkPlayersCopy = make([]string, len(networkPlayers))
-						copy(networkPlayersCopy, networkPlayers)
+						// Add offer to game and share with network, within the player's creation limits
+						shareErr = gm.sharePlayerOffer(playerID, *creationResponse.Offer)
 						
 						responseMessage = fmt.Sprintf("✅ Created offer: %s (€%.2f). It's now available to other players in your network!", creationResponse.Offer.Title, creationResponse.Offer.Price)
 						
@@ -2852,170 +3229,107 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 							MoneyChange:     creationResponse.Agreement.MoneyChange,
 							IsRecurring:     true,
 							RecurrenceType:  creationResponse.Agreement.RecurrenceType,
+							Category:        creationResponse.Agreement.Category,
 							CreatedBy:       playerID,
 						}
-						currentGame.ActiveOffers = append(currentGame.ActiveOffers, *offer)
-						
-						// Share with network (we already hold the lock, so use unlocked version)
-						networkRoot := gm.getNetworkRootUnlocked(playerID)
-						networkPlayers := []string{networkRoot}
-						
-						// Find all players who were invited by anyone in the network
-						visited := make(map[string]bool)
-						visited[networkRoot] = true
-						
-						// Recursively find all players in the network
-						var findNetwork func(rootID string)
-						findNetwork = func(rootID string) {
-							for pid, g := range gm.games {
-								if !visited[pid] && g.InvitedBy == rootID {
-									networkPlayers = append(networkPlayers, pid)
-									visited[pid] = true
-									findNetwork(pid) // Recursively find players invited by this one
-								}
-							}
-						}
-						findNetwork(networkRoot)
-						
-						for _, pid := range networkPlayers {
-							if pid != playerID {
-								if networkGame, exists := gm.games[pid]; exists {
-									networkGame.ActiveOffers = append(networkGame.ActiveOffers, *offer)
-								}
-							}
-						}
 						
-						// Store network players for use after releasing lock
-						networkPlayersCopy = make([]string, len(networkPlayers))
-						copy(networkPlayersCopy, networkPlayers)
+						// Add offer to game and share with network, within the player's creation limits
+						shareErr = gm.sharePlayerOffer(playerID, *offer)
 						
 						creationResponse.Offer = offer
 						responseMessage = fmt.Sprintf("✅ Created agreement offer: %s (€%.2f/%s). It's now available to other players in your network!", offer.Title, offer.Price, creationResponse.Agreement.RecurrenceType)
 					} else {
 						// Neither offer nor agreement - this shouldn't happen, but handle it
-						logLockRelease("gm.mu.Unlock", playerID)
-						gm.mu.Unlock()
-						log.Printf("[CHAT] ERROR: Creation response has no offer or agreement for player %s", playerID)
+						logErrorf("[CHAT] Creation response has no offer or agreement for player %s", playerID)
 						errorMsg := map[string]interface{}{
 							"type":    "chat_response",
 							"success": false,
 							"message": "Invalid creation response",
 						}
 						errorData, _ := json.Marshal(errorMsg)
-						select {
-						case wsConn.send <- errorData:
-						default:
-						}
+						wsConn.trySend(errorData)
 						return
 					}
 					
-					// Get fresh game state for sending (while lock is held)
-					var gameStateForSend *GameState
-					if g, exists := gm.games[playerID]; exists {
-						gameStateForSend = g
-					}
-					
-					// Release lock before WebSocket operations
-					logLockRelease("gm.mu.Unlock", playerID)
-					gm.mu.Unlock()
-					
-					// Notify all network players via WebSocket (outside of lock)
-					if len(networkPlayersCopy) > 0 {
-						logLockAcquire("wsConnectionsMu.RLock", playerID)
-						gm.wsConnectionsMu.RLock()
-						notifiedCount := 0
-						for _, pid := range networkPlayersCopy {
+					if shareErr != nil {
+						// Over the creation limits: tell the player instead of creating it
+						logInfof("[CHAT] Not creating offer for player %s: %v", playerID, shareErr)
+						creationResponse.Created = false
+						creationResponse.Offer = nil
+						responseMessage = getMessage(shareErr)
+					} else {
+						// Notify the other network players via WebSocket (outside of any game lock)
+						otherPlayers := make([]string, 0, len(networkPlayers))
+						for _, pid := range networkPlayers {
 							if pid != playerID {
-								if wsConn, exists := gm.wsConnections[pid]; exists {
-									logLockAcquire("gm.mu.RLock", pid)
-									gm.mu.RLock()
-									if g, exists := gm.games[pid]; exists {
-										wsConn.sendGameState(g)
-										notifiedCount++
-									}
-									logLockRelease("gm.mu.RUnlock", pid)
-									gm.mu.RUnlock()
-								}
+								otherPlayers = append(otherPlayers, pid)
 							}
 						}
-						logLockRelease("wsConnectionsMu.RUnlock", playerID)
-						gm.wsConnectionsMu.RUnlock()
-						log.Printf("[CHAT] Notified %d network players via WebSocket", notifiedCount)
+						gm.notifyPlayers(otherPlayers...)
+						logDebugf("[CHAT] Notified network players %v via WebSocket", otherPlayers)
 					}
 					
-					log.Printf("[CHAT] About to update creation response message for player %s", playerID)
+					logDebugf("[CHAT] About to update creation response message for player %s", playerID)
 					// Update creation response message
 					creationResponse.Message = responseMessage
-					log.Printf("[CHAT] Updated creation response message for player %s", playerID)
+					logDebugf("[CHAT] Updated creation response message for player %s", playerID)
 					
 					// Store response data (no lock needed here)
-					log.Printf("[CHAT] About to marshal response data for player %s", playerID)
+					logDebugf("[CHAT] About to marshal response data for player %s", playerID)
 					responseData, err := json.Marshal(map[string]interface{}{
 						"type":    "chat_response",
 						"success": true,
 						"result":  creationResponse,
 					})
 					if err != nil {
-						log.Printf("[CHAT] ERROR marshaling response data for player %s: %v", playerID, err)
+						logErrorf("[CHAT] Error marshaling response data for player %s: %v", playerID, err)
 					} else {
-						log.Printf("[CHAT] Successfully marshaled response data for player %s (size: %d bytes)", playerID, len(responseData))
+						logDebugf("[CHAT] Successfully marshaled response data for player %s (size: %d bytes)", playerID, len(responseData))
 					}
 					
-					log.Printf("[CHAT] Sending creation response to player %s via WebSocket", playerID)
+					logDebugf("[CHAT] Sending creation response to player %s via WebSocket", playerID)
 					// Send response via WebSocket
 					if err != nil {
-						log.Printf("[CHAT] ERROR marshaling response for player %s: %v", playerID, err)
+						logErrorf("[CHAT] Error marshaling response for player %s: %v", playerID, err)
 					} else {
-						select {
-						case wsConn.send <- responseData:
-							log.Printf("[CHAT] Successfully sent response to player %s", playerID)
-						default:
-							log.Printf("[CHAT] WARNING: WebSocket send channel full for player %s", playerID)
+						if wsConn.trySend(responseData) {
+							logDebugf("[CHAT] Successfully sent response to player %s", playerID)
+						} else {
+							logWarnf("[CHAT] WebSocket send channel full or closed for player %s", playerID)
 						}
 					}
 					
 					// Send updated state to creator
-					if gameStateForSend != nil {
-						log.Printf("[CHAT] About to send updated game state to player %s", playerID)
-						wsConn.sendGameState(gameStateForSend)
-						log.Printf("[CHAT] Sent updated game state to player %s", playerID)
+					logDebugf("[CHAT] About to send updated game state to player %s", playerID)
+					if gm.readGame(playerID, wsConn.sendGameState) {
+						logDebugf("[CHAT] Sent updated game state to player %s", playerID)
 					} else {
-						log.Printf("[CHAT] ERROR: Game not found when sending state to player %s", playerID)
+						logErrorf("[CHAT] Game not found when sending state to player %s", playerID)
 					}
-					log.Printf("[CHAT] Completed offer creation for player %s", playerID)
+					logDebugf("[CHAT] Completed offer creation for player %s", playerID)
 					return
 				}
 				
 				// Normal chat flow - get fresh game state
-				log.Printf("[CHAT] Processing normal chat for player %s", playerID)
+				logDebugf("[CHAT] Processing normal chat for player %s", playerID)
 				
 				// Get fresh game state for chat (in case it changed)
-				var freshGameForChat *GameState
-				logLockAcquire("gm.mu.RLock", playerID)
-				gm.mu.RLock()
-				if g, exists := gm.games[playerID]; exists {
-					// Create a copy to avoid holding the lock during AI call
-					freshGameForChat = g
-				}
-				logLockRelease("gm.mu.RUnlock", playerID)
-				gm.mu.RUnlock()
-				
-				if freshGameForChat == nil {
-					log.Printf("[CHAT] ERROR: Game not found for player %s in normal chat", playerID)
+				// Use a snapshot to avoid holding the lock during AI call
+				freshGameForChat, exists := gm.snapshotGame(playerID)
+				if !exists {
+					logErrorf("[CHAT] Game not found for player %s in normal chat", playerID)
 					errorMsg := map[string]interface{}{
 						"type":    "chat_response",
 						"success": false,
 						"message": "Game not found",
 					}
 					errorData, _ := json.Marshal(errorMsg)
-					select {
-					case wsConn.send <- errorData:
-					default:
-					}
+					wsConn.trySend(errorData)
 					return
 				}
 				
-				// Call ChatWithGuide with timeout protection
+				// Call ChatWithGuide with timeout protection (see parseTimeout)
+				chatTimeout := aiWaitTimeout("guide_chat")
 				chatResponseChan := make(chan *ChatResponse, 1)
 				errorChan := make(chan error, 1)
 				chatDoneChan := make(chan bool, 1) // Signal when goroutine completes
@@ -3031,104 +3345,104 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 						case chatDoneChan <- true:
 							logChannelOp("SEND", playerID+"_chat_done", len(chatDoneChan), cap(chatDoneChan))
 						default:
-							log.Printf("[CHAN_FULL] chatDoneChan full for player %s", playerID)
+							logWarnf("[CHAN_FULL] chatDoneChan full for player %s", playerID)
 						}
 						if r := recover(); r != nil {
-							log.Printf("[PANIC] PANIC in ChatWithGuide goroutine for player %s: %v", playerID, r)
+							logErrorf("[PANIC] Panic in ChatWithGuide goroutine for player %s: %v", playerID, r)
 							select {
 							case errorChan <- fmt.Errorf("panic: %v", r):
 								logChannelOp("SEND", playerID+"_chat_err", len(errorChan), cap(errorChan))
 							default:
-								log.Printf("[CHAN_FULL] errorChan full for player %s", playerID)
+								logWarnf("[CHAN_FULL] errorChan full for player %s", playerID)
 							}
 						}
 					}()
 					
-					log.Printf("[AI_CALL_START] ChatWithGuide for player %s", playerID)
-					response, chatErr := gm.ai.ChatWithGuide(freshGameForChat, message, context)
-					log.Printf("[AI_CALL_END] ChatWithGuide for player %s (error: %v)", playerID, chatErr != nil)
+					logDebugf("[AI_CALL_START] ChatWithGuide for player %s", playerID)
+					response, chatErr := gm.ai.ChatWithGuide(wsConn.ctx, freshGameForChat, message, context)
+					logDebugf("[AI_CALL_END] ChatWithGuide for player %s (error: %v)", playerID, chatErr != nil)
 					if chatErr != nil {
 						select {
 						case errorChan <- chatErr:
 							logChannelOp("SEND", playerID+"_chat_err", len(errorChan), cap(errorChan))
 						case <-time.After(1 * time.Second):
-							log.Printf("[CHAN_TIMEOUT] Could not send chat error for player %s (channel timeout)", playerID)
+							logWarnf("[CHAN_TIMEOUT] Could not send chat error for player %s (channel timeout)", playerID)
 						}
 					} else {
 						select {
 						case chatResponseChan <- response:
 							logChannelOp("SEND", playerID+"_chat_resp", len(chatResponseChan), cap(chatResponseChan))
 						case <-time.After(1 * time.Second):
-							log.Printf("[CHAN_TIMEOUT] Could not send chat response for player %s (channel timeout)", playerID)
+							logWarnf("[CHAN_TIMEOUT] Could not send chat response for player %s (channel timeout)", playerID)
 						}
 					}
 				}()
 				
 				var chatResponse *ChatResponse
 				var chatErr error
-				log.Printf("[SELECT_START] Waiting for ChatWithGuide response for player %s", playerID)
+				logDebugf("[SELECT_START] Waiting for ChatWithGuide response for player %s", playerID)
 				select {
 				case chatResponse = <-chatResponseChan:
 					logChannelOp("RECV", playerID+"_chat_resp", len(chatResponseChan), cap(chatResponseChan))
-					log.Printf("[CHAT] Received chat response for player %s", playerID)
+					logDebugf("[CHAT] Received chat response for player %s", playerID)
 				case chatErr = <-errorChan:
 					logChannelOp("RECV", playerID+"_chat_err", len(errorChan), cap(errorChan))
-					log.Printf("[CHAT] Received chat error for player %s: %v", playerID, chatErr)
-				case <-time.After(30 * time.Second):
-					log.Printf("[TIMEOUT] ChatWithGuide timeout for player %s", playerID)
-					chatErr = fmt.Errorf("Chat request timed out after 30 seconds")
+					logDebugf("[CHAT] Received chat error for player %s: %v", playerID, chatErr)
+				case <-time.After(chatTimeout):
+					logWarnf("[TIMEOUT] ChatWithGuide timeout for player %s", playerID)
+					chatErr = fmt.Errorf("Chat request timed out after %s", chatTimeout)
 					// Wait a bit for goroutine to finish (non-blocking)
 					select {
 					case <-chatDoneChan:
 						logChannelOp("RECV", playerID+"_chat_done", len(chatDoneChan), cap(chatDoneChan))
-						log.Printf("[CHAT] ChatWithGuide goroutine completed after timeout for player %s", playerID)
+						logDebugf("[CHAT] ChatWithGuide goroutine completed after timeout for player %s", playerID)
 					case <-time.After(2 * time.Second):
-						log.Printf("[WARNING] ChatWithGuide goroutine still running after timeout for player %s", playerID)
+						logWarnf("[TIMEOUT] ChatWithGuide goroutine still running after timeout for player %s", playerID)
 					}
 				}
-				log.Printf("[SELECT_END] ChatWithGuide select completed for player %s", playerID)
+				logDebugf("[SELECT_END] ChatWithGuide select completed for player %s", playerID)
+				if wsConn.ctx.Err() != nil {
+					logInfof("[CHAT] Player %s disconnected, dropping their chat", playerID)
+					return
+				}
 				
 				if chatErr != nil {
-					log.Printf("[CHAT] ERROR in ChatWithGuide for player %s: %v", playerID, chatErr)
+					logErrorf("[CHAT] Error in ChatWithGuide for player %s: %v", playerID, chatErr)
 					errorMsg := map[string]interface{}{
 						"type":    "chat_response",
 						"success": false,
 						"message": "Error: " + chatErr.Error(),
 					}
 					errorData, _ := json.Marshal(errorMsg)
-					select {
-					case wsConn.send <- errorData:
-						log.Printf("[CHAT] Sent error response to player %s", playerID)
-					default:
-						log.Printf("[CHAT] WARNING: Could not send error response to player %s (channel full)", playerID)
+					if wsConn.trySend(errorData) {
+						logDebugf("[CHAT] Sent error response to player %s", playerID)
+					} else {
+						logWarnf("[CHAT] Could not send error response to player %s (channel full or closed)", playerID)
 					}
 					return
 				}
 				
 				if chatResponse == nil {
-					log.Printf("[CHAT] ERROR: ChatWithGuide returned nil response for player %s", playerID)
+					logErrorf("[CHAT] ChatWithGuide returned nil response for player %s", playerID)
 					errorMsg := map[string]interface{}{
 						"type":    "chat_response",
 						"success": false,
 						"message": "No response from chat agent",
 					}
 					errorData, _ := json.Marshal(errorMsg)
-					select {
-					case wsConn.send <- errorData:
-					default:
-					}
+					wsConn.trySend(errorData)
 					return
 				}
 				
 				// Add hint about creating offers if it's a general question
 				if strings.Contains(strings.ToLower(message), "how") || strings.Contains(strings.ToLower(message), "can i") || strings.Contains(strings.ToLower(message), "create") {
 					if !strings.Contains(chatResponse.Message, "create") && !strings.Contains(chatResponse.Message, "offer") {
-						chatResponse.Message += "\n\n💡 Tip: You can create offers, agreements, or sell items to other players by describing what you want to offer in the chat! For example: 'I want to sell my laptop for €500' or 'I'm offering a monthly subscription service for €50/month'."
+						chatResponse.Message += localize(freshGameForChat.Language, createOfferTip)
 					}
 				}
 				
 				// Send chat response via WebSocket
-				log.Printf("[CHAT] Sending normal chat response to player %s", playerID)
+				logDebugf("[CHAT] Sending normal chat response to player %s", playerID)
 				response := map[string]interface{}{
 					"type":    "chat_response",
 					"success": true,
@@ -3136,16 +3450,15 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 				}
 				responseData, err := json.Marshal(response)
 				if err != nil {
-					log.Printf("[CHAT] ERROR marshaling chat response for player %s: %v", playerID, err)
+					logErrorf("[CHAT] Error marshaling chat response for player %s: %v", playerID, err)
 				} else {
-					select {
-					case wsConn.send <- responseData:
-						log.Printf("[CHAT] Successfully sent chat response to player %s", playerID)
-					default:
-						log.Printf("[CHAT] WARNING: WebSocket send channel full for player %s", playerID)
+					if wsConn.trySend(responseData) {
+						logDebugf("[CHAT] Successfully sent chat response to player %s", playerID)
+					} else {
+						logWarnf("[CHAT] WebSocket send channel full or closed for player %s", playerID)
 					}
 				}
-				log.Printf("[CHAT] Completed normal chat for player %s", playerID)
+				logDebugf("[CHAT] Completed normal chat for player %s", playerID)
 			}()
 			
 			// Return immediately - response will come via WebSocket
@@ -3153,13 +3466,21 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 		}
 
 	default:
-		result = map[string]interface{}{"success": false, "message": "Unknown action"}
+		result, followUps = gm.dispatchAction(playerID, game, action, dataMap)
+		if action == "advance_time" && result["success"] == true {
+			// For advance_time, we don't need to send state immediately
+			// The frontend updates time locally, and state will be synced via periodic updates
+			// This reduces WebSocket traffic significantly
+			result["skip_state"] = true
+		}
 	}
+	followUps = append(followUps, gm.finishActions(playerID, game, wasWon)...)
+	gm.invalidateCache(playerID)
+	entry.mu.Unlock()
 
-	// Invalidate cache
-	gm.stateCacheMu.Lock()
-	delete(gm.stateCache, playerID)
-	gm.stateCacheMu.Unlock()
+	for _, followUp := range followUps {
+		followUp()
+	}
 
 	// Skip state update for certain actions that handle their own state updates or don't need immediate state
 	skipState := false
@@ -3180,30 +3501,23 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 			"action":  action,
 			"result":  result,
 		}
+		if idempotencyKey != "" {
+			response["idempotency_key"] = idempotencyKey
+		}
 		responseData, _ := json.Marshal(response)
-		select {
-		case wsConn.send <- responseData:
-			log.Printf("[PROCESS_ACTION] Sent %s action result (skipped state) for player %s", action, playerID)
-		default:
-			log.Printf("[PROCESS_ACTION] Failed to send %s action result (channel full) for player %s", action, playerID)
+		if wsConn.trySend(responseData) {
+			logDebugf("[PROCESS_ACTION] Sent %s action result (skipped state) for player %s", action, playerID)
+		} else {
+			logWarnf("[PROCESS_ACTION] Failed to send %s action result (channel full or closed) for player %s", action, playerID)
 		}
 		return
 	}
 
-	// Get fresh game state (in case it was modified)
-	gm.mu.RLock()
-	freshGame, exists := gm.games[playerID]
-	gm.mu.RUnlock()
-	
-	if !exists {
-		wsConn.sendError("Game not found after action")
-		return
-	}
-
-	// Send updated state (always send fresh state)
-	result["game_state"] = freshGame
-	log.Printf("[PROCESS_ACTION] Sending state update for action %s to player %s", action, playerID)
-	wsConn.sendGameState(freshGame)
+	// Send updated state (always send fresh state, including changes made by follow-ups)
+	entry.mu.RLock()
+	result["game_state"] = entry.game
+	logDebugf("[PROCESS_ACTION] Sending state update for action %s to player %s", action, playerID)
+	wsConn.sendGameState(entry.game)
 
 	// Send action result
 	response := map[string]interface{}{
@@ -3211,11 +3525,33 @@ func (gm *GameManager) processWebSocketAction(playerID string, action string, da
 		"action":  action,
 		"result":  result,
 	}
+	if idempotencyKey != "" {
+		response["idempotency_key"] = idempotencyKey
+	}
 	responseData, _ := json.Marshal(response)
-	select {
-	case wsConn.send <- responseData:
-	default:
-		log.Printf("[PROCESS_ACTION] Failed to send action result (channel full) for player %s", playerID)
+	entry.mu.RUnlock()
+	if !wsConn.trySend(responseData) {
+		logWarnf("[PROCESS_ACTION] Failed to send action result (channel full or closed) for player %s", playerID)
+	}
+}
+
+// sendToPlayer queues a WebSocket message for the player if they are connected; it is dropped
+// when the send buffer is full or the connection closed. Callers must not hold any game lock.
+func (gm *GameManager) sendToPlayer(playerID string, msg map[string]interface{}) {
+	gm.wsConnectionsMu.RLock()
+	wsConn, exists := gm.wsConnections[playerID]
+	gm.wsConnectionsMu.RUnlock()
+	if !exists {
+		return
+	}
+	
+	data, err := json.Marshal(msg)
+	if err != nil {
+		logErrorf("[WS] Error marshaling %v message for player %s: %v", msg["type"], playerID, err)
+		return
+	}
+	if !wsConn.trySend(data) {
+		logWarnf("[WS] Send channel full or closed for player %s, dropped %v message", playerID, msg["type"])
 	}
 }
 
@@ -3226,10 +3562,7 @@ func (c *wsConnection) sendError(message string) {
 		"message": message,
 	}
 	data, _ := json.Marshal(msg)
-	select {
-	case c.send <- data:
-	default:
-	}
+	c.trySend(data)
 }
 
 // Helper functions
@@ -0,0 +1,842 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	
+	"github.com/gorilla/websocket"
+)
+
+// linkedAgreements returns, for each player, the LinkIDs of their buyer and reciprocal agreements
+func linkedAgreements(gm *GameManager, playerIDs ...string) (buyer map[string]string, reciprocal map[string]string) {
+	buyer, reciprocal = map[string]string{}, map[string]string{}
+	for _, playerID := range playerIDs {
+		gm.readGame(playerID, func(game *GameState) {
+			for _, agreement := range game.Agreements {
+				if agreement.LinkID == "" {
+					continue
+				}
+				if agreement.IsReciprocal {
+					reciprocal[agreement.LinkID] = playerID
+				} else {
+					buyer[agreement.LinkID] = agreement.OtherPartyID
+				}
+			}
+		})
+	}
+	return buyer, reciprocal
+}
+
+// buyerAgreementID returns the ID of the agreement playerID bought with the given offer
+func buyerAgreementID(gm *GameManager, playerID string, offerID string) string {
+	var id string
+	gm.readGame(playerID, func(game *GameState) {
+		for _, agreement := range game.Agreements {
+			if !agreement.IsReciprocal && agreement.LinkID == offerID {
+				id = agreement.ID
+			}
+		}
+	})
+	return id
+}
+
+func TestConcurrentQuitAgreementAndAcceptOffer(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	date := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	newTestGame(t, gm, "alice", date)
+	joinTestNetwork(t, gm, "bob", "alice")
+	expires := date.Add(7 * 24 * time.Hour)
+	for _, playerID := range []string{"alice", "bob"} {
+		gm.withGame(playerID, func(game *GameState) { game.Money = 100000 })
+	}
+	
+	// Each round both players buy from each other while each quits an agreement bought before
+	for round := 0; round < 20; round++ {
+		var aliceQuits, bobQuits string
+		gm.withGame("alice", func(game *GameState) {
+			game.ActiveOffers = append(game.ActiveOffers, playerOffer(fmt.Sprintf("bob-old-%d", round), "bob", 30, expires), playerOffer(fmt.Sprintf("bob-new-%d", round), "bob", 30, expires))
+		})
+		gm.withGame("bob", func(game *GameState) {
+			game.ActiveOffers = append(game.ActiveOffers, playerOffer(fmt.Sprintf("alice-old-%d", round), "alice", 30, expires), playerOffer(fmt.Sprintf("alice-new-%d", round), "alice", 30, expires))
+		})
+		if result := doAction(t, gm, "alice", "accept_offer", map[string]interface{}{"offer_id": fmt.Sprintf("bob-old-%d", round)}); result["success"] != true {
+			t.Fatalf("alice could not accept bob's offer: %v", result["message"])
+		}
+		if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": fmt.Sprintf("alice-old-%d", round)}); result["success"] != true {
+			t.Fatalf("bob could not accept alice's offer: %v", result["message"])
+		}
+		aliceQuits = buyerAgreementID(gm, "alice", fmt.Sprintf("bob-old-%d", round))
+		bobQuits = buyerAgreementID(gm, "bob", fmt.Sprintf("alice-old-%d", round))
+		
+		var wg sync.WaitGroup
+		actions := []struct {
+			playerID string
+			action   string
+			data     map[string]interface{}
+		}{
+			{"alice", "quit_agreement", map[string]interface{}{"agreement_id": aliceQuits}},
+			{"bob", "quit_agreement", map[string]interface{}{"agreement_id": bobQuits}},
+			{"alice", "accept_offer", map[string]interface{}{"offer_id": fmt.Sprintf("bob-new-%d", round)}},
+			{"bob", "accept_offer", map[string]interface{}{"offer_id": fmt.Sprintf("alice-new-%d", round)}},
+		}
+		for _, a := range actions {
+			wg.Add(1)
+			go func(playerID string, action string, data map[string]interface{}) {
+				defer wg.Done()
+				if result := doAction(t, gm, playerID, action, data); result["success"] != true {
+					t.Errorf("round %d: %s %s failed: %v", round, playerID, action, result["message"])
+				}
+			}(a.playerID, a.action, a.data)
+		}
+		
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("round %d: concurrent quit_agreement and accept_offer did not finish (deadlock?)", round)
+		}
+	}
+	
+	// Every agreement still running on one side runs on the other, and the quit ones on neither
+	buyer, reciprocal := linkedAgreements(gm, "alice", "bob")
+	if len(buyer) != 20*2 || len(reciprocal) != 20*2 {
+		t.Fatalf("got %d buyer and %d reciprocal agreements, want 40 of each", len(buyer), len(reciprocal))
+	}
+	for linkID, creatorID := range buyer {
+		if reciprocal[linkID] != creatorID {
+			t.Errorf("agreement %s: creator %s has no matching reciprocal agreement", linkID, creatorID)
+		}
+	}
+}
+
+func TestWebSocketUpgradeChecksOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"listed origin", []string{"https://planc.example"}, "https://planc.example", true},
+		{"unlisted origin", []string{"https://planc.example"}, "https://evil.example", false},
+		{"wildcard", []string{"*"}, "https://evil.example", true},
+		{"same origin without a list", nil, "http://SERVER", true},
+		{"other origin without a list", nil, "https://evil.example", false},
+		{"no origin header", []string{"https://planc.example"}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, func(config *Config) { config.Server.AllowedOrigins = tt.allowed })
+			gm, _ := newTestManager(t)
+			server := httptest.NewServer(http.HandlerFunc(gm.HandleWebSocket))
+			defer server.Close()
+			
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", strings.Replace(tt.origin, "http://SERVER", server.URL, 1))
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?player_id=p", header)
+			if conn != nil {
+				conn.Close()
+			}
+			if tt.want && err != nil {
+				t.Fatalf("upgrade was rejected: %v", err)
+			}
+			if !tt.want {
+				if err == nil {
+					t.Fatal("upgrade from a disallowed origin was accepted")
+				}
+				if resp == nil {
+					t.Fatalf("got no response (%v), want 403 Forbidden", err)
+				}
+				if resp.StatusCode != http.StatusForbidden {
+					t.Errorf("got status %d, want 403 Forbidden", resp.StatusCode)
+				}
+			}
+		})
+	}
+}
+
+// A WebSocket upgrade without a valid session token for the player is refused before it
+// upgrades, so no game is started and no connection registered
+func TestWebSocketUpgradeRequiresSession(t *testing.T) {
+	config := testConfig(t, func(config *Config) { config.Auth.Secret = "test-secret" })
+	previous := sessionAuth
+	if err := SetupAuth(config); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sessionAuth = previous })
+	gm, _ := newTestManager(t)
+	server := httptest.NewServer(gm.RequireSession(http.HandlerFunc(gm.HandleWebSocket)))
+	defer server.Close()
+	
+	aliceToken, _ := issueSessionToken("alice")
+	bobToken, _ := issueSessionToken("bob")
+	tests := []struct {
+		name  string
+		query string
+		want  int
+	}{
+		{"no token", "player_id=alice", http.StatusUnauthorized},
+		{"forged token", "player_id=alice&token=" + strings.Replace(aliceToken, ".", ".x", 1), http.StatusUnauthorized},
+		{"another player's token", "player_id=alice&token=" + bobToken, http.StatusForbidden},
+		{"own token", "player_id=alice&token=" + aliceToken, http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws?"+tt.query, nil)
+		if conn != nil {
+			conn.Close()
+		}
+		if resp == nil {
+			t.Fatalf("%s: got no response (%v), want status %d", tt.name, err, tt.want)
+		}
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+		if tt.want != http.StatusSwitchingProtocols {
+			if _, exists := gm.getEntry("alice"); exists {
+				t.Fatalf("%s: the refused upgrade started alice's game", tt.name)
+			}
+		}
+	}
+}
+
+// An offer accepted while the webhook answers a message about it must not be replaced by the
+// webhook's version, nor may the offer that took its place in the list
+func TestOfferMessageWhenOfferIsGoneAfterWebhook(t *testing.T) {
+	for _, path := range []string{"http", "websocket"} {
+		t.Run(path, func(t *testing.T) {
+			var gm *GameManager
+			webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The player accepts the offer while the webhook is thinking
+				gm.withGame("p", func(game *GameState) {
+					game.ActiveOffers = game.ActiveOffers[1:]
+				})
+				w.Header().Set("Content-Type", "application/json")
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"message": "Deal!",
+					"offer":   map[string]interface{}{"title": "Rewritten by the webhook", "price": 1},
+				})
+			}))
+			defer webhook.Close()
+			testConfig(t, func(config *Config) { config.N8N.WebhookURL = webhook.URL })
+			gm, _ = newTestManager(t)
+			newTestGame(t, gm, "p", testStart)
+			gm.withGame("p", func(game *GameState) {
+				game.ActiveOffers = []Offer{
+					{ID: "target", Title: "Target", Price: 50, ExpiresAt: testStart.Add(time.Hour)},
+					{ID: "bystander", Title: "Bystander", Price: 70, ExpiresAt: testStart.Add(time.Hour)},
+				}
+			})
+			
+			switch path {
+			case "http":
+				body := strings.NewReader(`{"offer_id": "target", "message": "Can you do 40?"}`)
+				rec := httptest.NewRecorder()
+				gm.HandleOfferMessage(rec, httptest.NewRequest(http.MethodPost, "/api/offer/message?player_id=p", body))
+				if rec.Code != http.StatusGone {
+					t.Errorf("got status %d (%s), want %d", rec.Code, bytes.TrimSpace(rec.Body.Bytes()), http.StatusGone)
+				}
+			case "websocket":
+				result := wsActionResult(t, gm, newTestConnection(gm, "p"), "offer_message", map[string]interface{}{"offer_id": "target", "message": "Can you do 40?"}, "")
+				if result["success"] != false {
+					t.Errorf("got %v, want a failure for the accepted offer", result)
+				}
+			}
+			
+			gm.readGame("p", func(game *GameState) {
+				if len(game.ActiveOffers) != 1 {
+					t.Fatalf("got %d offers, want only the bystander", len(game.ActiveOffers))
+				}
+				bystander := game.ActiveOffers[0]
+				if bystander.ID != "bystander" || bystander.Title != "Bystander" || bystander.Price != 70 || len(bystander.Messages) != 0 {
+					t.Errorf("the bystander offer was changed: %+v", bystander)
+				}
+			})
+		})
+	}
+}
+
+// The webhook's reply and updated offer are recorded on the offer that was messaged
+func TestOfferMessageUpdatesOffer(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message":   "Deal!",
+			"job_offer": map[string]interface{}{"title": "Senior Baker", "salary": 3100},
+		})
+	}))
+	defer webhook.Close()
+	testConfig(t, func(config *Config) { config.N8N.WebhookURL = webhook.URL })
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "p", testStart)
+	gm.withGame("p", func(game *GameState) {
+		game.JobOffers = []JobOffer{
+			{ID: "other", Title: "Cook", Salary: 2000, ExpiresAt: testStart.Add(time.Hour)},
+			{ID: "target", Title: "Baker", Salary: 2500, ExpiresAt: testStart.Add(time.Hour)},
+		}
+	})
+	
+	result := wsActionResult(t, gm, newTestConnection(gm, "p"), "offer_message", map[string]interface{}{"offer_id": "target", "message": "More pay?"}, "")
+	if result["success"] != true || result["offer_updated"] != true {
+		t.Fatalf("got %v, want the offer updated", result)
+	}
+	gm.readGame("p", func(game *GameState) {
+		target := game.JobOffers[1]
+		if target.ID != "target" || target.Title != "Senior Baker" || len(target.Messages) != 2 || len(target.Negotiation) != 1 {
+			t.Errorf("got %+v, want the webhook's offer with the message, reply and round kept", target)
+		}
+		if game.JobOffers[0].Title != "Cook" || len(game.JobOffers[0].Messages) != 0 {
+			t.Errorf("the other offer was changed: %+v", game.JobOffers[0])
+		}
+	})
+}
+
+// BenchmarkConcurrentPlayers measures many players acting at once with per-game locks, against
+// the same work serialized on one lock as it was with a single global game lock
+func BenchmarkConcurrentPlayers(b *testing.B) {
+	testConfig(b, nil)
+	gm, _ := newTestManager(b)
+	const players = 64
+	for i := 0; i < players; i++ {
+		newTestGame(b, gm, fmt.Sprintf("player-%d", i), testStart)
+	}
+	act := func(playerID string) {
+		gm.withGame(playerID, func(game *GameState) {
+			game.AdvanceTime(time.Minute)
+			game.AvailableActions()
+		})
+	}
+	
+	var global sync.Mutex
+	for _, locking := range []string{"per-game", "global"} {
+		b.Run(locking, func(b *testing.B) {
+			var next int64
+			var nextMu sync.Mutex
+			b.RunParallel(func(pb *testing.PB) {
+				nextMu.Lock()
+				playerID := fmt.Sprintf("player-%d", next%players)
+				next++
+				nextMu.Unlock()
+				for pb.Next() {
+					if locking == "global" {
+						global.Lock()
+						act(playerID)
+						global.Unlock()
+					} else {
+						act(playerID)
+					}
+				}
+			})
+		})
+	}
+}
+
+// An invitee who can't afford the invite fee pays what they have, and the inviter receives only that
+func TestInviteFeeUnderFunded(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Game.Scenarios["broke"] = Scenario{InitialMoney: 200, StartDate: "2000-01-02T08:00:00Z", Difficulty: "normal"}
+	})
+	gm, _ := newTestManager(t)
+	gm.GetOrCreateGame("alice", "en", "broke")
+	var aliceMoney float64
+	gm.readGame("alice", func(game *GameState) { aliceMoney = game.Money })
+	joinTestNetwork(t, gm, "bob", "alice")
+	
+	gm.readGame("bob", func(game *GameState) {
+		if game.Money != 0 || game.InitialMoney != 0 {
+			t.Errorf("bob has %.2f (initial %.2f), want 0 after paying everything", game.Money, game.InitialMoney)
+		}
+		fees := eventsOfType(game, "invite_fee")
+		if len(fees) != 1 || fees[0].Amount != -200 || !strings.Contains(fees[0].Message, "€200.00") {
+			t.Errorf("got invite fee events %+v, want one of -200 saying €200.00 was paid", fees)
+		}
+	})
+	gm.readGame("alice", func(game *GameState) {
+		if game.Money != aliceMoney+200 {
+			t.Errorf("alice has %.2f, want %.2f (credited only the 200 paid)", game.Money, aliceMoney+200)
+		}
+		rewards := eventsOfType(game, "invite_reward")
+		if len(rewards) != 1 || rewards[0].Amount != 200 {
+			t.Errorf("got invite reward events %+v, want one of 200", rewards)
+		}
+	})
+}
+
+func TestInviteRejectsSelfInvitesAndCycles(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "alice", testStart)
+	joinTestNetwork(t, gm, "bob", "alice")
+	joinTestNetwork(t, gm, "carol", "bob")
+	
+	var aliceCode, carolCode string
+	gm.readGame("alice", func(game *GameState) { aliceCode = game.InviteCode })
+	gm.readGame("carol", func(game *GameState) { carolCode = game.InviteCode })
+	if _, err := gm.CreateGameWithInvite("alice", aliceCode, "en"); err == nil {
+		t.Error("alice could use their own invite code")
+	}
+	
+	// alice's chain names a player that doesn't exist yet, as an imported save can: that player
+	// joining through carol would make the chain a loop
+	gm.withGame("alice", func(game *GameState) { game.InvitedBy = "dave" })
+	if _, err := gm.CreateGameWithInvite("dave", carolCode, "en"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("got %v, want the invite rejected as a cycle", err)
+	}
+	if _, exists := gm.getEntry("dave"); exists {
+		t.Error("dave's game was created")
+	}
+	gm.inviteCodesMu.Lock()
+	uses := gm.inviteCodes[carolCode].Uses
+	gm.inviteCodesMu.Unlock()
+	if uses != 0 {
+		t.Errorf("carol's code has %d uses, want the rejected one released", uses)
+	}
+}
+
+// Every member of a long invite chain sees the whole network, root first
+func TestInviteDeepChainNetwork(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	depth := GetConfig().Invites.MaxNetworkSize
+	newTestGame(t, gm, "player-0", testStart)
+	want := map[string]bool{"player-0": true}
+	for i := 1; i < depth; i++ {
+		joinTestNetwork(t, gm, fmt.Sprintf("player-%d", i), fmt.Sprintf("player-%d", i-1))
+		want[fmt.Sprintf("player-%d", i)] = true
+	}
+	
+	for _, playerID := range []string{"player-0", "player-25", fmt.Sprintf("player-%d", depth-1)} {
+		playerID := playerID
+		done := make(chan []string)
+		go func() { done <- gm.getNetworkPlayers(playerID) }()
+		var players []string
+		select {
+		case players = <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("getNetworkPlayers(%s) did not return", playerID)
+		}
+		if len(players) != depth || players[0] != "player-0" {
+			t.Errorf("getNetworkPlayers(%s) returned %d players starting with %v, want %d starting with player-0", playerID, len(players), players[:min(1, len(players))], depth)
+		}
+		seen := map[string]bool{}
+		for _, member := range players {
+			if !want[member] || seen[member] {
+				t.Errorf("getNetworkPlayers(%s) returned %s, who is not in the chain or is there twice", playerID, member)
+			}
+			seen[member] = true
+		}
+	}
+	
+	// The chain is as long as a network may be
+	var leafCode string
+	gm.readGame(fmt.Sprintf("player-%d", depth-1), func(game *GameState) { leafCode = game.InviteCode })
+	if _, err := gm.CreateGameWithInvite("one-too-many", leafCode, "en"); err == nil {
+		t.Error("a player joined a full network")
+	}
+}
+
+// acceptOutcome is what an accept_offer changed, without the IDs generated along the way
+type acceptOutcome struct {
+	Success      bool
+	Message      interface{}
+	BuyerMoney   float64
+	CreatorMoney float64
+	Buyer        []Agreement
+	Creator      []Agreement
+	OffersLeft   int
+}
+
+// HTTP and WebSocket run accept_offer through the same dispatch, so they must agree on the result
+// and on what happens to both players
+func TestAcceptOfferSameOverHTTPAndWebSocket(t *testing.T) {
+	tests := []struct {
+		name    string
+		offerID string
+		money   float64
+	}{
+		{"accepted", "mowing", 10000},
+		{"unknown offer", "missing", 10000},
+		{"not affordable", "mowing", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, nil)
+			outcomes := map[string]acceptOutcome{}
+			for _, path := range []string{"http", "websocket"} {
+				gm, _ := newTestManager(t)
+				newTestGame(t, gm, "alice", testStart)
+				joinTestNetwork(t, gm, "bob", "alice")
+				gm.withGame("bob", func(game *GameState) {
+					game.Money = tt.money
+					game.ActiveOffers = []Offer{playerOffer("mowing", "alice", 30, testStart.Add(time.Hour))}
+				})
+				
+				data := map[string]interface{}{"offer_id": tt.offerID}
+				var result map[string]interface{}
+				if path == "http" {
+					result = doAction(t, gm, "bob", "accept_offer", data)
+				} else {
+					result = wsActionResult(t, gm, newTestConnection(gm, "bob"), "accept_offer", data, "")
+				}
+				
+				outcome := acceptOutcome{Success: result["success"] == true, Message: result["message"]}
+				withoutIDs := func(agreements []Agreement) []Agreement {
+					copied := append([]Agreement(nil), agreements...)
+					for i := range copied {
+						copied[i].ID = ""
+					}
+					return copied
+				}
+				gm.readGame("bob", func(game *GameState) {
+					outcome.BuyerMoney = game.Money
+					outcome.Buyer = withoutIDs(game.Agreements)
+					outcome.OffersLeft = len(game.ActiveOffers)
+				})
+				gm.readGame("alice", func(game *GameState) {
+					outcome.CreatorMoney = game.Money
+					outcome.Creator = withoutIDs(game.Agreements)
+				})
+				outcomes[path] = outcome
+			}
+			
+			if !reflect.DeepEqual(outcomes["http"], outcomes["websocket"]) {
+				t.Errorf("HTTP and WebSocket differ:\nhttp:      %+v\nwebsocket: %+v", outcomes["http"], outcomes["websocket"])
+			}
+			if want := tt.name == "accepted"; outcomes["http"].Success != want {
+				t.Errorf("got success %v (%v), want %v", outcomes["http"].Success, outcomes["http"].Message, want)
+			}
+			if want := map[bool]int{true: 1, false: 0}[outcomes["http"].Success]; len(outcomes["http"].Buyer) != want || len(outcomes["http"].Creator) != want {
+				t.Errorf("got %d buyer and %d creator agreements, want %d of each", len(outcomes["http"].Buyer), len(outcomes["http"].Creator), want)
+			}
+		})
+	}
+}
+
+// A WebSocket action sent twice with the same idempotency key, as a flaky connection resends it,
+// runs once; the repeat gets the first outcome back
+func TestWebSocketActionReplayedWithSameKey(t *testing.T) {
+	testConfig(t, nil)
+	gm, clock := newTestManager(t)
+	newTestGame(t, gm, "alice", testStart)
+	joinTestNetwork(t, gm, "bob", "alice")
+	gm.withGame("bob", func(game *GameState) {
+		game.ActiveOffers = []Offer{playerOffer("mowing", "alice", 30, testStart.Add(24*time.Hour))}
+	})
+	conn := newTestConnection(gm, "bob")
+	
+	accept := map[string]interface{}{"offer_id": "mowing"}
+	first := wsActionResult(t, gm, conn, "accept_offer", accept, "key-1")
+	var money float64
+	gm.readGame("bob", func(game *GameState) { money = game.Money })
+	repeat := wsActionResult(t, gm, conn, "accept_offer", accept, "key-1")
+	if first["success"] != true || repeat["success"] != true || repeat["duplicate"] != true {
+		t.Fatalf("got %v then %v, want success and then the same success marked duplicate", first, repeat)
+	}
+	gm.readGame("bob", func(game *GameState) {
+		if len(game.Agreements) != 1 || game.Money != money {
+			t.Errorf("got %d agreements and €%.2f after the repeat, want 1 and €%.2f", len(game.Agreements), game.Money, money)
+		}
+	})
+	
+	// Sent again under a new key, the offer is reported as accepted rather than missing
+	if again := wsActionResult(t, gm, conn, "accept_offer", accept, "key-2"); again["success"] != false || again["message"] != "You already accepted this offer" {
+		t.Errorf("got %v, want the offer reported as already accepted", again)
+	}
+	
+	// A key reused for another action is refused
+	if other := wsActionResult(t, gm, conn, "advance_time", map[string]interface{}{"hours": 1.0}, "key-1"); other["success"] != false {
+		t.Errorf("got %v, want the reused key refused", other)
+	}
+	
+	var before time.Time
+	gm.readGame("bob", func(game *GameState) { before = game.CurrentDate })
+	advance := map[string]interface{}{"hours": 2.0}
+	wsActionResult(t, gm, conn, "advance_time", advance, "key-3")
+	wsActionResult(t, gm, conn, "advance_time", advance, "key-3")
+	gm.readGame("bob", func(game *GameState) {
+		if advanced := game.CurrentDate.Sub(before); advanced != 2*time.Hour {
+			t.Errorf("time advanced %v, want 2h for the one advance", advanced)
+		}
+	})
+	
+	// Once the window has passed the key is forgotten and the action runs again
+	clock.Advance(idempotencyWindow + time.Second)
+	if result := wsActionResult(t, gm, conn, "advance_time", advance, "key-3"); result["duplicate"] == true {
+		t.Errorf("got %v, want the action run after the idempotency window", result)
+	}
+	gm.readGame("bob", func(game *GameState) {
+		if advanced := game.CurrentDate.Sub(before); advanced != 4*time.Hour {
+			t.Errorf("time advanced %v, want 4h after the key expired", advanced)
+		}
+	})
+}
+
+// Messages queued while the writePump is busy go out one JSON document per frame, the pending
+// state first
+func TestWritePumpSendsOneMessagePerFrame(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	wsConn, client := dialTestConnection(t, gm, "p")
+	const queued = 5
+	for i := 0; i < queued; i++ {
+		data, _ := json.Marshal(map[string]interface{}{"type": "chat_response", "n": i})
+		wsConn.send <- data
+	}
+	wsConn.queueState([]byte(`{"type":"state"}`))
+	go wsConn.writePump()
+	defer close(wsConn.send)
+	
+	want := []string{"state", "chat_response", "chat_response", "chat_response", "chat_response", "chat_response"}
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for i, wantType := range want {
+		kind, frame, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("frame %d: %v", i, err)
+		}
+		var msg map[string]interface{}
+		if kind != websocket.TextMessage || json.Unmarshal(frame, &msg) != nil {
+			t.Fatalf("frame %d is not one JSON document: %q", i, frame)
+		}
+		if msg["type"] != wantType {
+			t.Errorf("frame %d is a %v message, want %s", i, msg["type"], wantType)
+		}
+	}
+}
+
+// Goroutines that outlive a connection, like the chat's, send to it after it was closed: the
+// messages are dropped instead of panicking on the closed channel
+func TestSendAfterConnectionClosed(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	wsConn, _ := dialTestConnection(t, gm, "p")
+	gm.wsConnectionsMu.Lock()
+	gm.wsConnections["p"] = wsConn
+	gm.wsConnectionsMu.Unlock()
+	// Without a readPump nothing unregisters it for the manager's shutdown
+	defer func() {
+		gm.wsConnectionsMu.Lock()
+		delete(gm.wsConnections, "p")
+		gm.wsConnectionsMu.Unlock()
+	}()
+	
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				wsConn.trySend([]byte(`{"type":"chat_typing_end"}`))
+			}
+		}()
+	}
+	wsConn.close()
+	wg.Wait()
+	
+	if wsConn.trySend([]byte(`{"type":"chat_typing"}`)) {
+		t.Error("a message was queued on a closed connection")
+	}
+	wsConn.sendError("too late")
+	gm.sendToPlayer("p", map[string]interface{}{"type": "achievement"})
+	wsConn.close()
+}
+
+// A creator polling /api/state sees the money from a sale made by another player straight away,
+// not a cached state from before it
+func TestAcceptOfferInvalidatesCreatorState(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	date := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+	newTestGame(t, gm, "alice", date)
+	joinTestNetwork(t, gm, "bob", "alice")
+	gm.withGame("alice", func(game *GameState) {
+		game.Money = 1000
+		game.ActiveOffers = append(game.ActiveOffers, playerOffer("mowing", "bob", 30, date.Add(7*24*time.Hour)))
+	})
+	
+	polledMoney := func() float64 {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		gm.HandleGetState(rec, httptest.NewRequest(http.MethodGet, "/api/state?player_id=bob", nil))
+		var state GameState
+		if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+			t.Fatalf("decoding bob's state: %v", err)
+		}
+		return state.Money
+	}
+	before := polledMoney()
+	gm.stateCacheMu.RLock()
+	_, cached := gm.stateCache["bob"]
+	gm.stateCacheMu.RUnlock()
+	if !cached {
+		t.Fatal("bob's state was not cached by the first poll")
+	}
+	
+	if result := doAction(t, gm, "alice", "accept_offer", map[string]interface{}{"offer_id": "mowing"}); result["success"] != true {
+		t.Fatalf("alice could not accept bob's offer: %v", result["message"])
+	}
+	if after := polledMoney(); after <= before {
+		t.Errorf("bob polled €%.2f after the sale, want more than the €%.2f before it", after, before)
+	}
+}
+
+// The buyer of a player-created recurring offer pays its price every period and the creator
+// receives it, even when the offer came with a money change of the wrong sign
+func TestRecurringPlayerOfferPaysCreatorEachPeriod(t *testing.T) {
+	for _, moneyChange := range []float64{0, -30, 30, 500} {
+		t.Run(fmt.Sprintf("money change %.0f", moneyChange), func(t *testing.T) {
+			testConfig(t, nil)
+			gm, _ := newTestManager(t)
+			date := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+			newTestGame(t, gm, "alice", date)
+			joinTestNetwork(t, gm, "bob", "alice")
+			money := map[string]float64{}
+			for _, playerID := range []string{"alice", "bob"} {
+				gm.withGame(playerID, func(game *GameState) {
+					game.Money = 1000
+					money[playerID] = game.Money
+				})
+			}
+			gm.withGame("bob", func(game *GameState) {
+				offer := playerOffer("mowing", "alice", 30, date.Add(7*24*time.Hour))
+				offer.MoneyChange = moneyChange
+				game.ActiveOffers = append(game.ActiveOffers, offer)
+			})
+			if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": "mowing"}); result["success"] != true {
+				t.Fatalf("bob could not accept alice's offer: %v", result["message"])
+			}
+			
+			for period := 0; period <= 3; period++ {
+				for playerID, sign := range map[string]float64{"bob": -1, "alice": 1} {
+					gm.withGame(playerID, func(game *GameState) {
+						if period > 0 {
+							month := game.CurrentDate.AddDate(0, 1, 0).Sub(game.CurrentDate)
+							game.CurrentDate = game.CurrentDate.Add(month)
+							game.processAgreements(month)
+						}
+						if want := money[playerID] + sign*30*float64(period+1); math.Abs(game.Money-want) > 0.005 {
+							t.Errorf("after %d periods %s has €%.2f, want €%.2f", period, playerID, game.Money, want)
+						}
+					})
+				}
+			}
+		})
+	}
+}
+
+// With two agreements between the same players, quitting either side of one ends only that one
+func TestQuitOneOfTwoAgreementsWithSamePlayer(t *testing.T) {
+	for _, quitter := range []string{"bob", "alice"} {
+		t.Run(quitter+" quits", func(t *testing.T) {
+			testConfig(t, nil)
+			gm, _ := newTestManager(t)
+			date := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+			newTestGame(t, gm, "alice", date)
+			joinTestNetwork(t, gm, "bob", "alice")
+			gm.withGame("bob", func(game *GameState) {
+				game.Money = 1000
+				game.ActiveOffers = append(game.ActiveOffers, playerOffer("mowing", "alice", 30, date.Add(7*24*time.Hour)), playerOffer("walking", "alice", 20, date.Add(7*24*time.Hour)))
+			})
+			for _, offerID := range []string{"mowing", "walking"} {
+				if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": offerID}); result["success"] != true {
+					t.Fatalf("bob could not accept %s: %v", offerID, result["message"])
+				}
+			}
+			
+			// Quit the agreement accepted first, so matching on the other player alone would find it
+			// for either one
+			quits := buyerAgreementID(gm, "bob", "mowing")
+			if quitter == "alice" {
+				gm.readGame("alice", func(game *GameState) {
+					for _, agreement := range game.Agreements {
+						if agreement.IsReciprocal && agreement.LinkID == "mowing" {
+							quits = agreement.ID
+						}
+					}
+				})
+			}
+			if result := doAction(t, gm, quitter, "quit_agreement", map[string]interface{}{"agreement_id": quits}); result["success"] != true {
+				t.Fatalf("%s could not quit the mowing agreement: %v", quitter, result["message"])
+			}
+			
+			buyer, reciprocal := linkedAgreements(gm, "alice", "bob")
+			if _, kept := buyer["mowing"]; kept {
+				t.Error("bob still has the mowing agreement")
+			}
+			if _, kept := reciprocal["mowing"]; kept {
+				t.Error("alice still has the creator's side of the mowing agreement")
+			}
+			if buyer["walking"] != "alice" || reciprocal["walking"] != "alice" {
+				t.Errorf("got buyer agreements %v and reciprocal %v, want the walking agreement intact on both sides", buyer, reciprocal)
+			}
+		})
+	}
+}
+
+// A short configured interval lets a network get its next round of offers sooner than the
+// defaults allow
+func TestOfferGenerationFollowsConfiguredInterval(t *testing.T) {
+	kinds := []struct {
+		name     string
+		cadence  func(config *Config) *OfferCadence
+		generate func(gm *GameManager)
+		count    func(game *GameState) int
+	}{
+		{"jobs", func(c *Config) *OfferCadence { return &c.Offers.Jobs }, (*GameManager).generateJobOffersForAllGames, func(g *GameState) int { return len(g.JobOffers) }},
+		{"apartments", func(c *Config) *OfferCadence { return &c.Offers.Apartments }, (*GameManager).generateApartmentOffersForAllGames, func(g *GameState) int { return len(g.ApartmentOffers) }},
+		{"stocks", func(c *Config) *OfferCadence { return &c.Offers.Stocks }, (*GameManager).generateStockOffersForAllGames, func(g *GameState) int { return len(g.StockOffers) }},
+		{"other", func(c *Config) *OfferCadence { return &c.Offers.Other }, (*GameManager).generateOtherOffersForAllGames, func(g *GameState) int { return len(g.ActiveOffers) }},
+	}
+	for _, kind := range kinds {
+		for _, seconds := range []int{2, 0} {
+			name := kind.name + " with the default interval"
+			if seconds > 0 {
+				name = fmt.Sprintf("%s every %d seconds", kind.name, seconds)
+			}
+			t.Run(name, func(t *testing.T) {
+				config := testConfig(t, func(config *Config) {
+					if seconds > 0 {
+						kind.cadence(config).MinIntervalSeconds = seconds
+						kind.cadence(config).MaxIntervalSeconds = seconds + 1
+					}
+				})
+				gm, clock := newTestManager(t)
+				newTestGame(t, gm, "alice", testStart)
+				gm.withGame("alice", func(game *GameState) { game.Money = 1000000 })
+				gm.markActive("alice")
+				
+				offers := func() int {
+					n := 0
+					gm.readGame("alice", func(game *GameState) { n = kind.count(game) })
+					return n
+				}
+				kind.generate(gm)
+				first := offers()
+				if first == 0 {
+					t.Fatal("the first round generated nothing")
+				}
+				clock.Advance(3 * time.Second)
+				gm.markActive("alice")
+				kind.generate(gm)
+				second := offers()
+				if soon := kind.cadence(config).MinIntervalSeconds <= 3; soon && second <= first {
+					t.Errorf("got %d offers 3s after the first round, want more than %d with a %ds interval", second, first, seconds)
+				} else if !soon && second != first {
+					t.Errorf("got %d offers 3s after the first round, want still %d within the default interval", second, first)
+				}
+			})
+		}
+	}
+}
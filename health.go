@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// aiProbeInterval limits how often /readyz actually contacts the AI provider
+const aiProbeInterval = 30 * time.Second
+
+// HandleHealth reports that the process is alive
+func (gm *GameManager) HandleHealth(w http.ResponseWriter, r *http.Request) {
+	uptime := gm.since(gm.startedAt)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "ok",
+		"uptime":         uptime.Round(time.Second).String(),
+		"uptime_seconds": int64(uptime.Seconds()),
+		"goroutines":     runtime.NumGoroutine(),
+	})
+}
+
+// HandleReady reports whether the server can serve games (config loaded, optionally AI reachable)
+func (gm *GameManager) HandleReady(w http.ResponseWriter, r *http.Request) {
+	checks := make(map[string]string)
+	ready := true
+	
+	if appConfig == nil {
+		checks["config"] = "not loaded"
+		ready = false
+	} else {
+		checks["config"] = "ok"
+	}
+	
+	if appConfig != nil && appConfig.Features.ReadyAIProbe {
+		if err := gm.probeAI(); err != nil {
+			checks["ai"] = err.Error()
+			ready = false
+		} else {
+			checks["ai"] = "ok"
+		}
+	}
+	
+	status := "ready"
+	w.Header().Set("Content-Type", "application/json")
+	if !ready {
+		status = "not_ready"
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": status,
+		"checks": checks,
+	})
+}
+
+// probeAI returns the cached AI connectivity result, refreshing it at most once per aiProbeInterval
+func (gm *GameManager) probeAI() error {
+	gm.aiProbeMu.Lock()
+	defer gm.aiProbeMu.Unlock()
+	
+	if !gm.aiProbeAt.IsZero() && gm.since(gm.aiProbeAt) < aiProbeInterval {
+		return gm.aiProbeErr
+	}
+	
+	gm.aiProbeErr = gm.ai.Probe()
+	gm.aiProbeAt = gm.now()
+	return gm.aiProbeErr
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	
+	"github.com/gorilla/websocket"
+)
+
+// TestMain keeps the game's logging to errors unless the tests run with -v
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		logLevel.Set(slog.LevelError)
+	}
+	os.Exit(m.Run())
+}
+
+// testStart is the real time the test clocks start at
+var testStart = time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+
+// testConfig installs the default configuration, changed by adjust, for the rest of the test.
+// The background offer generators are held back for an hour so they don't change the games
+// a test is looking at.
+func testConfig(t testing.TB, adjust func(config *Config)) *Config {
+	t.Helper()
+	previous := appConfig
+	config := LoadConfig()
+	for _, cadence := range []*OfferCadence{&config.Offers.Jobs, &config.Offers.Apartments, &config.Offers.Stocks, &config.Offers.Other} {
+		cadence.InitialDelaySeconds = 3600
+	}
+	if adjust != nil {
+		adjust(config)
+	}
+	appConfig = config
+	t.Cleanup(func() { appConfig = previous })
+	return config
+}
+
+// newTestManager returns a GameManager with a MockAI and a FakeClock, shut down when the test ends
+func newTestManager(t testing.TB) (*GameManager, *FakeClock) {
+	t.Helper()
+	if appConfig == nil {
+		testConfig(t, nil)
+	}
+	clock := NewFakeClock(testStart)
+	gm := NewGameManagerWithAI(NewMockAI(), clock)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		gm.Shutdown(ctx)
+	})
+	return gm, clock
+}
+
+// newTestGame creates a player's game, set to the given in-game date
+func newTestGame(t testing.TB, gm *GameManager, playerID string, date time.Time) {
+	t.Helper()
+	gm.GetOrCreateGame(playerID, "en", "")
+	gm.withGame(playerID, func(game *GameState) {
+		game.CurrentDate = date
+		game.StartDate = date
+		game.LastSalaryDate = date
+	})
+}
+
+// joinTestNetwork creates a game for playerID invited by inviterID
+func joinTestNetwork(t testing.TB, gm *GameManager, playerID string, inviterID string) {
+	t.Helper()
+	var code string
+	gm.readGame(inviterID, func(inviter *GameState) { code = inviter.InviteCode })
+	if _, err := gm.CreateGameWithInvite(playerID, code, "en"); err != nil {
+		t.Fatalf("%s could not join %s's network: %v", playerID, inviterID, err)
+	}
+}
+
+// doAction runs an action through HandleAction, as the player's HTTP request would, and returns
+// the decoded response
+func doAction(t testing.TB, gm *GameManager, playerID string, action string, data map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	body, _ := json.Marshal(ActionRequest{Action: action, Data: data})
+	rec := httptest.NewRecorder()
+	gm.HandleAction(rec, httptest.NewRequest(http.MethodPost, "/api/action?player_id="+playerID, bytes.NewReader(body)))
+	var result map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("%s %s: undecodable response %q (status %d)", playerID, action, rec.Body.String(), rec.Code)
+	}
+	return result
+}
+
+// playerOffer returns a recurring monthly offer that creatorID sells to their network
+func playerOffer(id string, creatorID string, price float64, expiresAt time.Time) Offer {
+	return Offer{
+		ID:             id,
+		Type:           "other",
+		Title:          "Lawn mowing " + id,
+		Price:          price,
+		IsRecurring:    true,
+		RecurrenceType: "monthly",
+		Category:       CategorySubscription,
+		CreatedBy:      creatorID,
+		ExpiresAt:      expiresAt,
+	}
+}
+
+// newTestConnection returns a player's WebSocket connection without a network connection behind
+// it, for calling processWebSocketAction; what the server sends stays in its send channel
+func newTestConnection(gm *GameManager, playerID string) *wsConnection {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &wsConnection{
+		playerID:   playerID,
+		send:       make(chan []byte, 256),
+		manager:    gm,
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		recentKeys: make(map[string]*seenAction),
+		stateReady: make(chan struct{}, 1),
+	}
+}
+
+// wsActionResult runs an action through processWebSocketAction and returns the result sent back
+func wsActionResult(t testing.TB, gm *GameManager, conn *wsConnection, action string, data map[string]interface{}, idempotencyKey string) map[string]interface{} {
+	t.Helper()
+	gm.processWebSocketAction(conn.playerID, action, data, idempotencyKey, conn)
+	for {
+		select {
+		case frame := <-conn.send:
+			var msg map[string]interface{}
+			if err := json.Unmarshal(frame, &msg); err != nil {
+				t.Fatalf("undecodable WebSocket message %q", frame)
+			}
+			if msg["type"] == "action_result" && msg["action"] == action {
+				result, _ := msg["result"].(map[string]interface{})
+				return result
+			}
+		default:
+			t.Fatalf("no action_result for %s was sent", action)
+			return nil
+		}
+	}
+}
+
+// newTestState returns a game outside any GameManager, set to the given in-game date
+func newTestState(t testing.TB, date time.Time) *GameState {
+	t.Helper()
+	if appConfig == nil {
+		testConfig(t, nil)
+	}
+	game := NewGame("p", "")
+	game.CurrentDate = date
+	game.StartDate = date
+	game.LastSalaryDate = date
+	game.LastNightHealthLossDate = date
+	return game
+}
+
+// eventsOfType returns the game's kept events of the given type, oldest first
+func eventsOfType(game *GameState, eventType string) []Event {
+	var events []Event
+	for _, event := range game.History.Last(game.History.Len()) {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// dialTestConnection returns a player's WebSocket connection backed by a real one to a test
+// server, and the client end of it, both closed when the test ends. Its pumps are not started.
+func dialTestConnection(t testing.TB, gm *GameManager, playerID string) (*wsConnection, *websocket.Conn) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("could not connect: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	
+	wsConn := newTestConnection(gm, playerID)
+	wsConn.conn = <-conns
+	t.Cleanup(func() { wsConn.conn.Close() })
+	return wsConn, client
+}
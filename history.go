@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHistoryCapacity is the number of events kept per player when history.capacity is not set
+const DefaultHistoryCapacity = 500
+
+// maxHistoryInState is how many of the most recent events are sent to clients with the game state
+const maxHistoryInState = 50
+
+// EventHistory is a fixed-capacity ring buffer of game events. Once it is full, adding an
+// event drops the oldest one. It marshals to JSON as a plain array, oldest event first.
+type EventHistory struct {
+	events   []Event
+	start    int // Index of the oldest event once the buffer has wrapped
+	capacity int
+	total    int // Number of events ever added, including dropped ones
+}
+
+// NewEventHistory creates an empty history that keeps at most capacity events
+func NewEventHistory(capacity int) EventHistory {
+	if capacity <= 0 {
+		capacity = DefaultHistoryCapacity
+	}
+	return EventHistory{capacity: capacity}
+}
+
+// Add appends an event, overwriting the oldest one when the history is full
+func (h *EventHistory) Add(event Event) {
+	if h.capacity <= 0 {
+		h.capacity = DefaultHistoryCapacity
+	}
+	h.total++
+	
+	if len(h.events) < h.capacity {
+		h.events = append(h.events, event)
+		return
+	}
+	h.events[h.start] = event
+	h.start = (h.start + 1) % len(h.events)
+}
+
+// Len returns the number of events currently kept
+func (h EventHistory) Len() int {
+	return len(h.events)
+}
+
+// Total returns the number of events ever added, including the ones that were dropped
+func (h EventHistory) Total() int {
+	return h.total
+}
+
+// Last returns the n most recent events, oldest first
+func (h EventHistory) Last(n int) []Event {
+	if n > len(h.events) {
+		n = len(h.events)
+	}
+	if n <= 0 {
+		return []Event{}
+	}
+	
+	events := make([]Event, 0, n)
+	for i := len(h.events) - n; i < len(h.events); i++ {
+		events = append(events, h.events[(h.start+i)%len(h.events)])
+	}
+	return events
+}
+
+// Tail returns a copy holding only the n most recent events, e.g. to limit what is sent to clients
+func (h EventHistory) Tail(n int) EventHistory {
+	return EventHistory{events: h.Last(n), capacity: h.capacity, total: h.total}
+}
+
+// Clone returns a copy that does not share storage with h
+func (h EventHistory) Clone() EventHistory {
+	return h.Tail(len(h.events))
+}
+
+// MarshalJSON encodes the kept events as an array, oldest first
+func (h EventHistory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Last(len(h.events)))
+}
+
+// UnmarshalJSON decodes an array of events, keeping only the most recent ones that fit
+func (h *EventHistory) UnmarshalJSON(data []byte) error {
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return err
+	}
+	
+	*h = NewEventHistory(h.capacity)
+	for _, event := range events {
+		h.Add(event)
+	}
+	return nil
+}
+
+// auditLog receives every game event when history.audit_log is set, so the full history
+// survives even though each player's in-memory history is bounded
+var auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// SetupAuditLog opens the audit log file if one is configured
+func SetupAuditLog(config *Config) error {
+	path := config.History.AuditLog
+	if path == "" {
+		return nil
+	}
+	
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	
+	auditLog.mu.Lock()
+	auditLog.file = file
+	auditLog.mu.Unlock()
+	logInfof("[AUDIT] Writing full event history to %s", path)
+	return nil
+}
+
+// CloseAuditLog flushes and closes the audit log file, after which events are no longer recorded
+func CloseAuditLog() {
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+	
+	if auditLog.file == nil {
+		return
+	}
+	if err := auditLog.file.Sync(); err != nil {
+		logErrorf("[AUDIT] Error syncing audit log: %v", err)
+	}
+	if err := auditLog.file.Close(); err != nil {
+		logErrorf("[AUDIT] Error closing audit log: %v", err)
+	}
+	auditLog.file = nil
+}
+
+// recordAuditEvent appends an event to the audit log as a JSON line; it is a no-op when the audit log is disabled
+func recordAuditEvent(playerID string, event Event) {
+	auditLog.mu.Lock()
+	defer auditLog.mu.Unlock()
+	
+	if auditLog.file == nil {
+		return
+	}
+	
+	line, err := json.Marshal(struct {
+		PlayerID string `json:"player_id"`
+		Event
+	}{playerID, event})
+	if err != nil {
+		logErrorf("[AUDIT] Error marshaling event for player %s: %v", playerID, err)
+		return
+	}
+	if _, err := auditLog.file.Write(append(line, '\n')); err != nil {
+		logErrorf("[AUDIT] Error writing event for player %s: %v", playerID, err)
+	}
+}
+
+// HandleExportHistory downloads every event the player's history still holds, as CSV or JSON
+// (?format=csv|json, default json). The X-History-Total header carries the number of events ever
+// recorded, which is larger than the export when older events have been dropped.
+func (gm *GameManager) HandleExportHistory(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "json"
+	}
+	if format != "csv" && format != "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "format must be csv or json"})
+		return
+	}
+	
+	var events []Event
+	var total int
+	if !gm.readGame(playerID, func(game *GameState) {
+		events = game.History.Last(game.History.Len())
+		total = game.History.Total()
+	}) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("X-History-Total", strconv.Itoa(total))
+	w.Header().Set("Content-Disposition", `attachment; filename="history-`+sanitizeFilename(playerID)+"."+format+`"`)
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(events)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"type", "message", "amount", "timestamp"})
+	for _, event := range events {
+		writer.Write([]string{
+			event.Type,
+			event.Message,
+			strconv.FormatFloat(event.Amount, 'f', 2, 64),
+			event.Timestamp.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logErrorf("[HISTORY] Error writing CSV export for player %s: %v", playerID, err)
+	}
+}
+
+// sanitizeFilename keeps only characters that are safe in a Content-Disposition filename
+func sanitizeFilename(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == "" {
+		return "player"
+	}
+	return safe
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+)
+
+// HospitalTerms are the medical rules of the game's difficulty, sent with the state so the UI
+// can explain them
+type HospitalTerms struct {
+	AdmitHealth     int     `json:"admit_health"`      // Admitted when health falls to this or below
+	ReleaseHealth   int     `json:"release_health"`    // Released once health reaches this
+	RecoveryPerHour int     `json:"recovery_per_hour"` // Health regained per hour in hospital
+	HourlyCost      float64 `json:"hourly_cost"`       // Cost per hour before insurance
+}
+
+// hospitalTerms returns the hospital rules of the game's difficulty
+func (gs *GameState) hospitalTerms() HospitalTerms {
+	difficulty := gs.difficulty()
+	return HospitalTerms{
+		AdmitHealth:     difficulty.HospitalAdmitHealth,
+		ReleaseHealth:   difficulty.HospitalReleaseHealth,
+		RecoveryPerHour: difficulty.HospitalRecoveryPerHour,
+		HourlyCost:      difficulty.HospitalHourlyCost,
+	}
+}
+
+// hospitalHourlyRate returns what the player pays per hour in hospital, after insurance
+func (gs *GameState) hospitalHourlyRate() float64 {
+	cost := gs.hospitalTerms().HourlyCost
+	if gs.InsuranceActive {
+		return cost * (1 - gs.InsuranceCoverage)
+	}
+	return cost
+}
+
+// hospitalReleaseMessage tells the player when they will be let out of hospital
+func (gs *GameState) hospitalReleaseMessage() string {
+	return fmt.Sprintf("You'll be released when your health reaches %d.", gs.hospitalTerms().ReleaseHealth)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// One long advance through a hospital stay charges only the hours below the release health and
+// keeps the health recovered after release
+func TestHospitalStayLargeAdvance(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []time.Duration
+	}{
+		{"one 100-hour advance", []time.Duration{100 * time.Hour}},
+		{"5 hours, then 95", []time.Duration{5 * time.Hour, 95 * time.Hour}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			game.Money = 5000
+			game.Health = 0
+			game.IsInHospital = true
+			game.HospitalEntryTime = game.CurrentDate
+			for _, step := range tt.steps {
+				game.CurrentDate = game.CurrentDate.Add(step)
+				game.processHospitalStay(step)
+			}
+			
+			// Normal difficulty: released at 20 health after 20 hours at €100 each
+			if game.IsInHospital {
+				t.Fatalf("still in hospital at %d health", game.Health)
+			}
+			if game.Money != 5000-2000 {
+				t.Errorf("paid €%.2f, want €2000 for 20 hours", 5000-game.Money)
+			}
+			if game.Health != 100 {
+				t.Errorf("health is %d, want the 100 recovered over 100 hours", game.Health)
+			}
+			releases := eventsOfType(game, "hospital_release")
+			if len(releases) != 1 || !strings.Contains(releases[0].Message, "Total cost: €2000.00") || !strings.Contains(releases[0].Message, "after 0.8 days") {
+				t.Fatalf("got release events %+v, want one for 20 hours costing €2000.00 in all", releases)
+			}
+			var charged float64
+			for _, event := range append(eventsOfType(game, "hospital_stay"), releases...) {
+				charged -= event.Amount
+			}
+			if charged != 2000 {
+				t.Errorf("the events charged €%.2f, want €2000", charged)
+			}
+		})
+	}
+}
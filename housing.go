@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// apartmentCount is how many apartments the player rents, the residence included
+func (gs *GameState) apartmentCount() int {
+	count := len(gs.Properties)
+	if gs.Apartment != nil {
+		count++
+	}
+	return count
+}
+
+// rentedApartments returns the residence, if any, followed by the other apartments. The pointers
+// stay valid until Properties changes.
+func (gs *GameState) rentedApartments() []*Apartment {
+	var apartments []*Apartment
+	if gs.Apartment != nil {
+		apartments = append(apartments, gs.Apartment)
+	}
+	for i := range gs.Properties {
+		apartments = append(apartments, &gs.Properties[i])
+	}
+	return apartments
+}
+
+// findProperty returns the index in Properties of the apartment with the given ID. An apartment
+// the player lives in is not a property: the error says so.
+func (gs *GameState) findProperty(apartmentID string) (int, error) {
+	if gs.Apartment != nil && gs.Apartment.ID == apartmentID {
+		return -1, &GameError{Message: "You live in " + gs.Apartment.Title + ". Only apartments you don't live in can be rented out."}
+	}
+	i := slices.IndexFunc(gs.Properties, func(a Apartment) bool { return a.ID == apartmentID })
+	if i < 0 {
+		return -1, &GameError{Message: "Apartment not found"}
+	}
+	return i, nil
+}
+
+// RentOut sublets an apartment the player rents but doesn't live in. The tenant pays the rent times
+// housing.sublet_rate, fixed when the tenant moves in, on the same days the player's own rent is due.
+func (gs *GameState) RentOut(apartmentID string) error {
+	if err := gs.actionGuard("rent_out"); err != nil {
+		return err
+	}
+	
+	i, err := gs.findProperty(apartmentID)
+	if err != nil {
+		return err
+	}
+	apartment := &gs.Properties[i]
+	if apartment.RentedOut {
+		return &GameError{Message: apartment.Title + " is already rented out"}
+	}
+	if apartment.IsTrickery {
+		// The listing the player paid for was a scam, so there is nothing a tenant would pay for
+		return &GameError{Message: "No tenant wants to rent " + apartment.Title + " from you"}
+	}
+	
+	apartment.RentedOut = true
+	apartment.SubletRent = apartment.Rent * GetConfig().Housing.SubletRate
+	gs.addEvent("apartment_rented_out", fmt.Sprintf("Rented out %s: the tenant pays %s, you pay %s", apartment.Title,
+		paymentTerms(apartment.SubletRent, apartment.RentFrequency), paymentTerms(apartment.Rent, apartment.RentFrequency)), 0)
+	return nil
+}
+
+// StopRentingOut ends the sublet of an apartment; the tenant's rent already paid is kept. A
+// player without a home moves into it.
+func (gs *GameState) StopRentingOut(apartmentID string) error {
+	if err := gs.actionGuard("stop_renting_out"); err != nil {
+		return err
+	}
+	
+	i, err := gs.findProperty(apartmentID)
+	if err != nil {
+		return err
+	}
+	apartment := &gs.Properties[i]
+	if !apartment.RentedOut {
+		return &GameError{Message: apartment.Title + " is not rented out"}
+	}
+	
+	apartment.RentedOut = false
+	apartment.SubletRent = 0
+	message := "Stopped renting out " + apartment.Title + ": the tenant moved out"
+	if gs.Apartment == nil {
+		home := *apartment
+		gs.Apartment = &home
+		gs.Properties = slices.Delete(gs.Properties, i, i+1)
+		message += " and you moved in"
+	}
+	gs.addEvent("apartment_tenant_left", message, 0)
+	return nil
+}
+
+// collectSubletRent receives the tenant's rent for an apartment the player rents out
+func (gs *GameState) collectSubletRent(apartment *Apartment) {
+	if !apartment.RentedOut {
+		return
+	}
+	frequency := normalizePayFrequency(apartment.RentFrequency)
+	amount := apartment.SubletRent * payShare(frequency)
+	gs.Money += amount
+	gs.addEvent("sublet_income", "Received "+frequency+" rent from your tenant: €"+formatMoney(amount)+" for "+apartment.Title, amount)
+}
@@ -0,0 +1,205 @@
+package main
+
+import "strings"
+
+// DefaultLanguage is used when a player has no language set
+const DefaultLanguage = "en"
+
+// supportedLanguages maps language codes to the name used in AI prompts
+var supportedLanguages = map[string]string{
+	"en": "English",
+	"es": "Spanish",
+	"de": "German",
+	"fr": "French",
+}
+
+// normalizeLanguage turns values like "de-DE" or "ES" into a supported language code, defaulting to English
+func normalizeLanguage(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if idx := strings.IndexAny(lang, "-_"); idx >= 0 {
+		lang = lang[:idx]
+	}
+	if _, ok := supportedLanguages[lang]; ok {
+		return lang
+	}
+	return DefaultLanguage
+}
+
+// languageInstruction returns the prompt suffix that tells the model which language to answer in
+func languageInstruction(gameState *GameState) string {
+	lang := normalizeLanguage(gameState.Language)
+	if lang == DefaultLanguage {
+		return ""
+	}
+	return "\n\nLANGUAGE: Write all human-readable text (titles, descriptions, messages, questions, reasons) in " +
+		supportedLanguages[lang] + ". Keep JSON keys and enum values (like \"monthly\", \"offer\", \"high\") in English."
+}
+
+// localize translates a hardcoded English string, falling back to the English text if no translation exists
+func localize(lang string, text string) string {
+	lang = normalizeLanguage(lang)
+	if lang == DefaultLanguage {
+		return text
+	}
+	if translated, ok := translations[lang][text]; ok {
+		return translated
+	}
+	return text
+}
+
+// createOfferTip is appended to guide answers that look like "how do I..." questions
+const createOfferTip = "\n\n💡 Tip: You can create offers, agreements, or sell items to other players by describing what you want to offer in the chat! For example: 'I want to sell my laptop for €500' or 'I'm offering a monthly subscription service for €50/month'."
+
+// translations holds localized versions of fallback offers and fixed UI strings, keyed by the English text
+var translations = map[string]map[string]string{
+	"es": {
+		createOfferTip: "\n\n💡 Consejo: ¡Puedes crear ofertas, acuerdos o vender artículos a otros jugadores describiendo lo que quieres ofrecer en el chat! Por ejemplo: 'Quiero vender mi portátil por 500 €' o 'Ofrezco un servicio de suscripción mensual por 50 €/mes'.",
+		// Trickery / good offers
+		"Limited Time: Get Rich Quick Scheme":                                                      "Tiempo limitado: plan para hacerse rico rápido",
+		"Invest now and double your money in 7 days! No risk! (Warning: This is a scam)":           "¡Invierte ahora y duplica tu dinero en 7 días! ¡Sin riesgo! (Aviso: esto es una estafa)",
+		"Get-rich-quick schemes are always scams. Real investments take time and have risks.":      "Los planes para hacerse rico rápido siempre son estafas. Las inversiones reales llevan tiempo y tienen riesgos.",
+		"Diversified Investment Package":                                                           "Paquete de inversión diversificado",
+		"A balanced mix of stocks and bonds with proven track record. 15% annual return expected.": "Una combinación equilibrada de acciones y bonos con historial probado. Rentabilidad anual esperada del 15%.",
+		"Diversified portfolio reduces risk while maintaining growth potential.":                   "Una cartera diversificada reduce el riesgo manteniendo el potencial de crecimiento.",
+		// Stock offers
+		"Established company with strong fundamentals and reliable dividends.": "Empresa consolidada con fundamentos sólidos y dividendos fiables.",
+		"Established company with good financials and stable growth":          "Empresa consolidada con buenas finanzas y crecimiento estable",
+		"New startup with high growth potential but significant risk.":        "Nueva startup con alto potencial de crecimiento pero riesgo significativo.",
+		"New company, high volatility, speculative investment":                "Empresa nueva, alta volatilidad, inversión especulativa",
+		// Other offers
+		"Urgent: Help Transfer Funds from African Prince": "Urgente: ayuda a transferir fondos de un príncipe africano",
+		"I am Prince Abubakar from Nigeria. I need your help to transfer €500,000. Send €2,000 processing fee to receive your share!": "Soy el príncipe Abubakar de Nigeria. Necesito tu ayuda para transferir 500.000 €. ¡Envía 2.000 € de gastos de gestión para recibir tu parte!",
+		"Classic advance fee fraud scam - you'll never see the money": "Estafa clásica de pago por adelantado: nunca verás el dinero",
+		"Charity Donation - Official Partners":                        "Donación benéfica - socios oficiales",
+		"Donate to help children in need. Official partners: UNICEF, Red Cross. Your donation makes a difference!": "Dona para ayudar a niños necesitados. Socios oficiales: UNICEF, Cruz Roja. ¡Tu donación marca la diferencia!",
+		"Legitimate charity donation that helps others": "Donación benéfica legítima que ayuda a otros",
+		"Buy Magical Ring from Traveler":                "Compra un anillo mágico a un viajero",
+		"A mysterious traveler offers you a 'lucky' ring. They say it brings good fortune. Only €50!": "Un viajero misterioso te ofrece un anillo 'de la suerte'. Dice que trae buena fortuna. ¡Solo 50 €!",
+		"Scam - the ring is worthless":  "Estafa: el anillo no vale nada",
+		"Legitimate - could bring luck": "Legítimo: podría traer suerte",
+		"Sell Your Passport":            "Vende tu pasaporte",
+		"A shady character offers €3,000 for your passport. Quick cash, but is it worth it?": "Un personaje sospechoso ofrece 3.000 € por tu pasaporte. Dinero rápido, pero ¿merece la pena?",
+		"Illegal and dangerous - selling identity documents": "Ilegal y peligroso: vender documentos de identidad",
+		"Buy Car from Friend": "Compra el coche de un amigo",
+		"Your friend offers to sell you their old car for €1,500. It's a good deal, but the car might have issues.": "Tu amigo te ofrece su coche viejo por 1.500 €. Es un buen precio, pero el coche podría tener problemas.",
+		"Car breaks down immediately - hidden problems":  "El coche se avería enseguida: problemas ocultos",
+		"Good deal - reliable car from friend":           "Buen trato: coche fiable de un amigo",
+		"Special Offer":                                  "Oferta especial",
+		"An interesting opportunity has come your way.":  "Se te ha presentado una oportunidad interesante.",
+		"Consider carefully before accepting":            "Piénsalo bien antes de aceptar",
+		// Job offers
+		"Work from Home - Make €10,000/month!": "Trabaja desde casa: ¡gana 10.000 €/mes!",
+		"No experience needed! Just pay €500 for training materials and start earning immediately! Commission-based only.": "¡No se necesita experiencia! Solo paga 500 € por los materiales de formación y empieza a ganar de inmediato. Solo a comisión.",
+		"This is a scam - requires upfront payment, commission-only (no guaranteed salary), unrealistic promises": "Es una estafa: exige un pago por adelantado, solo comisiones (sin salario garantizado) y promesas poco realistas",
+		"Software Developer": "Desarrollador de software",
+		"Full-time position with benefits. Competitive salary and growth opportunities.": "Puesto a tiempo completo con beneficios. Salario competitivo y oportunidades de crecimiento.",
+		"Fair salary, reasonable hours, legitimate opportunity": "Salario justo, horario razonable, oportunidad legítima",
+		// Apartment offers
+		"Cozy Studio Apartment":                              "Estudio acogedor",
+		"Nice studio apartment in good location":             "Bonito estudio en buena ubicación",
+		"Fair rent, good health and energy restoration":      "Alquiler justo, buena recuperación de salud y energía",
+		"Luxury Apartment - Great Deal!":                     "Apartamento de lujo: ¡gran oferta!",
+		"Amazing apartment at unbeatable price!":             "¡Apartamento increíble a un precio imbatible!",
+		"Overpriced rent with poor health/energy restoration": "Alquiler excesivo con poca recuperación de salud/energía",
+	},
+	"de": {
+		createOfferTip: "\n\n💡 Tipp: Du kannst Angebote, Vereinbarungen erstellen oder Gegenstände an andere Spieler verkaufen, indem du im Chat beschreibst, was du anbieten möchtest! Zum Beispiel: 'Ich möchte meinen Laptop für 500 € verkaufen' oder 'Ich biete ein Monatsabo für 50 €/Monat an'.",
+		// Trickery / good offers
+		"Limited Time: Get Rich Quick Scheme":                                                      "Nur für kurze Zeit: Schnell-reich-werden-Programm",
+		"Invest now and double your money in 7 days! No risk! (Warning: This is a scam)":           "Jetzt investieren und dein Geld in 7 Tagen verdoppeln! Kein Risiko! (Warnung: Das ist Betrug)",
+		"Get-rich-quick schemes are always scams. Real investments take time and have risks.":      "Schnell-reich-werden-Programme sind immer Betrug. Echte Investitionen brauchen Zeit und haben Risiken.",
+		"Diversified Investment Package":                                                           "Diversifiziertes Anlagepaket",
+		"A balanced mix of stocks and bonds with proven track record. 15% annual return expected.": "Eine ausgewogene Mischung aus Aktien und Anleihen mit nachweisbarer Erfolgsbilanz. 15 % erwartete Jahresrendite.",
+		"Diversified portfolio reduces risk while maintaining growth potential.":                   "Ein diversifiziertes Portfolio senkt das Risiko und erhält das Wachstumspotenzial.",
+		// Stock offers
+		"Established company with strong fundamentals and reliable dividends.": "Etabliertes Unternehmen mit soliden Fundamentaldaten und verlässlichen Dividenden.",
+		"Established company with good financials and stable growth":          "Etabliertes Unternehmen mit guten Finanzen und stabilem Wachstum",
+		"New startup with high growth potential but significant risk.":        "Neues Startup mit hohem Wachstumspotenzial, aber erheblichem Risiko.",
+		"New company, high volatility, speculative investment":                "Neues Unternehmen, hohe Volatilität, spekulative Anlage",
+		// Other offers
+		"Urgent: Help Transfer Funds from African Prince": "Dringend: Hilf einem afrikanischen Prinzen, Geld zu überweisen",
+		"I am Prince Abubakar from Nigeria. I need your help to transfer €500,000. Send €2,000 processing fee to receive your share!": "Ich bin Prinz Abubakar aus Nigeria. Ich brauche deine Hilfe, um 500.000 € zu überweisen. Sende 2.000 € Bearbeitungsgebühr, um deinen Anteil zu erhalten!",
+		"Classic advance fee fraud scam - you'll never see the money": "Klassischer Vorschussbetrug – du wirst das Geld nie sehen",
+		"Charity Donation - Official Partners":                        "Spende – offizielle Partner",
+		"Donate to help children in need. Official partners: UNICEF, Red Cross. Your donation makes a difference!": "Spende für bedürftige Kinder. Offizielle Partner: UNICEF, Rotes Kreuz. Deine Spende macht einen Unterschied!",
+		"Legitimate charity donation that helps others": "Seriöse Spende, die anderen hilft",
+		"Buy Magical Ring from Traveler":                "Kaufe einen magischen Ring von einem Reisenden",
+		"A mysterious traveler offers you a 'lucky' ring. They say it brings good fortune. Only €50!": "Ein geheimnisvoller Reisender bietet dir einen 'Glücksring' an. Er soll Glück bringen. Nur 50 €!",
+		"Scam - the ring is worthless":  "Betrug – der Ring ist wertlos",
+		"Legitimate - could bring luck": "Seriös – könnte Glück bringen",
+		"Sell Your Passport":            "Verkaufe deinen Reisepass",
+		"A shady character offers €3,000 for your passport. Quick cash, but is it worth it?": "Eine zwielichtige Gestalt bietet 3.000 € für deinen Reisepass. Schnelles Geld, aber lohnt es sich?",
+		"Illegal and dangerous - selling identity documents": "Illegal und gefährlich – Verkauf von Ausweisdokumenten",
+		"Buy Car from Friend": "Kaufe das Auto eines Freundes",
+		"Your friend offers to sell you their old car for €1,500. It's a good deal, but the car might have issues.": "Ein Freund bietet dir sein altes Auto für 1.500 € an. Ein gutes Angebot, aber das Auto könnte Mängel haben.",
+		"Car breaks down immediately - hidden problems":  "Das Auto geht sofort kaputt – versteckte Mängel",
+		"Good deal - reliable car from friend":           "Gutes Geschäft – zuverlässiges Auto vom Freund",
+		"Special Offer":                                  "Sonderangebot",
+		"An interesting opportunity has come your way.":  "Eine interessante Gelegenheit bietet sich dir.",
+		"Consider carefully before accepting":            "Überlege gut, bevor du annimmst",
+		// Job offers
+		"Work from Home - Make €10,000/month!": "Arbeiten von zu Hause – verdiene 10.000 €/Monat!",
+		"No experience needed! Just pay €500 for training materials and start earning immediately! Commission-based only.": "Keine Erfahrung nötig! Zahle nur 500 € für Schulungsmaterial und verdiene sofort! Nur auf Provisionsbasis.",
+		"This is a scam - requires upfront payment, commission-only (no guaranteed salary), unrealistic promises": "Das ist Betrug – Vorauszahlung, nur Provision (kein garantiertes Gehalt), unrealistische Versprechen",
+		"Software Developer": "Softwareentwickler",
+		"Full-time position with benefits. Competitive salary and growth opportunities.": "Vollzeitstelle mit Zusatzleistungen. Wettbewerbsfähiges Gehalt und Entwicklungsmöglichkeiten.",
+		"Fair salary, reasonable hours, legitimate opportunity": "Faires Gehalt, angemessene Arbeitszeiten, seriöse Gelegenheit",
+		// Apartment offers
+		"Cozy Studio Apartment":                              "Gemütliches Studio-Apartment",
+		"Nice studio apartment in good location":             "Schönes Studio-Apartment in guter Lage",
+		"Fair rent, good health and energy restoration":      "Faire Miete, gute Erholung von Gesundheit und Energie",
+		"Luxury Apartment - Great Deal!":                     "Luxuswohnung – tolles Angebot!",
+		"Amazing apartment at unbeatable price!":             "Fantastische Wohnung zum unschlagbaren Preis!",
+		"Overpriced rent with poor health/energy restoration": "Überteuerte Miete mit schlechter Erholung von Gesundheit/Energie",
+	},
+	"fr": {
+		createOfferTip: "\n\n💡 Astuce : vous pouvez créer des offres, des contrats ou vendre des objets à d'autres joueurs en décrivant ce que vous voulez proposer dans le chat ! Par exemple : 'Je veux vendre mon ordinateur portable pour 500 €' ou 'Je propose un abonnement mensuel à 50 €/mois'.",
+		// Trickery / good offers
+		"Limited Time: Get Rich Quick Scheme":                                                      "Durée limitée : plan pour devenir riche rapidement",
+		"Invest now and double your money in 7 days! No risk! (Warning: This is a scam)":           "Investissez maintenant et doublez votre argent en 7 jours ! Sans risque ! (Attention : c'est une arnaque)",
+		"Get-rich-quick schemes are always scams. Real investments take time and have risks.":      "Les plans pour devenir riche rapidement sont toujours des arnaques. Les vrais investissements prennent du temps et comportent des risques.",
+		"Diversified Investment Package":                                                           "Offre d'investissement diversifiée",
+		"A balanced mix of stocks and bonds with proven track record. 15% annual return expected.": "Un mélange équilibré d'actions et d'obligations aux résultats éprouvés. Rendement annuel attendu de 15 %.",
+		"Diversified portfolio reduces risk while maintaining growth potential.":                   "Un portefeuille diversifié réduit le risque tout en conservant un potentiel de croissance.",
+		// Stock offers
+		"Established company with strong fundamentals and reliable dividends.": "Entreprise établie aux fondamentaux solides et aux dividendes fiables.",
+		"Established company with good financials and stable growth":          "Entreprise établie aux bonnes finances et à la croissance stable",
+		"New startup with high growth potential but significant risk.":        "Nouvelle startup à fort potentiel de croissance mais au risque important.",
+		"New company, high volatility, speculative investment":                "Entreprise récente, forte volatilité, investissement spéculatif",
+		// Other offers
+		"Urgent: Help Transfer Funds from African Prince": "Urgent : aidez un prince africain à transférer des fonds",
+		"I am Prince Abubakar from Nigeria. I need your help to transfer €500,000. Send €2,000 processing fee to receive your share!": "Je suis le prince Abubakar du Nigeria. J'ai besoin de votre aide pour transférer 500 000 €. Envoyez 2 000 € de frais de dossier pour recevoir votre part !",
+		"Classic advance fee fraud scam - you'll never see the money": "Arnaque classique aux frais anticipés : vous ne reverrez jamais l'argent",
+		"Charity Donation - Official Partners":                        "Don caritatif - partenaires officiels",
+		"Donate to help children in need. Official partners: UNICEF, Red Cross. Your donation makes a difference!": "Faites un don pour aider les enfants dans le besoin. Partenaires officiels : UNICEF, Croix-Rouge. Votre don fait la différence !",
+		"Legitimate charity donation that helps others": "Don caritatif légitime qui aide les autres",
+		"Buy Magical Ring from Traveler":                "Achetez une bague magique à un voyageur",
+		"A mysterious traveler offers you a 'lucky' ring. They say it brings good fortune. Only €50!": "Un mystérieux voyageur vous propose une bague 'porte-bonheur'. Elle porterait chance. Seulement 50 € !",
+		"Scam - the ring is worthless":  "Arnaque : la bague ne vaut rien",
+		"Legitimate - could bring luck": "Légitime : pourrait porter chance",
+		"Sell Your Passport":            "Vendez votre passeport",
+		"A shady character offers €3,000 for your passport. Quick cash, but is it worth it?": "Un individu louche propose 3 000 € pour votre passeport. De l'argent facile, mais cela en vaut-il la peine ?",
+		"Illegal and dangerous - selling identity documents": "Illégal et dangereux : vente de papiers d'identité",
+		"Buy Car from Friend": "Achetez la voiture d'un ami",
+		"Your friend offers to sell you their old car for €1,500. It's a good deal, but the car might have issues.": "Un ami vous propose sa vieille voiture pour 1 500 €. C'est une bonne affaire, mais la voiture pourrait avoir des problèmes.",
+		"Car breaks down immediately - hidden problems":  "La voiture tombe en panne aussitôt : vices cachés",
+		"Good deal - reliable car from friend":           "Bonne affaire : voiture fiable d'un ami",
+		"Special Offer":                                  "Offre spéciale",
+		"An interesting opportunity has come your way.":  "Une opportunité intéressante se présente à vous.",
+		"Consider carefully before accepting":            "Réfléchissez bien avant d'accepter",
+		// Job offers
+		"Work from Home - Make €10,000/month!": "Travail à domicile : gagnez 10 000 €/mois !",
+		"No experience needed! Just pay €500 for training materials and start earning immediately! Commission-based only.": "Aucune expérience requise ! Payez seulement 500 € de matériel de formation et commencez à gagner tout de suite ! Uniquement à la commission.",
+		"This is a scam - requires upfront payment, commission-only (no guaranteed salary), unrealistic promises": "C'est une arnaque : paiement anticipé, uniquement à la commission (pas de salaire garanti), promesses irréalistes",
+		"Software Developer": "Développeur logiciel",
+		"Full-time position with benefits. Competitive salary and growth opportunities.": "Poste à temps plein avec avantages. Salaire compétitif et perspectives d'évolution.",
+		"Fair salary, reasonable hours, legitimate opportunity": "Salaire juste, horaires raisonnables, opportunité légitime",
+		// Apartment offers
+		"Cozy Studio Apartment":                              "Studio confortable",
+		"Nice studio apartment in good location":             "Joli studio bien situé",
+		"Fair rent, good health and energy restoration":      "Loyer correct, bonne récupération de santé et d'énergie",
+		"Luxury Apartment - Great Deal!":                     "Appartement de luxe : super affaire !",
+		"Amazing apartment at unbeatable price!":             "Appartement incroyable à un prix imbattable !",
+		"Overpriced rent with poor health/energy restoration": "Loyer excessif avec une faible récupération de santé/d'énergie",
+	},
+}
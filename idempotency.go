@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// idempotencyWindow is how long a WebSocket connection remembers the idempotency keys of its actions
+const idempotencyWindow = 30 * time.Second
+
+// seenAction is an action remembered under its client-supplied idempotency key
+type seenAction struct {
+	action  string
+	at      time.Time
+	success interface{}
+	message interface{}
+}
+
+// claimIdempotencyKey records key for action at the real time now and returns nil, or returns the
+// earlier action if the key was already used within idempotencyWindow. Actions are processed one
+// at a time by the connection's readPump, so the keys need no lock.
+func (c *wsConnection) claimIdempotencyKey(key string, action string, now time.Time) *seenAction {
+	for k, seen := range c.recentKeys {
+		if now.Sub(seen.at) > idempotencyWindow {
+			delete(c.recentKeys, k)
+		}
+	}
+	if seen, exists := c.recentKeys[key]; exists {
+		return seen
+	}
+	if c.recentKeys == nil {
+		c.recentKeys = make(map[string]*seenAction)
+	}
+	c.recentKeys[key] = &seenAction{action: action, at: now}
+	return nil
+}
+
+// rememberResult stores the outcome of the action claimed under key, for answering repeats
+func (c *wsConnection) rememberResult(key string, result map[string]interface{}) {
+	seen, exists := c.recentKeys[key]
+	if !exists || result == nil {
+		return
+	}
+	seen.success = result["success"]
+	seen.message = result["message"]
+}
+
+// sendDuplicateResult answers a repeated action with the outcome of the first one, without running it again
+func (c *wsConnection) sendDuplicateResult(action string, key string, seen *seenAction) {
+	result := map[string]interface{}{
+		"success":   seen.success,
+		"message":   seen.message,
+		"duplicate": true,
+	}
+	if seen.action != action {
+		result["success"] = false
+		result["message"] = "This request was already used for another action"
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"type":            "action_result",
+		"action":          action,
+		"idempotency_key": key,
+		"result":          result,
+	})
+	if !c.trySend(data) {
+		logWarnf("[PROCESS_ACTION] Failed to send duplicate %s result (channel full or closed) for player %s", action, c.playerID)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+)
+
+// BuyInsurance takes out health insurance at the configured terms. The first monthly premium is
+// paid right away, the following ones with the salary on the 1st of each month.
+func (gs *GameState) BuyInsurance() error {
+	if err := gs.actionGuard("buy_insurance"); err != nil {
+		return err
+	}
+
+	insurance := GetConfig().Insurance
+
+	gs.Money -= insurance.MonthlyPremium
+	gs.InsuranceActive = true
+	gs.InsurancePremium = insurance.MonthlyPremium
+	gs.InsuranceCoverage = insurance.Coverage
+	gs.addEvent("insurance_bought", fmt.Sprintf("Took out health insurance: €%.2f/month, covers %.0f%% of hospital costs", insurance.MonthlyPremium, insurance.Coverage*100), -insurance.MonthlyPremium)
+	return nil
+}
+
+// CancelInsurance ends the player's health insurance; premiums already paid are not refunded
+func (gs *GameState) CancelInsurance() error {
+	if err := gs.actionGuard("cancel_insurance"); err != nil {
+		return err
+	}
+
+	gs.InsuranceActive = false
+	gs.addEvent("insurance_cancelled", "Cancelled health insurance", 0)
+	return nil
+}
+
+// payInsurancePremium charges the monthly premium; the insurance lapses if it can't be paid
+func (gs *GameState) payInsurancePremium() {
+	if !gs.InsuranceActive {
+		return
+	}
+	if gs.Money < gs.InsurancePremium {
+		gs.InsuranceActive = false
+		gs.addEvent("insurance_lapsed", "Health insurance lapsed: could not pay the €"+formatMoney(gs.InsurancePremium)+" premium", 0)
+		return
+	}
+	gs.Money -= gs.InsurancePremium
+	gs.addEvent("insurance_paid", "Paid health insurance premium: €"+formatMoney(gs.InsurancePremium), -gs.InsurancePremium)
+}
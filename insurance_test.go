@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// An insured player pays only the uncovered share of the same hospital stay
+func TestHospitalStayInsuredAndUninsured(t *testing.T) {
+	tests := []struct {
+		name    string
+		insured bool
+		want    float64
+	}{
+		{"uninsured", false, 10 * 100},
+		{"insured", true, 10 * 100 * (1 - 0.8)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, func(config *Config) {
+				config.Insurance.MonthlyPremium = 150
+				config.Insurance.Coverage = 0.8
+			})
+			game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			game.Money = 5000
+			if tt.insured {
+				if err := game.BuyInsurance(); err != nil {
+					t.Fatalf("could not buy insurance: %v", err)
+				}
+			}
+			game.Health = 10
+			game.IsInHospital = true
+			game.HospitalEntryTime = game.CurrentDate
+			before := game.Money
+			
+			// Normal difficulty releases at 20 health, recovering 1 an hour at €100 an hour
+			game.CurrentDate = game.CurrentDate.Add(10 * time.Hour)
+			game.processHospitalStay(10 * time.Hour)
+			if paid := before - game.Money; paid != tt.want {
+				t.Errorf("paid €%.2f for the stay, want €%.2f", paid, tt.want)
+			}
+			if game.IsInHospital {
+				t.Errorf("still in hospital at %d health", game.Health)
+			}
+		})
+	}
+}
+
+func TestCancelInsuranceEndsCoverage(t *testing.T) {
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	game.Money = 5000
+	if err := game.BuyInsurance(); err != nil {
+		t.Fatalf("could not buy insurance: %v", err)
+	}
+	insured := game.hospitalHourlyRate()
+	if err := game.CancelInsurance(); err != nil {
+		t.Fatalf("could not cancel insurance: %v", err)
+	}
+	if uninsured := game.hospitalHourlyRate(); uninsured <= insured || uninsured != game.hospitalTerms().HourlyCost {
+		t.Errorf("hourly rate is €%.2f after cancelling (€%.2f insured), want the full €%.2f", uninsured, insured, game.hospitalTerms().HourlyCost)
+	}
+}
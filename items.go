@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Item effect frequencies
+const (
+	EffectOnUse   = "on_use"  // Applied when the player uses the item
+	EffectDaily   = "daily"   // Applied every day the player owns the item
+	EffectWeekly  = "weekly"
+	EffectMonthly = "monthly"
+)
+
+// Item categories, each with its own depreciation rate (items.depreciation_per_month)
+const (
+	ItemElectronics = "electronics"
+	ItemAccessories = "accessories"
+	ItemOther       = "other" // Also covers items without a category or with one that has no rate
+)
+
+// daysPerMonth is the length of the month depreciation rates are given for
+const daysPerMonth = 30.0
+
+// defaultDepreciationRates returns the built-in share of value items lose per in-game month
+func defaultDepreciationRates() map[string]float64 {
+	return map[string]float64{
+		ItemElectronics: 0.04,
+		ItemAccessories: 0.01,
+		ItemOther:       0.02,
+	}
+}
+
+// depreciationRate returns the share of its value the item loses per in-game month
+func (item Item) depreciationRate() float64 {
+	rates := GetConfig().Items.DepreciationPerMonth
+	if rate, exists := rates[item.Category]; exists {
+		return rate
+	}
+	return rates[ItemOther]
+}
+
+// depreciate sets the item's market price to its purchase value compounded down by its
+// category's monthly rate for the time it has been owned, and records the value lost. Items
+// bought before depreciation existed start from their market price at the time.
+func (item *Item) depreciate(now time.Time) {
+	if item.PurchaseValue == 0 {
+		item.PurchaseValue = item.MarketPrice
+	}
+	months := max(now.Sub(item.BoughtAt).Hours()/24/daysPerMonth, 0)
+	item.MarketPrice = item.PurchaseValue * math.Pow(1-item.depreciationRate(), months)
+	item.Depreciation = item.PurchaseValue - item.MarketPrice
+}
+
+// depreciateItems brings the market price of everything in the inventory up to the current date
+func (gs *GameState) depreciateItems() {
+	for i := range gs.Inventory {
+		gs.Inventory[i].depreciate(gs.CurrentDate)
+	}
+}
+
+// normalizeEffectFrequency maps an effect frequency from the AI onto a known one, using the item
+// by hand when it is missing or unknown
+func normalizeEffectFrequency(frequency string) string {
+	switch frequency = strings.ToLower(strings.TrimSpace(frequency)); frequency {
+	case EffectDaily, EffectWeekly, EffectMonthly:
+		return frequency
+	default:
+		return EffectOnUse
+	}
+}
+
+// hasEffects reports whether using or owning the item changes any stat
+func (item Item) hasEffects() bool {
+	return item.HealthChange != 0 || item.EnergyChange != 0 || item.ReputationChange != 0 || item.MoneyChange != 0
+}
+
+// nextEffectAt returns when a recurring item's effects are next due, or the zero time for an item
+// that is used by hand
+func (item Item) nextEffectAt() time.Time {
+	last := item.LastEffectAt
+	if last.IsZero() {
+		last = item.BoughtAt
+	}
+	switch item.EffectFrequency {
+	case EffectDaily:
+		return last.AddDate(0, 0, 1)
+	case EffectWeekly:
+		return last.AddDate(0, 0, 7)
+	case EffectMonthly:
+		return last.AddDate(0, 1, 0)
+	}
+	return time.Time{}
+}
+
+// applyStatChanges changes health and energy (kept within 0-100), reputation and money, and
+// describes each change for an event message. source names the offer, agreement or item the
+// changes come from, for the reputation_change event.
+func (gs *GameState) applyStatChanges(source string, health, energy, reputation int, money float64) []string {
+	gs.Health = min(max(gs.Health+health, 0), 100)
+	gs.Energy = min(max(gs.Energy+energy, 0), 100)
+	gs.adjustReputation(reputation, source)
+	gs.Money += money
+
+	var statChanges []string
+	if health != 0 {
+		statChanges = append(statChanges, fmt.Sprintf("Health: %+d", health))
+	}
+	if energy != 0 {
+		statChanges = append(statChanges, fmt.Sprintf("Energy: %+d", energy))
+	}
+	if reputation != 0 {
+		statChanges = append(statChanges, fmt.Sprintf("Reputation: %+d", reputation))
+	}
+	if money != 0 {
+		statChanges = append(statChanges, fmt.Sprintf("Money: %+.2f€", money))
+	}
+	return statChanges
+}
+
+// UseItem applies the stat effects of an item used by hand. A consumable is used up; any other
+// item can be used once per in-game day.
+func (gs *GameState) UseItem(itemID string) error {
+	if err := gs.actionGuard("use_item"); err != nil {
+		return err
+	}
+
+	itemIndex := -1
+	for i, item := range gs.Inventory {
+		if item.ID == itemID {
+			itemIndex = i
+			break
+		}
+	}
+	if itemIndex == -1 {
+		return &GameError{Message: "Item not found in inventory"}
+	}
+
+	item := &gs.Inventory[itemIndex]
+	if !item.hasEffects() {
+		return &GameError{Message: item.Name + " has no effect when used"}
+	}
+	if item.EffectFrequency != EffectOnUse {
+		return &GameError{Message: item.Name + " works by itself (" + item.EffectFrequency + ") while you own it"}
+	}
+	if !item.Consumable && !item.LastEffectAt.IsZero() && gs.CurrentDate.Before(item.LastEffectAt.AddDate(0, 0, 1)) {
+		return &GameError{Message: "You already used " + item.Name + " today"}
+	}
+
+	statChanges := gs.applyStatChanges(item.Name, item.HealthChange, item.EnergyChange, item.ReputationChange, item.MoneyChange)
+	eventMsg := "Used " + item.Name + " - " + strings.Join(statChanges, ", ")
+	moneyChange := item.MoneyChange
+	if item.Consumable {
+		gs.Inventory = append(gs.Inventory[:itemIndex], gs.Inventory[itemIndex+1:]...)
+	} else {
+		item.LastEffectAt = gs.CurrentDate
+	}
+	gs.addEvent("item_used", eventMsg, moneyChange)
+	return nil
+}
+
+// processItemEffects applies the effects of daily, weekly and monthly items once for every period
+// that has passed since they last applied
+func (gs *GameState) processItemEffects() {
+	for i := range gs.Inventory {
+		item := &gs.Inventory[i]
+		if !item.hasEffects() {
+			continue
+		}
+		for due := item.nextEffectAt(); !due.IsZero() && !due.After(gs.CurrentDate); due = item.nextEffectAt() {
+			statChanges := gs.applyStatChanges(item.Name, item.HealthChange, item.EnergyChange, item.ReputationChange, item.MoneyChange)
+			item.LastEffectAt = due
+			gs.addEvent("item_effect", fmt.Sprintf("%s (%s) - %s", item.Name, item.EffectFrequency, strings.Join(statChanges, ", ")), item.MoneyChange)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// An item held across months is worth less every month, at its category's rate, and sells and
+// counts towards net worth at that value
+func TestItemDepreciatesOverMonths(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		rate     float64
+	}{
+		{"electronics", ItemElectronics, 0.04},
+		{"accessories", ItemAccessories, 0.01},
+		{"no category", "", 0.02},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, func(config *Config) { config.Items.DepreciationPerMonth = defaultDepreciationRates() })
+			game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+			game.Apartment = &Apartment{ID: "home", Title: "Flat"}
+			game.Inventory = []Item{{ID: "laptop", Name: "Laptop", BuyPrice: 1000, MarketPrice: 1000, PurchaseValue: 1000, BoughtAt: game.CurrentDate, Category: tt.category}}
+			
+			previous := 1000.0
+			for month := 1; month <= 6; month++ {
+				game.AdvanceTime(daysPerMonth * 24 * time.Hour)
+				item := game.Inventory[0]
+				want := 1000 * math.Pow(1-tt.rate, float64(month))
+				if math.Abs(item.MarketPrice-want) > 0.01 || item.MarketPrice >= previous {
+					t.Fatalf("month %d: worth €%.2f, want €%.2f, less than the €%.2f a month before", month, item.MarketPrice, want, previous)
+				}
+				if math.Abs(item.Depreciation-(1000-want)) > 0.01 {
+					t.Errorf("month %d: recorded €%.2f depreciation, want €%.2f", month, item.Depreciation, 1000-want)
+				}
+				previous = item.MarketPrice
+			}
+			
+			withItem := game.NetWorth()
+			money := game.Money
+			if err := game.SellItem("laptop"); err != nil {
+				t.Fatalf("could not sell: %v", err)
+			}
+			fee := game.tradeFee(previous)
+			if got := game.Money - money; math.Abs(got-(previous-fee)) > 0.01 {
+				t.Errorf("sold for €%.2f, want the depreciated €%.2f less the €%.2f fee", got, previous, fee)
+			}
+			if got := withItem - game.NetWorth(); math.Abs(got-fee) > 0.01 {
+				t.Errorf("net worth fell €%.2f on selling, want only the €%.2f fee: it should have counted the item at its depreciated value", got, fee)
+			}
+		})
+	}
+}
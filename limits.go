@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// LimitRequestBodies caps request bodies at server.max_request_bytes. A request that declares a
+// larger body is refused with 413 right away; a body that turns out larger makes the handler's
+// decode fail, which requestTooLarge turns into a 413 as well.
+func LimitRequestBodies(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := GetConfig().Server.MaxRequestBytes
+		if r.ContentLength > limit {
+			writeTooLarge(w, limit)
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestTooLarge reports whether err comes from a body over the size limit, and if so writes
+// the 413 response
+func requestTooLarge(w http.ResponseWriter, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	writeTooLarge(w, maxBytesErr.Limit)
+	return true
+}
+
+// writeTooLarge writes a 413 response naming the limit
+func writeTooLarge(w http.ResponseWriter, limit int64) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(map[string]string{"error": fmt.Sprintf("request body is larger than %d bytes", limit)})
+}
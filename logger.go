@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// logLevel is shared by the handler so verbosity can be changed after startup
+var logLevel = new(slog.LevelVar)
+
+// logger is the process-wide leveled logger; messages tagged "[TAG] ..." get the tag as a separate field
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel}))
+
+// SetupLogging applies the configured level and format. LOG_LEVEL is already folded into config by LoadConfig.
+func SetupLogging(config *Config) {
+	level, ok := parseLogLevel(config.Logging.Level)
+	if !ok {
+		logWarnf("[LOG] Unknown log level %q, using info", config.Logging.Level)
+		level = slog.LevelInfo
+	}
+	logLevel.Set(level)
+	
+	opts := &slog.HandlerOptions{Level: logLevel}
+	if strings.EqualFold(config.Logging.Format, "json") {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	} else {
+		logger = slog.New(slog.NewTextHandler(os.Stderr, opts))
+	}
+	// Route any remaining standard library log output through the same handler
+	slog.SetDefault(logger)
+}
+
+// parseLogLevel maps debug/info/warn/error (case-insensitive) to a slog level
+func parseLogLevel(level string) (slog.Level, bool) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "", "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	}
+	return slog.LevelInfo, false
+}
+
+// logEnabled reports whether messages at level would be written; use it to skip expensive trace setup
+func logEnabled(level slog.Level) bool {
+	return logger.Enabled(context.Background(), level)
+}
+
+func logDebugf(format string, args ...interface{}) { logf(slog.LevelDebug, format, args...) }
+func logInfof(format string, args ...interface{})  { logf(slog.LevelInfo, format, args...) }
+func logWarnf(format string, args ...interface{})  { logf(slog.LevelWarn, format, args...) }
+func logErrorf(format string, args ...interface{}) { logf(slog.LevelError, format, args...) }
+
+// logf formats the message only if the level is enabled, so debug traces are free when filtered out
+func logf(level slog.Level, format string, args ...interface{}) {
+	if !logEnabled(level) {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if tag, rest, ok := splitLogTag(msg); ok {
+		logger.Log(context.Background(), level, rest, "tag", tag)
+		return
+	}
+	logger.Log(context.Background(), level, msg)
+}
+
+// splitLogTag separates a leading "[TAG] " from the message
+func splitLogTag(msg string) (string, string, bool) {
+	if !strings.HasPrefix(msg, "[") {
+		return "", msg, false
+	}
+	end := strings.Index(msg, "] ")
+	if end < 0 {
+		return "", msg, false
+	}
+	return msg[1:end], msg[end+2:], true
+}
+
+// Debug trace helpers for lock/goroutine/channel tracking; they are no-ops unless the level is debug
+func logLockAcquire(lockType, playerID string) {
+	if !logEnabled(slog.LevelDebug) {
+		return
+	}
+	_, file, line, _ := runtime.Caller(1)
+	logDebugf("[LOCK_ACQUIRE] %s for player %s at %s:%d", lockType, playerID, file, line)
+}
+
+func logLockRelease(lockType, playerID string) {
+	if !logEnabled(slog.LevelDebug) {
+		return
+	}
+	_, file, line, _ := runtime.Caller(1)
+	logDebugf("[LOCK_RELEASE] %s for player %s at %s:%d", lockType, playerID, file, line)
+}
+
+func logGoroutineStart(name, playerID string) {
+	logDebugf("[GOROUTINE_START] %s for player %s (goroutines: %d)", name, playerID, runtime.NumGoroutine())
+}
+
+func logGoroutineEnd(name, playerID string) {
+	logDebugf("[GOROUTINE_END] %s for player %s (goroutines: %d)", name, playerID, runtime.NumGoroutine())
+}
+
+func logChannelOp(op, playerID string, channelLen, channelCap int) {
+	logDebugf("[CHAN_%s] Player %s (len=%d, cap=%d)", op, playerID, channelLen, channelCap)
+}
+
+func logHTTPRequest(method, path, playerID string) {
+	logDebugf("[HTTP_REQUEST] %s %s for player %s", method, path, playerID)
+}
+
+func logHTTPResponse(method, path, playerID string, statusCode int) {
+	logDebugf("[HTTP_RESPONSE] %s %s for player %s - Status: %d", method, path, playerID, statusCode)
+}
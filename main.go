@@ -1,12 +1,17 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -15,6 +20,21 @@ func main() {
 	
 	// Load configuration from config.json (with env var overrides)
 	config := LoadConfig()
+	SetupLogging(config)
+	if err := SetupAuditLog(config); err != nil {
+		logErrorf("Could not open audit log: %v", err)
+		os.Exit(1)
+	}
+	if err := SetupEncryption(config); err != nil {
+		logErrorf("Could not set up encryption: %v", err)
+		os.Exit(1)
+	}
+	if err := SetupAuth(config); err != nil {
+		logErrorf("Could not set up sessions: %v", err)
+		os.Exit(1)
+	}
+	SetupWebSocket(config)
+	SetupRandom(config)
 	
 	// Initialize game manager
 	gm := NewGameManager()
@@ -22,25 +42,75 @@ func main() {
 	// Setup router
 	r := mux.NewRouter()
 	
+	// Health checks (outside /api so they stay cheap and unauthenticated)
+	r.HandleFunc("/healthz", gm.HandleHealth).Methods("GET")
+	r.HandleFunc("/readyz", gm.HandleReady).Methods("GET")
+	if config.Metrics.Enabled {
+		r.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	}
+	
 	// API routes
 	api := r.PathPrefix("/api").Subrouter()
-	// WebSocket endpoint (primary for real-time updates)
-	api.HandleFunc("/ws", gm.HandleWebSocket)
-	// HTTP endpoints (fallback/compatibility)
-	api.HandleFunc("/state", gm.HandleGetState).Methods("GET")
-	api.HandleFunc("/action", gm.HandleAction).Methods("POST")
-	api.HandleFunc("/offer", gm.HandleGenerateOffer).Methods("GET")
-	api.HandleFunc("/job-offer", gm.HandleGenerateJobOffer).Methods("GET")
-	api.HandleFunc("/chat", gm.HandleChat).Methods("POST")
+	api.Use(AllowCORS, LimitRequestBodies)
+	// Middleware only runs for matched routes, so match every preflight; AllowCORS answers it
+	api.PathPrefix("/").Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	// Public endpoints: login, shared market data and the stateless save encryption
+	api.HandleFunc("/login", gm.HandleLogin).Methods("POST")
 	api.HandleFunc("/market/items", gm.HandleGetMarketItems).Methods("GET")
 	api.HandleFunc("/market/stocks", gm.HandleGetStockSymbols).Methods("GET")
 	api.HandleFunc("/market/crypto", gm.HandleGetCryptoSymbols).Methods("GET")
+	api.HandleFunc("/market/index", gm.HandleGetMarketIndex).Methods("GET")
+	if config.Encryption.Enabled {
+		api.HandleFunc("/encrypt", gm.HandleEncrypt).Methods("POST")
+		api.HandleFunc("/decrypt", gm.HandleDecrypt).Methods("POST")
+	}
+	
+	// Admin endpoints for inspecting all games, only when an admin token is configured
+	if config.Admin.Token != "" {
+		admin := api.PathPrefix("/admin").Subrouter()
+		admin.Use(gm.RequireAdmin)
+		admin.HandleFunc("/games", gm.HandleAdminGames).Methods("GET")
+		admin.HandleFunc("/networks", gm.HandleAdminNetworks).Methods("GET")
+		admin.HandleFunc("/games", gm.HandleAdminRemoveGame).Methods("DELETE")
+		// Read-only WebSocket for watching a player's game, e.g. by an educator; matched before
+		// the player's own /ws below
+		api.Handle("/ws", gm.RequireSpectator(http.HandlerFunc(gm.HandleWebSocket))).Queries("spectator", "true")
+	}
+	
+	// Player endpoints require a session token bound to the player_id
+	player := api.NewRoute().Subrouter()
+	player.Use(gm.RequireSession)
+	// WebSocket endpoint (primary for real-time updates); the token is checked before the upgrade
+	player.HandleFunc("/ws", gm.HandleWebSocket)
+	// HTTP endpoints (fallback/compatibility)
+	player.HandleFunc("/state", gm.HandleGetState).Methods("GET")
+	player.HandleFunc("/action", gm.HandleAction).Methods("POST")
+	player.HandleFunc("/actions/batch", gm.HandleBatchActions).Methods("POST")
+	player.HandleFunc("/actions", gm.HandleAvailableActions).Methods("GET")
+	player.HandleFunc("/offer", gm.HandleGenerateOffer).Methods("GET")
+	player.HandleFunc("/offers", gm.HandleListOffers).Methods("GET")
+	player.HandleFunc("/compare", gm.HandleCompareOffers).Methods("POST")
+	player.HandleFunc("/reset", gm.HandleResetGame).Methods("POST")
+	player.HandleFunc("/my-offers", gm.HandleMyOffers).Methods("GET")
+	player.HandleFunc("/summary", gm.HandleSummary).Methods("GET")
+	player.HandleFunc("/net-worth", gm.HandleNetWorthHistory).Methods("GET")
+	player.HandleFunc("/stocks/history", gm.HandleStockHistory).Methods("GET")
+	player.HandleFunc("/history/export", gm.HandleExportHistory).Methods("GET")
+	// Encrypted save and load of the whole game, only with encryption configured
+	if config.Encryption.Enabled {
+		player.HandleFunc("/state/export", gm.HandleExportState).Methods("GET")
+		player.HandleFunc("/state/verify", gm.HandleVerifyState).Methods("POST")
+		player.HandleFunc("/state/import", gm.HandleImportState).Methods("POST")
+	}
+	player.HandleFunc("/job-offer", gm.HandleGenerateJobOffer).Methods("GET")
+	player.HandleFunc("/chat", gm.HandleChat).Methods("POST")
 	// Multiplayer/Invite endpoints
-	api.HandleFunc("/create-with-invite", gm.HandleCreateWithInvite).Methods("POST")
-	api.HandleFunc("/encrypt", gm.HandleEncrypt).Methods("POST")
-	api.HandleFunc("/decrypt", gm.HandleDecrypt).Methods("POST")
+	player.HandleFunc("/create-with-invite", gm.HandleCreateWithInvite).Methods("POST")
+	player.HandleFunc("/invites", gm.HandleListInvites).Methods("GET")
+	player.HandleFunc("/invites", gm.HandleCreateInvite).Methods("POST")
 	// Offer messaging endpoint (n8n integration)
-	api.HandleFunc("/offer/message", gm.HandleOfferMessage).Methods("POST")
+	player.HandleFunc("/offer/message", gm.HandleOfferMessage).Methods("POST")
+	player.HandleFunc("/offer/messages", gm.HandleOfferMessages).Methods("GET")
 	
 	// Serve static files
 	r.PathPrefix("/").Handler(http.FileServer(http.Dir("./web/")))
@@ -55,7 +125,31 @@ func main() {
 		MaxHeaderBytes: 1 << 20, // 1MB
 	}
 	
-	log.Printf("Server starting on port %s", config.Server.Port)
-	log.Fatal(server.ListenAndServe())
+	// WebSocket connections are hijacked, so Shutdown does not wait for them; close them as it starts
+	server.RegisterOnShutdown(gm.closeWebSockets)
+	
+	stopSignal, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	go func() {
+		logInfof("Server starting on port %s", config.Server.Port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logErrorf("Server stopped: %v", err)
+			os.Exit(1)
+		}
+	}()
+	
+	<-stopSignal.Done()
+	stop()
+	logInfof("Shutting down, waiting up to %s for requests and connections to finish", shutdownTimeout)
+	
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logWarnf("[SHUTDOWN] HTTP server did not stop cleanly: %v", err)
+	}
+	gm.Shutdown(ctx)
+	CloseAuditLog()
+	logInfof("Server stopped")
 }
 
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Market index tuning
+const (
+	marketIndexBase       = 1000.0 // Index value on marketIndexStart
+	marketIndexDailySwing = 0.03   // Normal days move the index by about ±1.5%, with a slight upward drift
+	marketCrashChance     = 0.015  // Chance per day of a crash (-8% to -15%)
+	marketRallyChance     = 0.02   // Chance per day of a rally (+5% to +10%)
+	marketIndexHistory    = 30     // Days of history returned by /api/market/index
+	marketIndexMaxDay     = 200 * 366 // Later dates get this day's value, so a far-off date cannot grow the walk without bound
+)
+
+// marketIndexStart is day zero of the index; earlier dates get the base value
+var marketIndexStart = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// MarketIndex is a market-wide index that moves once per in-game day. Every game on the same
+// in-game date sees the same value, so a crash hits all players at once. Stocks follow it in
+// proportion to their beta on top of their own daily drift.
+type MarketIndex struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	values []float64 // values[i] is the index on marketIndexStart plus i days
+}
+
+// market is the index shared by all games of the GameManager
+var market = NewMarketIndex(time.Now().UnixNano())
+
+// NewMarketIndex creates an index whose random walk is driven by seed
+func NewMarketIndex(seed int64) *MarketIndex {
+	return &MarketIndex{
+		rng:    rand.New(rand.NewSource(seed)),
+		values: []float64{marketIndexBase},
+	}
+}
+
+// marketDay returns the number of whole days between marketIndexStart and date
+func marketDay(date time.Time) int {
+	return min(max(int(date.Sub(marketIndexStart).Hours()/24), 0), marketIndexMaxDay)
+}
+
+// ValueOn returns the index on the in-game date, extending the walk up to that day if needed
+func (m *MarketIndex) ValueOn(date time.Time) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.valueOnDayLocked(marketDay(date))
+}
+
+// valueOnDayLocked returns the index on the given day; m.mu must be held
+func (m *MarketIndex) valueOnDayLocked(day int) float64 {
+	for len(m.values) <= day {
+		previous := m.values[len(m.values)-1]
+		var change float64
+		switch roll := m.rng.Float64(); {
+		case roll < marketCrashChance:
+			change = -0.08 - m.rng.Float64()*0.07
+		case roll < marketCrashChance+marketRallyChance:
+			change = 0.05 + m.rng.Float64()*0.05
+		default:
+			change = (m.rng.Float64() - 0.48) * marketIndexDailySwing
+		}
+		m.values = append(m.values, previous*(1+change))
+	}
+	return m.values[day]
+}
+
+// History returns the index for the days up to and including date, oldest first
+func (m *MarketIndex) History(date time.Time, days int) []MarketIndexPoint {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	last := marketDay(date)
+	first := max(last-days+1, 0)
+	points := make([]MarketIndexPoint, 0, last-first+1)
+	for day := first; day <= last; day++ {
+		points = append(points, MarketIndexPoint{
+			Date:  marketIndexStart.AddDate(0, 0, day),
+			Value: m.valueOnDayLocked(day),
+		})
+	}
+	return points
+}
+
+// MarketIndexPoint is the index value on one in-game day
+type MarketIndexPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// updateMarketIndex records today's index on the game state and warns the player about a crash
+func (gs *GameState) updateMarketIndex() {
+	previous := gs.MarketIndex
+	gs.MarketIndex = market.ValueOn(gs.CurrentDate)
+	if previous > 0 {
+		gs.MarketIndexChange = (gs.MarketIndex/previous - 1) * 100
+		if gs.MarketIndexChange <= -8 {
+			gs.addEvent("market_crash", "📉 Market crash! The index fell "+formatMoney(-gs.MarketIndexChange)+"% and stocks are dragged down with it", 0)
+		}
+	}
+}
+
+// HandleGetMarketIndex returns the market index on an in-game date (?date=YYYY-MM-DD) together
+// with the preceding days, so players can see the broad trend
+func (gm *GameManager) HandleGetMarketIndex(w http.ResponseWriter, r *http.Request) {
+	date, err := time.Parse("2006-01-02", r.URL.Query().Get("date"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "date must be given as YYYY-MM-DD"})
+		return
+	}
+	
+	history := market.History(date, marketIndexHistory)
+	current := history[len(history)-1].Value
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"date":    date,
+		"value":   current,
+		"change":  (current/history[0].Value - 1) * 100, // Percent over the returned history
+		"history": history,
+	})
+}
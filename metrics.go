@@ -0,0 +1,68 @@
+package main
+
+import (
+	"slices"
+	"time"
+	
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Prometheus metrics, exposed on /metrics when Config.Metrics.Enabled is set
+var (
+	metricsActiveGames = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "planc_active_games",
+		Help: "Number of games held in memory.",
+	})
+	metricsWebSocketConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "planc_websocket_connections",
+		Help: "Number of registered WebSocket connections.",
+	})
+	metricsAICalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "planc_ai_calls_total",
+		Help: "AI calls per agent, by result (success, fallback = succeeded on a later provider, failure, cancelled = caller went away).",
+	}, []string{"agent", "result"})
+	metricsOffersGenerated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "planc_offers_generated_total",
+		Help: "Offers produced by the background generators, by offer type and whether they are trickery.",
+	}, []string{"type", "trickery"})
+	metricsOtherOffersAccepted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "planc_other_offers_accepted_total",
+		Help: "Accepted \"other\" offers by category and whether they were trickery, showing which kinds of offer players fall for.",
+	}, []string{"category", "trickery"})
+	metricsActionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "planc_action_duration_seconds",
+		Help:    "HandleAction latency by action.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"action"})
+)
+
+// recordOfferGenerated counts an offer created by one of the generate*ForAllGames loops
+func recordOfferGenerated(offerType string, isTrickery bool) {
+	trickery := "false"
+	if isTrickery {
+		trickery = "true"
+	}
+	metricsOffersGenerated.WithLabelValues(offerType, trickery).Inc()
+}
+
+// recordOtherOfferAccepted counts an accepted "other" offer by its category
+func recordOtherOfferAccepted(category string, isTrickery bool) {
+	trickery := "false"
+	if isTrickery {
+		trickery = "true"
+	}
+	if category == "" {
+		category = "uncategorized"
+	}
+	metricsOtherOffersAccepted.WithLabelValues(category, trickery).Inc()
+}
+
+// recordActionDuration times one action. Actions dispatchAction does not know are recorded as
+// "unknown", so clients cannot create a series per made-up action name.
+func recordActionDuration(action string, elapsed time.Duration) {
+	if !slices.Contains(playerActions, action) {
+		action = "unknown"
+	}
+	metricsActionDuration.WithLabelValues(action).Observe(elapsed.Seconds())
+}
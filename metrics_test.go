@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+)
+
+// Made-up action names are timed under "unknown" instead of getting a series each
+func TestActionDurationLabelsUnknownActions(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "alice", testStart)
+	metricsActionDuration.DeleteLabelValues("unknown")
+	
+	garbage := "drop_table_players_0xdeadbeef"
+	if result := doAction(t, gm, "alice", garbage, nil); result["success"] == true {
+		t.Fatalf("the made-up action %s succeeded", garbage)
+	}
+	if metricsActionDuration.DeleteLabelValues(garbage) {
+		t.Errorf("got a series labelled %q, want it recorded as unknown", garbage)
+	}
+	if !metricsActionDuration.DeleteLabelValues("unknown") {
+		t.Error("no series labelled unknown after a made-up action")
+	}
+	
+	doAction(t, gm, "alice", "rest", map[string]interface{}{"hours": 1})
+	if !metricsActionDuration.DeleteLabelValues("rest") {
+		t.Error("no series labelled rest after resting")
+	}
+}
@@ -12,34 +12,81 @@ type GameState struct {
 	Energy        int       `json:"energy"`        // 0-100
 	CurrentDate   time.Time `json:"current_date"`
 	Job           *Job      `json:"job,omitempty"`
-	Apartment     *Apartment `json:"apartment,omitempty"`
+	Apartment     *Apartment `json:"apartment,omitempty"` // Where the player lives
+	Properties    []Apartment `json:"properties,omitempty"` // Other apartments the player rents, to rent out (see housing.max_apartments)
 	Stocks        []Stock   `json:"stocks"`
 	Crypto        []Crypto  `json:"crypto"`
 	Inventory     []Item    `json:"inventory"`
-	History       []Event   `json:"history"`
+	History       EventHistory `json:"history"` // Bounded; the oldest events are dropped (see history.capacity)
 	ActiveOffers  []Offer   `json:"active_offers"`
 	JobOffers     []JobOffer `json:"job_offers"`
 	ApartmentOffers []ApartmentOffer `json:"apartment_offers"`
 	StockOffers   []StockOffer `json:"stock_offers"`
 	StockHistory  []StockHistory `json:"stock_history"` // Historical stock price data
+	NetWorthHistory []NetWorthPoint `json:"net_worth_history"` // Net worth at the start of each in-game day, thinned out as it grows
 	Agreements    []Agreement `json:"agreements"` // Recurring agreements/subscriptions
 	IsWorking     bool      `json:"is_working"`
 	WorkStartTime time.Time `json:"work_start_time,omitempty"`
 	WorkEndTime   time.Time `json:"work_end_time,omitempty"`
 	LastSalaryDate time.Time `json:"last_salary_date,omitempty"`
+	// Hourly jobs are paid for logged hours: HoursWorkedThisPeriod since the last payday, and the same
+	// hours weighted by overtime and low-energy output, which the pay is based on. Fixed-time jobs
+	// count their scheduled hours in HoursWorkedThisPeriod too, though their salary is flat.
+	HoursWorkedThisPeriod float64   `json:"hours_worked_this_period,omitempty"`
+	PaidHoursThisPeriod   float64   `json:"paid_hours_this_period,omitempty"`
+	HoursWorkedToday      float64   `json:"hours_worked_today,omitempty"` // On WorkedDay, for overtime
+	WorkedDay             time.Time `json:"worked_day,omitempty"`
+	// Days off from a fixed-time job: the shift being skipped, and the starts of the shifts taken off
+	// this month and last (see TakeDayOff)
+	DayOffStart time.Time   `json:"day_off_start,omitempty"`
+	DayOffEnd   time.Time   `json:"day_off_end,omitempty"`
+	DaysOff     []time.Time `json:"days_off,omitempty"`
 	LastNightHealthLossDate time.Time `json:"last_night_health_loss_date,omitempty"` // Track when health was last lost at night
 	// Hospital state
 	IsInHospital  bool      `json:"is_in_hospital"`
 	HospitalEntryTime time.Time `json:"hospital_entry_time,omitempty"`
+	HospitalBill  float64   `json:"hospital_bill,omitempty"` // Charged so far for the current stay
+	Hospital      HospitalTerms `json:"hospital"`               // Medical rules of the difficulty, refreshed as time advances
+	// Health insurance; premium and coverage are fixed when it is bought
+	InsuranceActive   bool    `json:"insurance_active"`
+	InsurancePremium  float64 `json:"insurance_premium,omitempty"`  // Paid on the 1st of each month
+	InsuranceCoverage float64 `json:"insurance_coverage,omitempty"` // Share of hospital costs covered, 0-1
 	// Game over tracking
 	NegativeMoneyStartDate time.Time `json:"negative_money_start_date,omitempty"` // When money first went negative
 	GameOver              bool      `json:"game_over"`
 	GameOverReason        string    `json:"game_over_reason,omitempty"`
+	GameWon               bool      `json:"game_won"`                  // Set once the difficulty's victory goal is reached; play can continue
+	GameWonReason         string    `json:"game_won_reason,omitempty"`
+	StartDate             time.Time `json:"start_date"`                // In-game date the player started (joined, for invited players)
+	// Achievements and the counters behind them
+	Achievements          []Achievement `json:"achievements"`
+	ScamsAvoided          int       `json:"scams_avoided"`   // Scam offers that expired without being accepted or were reported
+	ScamsReported         int       `json:"scams_reported"`  // Scam offers the player correctly reported
+	FalseReports          int       `json:"false_reports"`   // Legitimate offers the player reported as scams
+	ScamsAccepted         int       `json:"scams_accepted"`  // Scam offers the player accepted (unsafe stocks included)
+	NightsHomeless        int       `json:"nights_homeless"` // Nights spent without an apartment
+	RecentScams           []bool    `json:"recent_scams,omitempty"` // Latest scams, oldest first: true if accepted, false if avoided
+	TrickeryFactor        float64   `json:"trickery_factor"` // Multiplier the player's record puts on the difficulty's scam odds (see trickeryFactor)
+	AcceptedOfferIDs      []string  `json:"accepted_offer_ids,omitempty"` // Most recently accepted other offers, to recognize repeated accepts
+	newAchievements       []Achievement // Unlocked since the last takeNewAchievements, for notifications
+	newNews               []StockNews   // Company news since the last takeNews, for notifications
+	newUnpaidCharges      []UnpaidCharge // Agreement charges since the last takeUnpaidCharges that left money below zero
 	// Multiplayer/Invite system
 	InviteCode            string    `json:"invite_code,omitempty"` // This player's invite code
+	InviteExpiresAt       time.Time `json:"invite_expires_at,omitempty"` // When InviteCode stops being accepted (real time)
 	InvitedBy             string    `json:"invited_by,omitempty"`  // Player ID who invited this player
 	IsFirstPlayer         bool      `json:"is_first_player"`       // True if this is the first player (no invite needed)
+	Language              string    `json:"language,omitempty"`    // Language code for AI prompts and messages (defaults to "en")
+	Scenario              string    `json:"scenario,omitempty"`    // Starting-conditions preset the game was created with
+	Difficulty            string    `json:"difficulty,omitempty"`  // Offer tuning table (trickery odds, caps, lifetimes), set by the scenario
+	// Market
+	MarketIndex           float64   `json:"market_index"`        // Market-wide index on CurrentDate (see MarketIndex)
+	MarketIndexChange     float64   `json:"market_index_change"` // Percent change of the index at the last daily update
 	CreatedAt     time.Time `json:"created_at"`
+	
+	rng   *gameRand // Source of the game's randomness; nil uses the global one (see Config.Game.Seed)
+	clock Clock     // Real time for event timestamps; nil uses the system time
+	prices *networkMarket // Stock and crypto prices shared with the player's invite network
 }
 
 // Job represents a job the player can have
@@ -54,6 +101,7 @@ type Job struct {
 	WorkEnd           string  `json:"work_end,omitempty"`   // For fixed_time: "17:00"
 	HealthLossPerHour float64 `json:"health_loss_per_hour"` // Health lost per hour of work (AI-determined)
 	EnergyLossPerHour float64 `json:"energy_loss_per_hour"` // Energy lost per hour of work (AI-determined)
+	PayFrequency      string  `json:"pay_frequency,omitempty"` // "weekly", "biweekly" or "monthly" (the default)
 	IsTrickery        bool    `json:"is_trickery,omitempty"`
 	Reason            string  `json:"reason,omitempty"`
 }
@@ -72,20 +120,33 @@ type JobOffer struct {
 	HealthLossPerHour float64   `json:"health_loss_per_hour"` // Health lost per hour of work (AI-determined)
 	EnergyLossPerHour float64   `json:"energy_loss_per_hour"` // Energy lost per hour of work (AI-determined)
 	UpfrontCost       float64   `json:"upfront_cost,omitempty"` // Upfront cost (training fees, etc.) for scam jobs
+	PayFrequency      string    `json:"pay_frequency,omitempty"` // "weekly", "biweekly" or "monthly" (the default)
 	ExpiresAt         time.Time `json:"expires_at"`
 	IsTrickery        bool      `json:"is_trickery"`
 	Reason            string    `json:"reason,omitempty"`
+	Source            string    `json:"source,omitempty"` // Where the offer came from: "ai", "fallback" or "catalog"
 	HintShown         bool      `json:"hint_shown,omitempty"` // Track if hint was purchased
 	Messages          []string  `json:"messages,omitempty"`   // Messages sent to this offer (for n8n integration)
+	Negotiation       []NegotiationRound `json:"negotiation,omitempty"` // Rounds of messaging and their effect on the price
 }
 
 // Stock represents a stock investment
 type Stock struct {
 	Symbol      string    `json:"symbol"`
 	Shares      int       `json:"shares"`
-	BuyPrice    float64   `json:"buy_price"`
+	BuyPrice    float64   `json:"buy_price"` // Entry price; for a short position, the price the shares were sold at
 	CurrentPrice float64  `json:"current_price"`
 	BoughtAt    time.Time `json:"bought_at"`
+	Beta        float64   `json:"beta,omitempty"`   // How strongly the price follows the market index (1 = in step with it)
+	Short       bool      `json:"short,omitempty"`  // Short position: gains when the price falls
+	Margin      float64   `json:"margin,omitempty"` // Money reserved when the short was opened, returned on cover
+	CompanyName string    `json:"company_name,omitempty"`
+	FailureChance float64 `json:"failure_chance,omitempty"` // From the offer; raises the odds of bad company news
+	NewsFactor  float64   `json:"news_factor,omitempty"`    // Lasting price multiplier from company news (0 = none yet)
+	NewsHint    string    `json:"news_hint,omitempty"`      // Paid tip about the next company news
+	FeesPaid    float64   `json:"fees_paid,omitempty"`      // Purchase fee of the shares still held, counted against profit on sale
+	NextNewsAt    time.Time `json:"-"` // In-game date the next company news breaks
+	NextNewsShock float64   `json:"-"` // Price change that news will cause, as a fraction
 }
 
 // StockOffer represents a stock offer generated by AI
@@ -97,11 +158,14 @@ type StockOffer struct {
 	CurrentPrice    float64   `json:"current_price"`
 	IsSafe          bool      `json:"is_safe"`          // Safe or unsafe stock
 	FailureChance   float64   `json:"failure_chance"`   // 0-100% chance of failure/crash
+	Beta            float64   `json:"beta"`             // Sensitivity to the market index; risky stocks swing harder
 	Reliability     string    `json:"reliability"`      // "high", "medium", "low"
 	Reason          string    `json:"reason,omitempty"` // Why it's safe/unsafe
+	Source          string    `json:"source,omitempty"` // Where the offer came from: "ai", "fallback" or "catalog"
 	HintShown       bool      `json:"hint_shown,omitempty"` // Track if hint was purchased
 	ExpiresAt       time.Time `json:"expires_at"`
 	Messages        []string  `json:"messages,omitempty"`   // Messages sent to this offer (for n8n integration)
+	Negotiation     []NegotiationRound `json:"negotiation,omitempty"` // Rounds of messaging (the share price itself is not negotiable)
 }
 
 // StockHistory represents historical stock price data
@@ -109,7 +173,8 @@ type StockHistory struct {
 	Symbol      string    `json:"symbol"`
 	Price       float64   `json:"price"`
 	Date        time.Time `json:"date"`
-	Event       string    `json:"event,omitempty"` // "buy", "sell", "crash", "surge"
+	Event       string    `json:"event,omitempty"` // "buy", "sell", "short", "crash", "surge", "news"; empty for a daily price
+	Headline    string    `json:"headline,omitempty"` // News headline for "news" events
 }
 
 // Agreement represents a recurring agreement/subscription from an offer
@@ -127,11 +192,25 @@ type Agreement struct {
 	MoneyChange     float64   `json:"money_change,omitempty"`     // Per period (can be negative for subscriptions)
 	IsTrickery      bool      `json:"is_trickery,omitempty"`
 	Reason          string    `json:"reason,omitempty"`
+	Category        string    `json:"category,omitempty"` // Category of the offer it came from
 	// For reciprocal agreements (when creator provides service to buyer)
 	IsReciprocal    bool      `json:"is_reciprocal,omitempty"`    // True if this is a reciprocal agreement for the creator
 	OtherPartyID    string    `json:"other_party_id,omitempty"`   // ID of the other party (buyer or creator)
 	OriginalPrice   float64   `json:"original_price,omitempty"`   // Original price per period (for penalty calculation)
 	Price           float64   `json:"price,omitempty"`            // Price per period (for display and offer creation)
+	LinkID          string    `json:"link_id,omitempty"`          // Shared by a buyer's agreement and the creator's reciprocal one (the sold offer's ID)
+	MissedPayments  int       `json:"missed_payments,omitempty"`  // Charges in a row that left the player's money below zero
+	Suspended       bool      `json:"suspended,omitempty"`        // Stopped after too many missed payments; no longer processed
+}
+
+// UnpaidCharge is an agreement charge the player could not afford, sent as an "agreement_unaffordable" message
+type UnpaidCharge struct {
+	AgreementID    string  `json:"agreement_id"`
+	Title          string  `json:"title"`
+	Amount         float64 `json:"amount"`          // The charge (negative)
+	Money          float64 `json:"money"`           // The player's money after it
+	MissedPayments int     `json:"missed_payments"`
+	Suspended      bool    `json:"suspended"`       // The agreement was suspended because of it
 }
 
 // Crypto represents a cryptocurrency investment
@@ -141,6 +220,7 @@ type Crypto struct {
 	BuyPrice  float64 `json:"buy_price"`
 	CurrentPrice float64 `json:"current_price"`
 	BoughtAt  time.Time `json:"bought_at"`
+	FeesPaid  float64   `json:"fees_paid,omitempty"` // Purchase fee of the amount still held
 }
 
 // Item represents an item in inventory
@@ -150,18 +230,25 @@ type Item struct {
 	BuyPrice        float64   `json:"buy_price"`
 	MarketPrice     float64   `json:"market_price"`
 	BoughtAt        time.Time `json:"bought_at"`
+	Category        string    `json:"category,omitempty"`       // Sets how fast it loses value (items.depreciation_per_month)
+	PurchaseValue   float64   `json:"purchase_value,omitempty"` // Resale value when bought, before depreciation
+	Depreciation    float64   `json:"depreciation,omitempty"`   // Resale value lost since it was bought
+	FeesPaid        float64   `json:"fees_paid,omitempty"` // Purchase fee, counted against the resale
 	// Stat effects (can be positive or negative)
 	HealthChange    int       `json:"health_change,omitempty"`    // Per day or per use
 	EnergyChange    int       `json:"energy_change,omitempty"`    // Per day or per use
 	ReputationChange int      `json:"reputation_change,omitempty"` // Per day or per use
 	MoneyChange     float64   `json:"money_change,omitempty"`     // Per day or per use
 	EffectFrequency string    `json:"effect_frequency,omitempty"` // "daily", "weekly", "monthly", "on_use"
+	Consumable      bool      `json:"consumable,omitempty"`       // Used up by UseItem
+	LastEffectAt    time.Time `json:"last_effect_at,omitempty"`   // In-game date the effects last applied
 }
 
 // Offer represents an AI-generated offer
 type Offer struct {
 	ID          string    `json:"id"`
 	Type        string    `json:"type"` // "trickery", "good", "market", "other"
+	Category    string    `json:"category,omitempty"` // For "other" offers: "scam", "charity", "purchase", "subscription" or "ethical_dilemma"
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Price       float64   `json:"price"`
@@ -178,8 +265,11 @@ type Offer struct {
 	HintShown       bool    `json:"hint_shown,omitempty"`       // Track if hint was purchased for other offers
 	IsRecurring     bool    `json:"is_recurring,omitempty"`     // If true, becomes an Agreement; if false, becomes an Item
 	RecurrenceType  string  `json:"recurrence_type,omitempty"`  // "daily", "weekly", "monthly" for agreements
+	EffectFrequency string  `json:"effect_frequency,omitempty"` // For one-time purchases: when the item's effects apply ("on_use" or "daily")
 	CreatedBy       string  `json:"created_by,omitempty"`       // Player ID who created this offer (for player-created offers)
+	Source          string  `json:"source,omitempty"`           // Where a generated offer came from: "ai", "fallback" or "catalog"
 	Messages        []string `json:"messages,omitempty"`        // Messages sent to this offer (for n8n integration)
+	Negotiation     []NegotiationRound `json:"negotiation,omitempty"` // Rounds of messaging and their effect on the price
 }
 
 // Event represents a game event
@@ -223,8 +313,11 @@ type Apartment struct {
 	Description string  `json:"description"`
 	HealthGain  int     `json:"health_gain"` // Health gained per hour in apartment
 	EnergyGain  int     `json:"energy_gain"` // Energy gained per hour in apartment
+	RentFrequency string `json:"rent_frequency,omitempty"` // "weekly", "biweekly" or "monthly" (the default)
 	IsTrickery  bool    `json:"is_trickery,omitempty"`
 	Reason      string  `json:"reason,omitempty"`
+	RentedOut   bool    `json:"rented_out,omitempty"`  // Sublet to a tenant; only for apartments the player doesn't live in
+	SubletRent  float64 `json:"sublet_rent,omitempty"` // Monthly rent the tenant pays, set by RentOut
 }
 
 // ApartmentOffer represents an apartment offer generated by AI
@@ -236,10 +329,13 @@ type ApartmentOffer struct {
 	Rent        float64   `json:"rent"`        // Monthly rent
 	HealthGain  int       `json:"health_gain"` // Health gained per hour
 	EnergyGain  int       `json:"energy_gain"` // Energy gained per hour
+	RentFrequency string  `json:"rent_frequency,omitempty"` // "weekly", "biweekly" or "monthly" (the default)
 	ExpiresAt   time.Time `json:"expires_at"`
 	IsTrickery  bool      `json:"is_trickery"`
 	Reason      string    `json:"reason,omitempty"`
+	Source      string    `json:"source,omitempty"` // Where the offer came from: "ai", "fallback" or "catalog"
 	HintShown   bool      `json:"hint_shown,omitempty"` // Track if hint was purchased
 	Messages    []string  `json:"messages,omitempty"`   // Messages sent to this offer (for n8n integration)
+	Negotiation []NegotiationRound `json:"negotiation,omitempty"` // Rounds of messaging and their effect on the price
 }
 
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Negotiation limits
+const (
+	maxNegotiationRounds = 5   // Messages a player can send about one offer
+	minNegotiatedShare   = 0.5 // Lowest price a negotiation can reach, as a share of the opening price
+	maxNegotiatedShare   = 1.5 // Highest price a negotiation can reach, as a share of the opening price
+)
+
+// NegotiationRound is one message exchange about an offer and what it did to the price. For job
+// offers the price is the salary, for apartments the rent.
+type NegotiationRound struct {
+	Round    int       `json:"round"`
+	Date     time.Time `json:"date"` // Game date of the round
+	Message  string    `json:"message"`
+	Reply    string    `json:"reply,omitempty"`
+	OldPrice float64   `json:"old_price,omitempty"`
+	NewPrice float64   `json:"new_price,omitempty"`
+}
+
+// proposedPrice returns the price the other side asks for, given the current one: counter_price
+// if set, otherwise the current price moved by price_delta
+func (resp *N8NWebhookResponse) proposedPrice(current float64) (float64, bool) {
+	switch {
+	case resp == nil:
+		return 0, false
+	case resp.CounterPrice != nil:
+		return *resp.CounterPrice, true
+	case resp.PriceDelta != nil:
+		return current + *resp.PriceDelta, true
+	}
+	return 0, false
+}
+
+// negotiableOffer finds an offer's negotiated price and negotiation history. Stock offers have no
+// negotiable price (shares trade at the market price), so price is nil for them.
+func (gs *GameState) negotiableOffer(offerType string, offerID string) (price *float64, rounds *[]NegotiationRound) {
+	switch offerType {
+	case "other":
+		for i := range gs.ActiveOffers {
+			if gs.ActiveOffers[i].ID == offerID {
+				return &gs.ActiveOffers[i].Price, &gs.ActiveOffers[i].Negotiation
+			}
+		}
+	case "job":
+		for i := range gs.JobOffers {
+			if gs.JobOffers[i].ID == offerID {
+				return &gs.JobOffers[i].Salary, &gs.JobOffers[i].Negotiation
+			}
+		}
+	case "apartment":
+		for i := range gs.ApartmentOffers {
+			if gs.ApartmentOffers[i].ID == offerID {
+				return &gs.ApartmentOffers[i].Rent, &gs.ApartmentOffers[i].Negotiation
+			}
+		}
+	case "stock":
+		for i := range gs.StockOffers {
+			if gs.StockOffers[i].ID == offerID {
+				return nil, &gs.StockOffers[i].Negotiation
+			}
+		}
+	}
+	return nil, nil
+}
+
+// negotiationClosed reports whether the offer has used up its negotiation rounds
+func (gs *GameState) negotiationClosed(offerType string, offerID string) bool {
+	_, rounds := gs.negotiableOffer(offerType, offerID)
+	return rounds != nil && len(*rounds) >= maxNegotiationRounds
+}
+
+// negotiate records a round of messaging about an offer and applies the price proposed in the
+// webhook response, kept within minNegotiatedShare-maxNegotiatedShare of the opening price.
+// The caller must hold the game's write lock. It returns nil if the offer is gone.
+func (gs *GameState) negotiate(offerType string, offerID string, message string, resp *N8NWebhookResponse) *NegotiationRound {
+	price, rounds := gs.negotiableOffer(offerType, offerID)
+	if rounds == nil {
+		return nil
+	}
+	
+	round := NegotiationRound{
+		Round:   len(*rounds) + 1,
+		Date:    gs.CurrentDate,
+		Message: message,
+	}
+	if resp != nil && resp.Message != nil {
+		round.Reply = *resp.Message
+	}
+	
+	if price != nil {
+		round.OldPrice = *price
+		round.NewPrice = *price
+		if proposed, ok := resp.proposedPrice(*price); ok {
+			opening := *price
+			if len(*rounds) > 0 {
+				opening = (*rounds)[0].OldPrice
+			}
+			proposed = math.Max(opening*minNegotiatedShare, math.Min(proposed, opening*maxNegotiatedShare))
+			round.NewPrice = math.Round(proposed*100) / 100
+			*price = round.NewPrice
+		}
+		if round.NewPrice != round.OldPrice {
+			gs.addEvent("offer_negotiated", fmt.Sprintf("Negotiated a %s offer from €%.2f to €%.2f (round %d of %d)", offerType, round.OldPrice, round.NewPrice, round.Round, maxNegotiationRounds), 0)
+		}
+	}
+	
+	*rounds = append(*rounds, round)
+	return &round
+}
+
+// findMessageableOffer finds the offer with the given ID among the player's offers of every
+// type. It returns the offer's type and a copy of it, which can be read without the game's lock,
+// or nil if the player has no such offer.
+func (gs *GameState) findMessageableOffer(offerID string) (string, interface{}) {
+	for i := range gs.ActiveOffers {
+		if gs.ActiveOffers[i].ID == offerID {
+			offer := gs.ActiveOffers[i]
+			return "other", &offer
+		}
+	}
+	for i := range gs.JobOffers {
+		if gs.JobOffers[i].ID == offerID {
+			offer := gs.JobOffers[i]
+			return "job", &offer
+		}
+	}
+	for i := range gs.ApartmentOffers {
+		if gs.ApartmentOffers[i].ID == offerID {
+			offer := gs.ApartmentOffers[i]
+			return "apartment", &offer
+		}
+	}
+	for i := range gs.StockOffers {
+		if gs.StockOffers[i].ID == offerID {
+			offer := gs.StockOffers[i]
+			return "stock", &offer
+		}
+	}
+	return "", nil
+}
+
+// applyOfferMessage records a message about an offer and the webhook's reply in the offer's
+// history, and replaces the offer with the webhook's updated one if it sent one. The offer is
+// looked up by ID, since it may have been accepted or expired while the webhook was called;
+// found is false if it is gone and nothing was changed. The caller must hold the game's write lock.
+func (gs *GameState) applyOfferMessage(offerType string, offerID string, message string, resp *N8NWebhookResponse) (found bool, updated bool) {
+	messages := func(current []string) []string {
+		current = append(current, message)
+		if resp != nil && resp.Message != nil {
+			current = append(current, *resp.Message)
+		}
+		return current
+	}
+	
+	switch offerType {
+	case "other":
+		i := slices.IndexFunc(gs.ActiveOffers, func(o Offer) bool { return o.ID == offerID })
+		if i < 0 {
+			return false, false
+		}
+		offer := &gs.ActiveOffers[i]
+		offer.Messages = messages(offer.Messages)
+		if resp != nil && resp.Offer != nil {
+			updatedOffer := *resp.Offer
+			updatedOffer.ID, updatedOffer.Messages, updatedOffer.Negotiation = offer.ID, offer.Messages, offer.Negotiation
+			if updatedOffer.Category == "" {
+				updatedOffer.Category = offer.Category
+			}
+			updatedOffer.Category = normalizeOfferCategory(updatedOffer.Category, updatedOffer.IsTrickery, updatedOffer.IsRecurring)
+			*offer = updatedOffer
+			updated = true
+		}
+	case "job":
+		i := slices.IndexFunc(gs.JobOffers, func(o JobOffer) bool { return o.ID == offerID })
+		if i < 0 {
+			return false, false
+		}
+		offer := &gs.JobOffers[i]
+		offer.Messages = messages(offer.Messages)
+		if resp != nil && resp.JobOffer != nil {
+			updatedOffer := *resp.JobOffer
+			updatedOffer.ID, updatedOffer.Messages, updatedOffer.Negotiation = offer.ID, offer.Messages, offer.Negotiation
+			*offer = updatedOffer
+			updated = true
+		}
+	case "apartment":
+		i := slices.IndexFunc(gs.ApartmentOffers, func(o ApartmentOffer) bool { return o.ID == offerID })
+		if i < 0 {
+			return false, false
+		}
+		offer := &gs.ApartmentOffers[i]
+		offer.Messages = messages(offer.Messages)
+		if resp != nil && resp.ApartmentOffer != nil {
+			updatedOffer := *resp.ApartmentOffer
+			updatedOffer.ID, updatedOffer.Messages, updatedOffer.Negotiation = offer.ID, offer.Messages, offer.Negotiation
+			*offer = updatedOffer
+			updated = true
+		}
+	case "stock":
+		i := slices.IndexFunc(gs.StockOffers, func(o StockOffer) bool { return o.ID == offerID })
+		if i < 0 {
+			return false, false
+		}
+		offer := &gs.StockOffers[i]
+		offer.Messages = messages(offer.Messages)
+		if resp != nil && resp.StockOffer != nil {
+			updatedOffer := *resp.StockOffer
+			updatedOffer.ID, updatedOffer.Messages, updatedOffer.Negotiation = offer.ID, offer.Messages, offer.Negotiation
+			*offer = updatedOffer
+			updated = true
+		}
+	default:
+		return false, false
+	}
+	return true, updated
+}
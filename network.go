@@ -0,0 +1,91 @@
+package main
+
+import "slices"
+
+// networkIndex maps every player to their network's root and every root to its members, root
+// first, so the network helpers do not walk the games map. Joins update it in place; removals,
+// which can move a network's root, drop it to be rebuilt on the next lookup.
+type networkIndex struct {
+	roots   map[string]string   // playerID -> network root player ID
+	members map[string][]string // root player ID -> members in invite order, root first
+}
+
+// buildNetworkIndexUnlocked computes the index from the invite links of every game. Each
+// player without an inviter roots a network holding everyone reachable through their invitees;
+// a player left out by a broken chain is indexed as a network of their own. Caller must hold gm.mu.
+func (gm *GameManager) buildNetworkIndexUnlocked() *networkIndex {
+	index := &networkIndex{roots: make(map[string]string), members: make(map[string][]string)}
+	invitees := make(map[string][]string)
+	var roots []string
+	for pid, entry := range gm.games {
+		if entry.game.IsFirstPlayer || entry.game.InvitedBy == "" {
+			roots = append(roots, pid)
+		} else {
+			invitees[entry.game.InvitedBy] = append(invitees[entry.game.InvitedBy], pid)
+		}
+	}
+	slices.Sort(roots)
+	
+	// Walk each tree breadth-first; the roots map doubles as the visited set
+	for _, root := range roots {
+		members := []string{root}
+		index.roots[root] = root
+		for i := 0; i < len(members); i++ {
+			children := invitees[members[i]]
+			slices.Sort(children)
+			for _, pid := range children {
+				if _, seen := index.roots[pid]; !seen {
+					index.roots[pid] = root
+					members = append(members, pid)
+				}
+			}
+		}
+		index.members[root] = members
+	}
+	for pid := range gm.games {
+		if _, seen := index.roots[pid]; !seen {
+			index.roots[pid] = pid
+			index.members[pid] = []string{pid}
+		}
+	}
+	return index
+}
+
+// networkIndexUnlocked returns the index, rebuilding it if a removal dropped it. Caller must
+// hold gm.mu and networkMu.
+func (gm *GameManager) networkIndexUnlocked() *networkIndex {
+	if gm.network == nil {
+		gm.network = gm.buildNetworkIndexUnlocked()
+	}
+	return gm.network
+}
+
+// indexNewGameUnlocked adds a game just put in the games map to the index: to its inviter's
+// network, or as the root of a new one when inviterID is empty. Caller must hold gm.mu for writing.
+func (gm *GameManager) indexNewGameUnlocked(playerID string, inviterID string) {
+	gm.networkMu.Lock()
+	defer gm.networkMu.Unlock()
+	
+	if gm.network == nil {
+		return // Rebuilt with the new game on the next lookup
+	}
+	root := playerID
+	if inviterID != "" {
+		inviterRoot, indexed := gm.network.roots[inviterID]
+		if !indexed {
+			gm.network = nil
+			return
+		}
+		root = inviterRoot
+	}
+	gm.network.roots[playerID] = root
+	gm.network.members[root] = append(gm.network.members[root], playerID)
+}
+
+// invalidateNetworkIndex drops the index after invite links changed in a way joins do not
+// cover, such as a removed game's invitees moving up. Caller must hold gm.mu for writing.
+func (gm *GameManager) invalidateNetworkIndex() {
+	gm.networkMu.Lock()
+	gm.network = nil
+	gm.networkMu.Unlock()
+}
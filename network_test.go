@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// BenchmarkShareOfferWithNetwork shares a job offer across a large network, looking its members
+// up in the maintained index ("cached") and with the index dropped before every share, so each
+// lookup recomputes membership from all games as before the index ("uncached")
+func BenchmarkShareOfferWithNetwork(b *testing.B) {
+	testConfig(b, func(config *Config) { config.Invites.MaxNetworkSize = 1000 })
+	gm, _ := newTestManager(b)
+	const members, outsiders = 500, 500
+	newTestGame(b, gm, "member-0", testStart)
+	for i := 1; i < members; i++ {
+		joinTestNetwork(b, gm, fmt.Sprintf("member-%d", i), fmt.Sprintf("member-%d", i/2))
+	}
+	for i := 0; i < outsiders; i++ {
+		newTestGame(b, gm, fmt.Sprintf("outsider-%d", i), testStart)
+	}
+	offer := JobOffer{ID: "bench-job", Type: "good", Title: "Benchmark job", Salary: 3000, HoursPerDay: 8, WorkType: "hourly"}
+	
+	for _, mode := range []string{"cached", "uncached"} {
+		b.Run(mode, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if mode == "uncached" {
+					gm.mu.Lock()
+					gm.invalidateNetworkIndex()
+					gm.mu.Unlock()
+				}
+				gm.shareJobOfferWithNetwork("member-0", offer)
+			}
+		})
+	}
+}
+
+// After random joins and removals the incrementally maintained index holds the same networks as
+// one recomputed from the invite links of every game
+func TestNetworkIndexMatchesRecomputation(t *testing.T) {
+	testConfig(t, func(config *Config) { config.Invites.MaxNetworkSize = 1000 })
+	gm, _ := newTestManager(t)
+	rng := rand.New(rand.NewSource(1))
+	var players []string
+	for i := 0; i < 200; i++ {
+		playerID := fmt.Sprintf("player-%d", i)
+		switch roll := rng.Intn(10); {
+		case len(players) == 0 || roll == 0:
+			newTestGame(t, gm, playerID, testStart)
+		case roll == 1 && len(players) > 1:
+			// Removing a player moves their invitees up, which drops the index for a rebuild
+			victim := rng.Intn(len(players))
+			gm.removeGame(players[victim])
+			players = append(players[:victim], players[victim+1:]...)
+			newTestGame(t, gm, playerID, testStart)
+		default:
+			joinTestNetwork(t, gm, playerID, players[rng.Intn(len(players))])
+		}
+		players = append(players, playerID)
+		gm.getNetworkPlayers(playerID) // Keeps an index to update in place on the next join
+		
+		gm.mu.RLock()
+		gm.networkMu.Lock()
+		got, want := gm.network, gm.buildNetworkIndexUnlocked()
+		gm.networkMu.Unlock()
+		gm.mu.RUnlock()
+		if !reflect.DeepEqual(got.roots, want.roots) {
+			t.Fatalf("after %s: index roots %v, recomputed %v", playerID, got.roots, want.roots)
+		}
+		if len(got.members) != len(want.members) {
+			t.Fatalf("after %s: index has %d networks, recomputed %d", playerID, len(got.members), len(want.members))
+		}
+		for root, members := range want.members {
+			indexed := slices.Clone(got.members[root])
+			if len(indexed) == 0 || indexed[0] != root {
+				t.Fatalf("after %s: network %s lists %v, want its root first", playerID, root, indexed)
+			}
+			slices.Sort(indexed)
+			members = slices.Clone(members)
+			slices.Sort(members)
+			if !slices.Equal(indexed, members) {
+				t.Fatalf("after %s: network %s has %v, recomputed %v", playerID, root, indexed, members)
+			}
+		}
+	}
+}
+
+// Removing a player from the middle of an invite chain moves the rest of the chain up to their
+// inviter, so it stays in one network
+func TestRemoveGameMidChainKeepsNetworkConnected(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "a", testStart)
+	joinTestNetwork(t, gm, "b", "a")
+	joinTestNetwork(t, gm, "c", "b")
+	joinTestNetwork(t, gm, "d", "c")
+	
+	if !gm.removeGame("b") {
+		t.Fatal("b's game was not removed")
+	}
+	network := slices.Clone(gm.getNetworkPlayers("a"))
+	slices.Sort(network)
+	if want := []string{"a", "c", "d"}; !slices.Equal(network, want) {
+		t.Errorf("got network %v, want %v", network, want)
+	}
+	for playerID, inviter := range map[string]string{"c": "a", "d": "c"} {
+		gm.readGame(playerID, func(game *GameState) {
+			if game.InvitedBy != inviter {
+				t.Errorf("%s is invited by %q, want %q", playerID, game.InvitedBy, inviter)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// DefaultNetWorthPoints is the number of net worth samples kept per player when
+// history.net_worth_points is not set
+const DefaultNetWorthPoints = 120
+
+// NetWorthPoint is the player's net worth when an in-game day began
+type NetWorthPoint struct {
+	Date     time.Time `json:"date"`
+	NetWorth float64   `json:"net_worth"`
+}
+
+// recordNetWorth samples the net worth once per in-game day, on the first time advance of the
+// day. Once more than history.net_worth_points samples are kept, every other sample of the older
+// half is dropped, so the series keeps covering the whole game with recent days in full detail.
+func (gs *GameState) recordNetWorth() {
+	if n := len(gs.NetWorthHistory); n > 0 && gs.NetWorthHistory[n-1].Date.Format("2006-01-02") == gs.CurrentDate.Format("2006-01-02") {
+		return
+	}
+	gs.NetWorthHistory = append(gs.NetWorthHistory, NetWorthPoint{Date: gs.CurrentDate, NetWorth: gs.NetWorth()})
+	
+	if limit := GetConfig().History.NetWorthPoints; len(gs.NetWorthHistory) > limit {
+		older := len(gs.NetWorthHistory) / 2
+		thinned := gs.NetWorthHistory[:0]
+		for i, point := range gs.NetWorthHistory {
+			if i >= older || i%2 == 0 {
+				thinned = append(thinned, point)
+			}
+		}
+		gs.NetWorthHistory = thinned
+	}
+}
+
+// HandleNetWorthHistory returns the player's current net worth and its daily samples, for charting
+func (gm *GameManager) HandleNetWorthHistory(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	var netWorth float64
+	var history []NetWorthPoint
+	if !gm.readGame(playerID, func(game *GameState) {
+		netWorth = game.NetWorth()
+		history = slices.Clone(game.NetWorthHistory)
+	}) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"net_worth": netWorth,
+		"history":   history,
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// StockNews is a company news event that moved a held stock
+type StockNews struct {
+	Symbol      string    `json:"symbol"`
+	CompanyName string    `json:"company_name"`
+	Headline    string    `json:"headline"`
+	Change      float64   `json:"change"` // Price change caused by the news, in percent
+	Date        time.Time `json:"date"`   // In-game date
+}
+
+// Headline templates; %s is the company name
+var (
+	goodNewsHeadlines = []string{
+		"%s beats earnings expectations",
+		"%s wins a major new contract",
+		"Analysts upgrade %s to a strong buy",
+		"%s announces a breakthrough product",
+	}
+	badNewsHeadlines = []string{
+		"%s misses earnings expectations",
+		"%s under investigation for accounting irregularities",
+		"%s CEO resigns unexpectedly",
+		"%s recalls its flagship product",
+	}
+)
+
+// scheduleNews picks when the next news about the stock's company breaks and how it will move
+// the price. The outcome is decided in advance so a paid tip (ShowNewsHint) can reveal it.
+func (gs *GameState) scheduleNews(stock *Stock) {
+	news := GetConfig().News
+	days := max(gs.rng.ExpFloat64()*news.AverageDays, 1)
+	stock.NextNewsAt = gs.CurrentDate.Add(time.Duration(days * 24 * float64(time.Hour)))
+
+	// Riskier companies are more likely to make bad news
+	badChance := stock.FailureChance / 100
+	if badChance <= 0 {
+		badChance = 0.3
+	}
+	shock := news.MinShock + gs.rng.Float64()*(news.MaxShock-news.MinShock)
+	if gs.rng.Float64() < badChance {
+		shock = -shock
+	}
+	stock.NextNewsShock = shock
+	stock.NewsHint = ""
+}
+
+// applyDueNews breaks the news that is due for held stocks, moving their price for good. Every
+// position in the same company is affected by one event.
+func (gs *GameState) applyDueNews() {
+	if !GetConfig().News.Enabled {
+		return
+	}
+
+	for i := range gs.Stocks {
+		stock := &gs.Stocks[i]
+		if stock.NextNewsAt.IsZero() {
+			gs.scheduleNews(stock)
+			continue
+		}
+		if gs.CurrentDate.Before(stock.NextNewsAt) {
+			continue
+		}
+
+		shock := stock.NextNewsShock
+		company := stock.CompanyName
+		if company == "" {
+			company = stock.Symbol
+		}
+		templates := goodNewsHeadlines
+		if shock < 0 {
+			templates = badNewsHeadlines
+		}
+		headline := fmt.Sprintf(localize(gs.Language, templates[gs.rng.Intn(len(templates))]), company)
+
+		for j := range gs.Stocks {
+			position := &gs.Stocks[j]
+			if position.Symbol != stock.Symbol {
+				continue
+			}
+			if position.NewsFactor == 0 {
+				position.NewsFactor = 1
+			}
+			position.NewsFactor *= 1 + shock
+			position.CurrentPrice *= 1 + shock
+			gs.scheduleNews(position)
+		}
+
+		gs.addStockHistory(StockHistory{
+			Symbol:   stock.Symbol,
+			Price:    stock.CurrentPrice,
+			Date:     gs.CurrentDate,
+			Event:    "news",
+			Headline: headline,
+		})
+		gs.addEvent("stock_news", fmt.Sprintf("📰 %s (%s %+.1f%%)", headline, stock.Symbol, shock*100), 0)
+		gs.newNews = append(gs.newNews, StockNews{
+			Symbol:      stock.Symbol,
+			CompanyName: company,
+			Headline:    headline,
+			Change:      shock * 100,
+			Date:        gs.CurrentDate,
+		})
+	}
+}
+
+// takeNews returns the news that broke since the last call and clears it
+func (gs *GameState) takeNews() []StockNews {
+	news := gs.newNews
+	gs.newNews = nil
+	return news
+}
+
+// ShowNewsHint sells a tip about whether the next news about a held stock will be good or bad
+func (gs *GameState) ShowNewsHint(symbol string) error {
+	news := GetConfig().News
+	if !news.Enabled {
+		return &GameError{Message: "There is no company news in this game"}
+	}
+	if gs.Money < news.HintCost {
+		return &GameError{Message: "Not enough money. Need €" + formatMoney(news.HintCost) + " for a tip"}
+	}
+
+	stockIndex := -1
+	for i, s := range gs.Stocks {
+		if s.Symbol == symbol {
+			stockIndex = i
+			break
+		}
+	}
+
+	if stockIndex == -1 {
+		return &GameError{Message: "You don't hold any " + symbol + " stock"}
+	}
+
+	stock := &gs.Stocks[stockIndex]
+	if stock.NewsHint != "" {
+		return &GameError{Message: "You already have a tip about " + symbol}
+	}
+	if stock.NextNewsAt.IsZero() {
+		gs.scheduleNews(stock)
+	}
+
+	outlook := "good"
+	if stock.NextNewsShock < 0 {
+		outlook = "bad"
+	}
+	days := max(int(math.Ceil(stock.NextNewsAt.Sub(gs.CurrentDate).Hours()/24)), 1)
+	stock.NewsHint = fmt.Sprintf("Insiders expect %s news about %s within %d days", outlook, symbol, days)
+
+	gs.Money -= news.HintCost
+	gs.addEvent("news_hint", "Bought a tip about "+symbol+": "+stock.NewsHint, -news.HintCost)
+	return nil
+}
+
+// sendNews pushes a "news" message per event to the player's WebSocket, if open.
+// Callers must not hold any game lock.
+func (gm *GameManager) sendNews(playerID string, news []StockNews) {
+	for _, item := range news {
+		gm.sendToPlayer(playerID, map[string]interface{}{
+			"type": "news",
+			"news": item,
+		})
+	}
+}
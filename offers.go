@@ -0,0 +1,432 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Offer listing defaults and limits for /api/offers
+const (
+	defaultOfferPageSize = 20
+	maxOfferPageSize     = 100
+)
+
+// Categories of "other" offers, so clients can group them and analytics can tell which kinds
+// of offer players fall for
+const (
+	CategoryScam           = "scam"
+	CategoryCharity        = "charity"
+	CategoryPurchase       = "purchase"
+	CategorySubscription   = "subscription"
+	CategoryEthicalDilemma = "ethical_dilemma"
+)
+
+// offerCategories lists every category in the order they are described to the AI
+var offerCategories = []string{CategoryScam, CategoryCharity, CategoryPurchase, CategorySubscription, CategoryEthicalDilemma}
+
+// normalizeOfferCategory maps a category from the AI onto a known one. When it is missing or
+// unknown the offer is guessed from: trickery is a scam, recurring is a subscription and
+// anything else is a purchase.
+func normalizeOfferCategory(category string, isTrickery, isRecurring bool) string {
+	category = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(category)), " ", "_")
+	if slices.Contains(offerCategories, category) {
+		return category
+	}
+	switch {
+	case isTrickery:
+		return CategoryScam
+	case isRecurring:
+		return CategorySubscription
+	default:
+		return CategoryPurchase
+	}
+}
+
+// OfferView is one offer of any kind, flattened so offers can be filtered and sorted together
+type OfferView struct {
+	OfferType  string      `json:"offer_type"` // "job", "apartment", "stock" or "other"
+	ID         string      `json:"id"`
+	Title      string      `json:"title"`
+	Price      float64     `json:"price"` // Salary, rent, share price or offer price
+	ExpiresAt  time.Time   `json:"expires_at"`
+	IsTrickery bool        `json:"is_trickery"` // For stock offers, true when the stock is unsafe
+	Category   string      `json:"category,omitempty"` // Only set for "other" offers
+	Offer      interface{} `json:"offer"`       // The full offer as sent in the game state
+}
+
+// OfferQuery selects a page of offers. Sort is "expires_at", "price" or "title", with a leading
+// "-" for descending order; pages start at 1.
+type OfferQuery struct {
+	Type       string // Empty or "all" for every type
+	Category   string // Empty for every category; otherwise only "other" offers of this category
+	IsTrickery *bool
+	Sort       string
+	Page       int
+	PageSize   int
+}
+
+// parseOfferQuery reads an OfferQuery from type, category, is_trickery, sort, page and page_size parameters
+func parseOfferQuery(values url.Values) (OfferQuery, error) {
+	query := OfferQuery{
+		Type:     strings.ToLower(values.Get("type")),
+		Sort:     values.Get("sort"),
+		Page:     1,
+		PageSize: defaultOfferPageSize,
+	}
+	
+	switch query.Type {
+	case "", "all", "job", "apartment", "stock", "other":
+	default:
+		return query, errors.New("type must be job, apartment, stock, other or all")
+	}
+	
+	category, err := parseOfferCategory(values)
+	if err != nil {
+		return query, err
+	}
+	query.Category = category
+	
+	switch strings.TrimPrefix(query.Sort, "-") {
+	case "", "expires_at", "price", "title":
+	default:
+		return query, errors.New("sort must be expires_at, price or title, optionally prefixed with -")
+	}
+	
+	if trickery := values.Get("is_trickery"); trickery != "" {
+		isTrickery, err := strconv.ParseBool(trickery)
+		if err != nil {
+			return query, errors.New("is_trickery must be true or false")
+		}
+		query.IsTrickery = &isTrickery
+	}
+	
+	if page := values.Get("page"); page != "" {
+		n, err := strconv.Atoi(page)
+		if err != nil || n < 1 {
+			return query, errors.New("page must be a positive number")
+		}
+		query.Page = n
+	}
+	
+	if pageSize := values.Get("page_size"); pageSize != "" {
+		n, err := strconv.Atoi(pageSize)
+		if err != nil || n < 1 {
+			return query, errors.New("page_size must be a positive number")
+		}
+		query.PageSize = min(n, maxOfferPageSize)
+	}
+	
+	return query, nil
+}
+
+// parseOfferCategory reads the optional category parameter
+func parseOfferCategory(values url.Values) (string, error) {
+	category := strings.ToLower(values.Get("category"))
+	if category != "" && !slices.Contains(offerCategories, category) {
+		return "", errors.New("category must be one of " + strings.Join(offerCategories, ", "))
+	}
+	return category, nil
+}
+
+// filterOffersByCategory returns the offers of the given category in a new slice
+func filterOffersByCategory(offers []Offer, category string) []Offer {
+	filtered := []Offer{}
+	for _, offer := range offers {
+		if offer.Category == category {
+			filtered = append(filtered, offer)
+		}
+	}
+	return filtered
+}
+
+// allOffers returns every offer the player currently sees, unsorted
+func (gs *GameState) allOffers() []OfferView {
+	offers := make([]OfferView, 0, len(gs.JobOffers)+len(gs.ApartmentOffers)+len(gs.StockOffers)+len(gs.ActiveOffers))
+	for _, offer := range gs.JobOffers {
+		offers = append(offers, OfferView{"job", offer.ID, offer.Title, offer.Salary, offer.ExpiresAt, offer.IsTrickery, "", offer})
+	}
+	for _, offer := range gs.ApartmentOffers {
+		offers = append(offers, OfferView{"apartment", offer.ID, offer.Title, offer.Rent, offer.ExpiresAt, offer.IsTrickery, "", offer})
+	}
+	for _, offer := range gs.StockOffers {
+		offers = append(offers, OfferView{"stock", offer.ID, offer.CompanyName, offer.CurrentPrice, offer.ExpiresAt, !offer.IsSafe, "", offer})
+	}
+	for _, offer := range gs.ActiveOffers {
+		offers = append(offers, OfferView{"other", offer.ID, offer.Title, offer.Price, offer.ExpiresAt, offer.IsTrickery, offer.Category, offer})
+	}
+	return offers
+}
+
+// filterOffers applies the query's filters and sort order and returns the requested page
+// together with the number of offers that matched before paging
+func filterOffers(offers []OfferView, query OfferQuery) ([]OfferView, int) {
+	matched := make([]OfferView, 0, len(offers))
+	for _, offer := range offers {
+		if query.Type != "" && query.Type != "all" && offer.OfferType != query.Type {
+			continue
+		}
+		if query.Category != "" && offer.Category != query.Category {
+			continue
+		}
+		if query.IsTrickery != nil && offer.IsTrickery != *query.IsTrickery {
+			continue
+		}
+		matched = append(matched, offer)
+	}
+	
+	field, descending := strings.CutPrefix(query.Sort, "-")
+	slices.SortStableFunc(matched, func(a, b OfferView) int {
+		var order int
+		switch field {
+		case "price":
+			order = cmp.Compare(a.Price, b.Price)
+		case "title":
+			order = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+		default:
+			order = a.ExpiresAt.Compare(b.ExpiresAt)
+		}
+		if descending {
+			return -order
+		}
+		return order
+	})
+	
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultOfferPageSize
+	}
+	start := (max(query.Page, 1) - 1) * pageSize
+	if start >= len(matched) {
+		return []OfferView{}, len(matched)
+	}
+	end := min(start+pageSize, len(matched))
+	return matched[start:end], len(matched)
+}
+
+// HandleListOffers returns a filtered, sorted page of the player's offers so clients can
+// lazy-load them instead of relying on the full lists in every state update
+func (gm *GameManager) HandleListOffers(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	query, err := parseOfferQuery(r.URL.Query())
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		return
+	}
+	
+	found := gm.readGame(playerID, func(game *GameState) {
+		page, total := filterOffers(game.allOffers(), query)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"offers":      page,
+			"total":       total,
+			"page":        query.Page,
+			"page_size":   query.PageSize,
+			"total_pages": (total + query.PageSize - 1) / query.PageSize,
+		})
+	})
+	if !found {
+		http.Error(w, "Game not found", http.StatusNotFound)
+	}
+}
+
+// refreshOffers checks that the player may ask for a new offer of offerType ("job", "apartment",
+// "stock" or "other") now, starts their cooldown and returns the function that generates the
+// offer. The caller must hold the game's write lock and run the function once it is released,
+// since generation calls the AI.
+func (gm *GameManager) refreshOffers(playerID string, game *GameState, offerType string) (func(), error) {
+	difficulty := game.difficulty()
+	var open, limit int
+	var generate func(string, *GameState) bool
+	switch offerType {
+	case "job":
+		open, limit, generate = len(game.JobOffers), difficulty.MaxJobOffers, gm.generateJobOffer
+	case "apartment":
+		open, limit, generate = len(game.ApartmentOffers), difficulty.MaxApartmentOffers, gm.generateApartmentOffer
+	case "stock":
+		open, limit, generate = len(game.StockOffers), difficulty.MaxStockOffers, gm.generateStockOffer
+	case "other":
+		for _, offer := range game.ActiveOffers {
+			if offer.Type == "other" {
+				open++
+			}
+		}
+		limit, generate = difficulty.MaxOtherOffers, gm.generateOtherOffer
+	default:
+		return nil, &GameError{Message: "offer_type must be job, apartment, stock or other"}
+	}
+	if open >= limit {
+		return nil, &GameError{Message: "You already have the most " + offerType + " offers open (" + strconv.Itoa(limit) + "). Wait for some to expire first."}
+	}
+	
+	cooldown := time.Duration(GetConfig().Offers.RefreshCooldownSeconds) * time.Second
+	gm.offerRefreshMu.Lock()
+	wait := cooldown - gm.since(gm.lastOfferRefresh[playerID])
+	if wait > 0 {
+		gm.offerRefreshMu.Unlock()
+		return nil, &GameError{Message: "You can refresh offers again in " + strconv.Itoa(int(wait.Seconds())+1) + " seconds"}
+	}
+	gm.lastOfferRefresh[playerID] = gm.now()
+	gm.offerRefreshMu.Unlock()
+	
+	snapshot := game.snapshot()
+	return func() {
+		if !generate(playerID, snapshot) {
+			logWarnf("[OFFERS] Could not generate a %s offer for %s on request", offerType, playerID)
+		}
+	}, nil
+}
+
+// sharePlayerOffer lists an offer the player created by chat on every game in their network. The
+// player must wait Config.Offers.PlayerOfferCooldownSeconds between offers and may have at most
+// Config.Offers.MaxPlayerOffers open at once; their open offers are counted on their own game,
+// where accepted offers are removed for the whole network and expired ones drop out with time.
+// It returns a *GameError for the player when a limit is hit. Callers must not hold any game lock.
+func (gm *GameManager) sharePlayerOffer(playerID string, offer Offer) error {
+	limits := GetConfig().Offers
+	cooldown := time.Duration(limits.PlayerOfferCooldownSeconds) * time.Second
+	
+	// Claim the cooldown first, so messages sent together cannot all create an offer
+	gm.playerOfferMu.Lock()
+	previous, created := gm.lastPlayerOffer[playerID]
+	if wait := cooldown - gm.since(previous); created && wait > 0 {
+		gm.playerOfferMu.Unlock()
+		return &GameError{Message: "You can create another offer in " + strconv.Itoa(int(wait.Seconds())+1) + " seconds"}
+	}
+	gm.lastPlayerOffer[playerID] = gm.now()
+	gm.playerOfferMu.Unlock()
+	
+	var err error
+	gm.withGames(append(gm.getNetworkPlayers(playerID), playerID), func(games map[string]*GameState) {
+		creator, exists := games[playerID]
+		if !exists {
+			err = &GameError{Message: "Game not found"}
+			return
+		}
+		open := 0
+		for _, o := range creator.ActiveOffers {
+			if o.CreatedBy == playerID {
+				open++
+			}
+		}
+		if open >= limits.MaxPlayerOffers {
+			err = &GameError{Message: "You already have " + strconv.Itoa(open) + " offers open. Wait until one is accepted or expires before creating another."}
+			return
+		}
+		for _, networkGame := range games {
+			networkGame.ActiveOffers = append(networkGame.ActiveOffers, offer)
+		}
+	})
+	
+	if err != nil {
+		// Nothing was created, so give the cooldown back
+		gm.playerOfferMu.Lock()
+		if created {
+			gm.lastPlayerOffer[playerID] = previous
+		} else {
+			delete(gm.lastPlayerOffer, playerID)
+		}
+		gm.playerOfferMu.Unlock()
+	}
+	return err
+}
+
+// claimOfferClose marks a player-created offer as closing ("accepted" or "withdrawn") until it
+// has been removed from every game in the network. It returns false, with how the offer is already
+// closing, if someone got there first, so an offer is sold at most once and never after its
+// creator withdrew it. closingOffersMu is a leaf lock, so game locks may be held.
+func (gm *GameManager) claimOfferClose(offerID string, how string) (string, bool) {
+	gm.closingOffersMu.Lock()
+	defer gm.closingOffersMu.Unlock()
+	if current, closing := gm.closingOffers[offerID]; closing {
+		return current, false
+	}
+	gm.closingOffers[offerID] = how
+	return how, true
+}
+
+// releaseOfferClose forgets a closing offer once it has been removed from the network, or once the
+// close it was claimed for failed
+func (gm *GameManager) releaseOfferClose(offerID string) {
+	gm.closingOffersMu.Lock()
+	defer gm.closingOffersMu.Unlock()
+	delete(gm.closingOffers, offerID)
+}
+
+// CreatedOfferView is an offer the player created by chat, with how it is doing
+type CreatedOfferView struct {
+	Offer         Offer      `json:"offer"`
+	Status        string     `json:"status"` // "open", or "accepted" while the sale is being settled
+	MessageCount  int        `json:"message_count"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"` // Real time of the latest message in its thread
+}
+
+// createdOffers lists the open offers the player created, with their status and messages.
+// The caller must hold the game's lock.
+func (gm *GameManager) createdOffers(game *GameState) []CreatedOfferView {
+	offers := []CreatedOfferView{}
+	for _, offer := range game.ActiveOffers {
+		if offer.CreatedBy != game.PlayerID {
+			continue
+		}
+		view := CreatedOfferView{Offer: offer, Status: "open"}
+		gm.closingOffersMu.Lock()
+		if how, closing := gm.closingOffers[offer.ID]; closing {
+			view.Status = how
+		}
+		gm.closingOffersMu.Unlock()
+		if thread := gm.offerThreadMessages(offer.ID); len(thread) > 0 {
+			view.MessageCount = len(thread)
+			view.LastMessageAt = &thread[len(thread)-1].SentAt
+		}
+		offers = append(offers, view)
+	}
+	return offers
+}
+
+// HandleMyOffers lists the offers the player created by chat that are still open in their network
+func (gm *GameManager) HandleMyOffers(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	found := gm.readGame(playerID, func(game *GameState) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"offers": gm.createdOffers(game)})
+	})
+	if !found {
+		http.Error(w, "Game not found", http.StatusNotFound)
+	}
+}
+
+// withdrawOffer takes back an offer the player created that nobody has accepted. It removes the
+// player's own copy; the caller removes the rest of the network's copies with removeOfferFromNetwork
+// once the game lock is released, then calls releaseOfferClose. Nothing is refunded, since
+// creating an offer costs nothing. The caller must hold the game's write lock.
+func (gm *GameManager) withdrawOffer(game *GameState, offerID string) error {
+	offerIndex := -1
+	for i, offer := range game.ActiveOffers {
+		if offer.ID == offerID && offer.CreatedBy == game.PlayerID {
+			offerIndex = i
+			break
+		}
+	}
+	if offerIndex == -1 {
+		return &GameError{Message: "You have no open offer with that ID"}
+	}
+	if how, ok := gm.claimOfferClose(offerID, "withdrawn"); !ok {
+		return &GameError{Message: "This offer was already " + how + " and can no longer be withdrawn"}
+	}
+	
+	offer := game.ActiveOffers[offerIndex]
+	game.ActiveOffers = append(game.ActiveOffers[:offerIndex], game.ActiveOffers[offerIndex+1:]...)
+	game.addEvent("offer_withdrawn", "Withdrew your offer: "+offer.Title, 0)
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import "math"
+
+// checkGoodOfferValue makes sure a good offer is the deal it claims to be, whatever the AI
+// produced: its real discount (from price and original price) lies within offer_value's
+// min_good_discount and max_good_discount, it costs at most max_good_price_share of the player's
+// money and it has no harmful effects. It reports whether the offer had to be adjusted.
+func (gs *GameState) checkGoodOfferValue(offer *Offer) bool {
+	settings := GetConfig().OfferValue
+	before := *offer
+	
+	if offer.Price <= 0 || math.IsNaN(offer.Price) || math.IsInf(offer.Price, 0) {
+		offer.Price = max(gs.Money*settings.MaxGoodPriceShare, 1)
+	}
+	if maxPrice := gs.Money * settings.MaxGoodPriceShare; maxPrice >= 1 && offer.Price > maxPrice {
+		offer.OriginalPrice *= maxPrice / offer.Price // Keep the discount
+		offer.Price = maxPrice
+	}
+	
+	discount := 0.0
+	if offer.OriginalPrice > 0 && !math.IsInf(offer.OriginalPrice, 0) {
+		discount = (offer.OriginalPrice - offer.Price) / offer.OriginalPrice * 100
+	}
+	discount = min(max(discount, settings.MinGoodDiscount), settings.MaxGoodDiscount)
+	offer.OriginalPrice = offer.Price / (1 - discount/100)
+	offer.Discount = math.Round(discount)
+	
+	offer.HealthChange = max(offer.HealthChange, 0)
+	offer.EnergyChange = max(offer.EnergyChange, 0)
+	offer.ReputationChange = max(offer.ReputationChange, 0)
+	offer.MoneyChange = max(offer.MoneyChange, 0)
+	
+	// Prices within a cent count as unchanged, since the original price is recomputed
+	return math.Abs(before.Price-offer.Price) >= 0.01 || math.Abs(before.OriginalPrice-offer.OriginalPrice) >= 0.01 ||
+		before.Discount != offer.Discount || before.HealthChange != offer.HealthChange || before.EnergyChange != offer.EnergyChange ||
+		before.ReputationChange != offer.ReputationChange || before.MoneyChange != offer.MoneyChange
+}
+
+// checkTrickeryOfferCost makes sure a scam offer leaves the player worse off, whatever the AI
+// produced: it costs at least min_trickery_price_share of the player's money (and at least €1)
+// and none of its effects help them. The advertised discount is left alone, since it is the bait.
+// It reports whether the offer had to be adjusted.
+func (gs *GameState) checkTrickeryOfferCost(offer *Offer) bool {
+	before := *offer
+	
+	minPrice := max(gs.Money*GetConfig().OfferValue.MinTrickeryPriceShare, 1)
+	if offer.Price < minPrice || math.IsNaN(offer.Price) || math.IsInf(offer.Price, 0) {
+		offer.Price = math.Round(minPrice*100) / 100
+	}
+	if offer.OriginalPrice < offer.Price {
+		offer.OriginalPrice = offer.Price
+		offer.Discount = 0
+	}
+	
+	offer.HealthChange = min(offer.HealthChange, 0)
+	offer.EnergyChange = min(offer.EnergyChange, 0)
+	offer.ReputationChange = min(offer.ReputationChange, 0)
+	offer.MoneyChange = min(offer.MoneyChange, 0)
+	
+	return before.Price != offer.Price || before.OriginalPrice != offer.OriginalPrice || before.Discount != offer.Discount ||
+		before.HealthChange != offer.HealthChange || before.EnergyChange != offer.EnergyChange ||
+		before.ReputationChange != offer.ReputationChange || before.MoneyChange != offer.MoneyChange
+}
+
+// checkOfferValue applies checkGoodOfferValue or checkTrickeryOfferCost to a generated offer and
+// logs when the AI's offer had to be adjusted
+func (gs *GameState) checkOfferValue(offer *Offer) {
+	if offer.IsTrickery {
+		if gs.checkTrickeryOfferCost(offer) {
+			logWarnf("[OFFERS] Adjusted scam offer %q so it costs the player: now €%.2f", offer.Title, offer.Price)
+		}
+		return
+	}
+	if gs.checkGoodOfferValue(offer) {
+		logWarnf("[OFFERS] Adjusted good offer %q to real value: now €%.2f instead of €%.2f (%.0f%% off)", offer.Title, offer.Price, offer.OriginalPrice, offer.Discount)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// randomAIOffer returns an offer with the kind of values an AI may produce: prices that are
+// negative, missing, huge or not numbers at all, discounts that do not match them and effects of
+// either sign
+func randomAIOffer(rng *rand.Rand, isTrickery bool) *Offer {
+	prices := []float64{-50, 0, math.NaN(), math.Inf(1), 0.5, 20, 300, 2500, 1e7}
+	effect := func() int { return rng.Intn(21) - 10 }
+	return &Offer{
+		Title:            "Generated offer",
+		Price:            prices[rng.Intn(len(prices))] * (0.5 + rng.Float64()),
+		OriginalPrice:    prices[rng.Intn(len(prices))] * (0.5 + rng.Float64()),
+		Discount:         float64(rng.Intn(120) - 10),
+		IsTrickery:       isTrickery,
+		HealthChange:     effect(),
+		EnergyChange:     effect(),
+		ReputationChange: effect(),
+		MoneyChange:      float64(effect()) * 100,
+	}
+}
+
+// Whatever the AI produces, a checked good offer is a real deal the player can afford and a
+// checked scam offer costs the player without helping them
+func TestCheckOfferValueInvariant(t *testing.T) {
+	settings := testConfig(t, nil).OfferValue
+	rng := rand.New(rand.NewSource(1))
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	ai := &AIClient{}
+	for i := 0; i < 5000; i++ {
+		game.Money = []float64{0, 0.5, 40, 1000, 25000}[rng.Intn(5)] * (0.5 + rng.Float64())
+		isTrickery := rng.Intn(2) == 0
+		offer := randomAIOffer(rng, isTrickery)
+		if i%10 == 0 {
+			// The built-in fallbacks go through the same check
+			offer = ai.generateFallbackGoodOffer(game)
+			if isTrickery {
+				offer = ai.generateFallbackTrickeryOffer(game)
+			}
+		}
+		generated := *offer
+		game.checkOfferValue(offer)
+		
+		if offer.HealthChange != 0 || offer.EnergyChange != 0 || offer.ReputationChange != 0 || offer.MoneyChange != 0 {
+			helps := offer.HealthChange > 0 || offer.EnergyChange > 0 || offer.ReputationChange > 0 || offer.MoneyChange > 0
+			harms := offer.HealthChange < 0 || offer.EnergyChange < 0 || offer.ReputationChange < 0 || offer.MoneyChange < 0
+			if (isTrickery && helps) || (!isTrickery && harms) {
+				t.Fatalf("offer %+v (generated %+v, trickery %v) has effects on the wrong side", *offer, generated, isTrickery)
+			}
+		}
+		if isTrickery {
+			minPrice := max(game.Money*settings.MinTrickeryPriceShare, 1)
+			if !(offer.Price >= minPrice-0.005) || offer.OriginalPrice < offer.Price {
+				t.Fatalf("scam offer %+v (generated %+v) with €%.2f to spend: want a price of at least €%.2f under its original price", *offer, generated, game.Money, minPrice)
+			}
+			continue
+		}
+		if !(offer.Price > 0) || math.IsInf(offer.Price, 0) {
+			t.Fatalf("good offer %+v (generated %+v): want a positive price", *offer, generated)
+		}
+		if maxPrice := game.Money * settings.MaxGoodPriceShare; maxPrice >= 1 && offer.Price > maxPrice+0.005 {
+			t.Fatalf("good offer %+v (generated %+v) costs more than €%.2f of the player's €%.2f", *offer, generated, maxPrice, game.Money)
+		}
+		discount := (offer.OriginalPrice - offer.Price) / offer.OriginalPrice * 100
+		if discount < settings.MinGoodDiscount-1e-6 || discount > settings.MaxGoodDiscount+1e-6 {
+			t.Fatalf("good offer %+v (generated %+v) gives a real discount of %.2f%%, want %v-%v%%", *offer, generated, discount, settings.MinGoodDiscount, settings.MaxGoodDiscount)
+		}
+		if offer.Discount != math.Round(discount) {
+			t.Fatalf("good offer %+v (generated %+v) advertises %v%% off for a real %.2f%%", *offer, generated, offer.Discount, discount)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Payment frequencies for salaries and rent. Salary and Rent are always monthly amounts; weekly
+// and biweekly payments are the matching share of them.
+const (
+	PayWeekly   = "weekly"   // Every Friday
+	PayBiweekly = "biweekly" // Every other Friday
+	PayMonthly  = "monthly"  // On SalaryPaymentDay
+)
+
+// normalizePayFrequency maps a payment frequency from the AI or a save onto a known one,
+// paying monthly when it is missing or unknown
+func normalizePayFrequency(frequency string) string {
+	switch frequency = strings.ToLower(strings.TrimSpace(frequency)); frequency {
+	case PayWeekly, PayBiweekly:
+		return frequency
+	default:
+		return PayMonthly
+	}
+}
+
+// payShare returns the share of a monthly amount paid on each payment day
+func payShare(frequency string) float64 {
+	switch frequency {
+	case PayWeekly:
+		return 12.0 / 52
+	case PayBiweekly:
+		return 12.0 / 26
+	}
+	return 1
+}
+
+// nextPayDay returns the first payment day of the frequency strictly after after. Weekly and
+// biweekly payment days are counted in whole weeks from Friday 7 January 2000, so every game
+// pays on the same Fridays.
+func nextPayDay(frequency string, after time.Time) time.Time {
+	if frequency != PayWeekly && frequency != PayBiweekly {
+		payDay := time.Date(after.Year(), after.Month(), SalaryPaymentDay, 0, 0, 0, 0, after.Location())
+		if !payDay.After(after) {
+			payDay = payDay.AddDate(0, 1, 0)
+		}
+		return payDay
+	}
+	step := 7
+	if frequency == PayBiweekly {
+		step = 14
+	}
+	firstFriday := time.Date(2000, 1, 7, 0, 0, 0, 0, after.Location())
+	days := int(math.Floor(after.Sub(firstFriday).Hours() / 24))
+	payDay := firstFriday.AddDate(0, 0, int(math.Floor(float64(days)/float64(step)))*step)
+	if !payDay.After(after) {
+		payDay = payDay.AddDate(0, 0, step)
+	}
+	return payDay
+}
+
+// scheduledPayment is one recurring payment settled by processSalary
+type scheduledPayment struct {
+	frequency string
+	due       time.Time
+	settle    func(payDay time.Time)
+}
+
+// paySalary pays the job's salary for payDay unless it was already paid. Hourly jobs pay for the
+// hours logged since the last payday instead (see payWorkedHours).
+func (gs *GameState) paySalary(payDay time.Time) {
+	if !payDay.After(gs.LastSalaryDate) {
+		return
+	}
+	frequency := normalizePayFrequency(gs.Job.PayFrequency)
+	if gs.Job.isHourly() {
+		gs.LastSalaryDate = payDay
+		gs.payWorkedHours(frequency + " pay")
+		return
+	}
+	amount := gs.Job.Salary * payShare(frequency)
+	gs.Money += amount
+	gs.LastSalaryDate = payDay
+	message := "Received " + frequency + " salary: €" + formatMoney(amount) + " from " + gs.Job.Title
+	if gs.HoursWorkedThisPeriod > 0 {
+		message += fmt.Sprintf(" (%.1f hours on schedule)", gs.HoursWorkedThisPeriod)
+	}
+	gs.HoursWorkedThisPeriod = 0
+	gs.addEvent("salary", message, amount)
+}
+
+// payRent pays an apartment's rent that is due, after collecting its tenant's rent if it is
+// rented out, or records the missed payment
+func (gs *GameState) payRent(apartment *Apartment) {
+	gs.collectSubletRent(apartment)
+	frequency := normalizePayFrequency(apartment.RentFrequency)
+	amount := apartment.Rent * payShare(frequency)
+	if gs.Money >= amount {
+		gs.Money -= amount
+		gs.addEvent("rent_paid", "Paid "+frequency+" rent: €"+formatMoney(amount)+" for "+apartment.Title, -amount)
+		gs.adjustReputation(GetConfig().Reputation.RentPaid, "paid rent on time")
+	} else {
+		gs.addEvent("rent_failed", "Failed to pay rent: €"+formatMoney(amount)+" for "+apartment.Title+" (Not enough money!)", 0)
+		gs.adjustReputation(GetConfig().Reputation.RentMissed, "missed a rent payment")
+		// Could add logic to evict player if rent not paid
+	}
+}
+
+// paymentTerms describes a monthly amount and how it is paid, for event messages
+func paymentTerms(monthly float64, frequency string) string {
+	frequency = normalizePayFrequency(frequency)
+	if frequency == PayMonthly {
+		return "€" + formatMoney(monthly) + " a month"
+	}
+	return "€" + formatMoney(monthly) + " a month, paid " + frequency
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// Shared price tuning
+const (
+	stockDailySwing  = 0.1  // A stock's own move each day is up to ±5% around its listing price, on top of the market
+	cryptoDailySwing = 0.15 // Daily swing of a coin held from before it was dropped from the catalog
+	cryptoMinListing = 1000.0
+	cryptoMaxListing = 5000.0
+	cryptoListingSpread = 0.2 // A network first sees a coin within ±20% of its catalog base price
+)
+
+// defaultCryptoCoins returns the built-in crypto catalog
+func defaultCryptoCoins() []CryptoCoin {
+	return []CryptoCoin{
+		{Symbol: "BTC", Name: "Bitcoin", BasePrice: 30000, Volatility: 0.08},
+		{Symbol: "ETH", Name: "Ethereum", BasePrice: 2000, Volatility: 0.1},
+		{Symbol: "SOL", Name: "Solana", BasePrice: 100, Volatility: 0.16},
+		{Symbol: "ADA", Name: "Cardano", BasePrice: 0.5, Volatility: 0.14},
+		{Symbol: "DOT", Name: "Polkadot", BasePrice: 7, Volatility: 0.14},
+	}
+}
+
+// findCryptoCoin returns the catalog entry of symbol
+func findCryptoCoin(symbol string) (CryptoCoin, bool) {
+	for _, coin := range GetConfig().Crypto.Coins {
+		if coin.Symbol == symbol {
+			return coin, true
+		}
+	}
+	return CryptoCoin{}, false
+}
+
+// marketListing is the price of one stock or coin in a network's market. The price on any day is
+// the listing price moved by that day's own drift and, for stocks, the market index move since the
+// listing scaled by beta.
+type marketListing struct {
+	price    float64   // Price when the network first traded or was offered the symbol
+	listedOn time.Time // In-game date of price
+	beta     float64
+	drift    float64 // The symbol's own move on day, as a fraction
+	day      int     // marketDay drift was drawn for
+}
+
+// networkMarket holds the stock and crypto prices of one invite network, so everyone in it buys,
+// sells and values a symbol at the same price. Each symbol's drift is drawn once per in-game day
+// for the whole network. mu is a leaf lock (only the market index is locked inside it).
+type networkMarket struct {
+	mu     sync.Mutex
+	rng    *gameRand
+	stocks map[string]*marketListing
+	crypto map[string]*marketListing
+}
+
+// newNetworkMarket returns an empty market drawing its price moves from rng
+func newNetworkMarket(rng *gameRand) *networkMarket {
+	return &networkMarket{
+		rng:    rng,
+		stocks: make(map[string]*marketListing),
+		crypto: make(map[string]*marketListing),
+	}
+}
+
+// networkMarket returns the market of the network rooted at rootID, creating it on first use
+func (gm *GameManager) networkMarket(rootID string) *networkMarket {
+	gm.marketsMu.Lock()
+	defer gm.marketsMu.Unlock()
+
+	prices, exists := gm.markets[rootID]
+	if !exists {
+		prices = newNetworkMarket(seededRand("market:" + rootID))
+		gm.markets[rootID] = prices
+	}
+	return prices
+}
+
+// driftOn returns the listing's own move on date, drawing a new one the first time a later day is
+// asked for. Players a little behind the network's newest day get the current drift. m.mu must be held.
+func (m *networkMarket) driftOn(listing *marketListing, date time.Time, swing float64) float64 {
+	if day := marketDay(date); day > listing.day {
+		listing.drift = (m.rng.Float64() - 0.5) * swing
+		listing.day = day
+	}
+	return listing.drift
+}
+
+// stockPrice returns the network's price of symbol on date. A symbol the network has not seen is
+// listed at price with the given beta (an offer's or position's own terms).
+func (m *networkMarket) stockPrice(symbol string, date time.Time, price float64, beta float64) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	listing, exists := m.stocks[symbol]
+	if !exists {
+		if beta == 0 {
+			beta = 1 // Positions opened before stocks had a beta follow the market one to one
+		}
+		listing = &marketListing{price: price, listedOn: date, beta: beta, day: marketDay(date)}
+		m.stocks[symbol] = listing
+		return price
+	}
+	marketMove := market.ValueOn(date)/market.ValueOn(listing.listedOn) - 1
+	change := m.driftOn(listing, date, stockDailySwing) + listing.beta*marketMove
+	return listing.price * max(1+change, 0.01) // A stock can crash but not go below 1% of its listing price
+}
+
+// cryptoPrice returns the network's price of a coin on date. The first time it is traded it is
+// listed near its catalog base price and then swings each day by up to its volatility; a coin no
+// longer in the catalog is listed at a random price between cryptoMinListing and cryptoMaxListing.
+func (m *networkMarket) cryptoPrice(symbol string, date time.Time) float64 {
+	coin, known := findCryptoCoin(symbol)
+	swing := cryptoDailySwing
+	if known {
+		swing = coin.Volatility
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	listing, exists := m.crypto[symbol]
+	if !exists {
+		price := cryptoMinListing + m.rng.Float64()*(cryptoMaxListing-cryptoMinListing)
+		if known {
+			price = coin.BasePrice * (1 + (m.rng.Float64()*2-1)*cryptoListingSpread)
+		}
+		listing = &marketListing{price: price, listedOn: date, day: marketDay(date)}
+		m.crypto[symbol] = listing
+		return price
+	}
+	return listing.price * (1 + m.driftOn(listing, date, swing))
+}
+
+// stockPrice returns the position's price on the current date: the network's price for the
+// symbol times the lasting effect of the company news the player has seen
+func (gs *GameState) stockPrice(stock Stock) float64 {
+	newsFactor := stock.NewsFactor
+	if newsFactor == 0 {
+		newsFactor = 1
+	}
+	return gs.prices.stockPrice(stock.Symbol, gs.CurrentDate, stock.BuyPrice, stock.Beta) * newsFactor
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// gameRand is the source of game-logic randomness (offer dice, price moves, news, etc.). A nil
+// *gameRand draws from the global math/rand source; a seeded one makes a game reproducible. It
+// is safe for concurrent use, since the snapshots handed to AI calls share their game's source.
+type gameRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// idRand, when set by SetupRandom, makes generateID deterministic as well
+var idRand *gameRand
+
+// newGameRand returns a source seeded with seed
+func newGameRand(seed int64) *gameRand {
+	return &gameRand{rng: rand.New(rand.NewSource(seed))}
+}
+
+// seededRand returns a source for the given key (a player ID, or "" for the GameManager) derived
+// from the configured seed, or nil when no seed is set
+func seededRand(key string) *gameRand {
+	seed := GetConfig().Game.Seed
+	if seed == 0 {
+		return nil
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return newGameRand(seed ^ int64(h.Sum64()))
+}
+
+// Float64 returns a number in [0.0, 1.0)
+func (r *gameRand) Float64() float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+// Intn returns a number in [0, n); it panics if n <= 0
+func (r *gameRand) Intn(n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Intn(n)
+}
+
+// ExpFloat64 returns an exponentially distributed number with mean 1
+func (r *gameRand) ExpFloat64() float64 {
+	if r == nil {
+		return rand.ExpFloat64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.ExpFloat64()
+}
+
+// Read fills b with random bytes
+func (r *gameRand) Read(b []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rng.Read(b)
+}
+
+// SetupRandom applies Config.Game.Seed. With a seed, IDs and the market index are drawn from
+// seeded sources too, so a session can be replayed; each game gets its own source in NewGame.
+func SetupRandom(config *Config) {
+	if config.Game.Seed == 0 {
+		return
+	}
+	idRand = newGameRand(config.Game.Seed)
+	market = NewMarketIndex(config.Game.Seed)
+	logInfof("Deterministic mode: game randomness is seeded with %d", config.Game.Seed)
+}
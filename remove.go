@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+)
+
+// removeGame deletes playerID's game and keeps its invite network connected (see leaveNetwork).
+// The other side of the player's agreements with other players ends, their connections and those
+// of their spectators are closed, and their player ID can be claimed again. It reports whether
+// the game existed.
+func (gm *GameManager) removeGame(playerID string) bool {
+	var ended []Agreement
+	removed := gm.leaveNetwork(playerID, func(game *GameState) {
+		ended = endedLinkedAgreements(game.Agreements, nil)
+		delete(gm.games, playerID)
+		metricsActiveGames.Set(float64(len(gm.games)))
+	})
+	if !removed {
+		return false
+	}
+	gm.endLinkedAgreements(playerID, ended)
+	gm.closeConnections(playerID)
+	gm.sessionClaimsMu.Lock()
+	delete(gm.sessionClaims, playerID)
+	gm.sessionClaimsMu.Unlock()
+	logInfof("[ADMIN] Removed game of %s", playerID)
+	return true
+}
+
+// closeConnections closes playerID's WebSocket connection and those of their spectators, and
+// unregisters them. Must be called without holding any game lock.
+func (gm *GameManager) closeConnections(playerID string) {
+	gm.wsConnectionsMu.Lock()
+	defer gm.wsConnectionsMu.Unlock()
+	if wsConn, exists := gm.wsConnections[playerID]; exists {
+		wsConn.close()
+		delete(gm.wsConnections, playerID)
+		metricsWebSocketConnections.Set(float64(len(gm.wsConnections)))
+	}
+	for spectator := range gm.spectators[playerID] {
+		spectator.close()
+	}
+	delete(gm.spectators, playerID)
+}
+
+// leaveNetwork takes playerID out of their invite network and keeps the rest of it connected. The
+// players it invited move up to its inviter; if it was the network's root, the first of them by
+// player ID becomes the root (and the first player, if it was) with the others as its invitees,
+// and takes over the network's shared job offers and market. The player's invite codes stop
+// working. leave then deletes or replaces the game, with its lock and gm.mu held. It reports
+// whether the game existed.
+func (gm *GameManager) leaveNetwork(playerID string, leave func(game *GameState)) bool {
+	for {
+		gm.mu.RLock()
+		_, exists := gm.games[playerID]
+		children := gm.inviteesUnlocked(playerID)
+		gm.mu.RUnlock()
+		if !exists {
+			return false
+		}
+		
+		// Invitees need their game locks to be re-parented, and those come before gm.mu. If
+		// someone joins in between, let go and start over with them included.
+		removed, retry := false, false
+		gm.withGames(append([]string{playerID}, children...), func(games map[string]*GameState) {
+			gm.mu.Lock()
+			defer gm.mu.Unlock()
+			
+			game, locked := games[playerID]
+			if !locked || gm.games[playerID] == nil {
+				return // Removed meanwhile
+			}
+			current := gm.inviteesUnlocked(playerID)
+			for _, pid := range current {
+				if games[pid] == nil {
+					retry = true
+					return
+				}
+			}
+			
+			oldRoot := gm.getNetworkRootUnlocked(playerID)
+			parent := game.InvitedBy
+			if oldRoot == playerID && len(current) > 0 {
+				parent = current[0]
+				games[parent].InvitedBy = ""
+				games[parent].IsFirstPlayer = game.IsFirstPlayer
+				if game.IsFirstPlayer {
+					gm.firstPlayerMu.Lock()
+					gm.firstPlayerID = parent
+					gm.firstPlayerMu.Unlock()
+				}
+				gm.moveNetworkRoot(playerID, parent)
+			}
+			for _, pid := range current {
+				if pid != parent {
+					games[pid].InvitedBy = parent
+				}
+			}
+			
+			gm.inviteCodesMu.Lock()
+			for code, invite := range gm.inviteCodes {
+				if invite.PlayerID == playerID {
+					delete(gm.inviteCodes, code)
+				}
+			}
+			gm.inviteCodesMu.Unlock()
+			leave(game)
+			gm.invalidateNetworkIndex()
+			removed = true
+		})
+		if !retry {
+			if removed && len(children) > 0 {
+				logInfof("[NETWORK] %s left their network; %d invitee(s) re-parented", playerID, len(children))
+			}
+			return removed
+		}
+	}
+}
+
+// inviteesUnlocked returns the players playerID invited, sorted by ID. Caller must hold gm.mu.
+func (gm *GameManager) inviteesUnlocked(playerID string) []string {
+	var invitees []string
+	for pid, entry := range gm.games {
+		if pid != playerID && entry.game.InvitedBy == playerID {
+			invitees = append(invitees, pid)
+		}
+	}
+	slices.Sort(invitees)
+	return invitees
+}
+
+// moveNetworkRoot hands the network state kept under the root's ID, its shared job offers and
+// its market, from oldRoot to newRoot
+func (gm *GameManager) moveNetworkRoot(oldRoot string, newRoot string) {
+	gm.sharedJobOffersMu.Lock()
+	for offerID, root := range gm.sharedJobOffers {
+		if root == oldRoot {
+			gm.sharedJobOffers[offerID] = newRoot
+		}
+	}
+	gm.sharedJobOffersMu.Unlock()
+	
+	gm.marketsMu.Lock()
+	if prices, exists := gm.markets[oldRoot]; exists {
+		gm.markets[newRoot] = prices
+		delete(gm.markets, oldRoot)
+	}
+	gm.marketsMu.Unlock()
+}
+
+// HandleAdminRemoveGame deletes a player's game (?player_id=), keeping the players they invited
+// in the network (see removeGame)
+func (gm *GameManager) HandleAdminRemoveGame(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	if !gm.removeGame(playerID) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Game not found"})
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"success": true, "player_id": playerID})
+}
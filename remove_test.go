@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Removing a player ends the other side of their agreements with other players, whichever side
+// they were on
+func TestRemoveGameEndsLinkedAgreements(t *testing.T) {
+	for _, removed := range []string{"alice", "bob"} {
+		t.Run("remove "+removed, func(t *testing.T) {
+			testConfig(t, nil)
+			gm, _ := newTestManager(t)
+			date := time.Date(2025, 3, 3, 10, 0, 0, 0, time.UTC)
+			newTestGame(t, gm, "alice", date)
+			joinTestNetwork(t, gm, "bob", "alice")
+			gm.withGame("bob", func(game *GameState) {
+				game.Money = 1000
+				game.ActiveOffers = append(game.ActiveOffers, playerOffer("mowing", "alice", 30, date.Add(7*24*time.Hour)))
+			})
+			if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": "mowing"}); result["success"] != true {
+				t.Fatalf("bob could not accept alice's offer: %v", result["message"])
+			}
+			
+			gm.removeGame(removed)
+			remaining := "alice"
+			if removed == "alice" {
+				remaining = "bob"
+			}
+			buyer, reciprocal := linkedAgreements(gm, remaining)
+			if len(buyer) != 0 || len(reciprocal) != 0 {
+				t.Errorf("%s still has agreements with %s: buyer %v, reciprocal %v", remaining, removed, buyer, reciprocal)
+			}
+		})
+	}
+}
+
+// Removing a player closes their connection and their spectators', and frees their player ID
+func TestRemoveGameClosesConnectionsAndFreesClaim(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "alice", testStart)
+	player, _ := dialTestConnection(t, gm, "alice")
+	spectator, _ := dialTestConnection(t, gm, "alice")
+	spectator.spectator = true
+	gm.wsConnectionsMu.Lock()
+	gm.wsConnections["alice"] = player
+	gm.addSpectatorUnlocked(spectator)
+	gm.wsConnectionsMu.Unlock()
+	gm.sessionClaimsMu.Lock()
+	gm.sessionClaims["alice"] = true
+	gm.sessionClaimsMu.Unlock()
+	
+	if !gm.removeGame("alice") {
+		t.Fatal("alice's game was not removed")
+	}
+	for name, conn := range map[string]*wsConnection{"player": player, "spectator": spectator} {
+		conn.mu.Lock()
+		if !conn.closed {
+			t.Errorf("the %s connection is still open", name)
+		}
+		conn.mu.Unlock()
+	}
+	gm.wsConnectionsMu.RLock()
+	if _, exists := gm.wsConnections["alice"]; exists {
+		t.Error("alice's connection is still registered")
+	}
+	if len(gm.spectators["alice"]) != 0 {
+		t.Error("alice's spectators are still registered")
+	}
+	gm.wsConnectionsMu.RUnlock()
+	gm.sessionClaimsMu.Lock()
+	if gm.sessionClaims["alice"] {
+		t.Error("alice's player ID is still claimed")
+	}
+	gm.sessionClaimsMu.Unlock()
+}
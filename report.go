@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+// ReportOffer flags one of the player's offers ("job", "apartment", "stock" or "other") as a scam.
+// A correct report removes the offer and earns reports.scam_reward and reputation.scam_reported;
+// a false one costs reputation.false_report and leaves it. A scam the player bought the hint for
+// earns no money, since the hint already told them. It reports whether the offer was a scam and
+// the money it earned.
+func (gs *GameState) ReportOffer(offerType string, offerID string) (bool, float64, error) {
+	if err := gs.actionGuard("report_offer"); err != nil {
+		return false, 0, err
+	}
+
+	var found, isScam, hinted bool
+	var title string
+	switch offerType {
+	case "job":
+		if i := slices.IndexFunc(gs.JobOffers, func(o JobOffer) bool { return o.ID == offerID }); i >= 0 {
+			found, isScam, hinted, title = true, gs.JobOffers[i].IsTrickery, gs.JobOffers[i].HintShown, gs.JobOffers[i].Title
+			if isScam {
+				gs.JobOffers = slices.Delete(gs.JobOffers, i, i+1)
+			}
+		}
+	case "apartment":
+		if i := slices.IndexFunc(gs.ApartmentOffers, func(o ApartmentOffer) bool { return o.ID == offerID }); i >= 0 {
+			found, isScam, hinted, title = true, gs.ApartmentOffers[i].IsTrickery, gs.ApartmentOffers[i].HintShown, gs.ApartmentOffers[i].Title
+			if isScam {
+				gs.ApartmentOffers = slices.Delete(gs.ApartmentOffers, i, i+1)
+			}
+		}
+	case "stock":
+		if i := slices.IndexFunc(gs.StockOffers, func(o StockOffer) bool { return o.ID == offerID }); i >= 0 {
+			found, isScam, hinted, title = true, !gs.StockOffers[i].IsSafe, gs.StockOffers[i].HintShown, gs.StockOffers[i].CompanyName
+			if isScam {
+				gs.StockOffers = slices.Delete(gs.StockOffers, i, i+1)
+			}
+		}
+	case "other":
+		if i := slices.IndexFunc(gs.ActiveOffers, func(o Offer) bool { return o.ID == offerID }); i >= 0 {
+			found, isScam, hinted, title = true, gs.ActiveOffers[i].IsTrickery, gs.ActiveOffers[i].HintShown, gs.ActiveOffers[i].Title
+			if isScam {
+				gs.ActiveOffers = slices.Delete(gs.ActiveOffers, i, i+1)
+			}
+		}
+	default:
+		return false, 0, &GameError{Message: "offer_type must be job, apartment, stock or other"}
+	}
+	if !found {
+		return false, 0, &GameError{Message: "Offer not found or expired"}
+	}
+
+	reputation := GetConfig().Reputation
+	if !isScam {
+		gs.FalseReports++
+		gs.adjustReputation(reputation.FalseReport, "reported a legitimate offer as a scam")
+		gs.addEvent("false_report", fmt.Sprintf("Reported \"%s\" as a scam, but it was legitimate. Reputation %+d", title, reputation.FalseReport), 0)
+		return false, 0, nil
+	}
+
+	gs.ScamsReported++
+	gs.noteScamAvoided()
+	gs.adjustReputation(reputation.ScamReported, "reported a scam")
+	if hinted {
+		gs.addEvent("scam_reported", fmt.Sprintf("Correctly reported \"%s\" as a scam after buying its hint. Reputation %+d", title, reputation.ScamReported), 0)
+		return true, 0, nil
+	}
+	reward := GetConfig().Reports.ScamReward
+	gs.Money += reward
+	gs.addEvent("scam_reported", fmt.Sprintf("Correctly reported \"%s\" as a scam. Reward: €%s, reputation %+d", title, formatMoney(reward), reputation.ScamReported), reward)
+	return true, reward, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportOffer(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Reports.ScamReward = 30
+		config.Reputation.ScamReported = 4
+		config.Reputation.FalseReport = -5
+	})
+	newGame := func() *GameState {
+		game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+		game.Money = 1000
+		game.JobOffers = []JobOffer{
+			{ID: "scam", Title: "Easy money", IsTrickery: true},
+			{ID: "legit", Title: "Clerk"},
+		}
+		return game
+	}
+	
+	t.Run("scam", func(t *testing.T) {
+		game := newGame()
+		isScam, reward, err := game.ReportOffer("job", "scam")
+		if err != nil || !isScam || reward != 30 {
+			t.Fatalf("got scam %v, reward %v, error %v; want the configured €30 reward", isScam, reward, err)
+		}
+		if game.Money != 1030 || game.Reputation != 4 || len(game.JobOffers) != 1 {
+			t.Errorf("got €%.2f, reputation %d and %d offers, want €1030, 4 and the scam removed", game.Money, game.Reputation, len(game.JobOffers))
+		}
+	})
+	
+	t.Run("legitimate", func(t *testing.T) {
+		game := newGame()
+		isScam, reward, err := game.ReportOffer("job", "legit")
+		if err != nil || isScam || reward != 0 {
+			t.Fatalf("got scam %v, reward %v, error %v; want a false report", isScam, reward, err)
+		}
+		if game.Money != 1000 || game.Reputation != -5 || len(game.JobOffers) != 2 {
+			t.Errorf("got €%.2f, reputation %d and %d offers, want €1000, -5 and the offer kept", game.Money, game.Reputation, len(game.JobOffers))
+		}
+	})
+	
+	// Buying the hint and then reporting what it revealed must not turn a profit
+	t.Run("scam after its hint", func(t *testing.T) {
+		game := newGame()
+		if err := game.ShowHint("scam"); err != nil {
+			t.Fatal(err)
+		}
+		isScam, reward, err := game.ReportOffer("job", "scam")
+		if err != nil || !isScam || reward != 0 {
+			t.Fatalf("got scam %v, reward %v, error %v; want no money for a hinted scam", isScam, reward, err)
+		}
+		if game.Money >= 1000 {
+			t.Errorf("got €%.2f after the hint and the report, want less than the €1000 before", game.Money)
+		}
+	})
+	
+	t.Run("while working", func(t *testing.T) {
+		game := newGame()
+		game.IsWorking = true
+		if _, _, err := game.ReportOffer("job", "scam"); err == nil || !strings.Contains(err.Error(), "currently working") {
+			t.Fatalf("got %v, want it refused while working", err)
+		}
+		if len(game.JobOffers) != 2 || game.Money != 1000 {
+			t.Error("a refused report changed the game")
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+)
+
+// adjustReputation changes the player's reputation and records why as a reputation_change event,
+// followed by a credit_rate event when the change moves the player's credit rate
+func (gs *GameState) adjustReputation(delta int, reason string) {
+	if delta == 0 {
+		return
+	}
+	rate := gs.CreditRate()
+	gs.Reputation += delta
+	gs.addEvent("reputation_change", fmt.Sprintf("Reputation %+d: %s (now %d)", delta, reason, gs.Reputation), 0)
+	if gs.CreditRate() != rate {
+		gs.addEvent("credit_rate", gs.creditRateMessage(), 0)
+	}
+}
+
+// lowReputation reports whether the player's reputation costs them good job offers
+func (gs *GameState) lowReputation() bool {
+	return gs.Reputation < GetConfig().Reputation.LowThreshold
+}
+
+// highReputation reports whether the player's reputation unlocks better paid jobs
+func (gs *GameState) highReputation() bool {
+	return gs.Reputation >= GetConfig().Reputation.HighThreshold
+}
+
+// defaultCreditCurve doubles the interest of a player below the low reputation threshold and
+// takes 40% off for one at the high threshold
+func defaultCreditCurve() []CreditPoint {
+	return []CreditPoint{
+		{Reputation: -5, Multiplier: 2},
+		{Reputation: 0, Multiplier: 1},
+		{Reputation: 10, Multiplier: 0.6},
+	}
+}
+
+// validCreditCurve reports whether the curve has points in strictly ascending reputation order,
+// all with a positive multiplier
+func validCreditCurve(curve []CreditPoint) bool {
+	if len(curve) == 0 {
+		return false
+	}
+	for i, point := range curve {
+		if point.Multiplier <= 0 {
+			return false
+		}
+		if i > 0 && point.Reputation <= curve[i-1].Reputation {
+			return false
+		}
+	}
+	return true
+}
+
+// CreditRate returns the multiplier on the base interest rate the player's reputation qualifies
+// them for, read off reputation.credit_curve. Nothing charges or pays interest yet, so for now it
+// only feeds the credit_rate event.
+func (gs *GameState) CreditRate() float64 {
+	return creditRateFor(gs.Reputation, GetConfig().Reputation.CreditCurve)
+}
+
+// creditRateFor interpolates the curve at the reputation
+func creditRateFor(reputation int, curve []CreditPoint) float64 {
+	if len(curve) == 0 {
+		return 1
+	}
+	if reputation <= curve[0].Reputation {
+		return curve[0].Multiplier
+	}
+	for i := 1; i < len(curve); i++ {
+		lo, hi := curve[i-1], curve[i]
+		if reputation <= hi.Reputation {
+			share := float64(reputation-lo.Reputation) / float64(hi.Reputation-lo.Reputation)
+			return lo.Multiplier + share*(hi.Multiplier-lo.Multiplier)
+		}
+	}
+	return curve[len(curve)-1].Multiplier
+}
+
+// creditRateMessage explains the rate the player qualifies for
+func (gs *GameState) creditRateMessage() string {
+	rate := gs.CreditRate()
+	switch {
+	case rate < 1:
+		return fmt.Sprintf("With reputation %d you qualify for credit at %.2fx the base interest rate: lenders trust you and charge %.0f%% less", gs.Reputation, rate, (1-rate)*100)
+	case rate > 1:
+		return fmt.Sprintf("With reputation %d you qualify for credit at %.2fx the base interest rate: lenders see you as a risk and charge %.0f%% more", gs.Reputation, rate, (rate-1)*100)
+	default:
+		return fmt.Sprintf("With reputation %d you qualify for credit at the base interest rate", gs.Reputation)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreditRateFollowsCurve(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Reputation.CreditCurve = []CreditPoint{
+			{Reputation: -5, Multiplier: 2},
+			{Reputation: 0, Multiplier: 1},
+			{Reputation: 10, Multiplier: 0.6},
+		}
+	})
+	tests := []struct {
+		reputation int
+		want       float64
+	}{
+		{-20, 2},
+		{-5, 2},
+		{-2, 1.4},
+		{0, 1},
+		{5, 0.8},
+		{10, 0.6},
+		{50, 0.6},
+	}
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	for _, tt := range tests {
+		game.Reputation = tt.reputation
+		if got := game.CreditRate(); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("reputation %d: got rate %v, want %v", tt.reputation, got, tt.want)
+		}
+	}
+}
+
+// A reputation change that moves the rate is followed by an event explaining the new rate
+func TestReputationChangeExplainsCreditRate(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Reputation.CreditCurve = []CreditPoint{{Reputation: 0, Multiplier: 1}, {Reputation: 10, Multiplier: 0.5}}
+	})
+	game := newTestState(t, time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	game.adjustReputation(2, "took a legitimate job")
+	events := eventsOfType(game, "credit_rate")
+	if len(events) != 1 {
+		t.Fatalf("got %d credit_rate events, want 1", len(events))
+	}
+	if !strings.Contains(events[0].Message, "0.90x") || !strings.Contains(events[0].Message, "10% less") {
+		t.Errorf("got %q, want it to explain the 0.90x rate", events[0].Message)
+	}
+
+	// Below the curve the rate stays put, so there is nothing new to explain
+	game.Reputation = -10
+	game.adjustReputation(-1, "missed rent")
+	if events := eventsOfType(game, "credit_rate"); len(events) != 1 {
+		t.Errorf("got %d credit_rate events after a change that kept the rate, want 1", len(events))
+	}
+}
+
+// Quitting a legitimate job costs the configured reputation and reports it like any other change
+func TestQuitLegitJobAdjustsReputation(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Reputation.QuitLegitJob = -4
+	})
+	game := newTestState(t, time.Date(2025, 1, 6, 20, 0, 0, 0, time.UTC))
+	game.Job = paidTestJob()
+	if err := game.QuitJob(); err != nil {
+		t.Fatalf("quit job: %v", err)
+	}
+	if game.Reputation != -4 {
+		t.Errorf("got reputation %d, want -4", game.Reputation)
+	}
+	if events := eventsOfType(game, "reputation_change"); len(events) != 1 {
+		t.Errorf("got %d reputation_change events, want 1", len(events))
+	}
+}
+
+// Quitting a legitimate job moves the rate off the base, which the player hears about
+func TestQuitLegitJobExplainsCreditRate(t *testing.T) {
+	testConfig(t, func(config *Config) {
+		config.Reputation.QuitLegitJob = -1
+		config.Reputation.CreditCurve = []CreditPoint{{Reputation: -5, Multiplier: 2}, {Reputation: 0, Multiplier: 1}}
+	})
+	game := newTestState(t, time.Date(2025, 1, 6, 20, 0, 0, 0, time.UTC))
+	game.Job = paidTestJob()
+	if err := game.QuitJob(); err != nil {
+		t.Fatalf("quit job: %v", err)
+	}
+	events := eventsOfType(game, "credit_rate")
+	if len(events) != 1 {
+		t.Fatalf("got %d credit_rate events, want 1", len(events))
+	}
+	if !strings.Contains(events[0].Message, "1.20x") {
+		t.Errorf("got %q, want it to explain the 1.20x rate", events[0].Message)
+	}
+}
+
+func TestValidCreditCurve(t *testing.T) {
+	tests := []struct {
+		name  string
+		curve []CreditPoint
+		want  bool
+	}{
+		{"default", defaultCreditCurve(), true},
+		{"single point", []CreditPoint{{Reputation: 0, Multiplier: 1}}, true},
+		{"empty", nil, false},
+		{"out of order", []CreditPoint{{Reputation: 5, Multiplier: 1}, {Reputation: 0, Multiplier: 2}}, false},
+		{"repeated reputation", []CreditPoint{{Reputation: 0, Multiplier: 1}, {Reputation: 0, Multiplier: 2}}, false},
+		{"zero multiplier", []CreditPoint{{Reputation: 0, Multiplier: 0}}, false},
+	}
+	for _, tt := range tests {
+		if got := validCreditCurve(tt.curve); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
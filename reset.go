@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// resetGame starts playerID's game over in the same scenario and language, in place in its
+// existing entry. By default the player stays in their invite network with the same invite code
+// and inviter; a network's shared clock, market and job offers carry over, and only a player on
+// their own goes back to the scenario's start date. With full the player leaves their network the
+// way removeGame does and starts like a brand-new player, with a new invite code and market.
+// Either way the other side of every agreement with another player ends. It reports whether the
+// game existed.
+func (gm *GameManager) resetGame(playerID string, full bool) bool {
+	var ended []Agreement
+	var exists bool
+	if full {
+		exists = gm.leaveNetwork(playerID, func(game *GameState) {
+			fresh := gm.newGame(playerID, game.Scenario)
+			fresh.Language = game.Language
+			gm.marketsMu.Lock()
+			delete(gm.markets, playerID) // Still here unless it moved to an invitee
+			gm.marketsMu.Unlock()
+			fresh.prices = gm.networkMarket(playerID)
+			
+			// First player again unless that passed to an invitee
+			gm.firstPlayerMu.Lock()
+			if gm.firstPlayerID == "" || gm.firstPlayerID == playerID {
+				gm.firstPlayerID = playerID
+				fresh.IsFirstPlayer = true
+			}
+			gm.firstPlayerMu.Unlock()
+			gm.issueInviteCode(fresh)
+			
+			ended = endedLinkedAgreements(game.Agreements, nil)
+			*game = *fresh
+			game.addEvent("game_reset", "Started a new game outside your network", 0)
+		})
+	} else {
+		inNetwork := len(gm.getNetworkPlayers(playerID)) > 1
+		exists = gm.withGame(playerID, func(game *GameState) {
+			fresh := gm.newGame(playerID, game.Scenario)
+			fresh.Language = game.Language
+			fresh.InviteCode = game.InviteCode
+			fresh.InviteExpiresAt = game.InviteExpiresAt
+			fresh.InvitedBy = game.InvitedBy
+			fresh.IsFirstPlayer = game.IsFirstPlayer
+			fresh.prices = game.prices
+			if inNetwork {
+				// The network's clock is never moved back, and its job offers are shared
+				fresh.CurrentDate = game.CurrentDate
+				fresh.StartDate = game.CurrentDate
+				fresh.MarketIndex = game.MarketIndex
+				fresh.JobOffers = append(fresh.JobOffers, game.JobOffers...)
+			}
+			ended = endedLinkedAgreements(game.Agreements, fresh.Agreements)
+			*game = *fresh
+			game.addEvent("game_reset", "Started a new game", 0)
+		})
+	}
+	if !exists {
+		return false
+	}
+	gm.endLinkedAgreements(playerID, ended)
+	logInfof("[RESET] Player %s started over (full: %v)", playerID, full)
+	return true
+}
+
+// HandleResetGame starts the player's game over (see resetGame). So it can't happen by accident,
+// the body must say so: {"confirm": true, "full": false}
+func (gm *GameManager) HandleResetGame(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	var req struct {
+		Confirm bool `json:"confirm"`
+		Full    bool `json:"full"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if requestTooLarge(w, err) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid request body"})
+		return
+	}
+	if !req.Confirm {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Resetting deletes all progress; send \"confirm\": true to start over"})
+		return
+	}
+	
+	if !gm.resetGame(playerID, req.Full) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	gm.notifyPlayers(playerID)
+	gm.generateOffersSoon()
+	
+	entry, exists := gm.getEntry(playerID)
+	if !exists {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	gm.writeActionResponse(w, r, entry, map[string]interface{}{"success": true, "message": "Started a new game"})
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Resetting either side of an agreement between two players ends the other side too, so nobody
+// keeps paying for, or being paid for, an agreement the other player no longer has
+func TestResetEndsCounterpartAgreements(t *testing.T) {
+	tests := []struct {
+		name   string
+		resets string
+		other  string
+		full   bool
+	}{
+		{"buyer resets", "bob", "alice", false},
+		{"creator resets", "alice", "bob", false},
+		{"buyer resets fully", "bob", "alice", true},
+		{"creator resets fully", "alice", "bob", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, nil)
+			gm, _ := newTestManager(t)
+			newTestGame(t, gm, "alice", testStart)
+			joinTestNetwork(t, gm, "bob", "alice")
+			gm.withGame("bob", func(game *GameState) {
+				game.ActiveOffers = []Offer{playerOffer("mowing", "alice", 30, testStart.Add(time.Hour))}
+			})
+			if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": "mowing"}); result["success"] != true {
+				t.Fatalf("bob could not accept alice's offer: %v", result["message"])
+			}
+			
+			if !gm.resetGame(tt.resets, tt.full) {
+				t.Fatalf("%s's game was not found", tt.resets)
+			}
+			gm.readGame(tt.other, func(game *GameState) {
+				if len(game.Agreements) != 0 {
+					t.Errorf("%s still has %d agreements, want the mowing agreement ended", tt.other, len(game.Agreements))
+				}
+				if cancelled := eventsOfType(game, "agreement_cancelled"); len(cancelled) != 1 {
+					t.Errorf("%s got %d agreement_cancelled events, want 1", tt.other, len(cancelled))
+				}
+			})
+		})
+	}
+}
+
+// A full reset replaces the game inside its existing entry, so holders of the entry keep seeing
+// the player's game, and takes the player out of their network with the invitees moving up
+func TestFullResetInPlace(t *testing.T) {
+	testConfig(t, nil)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "alice", testStart)
+	joinTestNetwork(t, gm, "bob", "alice")
+	joinTestNetwork(t, gm, "carol", "bob")
+	entry, _ := gm.getEntry("bob")
+	game := entry.game
+	var oldCode string
+	gm.withGame("bob", func(game *GameState) {
+		oldCode = game.InviteCode
+		game.Money = 123456
+	})
+	
+	if !gm.resetGame("bob", true) {
+		t.Fatal("bob's game was not found")
+	}
+	if after, _ := gm.getEntry("bob"); after != entry || after.game != game {
+		t.Fatal("the full reset replaced bob's entry, want the game reset inside it")
+	}
+	gm.readGame("bob", func(game *GameState) {
+		if game.Money == 123456 || game.InvitedBy != "" || game.InviteCode == "" || game.InviteCode == oldCode {
+			t.Errorf("got money €%.2f, inviter %q and invite code %q (was %q), want a brand-new game with a new code", game.Money, game.InvitedBy, game.InviteCode, oldCode)
+		}
+	})
+	if players := gm.getNetworkPlayers("bob"); len(players) != 1 {
+		t.Errorf("bob's network is %v, want bob on their own", players)
+	}
+	gm.readGame("carol", func(game *GameState) {
+		if game.InvitedBy != "alice" {
+			t.Errorf("carol's inviter is %q, want alice after bob left", game.InvitedBy)
+		}
+	})
+	if _, err := gm.CreateGameWithInvite("dave", oldCode, "en"); err == nil {
+		t.Error("bob's old invite code still works after the full reset")
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// saveVersion is the format of exported saves; imports of other versions are rejected
+const saveVersion = 1
+
+// savedGame is the content of an exported save before encryption
+type savedGame struct {
+	Version int        `json:"version"`
+	SavedAt time.Time  `json:"saved_at"`
+	Game    *GameState `json:"game"`
+}
+
+// saveContext binds an export to its player, so it cannot be imported by someone else and a blob
+// made with /api/encrypt does not pass as a save
+func saveContext(playerID string) []byte {
+	return []byte(fmt.Sprintf("planc-save:v%d:%s", saveVersion, playerID))
+}
+
+// exportGame encrypts the whole game state, full event history included, as a save.
+// The caller must hold the game's lock.
+func (gm *GameManager) exportGame(game *GameState) (string, error) {
+	data, err := json.Marshal(savedGame{Version: saveVersion, SavedAt: gm.now(), Game: game})
+	if err != nil {
+		return "", err
+	}
+	return encryptWithContext(string(data), saveContext(game.PlayerID))
+}
+
+// openSave decrypts and decodes playerID's save and checks it with Validate. The error
+// explains why the save was rejected; problems lists every invariant the game breaks.
+func openSave(playerID string, encrypted string) (game *GameState, problems []string, err error) {
+	plaintext, err := decryptWithContext(encrypted, saveContext(playerID))
+	if err != nil {
+		return nil, nil, errors.New("not a save exported for this player, or it was changed")
+	}
+
+	save := savedGame{Game: &GameState{History: NewEventHistory(GetConfig().History.Capacity)}}
+	if err := json.Unmarshal([]byte(plaintext), &save); err != nil {
+		return nil, nil, errors.New("save does not hold a game state: " + err.Error())
+	}
+	if save.Version != saveVersion {
+		return nil, nil, fmt.Errorf("save version %d is not supported", save.Version)
+	}
+	if save.Game.PlayerID != playerID {
+		return nil, nil, errors.New("save belongs to another player")
+	}
+	var stateErr *StateError
+	if errors.As(save.Game.Validate(), &stateErr) {
+		return nil, stateErr.Problems, errors.New("save is not a valid game state")
+	}
+	return save.Game, nil, nil
+}
+
+// installSave replaces the player's game with an imported one. The server stays authoritative
+// for who invited the player, their invite code, the game's random source, clock and prices, the
+// scenario's difficulty and starting money, the market index, the hospital terms and the trickery
+// factor, which are kept or recomputed rather than taken from the save. The caller must hold the
+// game's write lock.
+func (gm *GameManager) installSave(game *GameState, imported *GameState, savedAt string) {
+	_, scenario := resolveScenario(imported.Scenario)
+	imported.InvitedBy = game.InvitedBy
+	imported.IsFirstPlayer = game.IsFirstPlayer
+	imported.InviteCode = game.InviteCode
+	imported.InviteExpiresAt = game.InviteExpiresAt
+	imported.rng = game.rng
+	imported.clock = game.clock
+	imported.prices = game.prices
+	imported.Difficulty = scenario.Difficulty
+	imported.InitialMoney = scenario.InitialMoney
+	imported.MarketIndex = market.ValueOn(imported.CurrentDate)
+	imported.Hospital = imported.hospitalTerms()
+	imported.TrickeryFactor = imported.trickeryFactor()
+	*game = *imported
+	game.addEvent("save_imported", "Loaded the game saved "+savedAt, 0)
+}
+
+// HandleExportState returns the player's game as an encrypted save for HandleImportState
+func (gm *GameManager) HandleExportState(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+
+	var encrypted string
+	var err error
+	found := gm.readGame(playerID, func(game *GameState) {
+		encrypted, err = gm.exportGame(game)
+	})
+	if !found {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Export failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"encrypted": encrypted})
+}
+
+// readSaveRequest decodes {"encrypted": "..."} and opens the save, writing the error response
+// and returning nil if it is rejected
+func readSaveRequest(w http.ResponseWriter, r *http.Request, playerID string) *GameState {
+	var req struct {
+		Encrypted string `json:"encrypted"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Encrypted == "" {
+		if requestTooLarge(w, err) {
+			return nil
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "encrypted is required"})
+		return nil
+	}
+
+	game, problems, err := openSave(playerID, req.Encrypted)
+	if err != nil {
+		response := map[string]interface{}{"error": err.Error()}
+		if len(problems) > 0 {
+			response["problems"] = problems
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(response)
+		return nil
+	}
+	return game
+}
+
+// HandleVerifyState checks an exported save without loading it
+func (gm *GameManager) HandleVerifyState(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	game := readSaveRequest(w, r, playerID)
+	if game == nil {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"valid":        true,
+		"current_date": game.CurrentDate,
+		"money":        game.Money,
+		"net_worth":    game.NetWorth(),
+	})
+}
+
+// HandleImportState replaces the player's game with an exported save in one step, after checking
+// that it is unchanged, belongs to the player and is a valid game state, and, if the server still
+// has the player's game, that it is not older and its net worth is plausible next to it (see
+// checkSaveProgress).
+// Reciprocal agreements whose buyer no longer has the agreement are dropped, and the other side of
+// agreements with other players that the save doesn't have is ended. The player's game is
+// created first if the server no longer has it (e.g. after a restart).
+func (gm *GameManager) HandleImportState(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	imported := readSaveRequest(w, r, playerID)
+	if imported == nil {
+		return
+	}
+
+	_, existed := gm.getEntry(playerID)
+	entry := gm.GetOrCreateGame(playerID, imported.Language, imported.Scenario)
+
+	// Lock the buyers of the save's reciprocal agreements too, so none can end between the check and the install
+	playerIDs := []string{playerID}
+	for _, agreement := range imported.Agreements {
+		if agreement.IsReciprocal {
+			playerIDs = append(playerIDs, agreement.OtherPartyID)
+		}
+	}
+	var err error
+	var dropped []string
+	var followUps []func()
+	gm.withGames(playerIDs, func(games map[string]*GameState) {
+		live := games[playerID]
+		if existed {
+			if err = checkSaveProgress(imported, live); err != nil {
+				return
+			}
+		}
+		dropped = dropOrphanedAgreements(imported, games)
+		// Agreements with other players that the save doesn't have end on their side too
+		if ended := endedLinkedAgreements(live.Agreements, imported.Agreements); len(ended) > 0 {
+			followUps = append(followUps, func() { gm.endLinkedAgreements(playerID, ended) })
+		}
+		gm.installSave(live, imported, imported.CurrentDate.Format("2006-01-02"))
+	})
+	var stateErr *StateError
+	if errors.As(err, &stateErr) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{"error": "save does not match your game on the server", "problems": stateErr.Problems})
+		return
+	}
+	for _, followUp := range followUps {
+		followUp()
+	}
+
+	gm.notifyPlayers(playerID)
+	result := map[string]interface{}{"success": true, "message": "Saved game loaded"}
+	if len(dropped) > 0 {
+		result["dropped_agreements"] = dropped
+	}
+	gm.writeActionResponse(w, r, entry, result)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTestEncryptionKey sets up save encryption with a fixed key for the rest of the test
+func useTestEncryptionKey(t testing.TB) {
+	t.Helper()
+	previous := encryptionKeys
+	encryptionKeys = [][]byte{deriveKey("test-key")}
+	t.Cleanup(func() { encryptionKeys = previous })
+}
+
+// exportTestSave returns the player's game as an encrypted save
+func exportTestSave(t testing.TB, gm *GameManager, playerID string) string {
+	t.Helper()
+	var encrypted string
+	var err error
+	gm.readGame(playerID, func(game *GameState) { encrypted, err = gm.exportGame(game) })
+	if err != nil {
+		t.Fatalf("could not export %s's game: %v", playerID, err)
+	}
+	return encrypted
+}
+
+// importTestSave posts an encrypted save to HandleImportState and returns the response
+func importTestSave(gm *GameManager, playerID string, encrypted string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(map[string]string{"encrypted": encrypted})
+	rec := httptest.NewRecorder()
+	gm.HandleImportState(rec, httptest.NewRequest(http.MethodPost, "/api/state/import?player_id="+playerID, strings.NewReader(string(body))))
+	return rec
+}
+
+// A save from before the server's game would undo what happened since, so it is rejected
+func TestImportRejectsOlderSave(t *testing.T) {
+	testConfig(t, nil)
+	useTestEncryptionKey(t)
+	gm, _ := newTestManager(t)
+	newTestGame(t, gm, "p", time.Date(2025, 1, 6, 10, 0, 0, 0, time.UTC))
+	old := exportTestSave(t, gm, "p")
+	gm.withGame("p", func(game *GameState) {
+		game.CurrentDate = game.CurrentDate.Add(48 * time.Hour)
+		game.Money -= 3000
+	})
+	
+	rec := importTestSave(gm, "p", old)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "older save cannot be loaded") {
+		t.Errorf("got status %d: %s, want the older save rejected", rec.Code, rec.Body.String())
+	}
+	gm.readGame("p", func(game *GameState) {
+		if !game.CurrentDate.Equal(time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("the game was set back to %v", game.CurrentDate)
+		}
+	})
+	
+	// A save from the same moment loads
+	if rec := importTestSave(gm, "p", exportTestSave(t, gm, "p")); rec.Code != http.StatusOK {
+		t.Errorf("got status %d: %s, want a current save loaded", rec.Code, rec.Body.String())
+	}
+}
+
+// Agreements with other players that an imported save doesn't have end for the other player too
+func TestImportEndsCounterpartAgreements(t *testing.T) {
+	tests := []struct {
+		name     string
+		importer string
+		other    string
+	}{
+		{"buyer loads a save from before buying", "bob", "alice"},
+		{"creator loads a save from before selling", "alice", "bob"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testConfig(t, nil)
+			useTestEncryptionKey(t)
+			gm, _ := newTestManager(t)
+			newTestGame(t, gm, "alice", testStart)
+			joinTestNetwork(t, gm, "bob", "alice")
+			save := exportTestSave(t, gm, tt.importer)
+			gm.withGame("bob", func(game *GameState) {
+				game.ActiveOffers = []Offer{playerOffer("mowing", "alice", 30, testStart.Add(time.Hour))}
+			})
+			if result := doAction(t, gm, "bob", "accept_offer", map[string]interface{}{"offer_id": "mowing"}); result["success"] != true {
+				t.Fatalf("bob could not accept alice's offer: %v", result["message"])
+			}
+			
+			if rec := importTestSave(gm, tt.importer, save); rec.Code != http.StatusOK {
+				t.Fatalf("got status %d: %s, want the save loaded", rec.Code, rec.Body.String())
+			}
+			for _, playerID := range []string{tt.importer, tt.other} {
+				gm.readGame(playerID, func(game *GameState) {
+					if len(game.Agreements) != 0 {
+						t.Errorf("%s still has %d agreements, want the mowing agreement ended on both sides", playerID, len(game.Agreements))
+					}
+				})
+			}
+			gm.readGame(tt.other, func(game *GameState) {
+				if cancelled := eventsOfType(game, "agreement_cancelled"); len(cancelled) != 1 {
+					t.Errorf("%s got %d agreement_cancelled events, want 1", tt.other, len(cancelled))
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// shutdownTimeout bounds how long a graceful shutdown waits for requests, WebSocket clients,
+// background loops and queued events before the process exits anyway
+const shutdownTimeout = 20 * time.Second
+
+// runInBackground starts fn as a background loop that Shutdown waits for
+func (gm *GameManager) runInBackground(fn func()) {
+	gm.background.Add(1)
+	go func() {
+		defer gm.background.Done()
+		fn()
+	}()
+}
+
+// sleep waits for d and reports whether the manager is still running, returning early on shutdown
+func (gm *GameManager) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-gm.ctx.Done():
+		return false
+	}
+}
+
+// closeWebSockets asks every connected client's writePump to flush its queue and send a close message
+func (gm *GameManager) closeWebSockets() {
+	gm.wsConnectionsMu.RLock()
+	defer gm.wsConnectionsMu.RUnlock()
+	closing := 0
+	shutDown := func(conn *wsConnection) {
+		conn.doneOnce.Do(func() {
+			close(conn.done)
+			closing++
+		})
+	}
+	for _, conn := range gm.wsConnections {
+		shutDown(conn)
+	}
+	for _, watching := range gm.spectators {
+		for conn := range watching {
+			shutDown(conn)
+		}
+	}
+	if closing > 0 {
+		logInfof("[SHUTDOWN] Closing %d WebSocket connections", closing)
+	}
+}
+
+// Shutdown stops the offer generators, closes WebSocket connections and delivers queued events,
+// waiting for each until ctx is done. The HTTP server should be shut down first so no new work arrives.
+func (gm *GameManager) Shutdown(ctx context.Context) {
+	gm.stop()
+	gm.closeWebSockets()
+	
+	// Wait for clients to disconnect; readPump unregisters each one as its connection ends
+	for {
+		gm.wsConnectionsMu.RLock()
+		remaining := len(gm.wsConnections) + len(gm.spectators)
+		gm.wsConnectionsMu.RUnlock()
+		if remaining == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			logWarnf("[SHUTDOWN] %d WebSocket connections still open", remaining)
+			return
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	
+	// Let a generator round that is waiting on the AI finish
+	stopped := make(chan struct{})
+	go func() {
+		gm.background.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logWarnf("[SHUTDOWN] Background offer generation did not stop in time")
+		return
+	}
+	
+	if gm.events != nil {
+		if err := gm.events.flush(ctx); err != nil {
+			logWarnf("[SHUTDOWN] Undelivered events: %v", err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// spectatorMessageTypes are the messages of a player's own connection that are copied to the
+// spectators watching them, besides the game state: the conversation with the guide
+var spectatorMessageTypes = map[string]bool{
+	"chat_typing":     true,
+	"chat_typing_end": true,
+	"chat_response":   true,
+	"lesson":          true,
+}
+
+// spectatorContextKey marks a request admitted by RequireSpectator
+type spectatorContextKey struct{}
+
+// RequireSpectator admits read-only WebSocket connections to any player's game. They carry the
+// admin token instead of the player's session, in the token query parameter (browsers cannot set
+// headers on a WebSocket) or like RequireAdmin's requests.
+func (gm *GameManager) RequireSpectator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if token == "" {
+			token = requestAdminToken(r)
+		}
+		if !adminTokenValid(token) {
+			logWarnf("[SPECTATE] Rejected spectator for %s from %s", r.URL.Query().Get("player_id"), r.RemoteAddr)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), spectatorContextKey{}, true)))
+	})
+}
+
+// isSpectator reports whether RequireSpectator admitted the request
+func isSpectator(r *http.Request) bool {
+	spectator, _ := r.Context().Value(spectatorContextKey{}).(bool)
+	return spectator
+}
+
+// addSpectatorUnlocked registers a spectator connection; the caller must hold wsConnectionsMu
+func (gm *GameManager) addSpectatorUnlocked(c *wsConnection) {
+	if gm.spectators[c.playerID] == nil {
+		gm.spectators[c.playerID] = make(map[*wsConnection]bool)
+	}
+	gm.spectators[c.playerID][c] = true
+}
+
+// removeSpectatorUnlocked unregisters a spectator connection; the caller must hold wsConnectionsMu
+func (gm *GameManager) removeSpectatorUnlocked(c *wsConnection) {
+	delete(gm.spectators[c.playerID], c)
+	if len(gm.spectators[c.playerID]) == 0 {
+		delete(gm.spectators, c.playerID)
+	}
+}
+
+// mirrorToSpectators copies a frame the player's own connection just wrote to everyone watching
+// them: game states replace the spectator's unsent state, and only spectatorMessageTypes are
+// copied of the rest. Callers must not hold wsConnectionsMu.
+func (gm *GameManager) mirrorToSpectators(playerID string, message []byte) {
+	gm.wsConnectionsMu.RLock()
+	defer gm.wsConnectionsMu.RUnlock()
+	if len(gm.spectators[playerID]) == 0 {
+		return
+	}
+	
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(message, &header); err != nil {
+		return
+	}
+	for spectator := range gm.spectators[playerID] {
+		if header.Type == "state" {
+			spectator.queueState(message)
+			continue
+		}
+		if !spectatorMessageTypes[header.Type] {
+			continue
+		}
+		if !spectator.trySend(message) {
+			logWarnf("[SPECTATE] Send channel full or closed for a spectator of player %s, dropped %s message", playerID, header.Type)
+		}
+	}
+}
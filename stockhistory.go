@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// maxStockHistoryPerSymbol is how many price points are kept for each stock symbol; the oldest
+// are dropped first
+const maxStockHistoryPerSymbol = 60
+
+// addStockHistory records a price point, dropping the symbol's oldest point once it has more than
+// maxStockHistoryPerSymbol
+func (gs *GameState) addStockHistory(point StockHistory) {
+	gs.StockHistory = append(gs.StockHistory, point)
+	
+	count := 0
+	for _, existing := range gs.StockHistory {
+		if existing.Symbol == point.Symbol {
+			count++
+		}
+	}
+	if count <= maxStockHistoryPerSymbol {
+		return
+	}
+	for i, existing := range gs.StockHistory {
+		if existing.Symbol == point.Symbol {
+			gs.StockHistory = append(gs.StockHistory[:i], gs.StockHistory[i+1:]...)
+			return
+		}
+	}
+}
+
+// sampleStockPrices records the day's price of every stock on offer that the player does not
+// hold, so a symbol's chart has a point for each day before they buy it (held stocks are sampled
+// with the daily price update)
+func (gs *GameState) sampleStockPrices(held map[string]bool) {
+	for _, offer := range gs.StockOffers {
+		if held[offer.Symbol] {
+			continue
+		}
+		held[offer.Symbol] = true
+		gs.addStockHistory(StockHistory{Symbol: offer.Symbol, Price: offer.CurrentPrice, Date: gs.CurrentDate})
+	}
+}
+
+// HandleStockHistory returns the recorded price points of one stock symbol (?symbol=), oldest
+// first, for a price chart
+func (gm *GameManager) HandleStockHistory(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "symbol is required"})
+		return
+	}
+	
+	history := []StockHistory{}
+	if !gm.readGame(playerID, func(game *GameState) {
+		for _, point := range game.StockHistory {
+			if point.Symbol == symbol {
+				history = append(history, point)
+			}
+		}
+	}) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"symbol":  symbol,
+		"history": history,
+	})
+}
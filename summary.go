@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// tradeEventTypes are events that move money between cash and assets rather than earning or
+// spending it; they are left out of income and expenses and counted as investment gains instead
+var tradeEventTypes = map[string]bool{
+	"stock_buy":  true,
+	"stock_sell": true,
+	"crypto_buy": true,
+	"crypto_sell": true,
+	"item_buy":   true,
+	"item_sell":  true,
+	"profit":     true,
+	"loss":       true,
+	"short_open": true,
+	"short_cover": true,
+	"short_liquidated": true,
+	"transaction_fee": true, // Already part of the profit and loss events
+}
+
+// FinancialSummary is a snapshot of how the player is doing over time
+type FinancialSummary struct {
+	NetWorth          float64            `json:"net_worth"`
+	InitialMoney      float64            `json:"initial_money"`
+	NetWorthDelta     float64            `json:"net_worth_delta"` // NetWorth - InitialMoney
+	ROIPercent        float64            `json:"roi_percent"`     // NetWorthDelta as a percentage of InitialMoney
+	TotalIncome       float64            `json:"total_income"`
+	TotalExpenses     float64            `json:"total_expenses"` // Positive number
+	IncomeByType      map[string]float64 `json:"income_by_type"`
+	ExpensesByType    map[string]float64 `json:"expenses_by_type"`
+	RealizedGains     float64            `json:"realized_gains"`   // Profit minus loss from completed sales
+	UnrealizedGains   float64            `json:"unrealized_gains"` // Current value minus cost of held stocks, crypto and items
+	ScamsAccepted     int                `json:"scams_accepted"`
+	ScamsAvoided      int                `json:"scams_avoided"`
+	ScamsReported     int                `json:"scams_reported"`
+	FalseReports      int                `json:"false_reports"`
+	DaysSurvived      int                `json:"days_survived"`
+	EventsCounted     int                `json:"events_counted"`
+	HistoryTruncated  bool               `json:"history_truncated"` // Older events were dropped, so the totals only cover the kept history
+}
+
+// Summary computes the player's financial summary. Income, expenses and realized gains come from
+// the event history, so they only cover events that are still kept (see history.capacity).
+func (gs *GameState) Summary() FinancialSummary {
+	summary := FinancialSummary{
+		NetWorth:         gs.NetWorth(),
+		InitialMoney:     gs.InitialMoney,
+		IncomeByType:     map[string]float64{},
+		ExpensesByType:   map[string]float64{},
+		ScamsAccepted:    gs.ScamsAccepted,
+		ScamsAvoided:     gs.ScamsAvoided,
+		ScamsReported:    gs.ScamsReported,
+		FalseReports:     gs.FalseReports,
+		DaysSurvived:     int(gs.CurrentDate.Sub(gs.StartDate).Hours() / 24),
+		EventsCounted:    gs.History.Len(),
+		HistoryTruncated: gs.History.Total() > gs.History.Len(),
+	}
+	summary.NetWorthDelta = summary.NetWorth - gs.InitialMoney
+	if gs.InitialMoney > 0 {
+		summary.ROIPercent = summary.NetWorthDelta / gs.InitialMoney * 100
+	}
+	
+	for _, event := range gs.History.Last(gs.History.Len()) {
+		switch {
+		case event.Type == "profit":
+			summary.RealizedGains += event.Amount
+		case event.Type == "loss":
+			// Loss events carry the size of the loss as a positive amount
+			summary.RealizedGains -= abs(event.Amount)
+		case tradeEventTypes[event.Type]:
+		case event.Amount > 0:
+			summary.TotalIncome += event.Amount
+			summary.IncomeByType[event.Type] += event.Amount
+		case event.Amount < 0:
+			summary.TotalExpenses -= event.Amount
+			summary.ExpensesByType[event.Type] -= event.Amount
+		}
+	}
+	
+	// Purchase fees count against the gains, as they will when the asset is sold
+	for _, stock := range gs.Stocks {
+		summary.UnrealizedGains += stock.UnrealizedGain() - stock.FeesPaid
+	}
+	for _, crypto := range gs.Crypto {
+		summary.UnrealizedGains += (crypto.CurrentPrice-crypto.BuyPrice)*crypto.Amount - crypto.FeesPaid
+	}
+	for _, item := range gs.Inventory {
+		summary.UnrealizedGains += item.MarketPrice - item.BuyPrice - item.FeesPaid
+	}
+	return summary
+}
+
+// summaryContext describes the summary in a few lines for AI prompts
+func (s FinancialSummary) summaryContext() string {
+	return fmt.Sprintf(`- Net worth: €%.2f (started with €%.2f, %+.1f%%)
+- Income so far: €%.2f, expenses so far: €%.2f
+- Investment gains: €%.2f realized, €%.2f unrealized
+- Scams fallen for: %d, scams avoided: %d (%d reported, %d false reports)
+- Days survived: %d`,
+		s.NetWorth, s.InitialMoney, s.ROIPercent,
+		s.TotalIncome, s.TotalExpenses,
+		s.RealizedGains, s.UnrealizedGains,
+		s.ScamsAccepted, s.ScamsAvoided, s.ScamsReported, s.FalseReports,
+		s.DaysSurvived)
+}
+
+// HandleSummary returns the player's financial summary
+func (gm *GameManager) HandleSummary(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	
+	var summary FinancialSummary
+	if !gm.readGame(playerID, func(game *GameState) {
+		summary = game.Summary()
+	}) {
+		http.Error(w, "Game not found", http.StatusNotFound)
+		return
+	}
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
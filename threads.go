@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Offer message threads
+const (
+	maxThreadMessages = 200            // Oldest messages are dropped beyond this
+	threadRetention   = 7 * 24 * time.Hour // Threads without activity for this long (real time) are dropped
+)
+
+// OfferThreadMessage is one message in the conversation about an offer
+type OfferThreadMessage struct {
+	PlayerID string    `json:"player_id,omitempty"` // Who wrote it; empty for replies from the offer's side
+	From     string    `json:"from"`                // "player" or "offer"
+	Text     string    `json:"text"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+// offerThread is the conversation about one offer, shared by every network copy of it
+type offerThread struct {
+	messages     []OfferThreadMessage
+	lastActivity time.Time
+}
+
+// recordOfferMessage adds a player's message, and the reply if there is one, to the offer's thread
+// and returns a copy of the whole thread. offerThreadsMu is a leaf lock, so game locks may be held.
+func (gm *GameManager) recordOfferMessage(offerID string, playerID string, message string, resp *N8NWebhookResponse) []OfferThreadMessage {
+	now := gm.now()
+	gm.offerThreadsMu.Lock()
+	defer gm.offerThreadsMu.Unlock()
+	
+	thread, exists := gm.offerThreads[offerID]
+	if !exists {
+		thread = &offerThread{}
+		gm.offerThreads[offerID] = thread
+	}
+	thread.messages = append(thread.messages, OfferThreadMessage{PlayerID: playerID, From: "player", Text: message, SentAt: now})
+	if resp != nil && resp.Message != nil {
+		thread.messages = append(thread.messages, OfferThreadMessage{From: "offer", Text: *resp.Message, SentAt: now})
+	}
+	if len(thread.messages) > maxThreadMessages {
+		thread.messages = slices.Clone(thread.messages[len(thread.messages)-maxThreadMessages:])
+	}
+	thread.lastActivity = now
+	return slices.Clone(thread.messages)
+}
+
+// offerThreadMessages returns a copy of the offer's thread, or nil if nobody has written about it
+func (gm *GameManager) offerThreadMessages(offerID string) []OfferThreadMessage {
+	gm.offerThreadsMu.Lock()
+	defer gm.offerThreadsMu.Unlock()
+	if thread, exists := gm.offerThreads[offerID]; exists {
+		return slices.Clone(thread.messages)
+	}
+	return nil
+}
+
+// syncOfferMessages copies the thread into the Messages of every network copy of the offer, so all
+// players see the same conversation. Callers must not hold any game lock.
+func (gm *GameManager) syncOfferMessages(playerID string, offerID string, thread []OfferThreadMessage) {
+	texts := make([]string, len(thread))
+	for i, msg := range thread {
+		texts[i] = msg.Text
+	}
+	
+	networkPlayers := gm.getNetworkPlayers(playerID)
+	gm.withGames(networkPlayers, func(games map[string]*GameState) {
+		for _, game := range games {
+			if messages := game.offerMessages(offerID); messages != nil {
+				*messages = slices.Clone(texts)
+			}
+		}
+	})
+	gm.notifyPlayers(networkPlayers...)
+}
+
+// offerMessages finds the Messages of the offer with the given ID, whatever its type
+func (gs *GameState) offerMessages(offerID string) *[]string {
+	for i := range gs.ActiveOffers {
+		if gs.ActiveOffers[i].ID == offerID {
+			return &gs.ActiveOffers[i].Messages
+		}
+	}
+	for i := range gs.JobOffers {
+		if gs.JobOffers[i].ID == offerID {
+			return &gs.JobOffers[i].Messages
+		}
+	}
+	for i := range gs.ApartmentOffers {
+		if gs.ApartmentOffers[i].ID == offerID {
+			return &gs.ApartmentOffers[i].Messages
+		}
+	}
+	for i := range gs.StockOffers {
+		if gs.StockOffers[i].ID == offerID {
+			return &gs.StockOffers[i].Messages
+		}
+	}
+	return nil
+}
+
+// sweepOfferThreads drops threads that have been quiet for longer than threadRetention
+func (gm *GameManager) sweepOfferThreads(now time.Time) {
+	gm.offerThreadsMu.Lock()
+	defer gm.offerThreadsMu.Unlock()
+	for offerID, thread := range gm.offerThreads {
+		if now.Sub(thread.lastActivity) > threadRetention {
+			delete(gm.offerThreads, offerID)
+		}
+	}
+}
+
+// HandleOfferMessages returns the full message thread of an offer the player can see
+func (gm *GameManager) HandleOfferMessages(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	offerID := r.URL.Query().Get("offer_id")
+	w.Header().Set("Content-Type", "application/json")
+	if offerID == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "offer_id is required"})
+		return
+	}
+	
+	visible := false
+	found := gm.readGame(playerID, func(game *GameState) {
+		visible = game.offerMessages(offerID) != nil
+	})
+	if !found {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Game not found"})
+		return
+	}
+	
+	thread := gm.offerThreadMessages(offerID)
+	// A player who wrote about an offer can still read the thread after the offer is gone
+	if !visible && !slices.ContainsFunc(thread, func(msg OfferThreadMessage) bool { return msg.PlayerID == playerID }) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "Offer not found"})
+		return
+	}
+	if thread == nil {
+		thread = []OfferThreadMessage{}
+	}
+	
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"offer_id": offerID,
+		"messages": thread,
+	})
+}
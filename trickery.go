@@ -0,0 +1,37 @@
+package main
+
+// recordScamOutcome remembers whether the player accepted or avoided a scam, keeping the last
+// trickery.window outcomes, and updates the adaptive trickery factor shown in the state
+func (gs *GameState) recordScamOutcome(accepted bool) {
+	gs.RecentScams = append(gs.RecentScams, accepted)
+	if window := GetConfig().Trickery.Window; len(gs.RecentScams) > window {
+		gs.RecentScams = gs.RecentScams[len(gs.RecentScams)-window:]
+	}
+	gs.TrickeryFactor = gs.trickeryFactor()
+}
+
+// trickeryFactor returns the multiplier on the difficulty's scam odds. Once the player has seen
+// trickery.min_samples scams it moves from 1 towards max_factor the more of the recent ones they
+// avoided than accepted, and towards min_factor the more they accepted.
+func (gs *GameState) trickeryFactor() float64 {
+	settings := GetConfig().Trickery
+	if !settings.Adaptive || len(gs.RecentScams) < settings.MinSamples {
+		return 1
+	}
+	accepted := 0
+	for _, wasAccepted := range gs.RecentScams {
+		if wasAccepted {
+			accepted++
+		}
+	}
+	skill := 1 - 2*float64(accepted)/float64(len(gs.RecentScams)) // 1 avoided all, -1 accepted all
+	if skill >= 0 {
+		return 1 + skill*(settings.MaxFactor-1)
+	}
+	return 1 + skill*(1-settings.MinFactor)
+}
+
+// trickeryChance returns the chance that a new offer is a scam, given the difficulty's chance
+func (gs *GameState) trickeryChance(chance float64) float64 {
+	return min(chance*gs.trickeryFactor(), 1)
+}
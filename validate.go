@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maxStateMoney bounds money amounts in a game state
+const maxStateMoney = 1e12
+
+// maxSaveNetWorthGrowthPerDay is how far a loaded save's net worth may exceed the server's copy
+// of the game, per in-game day the save is ahead of it (at least one day), as a share of the
+// larger of the copy's net worth and the starting money
+const maxSaveNetWorthGrowthPerDay = 0.1
+
+// StateError lists the invariants a game state breaks
+type StateError struct {
+	Problems []string
+}
+
+func (e *StateError) Error() string {
+	return "invalid game state: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks the game's invariants: money, health and energy ranges, non-negative counters,
+// dates, known scenario and difficulty, positions, and that offer and agreement IDs are unique
+// and agreements have a valid schedule and other party. It returns a *StateError listing every
+// problem, or nil.
+func (gs *GameState) Validate() error {
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+	validMoney := func(amount float64) bool {
+		return !math.IsNaN(amount) && math.Abs(amount) < maxStateMoney
+	}
+
+	check(validMoney(gs.Money), "money is out of range (%v)", gs.Money)
+	check(validMoney(gs.InitialMoney) && gs.InitialMoney > 0, "initial_money must be positive (%v)", gs.InitialMoney)
+	check(gs.Health >= 0 && gs.Health <= 100, "health must be between 0 and 100 (%d)", gs.Health)
+	check(gs.Energy >= 0 && gs.Energy <= 100, "energy must be between 0 and 100 (%d)", gs.Energy)
+	check(validMoney(gs.HospitalBill) && gs.HospitalBill >= 0, "hospital_bill is out of range (%v)", gs.HospitalBill)
+	check(gs.InsuranceCoverage >= 0 && gs.InsuranceCoverage <= 1, "insurance_coverage must be between 0 and 1 (%v)", gs.InsuranceCoverage)
+
+	counters := []struct {
+		name  string
+		count int
+	}{
+		{"scams_avoided", gs.ScamsAvoided},
+		{"scams_reported", gs.ScamsReported},
+		{"false_reports", gs.FalseReports},
+		{"scams_accepted", gs.ScamsAccepted},
+		{"nights_homeless", gs.NightsHomeless},
+	}
+	for _, counter := range counters {
+		check(counter.count >= 0, "%s must not be negative (%d)", counter.name, counter.count)
+	}
+
+	check(!gs.CurrentDate.IsZero(), "current_date is missing")
+	check(!gs.StartDate.IsZero(), "start_date is missing")
+	check(!gs.StartDate.After(gs.CurrentDate), "start_date is after current_date")
+	check(!gs.LastSalaryDate.After(gs.CurrentDate), "last_salary_date is after current_date")
+	check(!gs.IsInHospital || !gs.HospitalEntryTime.IsZero(), "hospital_entry_time is missing for a hospital stay")
+
+	config := GetConfig()
+	_, knownScenario := config.Game.Scenarios[gs.Scenario]
+	check(knownScenario, "unknown scenario %q", gs.Scenario)
+	_, knownDifficulty := config.Game.Difficulties[gs.Difficulty]
+	check(knownDifficulty, "unknown difficulty %q", gs.Difficulty)
+
+	if gs.Job != nil {
+		check(validMoney(gs.Job.Salary) && gs.Job.Salary >= 0, "job salary is out of range (%v)", gs.Job.Salary)
+	}
+	if gs.Apartment != nil {
+		check(validMoney(gs.Apartment.Rent) && gs.Apartment.Rent >= 0, "apartment rent is out of range (%v)", gs.Apartment.Rent)
+	}
+	for _, stock := range gs.Stocks {
+		check(stock.Shares > 0 && validMoney(stock.BuyPrice) && stock.BuyPrice >= 0 && validMoney(stock.CurrentPrice) && stock.CurrentPrice >= 0, "stock position %s is invalid", stock.Symbol)
+	}
+	for _, crypto := range gs.Crypto {
+		check(crypto.Amount > 0 && validMoney(crypto.BuyPrice) && crypto.BuyPrice >= 0 && validMoney(crypto.CurrentPrice) && crypto.CurrentPrice >= 0, "crypto position %s is invalid", crypto.Symbol)
+	}
+	for _, item := range gs.Inventory {
+		check(validMoney(item.MarketPrice) && item.MarketPrice >= 0, "item %q has an invalid market price", item.Name)
+	}
+
+	offerIDs := map[string]bool{}
+	for _, offer := range gs.allOffers() {
+		check(offer.ID != "" && !offerIDs[offer.ID], "%s offer ID %q is missing or repeated", offer.OfferType, offer.ID)
+		offerIDs[offer.ID] = true
+	}
+
+	agreementIDs := map[string]bool{}
+	for _, agreement := range gs.Agreements {
+		check(agreement.ID != "" && !agreementIDs[agreement.ID], "agreement ID %q is missing or repeated", agreement.ID)
+		agreementIDs[agreement.ID] = true
+		switch agreement.RecurrenceType {
+		case "daily", "weekly", "monthly":
+		default:
+			problems = append(problems, fmt.Sprintf("agreement %q has unknown recurrence %q", agreement.ID, agreement.RecurrenceType))
+		}
+		check(!agreement.StartedAt.After(gs.CurrentDate) && !agreement.LastProcessedAt.After(gs.CurrentDate), "agreement %q has dates after current_date", agreement.ID)
+		check(!agreement.IsReciprocal || agreement.OtherPartyID != "", "reciprocal agreement %q has no other party", agreement.ID)
+		check(agreement.OtherPartyID != gs.PlayerID, "agreement %q is with the player themselves", agreement.ID)
+	}
+
+	if len(problems) > 0 {
+		return &StateError{Problems: problems}
+	}
+	return nil
+}
+
+// checkSaveProgress rejects a save that is behind live, the server's copy of the player's game,
+// or whose net worth the player could not plausibly have reached from it: it may only exceed
+// live's net worth by maxSaveNetWorthGrowthPerDay for each in-game day it is ahead. This stops
+// loading an older, richer save to undo losses.
+func checkSaveProgress(save *GameState, live *GameState) error {
+	if save.CurrentDate.Before(live.CurrentDate) {
+		return &StateError{Problems: []string{fmt.Sprintf("save is from %s, before your game on the server (%s); an older save cannot be loaded",
+			save.CurrentDate.Format("2006-01-02 15:04"), live.CurrentDate.Format("2006-01-02 15:04"))}}
+	}
+	days := max(save.CurrentDate.Sub(live.CurrentDate).Hours()/24, 1)
+	base := max(live.NetWorth(), live.InitialMoney)
+	allowed := live.NetWorth() + base*maxSaveNetWorthGrowthPerDay*days
+	if save.NetWorth() > allowed {
+		return &StateError{Problems: []string{fmt.Sprintf("net worth €%.2f is implausible next to the server's game (€%.2f on %s)",
+			save.NetWorth(), live.NetWorth(), live.CurrentDate.Format("2006-01-02"))}}
+	}
+	return nil
+}
+
+// dropOrphanedAgreements removes reciprocal agreements (the player receiving payments from
+// another player) whose buyer has no matching agreement with the player in games, and returns
+// their titles. Only the buyer's agreement is authoritative, so a save cannot bring back income
+// from an agreement that has since ended.
+func dropOrphanedAgreements(game *GameState, games map[string]*GameState) []string {
+	var dropped []string
+	kept := game.Agreements[:0]
+	for _, agreement := range game.Agreements {
+		if agreement.IsReciprocal && !hasBuyerAgreement(games[agreement.OtherPartyID], game.PlayerID, &agreement) {
+			dropped = append(dropped, agreement.Title)
+			continue
+		}
+		kept = append(kept, agreement)
+	}
+	game.Agreements = kept
+	return dropped
+}
+
+// hasBuyerAgreement reports whether buyer has the agreement bought from creatorID that reciprocal
+// is the creator's side of
+func hasBuyerAgreement(buyer *GameState, creatorID string, reciprocal *Agreement) bool {
+	if buyer == nil {
+		return false
+	}
+	for _, agreement := range buyer.Agreements {
+		if agreement.OtherPartyID == creatorID && agreement.matchesReciprocal(reciprocal, buyer.PlayerID) {
+			return true
+		}
+	}
+	return false
+}
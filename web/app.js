@@ -4,6 +4,10 @@ let lastWebSocketState = null; // Track last state received from WebSocket
 const API_BASE = '/api';
 let PLAYER_ID = null; // Will be set after game creation
 const STORAGE_KEY = 'planc_game_state';
+const SESSION_KEY = 'planc_session_token';
+let SESSION_TOKEN = localStorage.getItem(SESSION_KEY); // Signed token proving we own PLAYER_ID
+const PLAYER_SCENARIO = new URLSearchParams(window.location.search).get('scenario') || ''; // Starting-conditions preset for new games (easy, normal, hard, ...)
+const PLAYER_LANG = (new URLSearchParams(window.location.search).get('lang') || navigator.language || 'en').split('-')[0]; // Language for AI messages
 let lastSaveTime = 0; // Track last save time to debounce saves
 
 // Local time tracking for smooth UI updates
@@ -15,6 +19,7 @@ let calculatedGameTime = null; // Currently calculated game time for display
 let ws = null;
 let wsReconnectAttempts = 0;
 const MAX_RECONNECT_ATTEMPTS = 5;
+let sessionExpired = false; // Set by the server's "reauth" message; reconnecting with the old token would fail
 let useWebSocket = true; // Use WebSocket by default, fallback to HTTP if fails
 
 // UI update interval
@@ -70,7 +75,7 @@ function connectWebSocket() {
     if (!PLAYER_ID || !useWebSocket) return;
     
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-    const wsUrl = `${protocol}//${window.location.host}${API_BASE}/ws?player_id=${PLAYER_ID}`;
+    const wsUrl = `${protocol}//${window.location.host}${API_BASE}/ws?player_id=${PLAYER_ID}&lang=${PLAYER_LANG}&token=${encodeURIComponent(SESSION_TOKEN || '')}`;
     
     try {
         ws = new WebSocket(wsUrl);
@@ -82,55 +87,90 @@ function connectWebSocket() {
         
         ws.onmessage = (event) => {
             try {
-                // Handle multiple messages (separated by newlines)
-                const messages = event.data.split('\n').filter(m => m.trim());
+                // Each frame carries exactly one JSON message
+                const message = JSON.parse(event.data);
                 
-                messages.forEach(msgText => {
-                    const message = JSON.parse(msgText);
-                    
-                    if (message.type === 'state' && message.game_state) {
-                        lastWebSocketState = message.game_state;
-                        gameState = message.game_state;
-                        PLAYER_ID = gameState.player_id;
+                if (message.type === 'state' && message.game_state) {
+                    lastWebSocketState = message.game_state;
+                    gameState = message.game_state;
+                    PLAYER_ID = gameState.player_id;
+                    // Update base time when receiving new state from server
+                    updateBaseTimeFromState(gameState);
+                    updateUI();
+                } else if (message.type === 'action_result') {
+                    // A repeat of an action the server already ran: nothing changed
+                    if (message.result && message.result.duplicate) {
+                        return;
+                    }
+                    // Always update state from action result if available
+                    if (message.result && message.result.game_state) {
+                        lastWebSocketState = message.result.game_state;
+                        gameState = message.result.game_state;
                         // Update base time when receiving new state from server
                         updateBaseTimeFromState(gameState);
                         updateUI();
-                    } else if (message.type === 'action_result') {
-                        // Always update state from action result if available
-                        if (message.result && message.result.game_state) {
-                            lastWebSocketState = message.result.game_state;
-                            gameState = message.result.game_state;
-                            // Update base time when receiving new state from server
-                            updateBaseTimeFromState(gameState);
-                            updateUI();
-                        } else if (message.result) {
-                            // If no game_state in result, refresh from server
+                    } else if (message.result) {
+                        // If no game_state in result, refresh from server
+                        loadGameState();
+                    }
+                    if (message.result && message.result.message) {
+                        showMessage(message.result.message, message.result.success ? 'success' : 'error');
+                    }
+                } else if (message.type === 'chat_typing') {
+                    showChatTyping(true);
+                } else if (message.type === 'chat_typing_end') {
+                    showChatTyping(false);
+                    console.debug(`Guide answered in ${(message.elapsed_ms / 1000).toFixed(1)}s`);
+                } else if (message.type === 'chat_response') {
+                    // Handle chat response
+                    if (message.success && message.result) {
+                        const result = message.result;
+                        // Check if something was created
+                        if (result.created && result.offer) {
+                            addChatMessage('agent', result.message, 'Guide Agent', result.questions);
+                            showMessage('Offer created and shared with your network!', 'success');
+                            // Refresh game state to show new offer
                             loadGameState();
-                        }
-                        if (message.result && message.result.message) {
-                            showMessage(message.result.message, message.result.success ? 'success' : 'error');
-                        }
-                    } else if (message.type === 'chat_response') {
-                        // Handle chat response
-                        if (message.success && message.result) {
-                            const result = message.result;
-                            // Check if something was created
-                            if (result.created && result.offer) {
-                                addChatMessage('agent', result.message, 'Guide Agent', result.questions);
-                                showMessage('Offer created and shared with your network!', 'success');
-                                // Refresh game state to show new offer
-                                loadGameState();
-                            } else {
-                                addChatMessage('agent', result.message, 'Guide Agent', result.questions);
-                            }
                         } else {
-                            addChatMessage('agent', message.message || 'Error processing chat', 'Guide Agent');
+                            addChatMessage('agent', result.message, 'Guide Agent', result.questions);
                         }
-                    } else if (message.type === 'error') {
-                        console.error('WebSocket error:', message.message);
-                        showMessage(message.message, 'error');
+                    } else {
+                        addChatMessage('agent', message.message || 'Error processing chat', 'Guide Agent');
                     }
-                });
+                } else if (message.type === 'lesson') {
+                    // Explanation of the red flags in a trickery offer the player just accepted
+                    addChatMessage('agent', message.message, 'Lesson: ' + message.title);
+                } else if (message.type === 'daily_digest' && message.digest) {
+                    // Recap of the in-game day that just ended
+                    const digest = message.digest;
+                    const change = digest.money_end - digest.money_start;
+                    const moves = (digest.price_moves || []).map(m => `${m.symbol} ${m.change_percent >= 0 ? '+' : ''}${m.change_percent.toFixed(1)}%`);
+                    let text = `Income €${digest.income.toFixed(2)}, spending €${digest.spending.toFixed(2)}, money ${change >= 0 ? '+' : ''}€${change.toFixed(2)}.`;
+                    if (moves.length > 0) text += ` Prices: ${moves.join(', ')}.`;
+                    if (digest.narrative) text += ' ' + digest.narrative;
+                    addChatMessage('agent', text, 'Daily digest: ' + new Date(digest.from).toLocaleDateString());
+                } else if (message.type === 'game_won') {
+                    showMessage(message.message, 'success');
+                } else if (message.type === 'news' && message.news) {
+                    // Company news that just moved a stock the player holds
+                    const change = message.news.change;
+                    showMessage(`📰 ${message.news.headline} (${message.news.symbol} ${change >= 0 ? '+' : ''}${change.toFixed(1)}%)`, change >= 0 ? 'success' : 'error');
+                } else if (message.type === 'agreement_unaffordable' && message.charge) {
+                    // An agreement charge the player couldn't afford
+                    const charge = message.charge;
+                    let text = `💸 Couldn't afford ${charge.title} (€${(-charge.amount).toFixed(2)}): you now have €${charge.money.toFixed(2)}.`;
+                    if (charge.suspended) text += ' The agreement has been suspended.';
+                    showMessage(text, 'error');
+                } else if (message.type === 'achievement' && message.achievement) {
+                    showMessage(`🏆 Achievement unlocked: ${message.achievement.title}`, 'success');
+                } else if (message.type === 'reauth') {
+                    // The session token expired; the server closes the socket after this
+                    sessionExpired = true;
+                    showMessage(message.message, 'error');
+                } else if (message.type === 'error') {
+                    console.error('WebSocket error:', message.message);
+                    showMessage(message.message, 'error');
+                }
             } catch (error) {
                 console.error('Error parsing WebSocket message:', error);
             }
@@ -143,6 +183,7 @@ function connectWebSocket() {
         ws.onclose = () => {
             console.log('WebSocket disconnected');
             ws = null;
+            if (sessionExpired) return;
             
             // Attempt to reconnect
             if (wsReconnectAttempts < MAX_RECONNECT_ATTEMPTS) {
@@ -220,9 +261,12 @@ function statesDiffer(state1, state2) {
         state1.current_date !== state2.current_date ||
         state1.is_working !== state2.is_working ||
         state1.is_in_hospital !== state2.is_in_hospital ||
+        state1.insurance_active !== state2.insurance_active ||
         state1.game_over !== state2.game_over ||
+        state1.game_won !== state2.game_won ||
         JSON.stringify(state1.job) !== JSON.stringify(state2.job) ||
         JSON.stringify(state1.apartment) !== JSON.stringify(state2.apartment) ||
+        JSON.stringify(state1.properties) !== JSON.stringify(state2.properties) ||
         state1.job_offers?.length !== state2.job_offers?.length ||
         state1.apartment_offers?.length !== state2.apartment_offers?.length ||
         state1.active_offers?.length !== state2.active_offers?.length ||
@@ -343,7 +387,7 @@ function startGameLoop() {
                     hoursToAdvance = (1.0/60.0) * 5;
                 }
                 
-                const response = await fetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
+                const response = await apiFetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
                     method: 'POST',
                     headers: { 'Content-Type': 'application/json' },
                     body: JSON.stringify({ 
@@ -385,13 +429,36 @@ function startGameLoop() {
     }, 10000);
 }
 
+// Log in as PLAYER_ID and keep the session token for later requests
+async function login() {
+    const response = await apiFetch(`${API_BASE}/login`, {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify({ player_id: PLAYER_ID })
+    });
+    if (!response.ok) {
+        throw new Error('Login failed: ' + response.status);
+    }
+    const result = await response.json();
+    SESSION_TOKEN = result.token;
+    localStorage.setItem(SESSION_KEY, SESSION_TOKEN);
+}
+
+// fetch wrapper that sends the session token with API requests
+function apiFetch(url, options = {}) {
+    if (SESSION_TOKEN) {
+        options.headers = { ...(options.headers || {}), 'Authorization': `Bearer ${SESSION_TOKEN}` };
+    }
+    return fetch(url, options);
+}
+
 // Load game state (with caching support)
 async function loadGameState() {
     if (!PLAYER_ID) return;
     
     try {
         // Load from server with cache headers
-        const response = await fetch(`${API_BASE}/state?player_id=${PLAYER_ID}`, {
+        const response = await apiFetch(`${API_BASE}/state?player_id=${PLAYER_ID}&lang=${PLAYER_LANG}`, {
             headers: {
                 'Accept-Encoding': 'gzip',
                 'Cache-Control': 'max-age=5'
@@ -460,12 +527,21 @@ async function loadGameFromStorage() {
         gameState = JSON.parse(decrypted);
         PLAYER_ID = gameState.player_id;
         
+        // Saves from before sessions have no token; claim the player ID if the server still allows it
+        if (!SESSION_TOKEN) {
+            try {
+                await login();
+            } catch (error) {
+                console.warn('Could not start a session for saved game:', error);
+            }
+        }
+        
         // Update base time from loaded state
         updateBaseTimeFromState(gameState);
         
         // Verify the game still exists on the server
         try {
-            const response = await fetch(`${API_BASE}/state?player_id=${PLAYER_ID}`);
+            const response = await apiFetch(`${API_BASE}/state?player_id=${PLAYER_ID}&lang=${PLAYER_LANG}`);
             if (response.ok) {
                 // Update with server state (in case of changes)
                 const serverState = await response.json();
@@ -603,21 +679,24 @@ function setupInviteModalListeners() {
             PLAYER_ID = 'player_' + Date.now() + '_' + Math.random().toString(36).substr(2, 9);
             
             try {
+                await login();
+                
                 let response;
                 if (inviteCode) {
                     // Create game with invite code
                     console.log('Creating game with invite code:', inviteCode);
-                    response = await fetch(`${API_BASE}/create-with-invite`, {
+                    response = await apiFetch(`${API_BASE}/create-with-invite`, {
                         method: 'POST',
                         headers: { 'Content-Type': 'application/json' },
                         body: JSON.stringify({
                             player_id: PLAYER_ID,
-                            invite_code: inviteCode
+                            invite_code: inviteCode,
+                            language: PLAYER_LANG
                         })
                     });
                 } else {
                     // Create first player game
-                    response = await fetch(`${API_BASE}/state?player_id=${PLAYER_ID}`);
+                    response = await apiFetch(`${API_BASE}/state?player_id=${PLAYER_ID}&lang=${PLAYER_LANG}&scenario=${encodeURIComponent(PLAYER_SCENARIO)}`);
                 }
                 
                 if (response.ok) {
@@ -704,7 +783,7 @@ async function loadMarketData() {
         
         const marketItems = await itemsRes.json();
         const stockSymbols = await stocksRes.json();
-        const cryptoSymbols = await cryptoRes.json();
+        const cryptoCoins = await cryptoRes.json();
         
         // Populate market items
         const marketBuySelect = document.getElementById('market-item-buy');
@@ -727,10 +806,10 @@ async function loadMarketData() {
         
         // Populate crypto symbols
         const cryptoSelect = document.getElementById('crypto-symbol');
-        cryptoSymbols.forEach(symbol => {
+        cryptoCoins.forEach(coin => {
             const option = document.createElement('option');
-            option.value = symbol;
-            option.textContent = symbol;
+            option.value = coin.symbol;
+            option.textContent = `${coin.symbol} - ${coin.name} (~€${coin.base_price})`;
             cryptoSelect.appendChild(option);
         });
     } catch (error) {
@@ -743,10 +822,21 @@ function setupEventListeners() {
     // Work buttons
     document.getElementById('btn-start-work').addEventListener('click', () => performAction('start_work', {}));
     document.getElementById('btn-stop-work').addEventListener('click', () => performAction('stop_work', {}));
+    document.getElementById('btn-day-off').addEventListener('click', () => performAction('take_day_off', {}));
     document.getElementById('btn-quit-job').addEventListener('click', () => performAction('quit_job', {}));
     
     // Apartment button
     document.getElementById('btn-quit-apartment').addEventListener('click', () => performAction('quit_apartment', {}));
+    document.getElementById('btn-rest').addEventListener('click', () => performAction('rest', { hours: 8 }));
+    
+    // Manual offer refresh (limited by a server-side cooldown)
+    document.querySelectorAll('.btn-refresh-offers').forEach(button => {
+        button.addEventListener('click', () => performAction('refresh_offers', { offer_type: button.dataset.offerType }));
+    });
+    
+    // Insurance buttons
+    document.getElementById('btn-buy-insurance').addEventListener('click', () => performAction('buy_insurance', {}));
+    document.getElementById('btn-cancel-insurance').addEventListener('click', () => performAction('cancel_insurance', {}));
     
     // Stock buttons
     document.getElementById('btn-buy-stock').addEventListener('click', () => {
@@ -870,6 +960,49 @@ function setupEventListeners() {
         });
     }
     
+    // Revoke invite code button (the server issues a new code right away)
+    const revokeInviteBtn = document.getElementById('btn-revoke-invite');
+    if (revokeInviteBtn) {
+        revokeInviteBtn.addEventListener('click', async () => {
+            if (confirm('Revoke your invite code? Anyone holding the old code will no longer be able to join.')) {
+                await performAction('revoke_invite', {});
+            }
+        });
+    }
+    
+    // Start over: a fresh game in the same network; the server insists on the confirm flag
+    const resetGameBtn = document.getElementById('btn-reset-game');
+    if (resetGameBtn) {
+        resetGameBtn.addEventListener('click', async () => {
+            if (!confirm('Start a new game? All your money, possessions and history will be lost.')) return;
+            try {
+                const response = await apiFetch(`${API_BASE}/reset?player_id=${PLAYER_ID}`, {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({ confirm: true })
+                });
+                const result = await response.json();
+                if (!response.ok) throw new Error(result.error || response.status);
+                gameState = result.game_state;
+                updateBaseTimeFromState(gameState);
+                updateUI();
+                showMessage(result.message, 'success');
+            } catch (error) {
+                showMessage('Could not start over: ' + error.message, 'error');
+            }
+        });
+    }
+    
+    // History export buttons (a plain link cannot send the Authorization header, so the token goes in the URL)
+    ['csv', 'json'].forEach(format => {
+        const exportBtn = document.getElementById(`btn-export-history-${format}`);
+        if (exportBtn) {
+            exportBtn.addEventListener('click', () => {
+                window.location.href = `${API_BASE}/history/export?player_id=${PLAYER_ID}&format=${format}&token=${encodeURIComponent(SESSION_TOKEN || '')}`;
+            });
+        }
+    });
+    
     // Share invite code button
     const shareInviteBtn = document.getElementById('btn-share-invite');
     if (shareInviteBtn) {
@@ -955,6 +1088,14 @@ function setupTabs() {
     });
 }
 
+// Generate a unique key for an action sent over WebSocket
+function newIdempotencyKey() {
+    if (window.crypto && crypto.randomUUID) {
+        return crypto.randomUUID();
+    }
+    return `${Date.now()}-${Math.random().toString(36).slice(2)}`;
+}
+
 // Perform action (via WebSocket if available, otherwise HTTP)
 async function performAction(action, data) {
     if (!PLAYER_ID) {
@@ -965,7 +1106,8 @@ async function performAction(action, data) {
     // Use WebSocket if available
     if (ws && ws.readyState === WebSocket.OPEN) {
         try {
-            ws.send(JSON.stringify({ action, data }));
+            // The key lets the server ignore this action if it arrives twice
+            ws.send(JSON.stringify({ action, data, idempotency_key: newIdempotencyKey() }));
             // State will be updated via WebSocket message
             return { success: true };
         } catch (error) {
@@ -976,7 +1118,7 @@ async function performAction(action, data) {
     
     // HTTP fallback
     try {
-        const response = await fetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
+        const response = await apiFetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ action, data })
@@ -1013,7 +1155,7 @@ async function performAction(action, data) {
 // Generate offer
 async function generateOffer(type) {
     try {
-        const response = await fetch(`${API_BASE}/offer?player_id=${PLAYER_ID}&type=${type}`);
+        const response = await apiFetch(`${API_BASE}/offer?player_id=${PLAYER_ID}&type=${type}`);
         const result = await response.json();
         if (result.success) {
             gameState = result.game_state;
@@ -1094,7 +1236,7 @@ async function sendChat() {
         }
         
         // Fallback to HTTP
-        const response = await fetch(`${API_BASE}/chat?player_id=${PLAYER_ID}`, {
+        const response = await apiFetch(`${API_BASE}/chat?player_id=${PLAYER_ID}`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({
@@ -1120,6 +1262,25 @@ async function sendChat() {
     }
 }
 
+// Show or hide the "guide is typing" indicator at the bottom of the chat
+function showChatTyping(typing) {
+    const messagesDiv = document.getElementById('chat-messages');
+    let indicator = document.getElementById('chat-typing');
+    if (!typing) {
+        if (indicator) indicator.remove();
+        return;
+    }
+    if (!indicator) {
+        indicator = document.createElement('div');
+        indicator.id = 'chat-typing';
+        indicator.className = 'chat-message agent chat-typing';
+        indicator.innerHTML = '<h4>Guide Agent</h4><p>Thinking<span class="typing-dots"><span>.</span><span>.</span><span>.</span></span></p>';
+    }
+    // Keep it below the newest message
+    messagesDiv.appendChild(indicator);
+    messagesDiv.scrollTop = messagesDiv.scrollHeight;
+}
+
 // Add chat message
 function addChatMessage(type, message, sender, questions = []) {
     const messagesDiv = document.getElementById('chat-messages');
@@ -1135,7 +1296,8 @@ function addChatMessage(type, message, sender, questions = []) {
         html += '</ul>';
     }
     messageDiv.innerHTML = html;
-    messagesDiv.appendChild(messageDiv);
+    const typingIndicator = document.getElementById('chat-typing');
+    messagesDiv.insertBefore(messageDiv, typingIndicator);
     messagesDiv.scrollTop = messagesDiv.scrollHeight;
 }
 
@@ -1156,6 +1318,14 @@ function updateUI() {
     // Update hospital status
     const hospitalStatus = document.getElementById('hospital-status');
     const hospitalInfo = document.getElementById('hospital-info');
+    const hospitalRate = document.getElementById('hospital-rate');
+    const hospital = gameState.hospital || { hourly_cost: 100, release_health: 20 };
+    if (hospitalRate) {
+        const coverage = gameState.insurance_active ? (gameState.insurance_coverage || 0) : 0;
+        hospitalRate.textContent = (hospital.hourly_cost * (1 - coverage)).toFixed(0);
+    }
+    const hospitalRelease = document.getElementById('hospital-release');
+    if (hospitalRelease) hospitalRelease.textContent = hospital.release_health;
     if (gameState.is_in_hospital) {
         if (hospitalStatus) hospitalStatus.style.display = 'inline';
         if (hospitalInfo) hospitalInfo.style.display = 'block';
@@ -1164,6 +1334,18 @@ function updateUI() {
         if (hospitalInfo) hospitalInfo.style.display = 'none';
     }
     
+    // Update victory status
+    const gameWonInfo = document.getElementById('game-won-info');
+    const gameWonReason = document.getElementById('game-won-reason');
+    if (gameState.game_won) {
+        if (gameWonInfo) gameWonInfo.style.display = 'block';
+        if (gameWonReason && gameState.game_won_reason) {
+            gameWonReason.textContent = gameState.game_won_reason;
+        }
+    } else {
+        if (gameWonInfo) gameWonInfo.style.display = 'none';
+    }
+    
     // Update game over status
     const gameOverInfo = document.getElementById('game-over-info');
     const gameOverReason = document.getElementById('game-over-reason');
@@ -1188,12 +1370,23 @@ function updateUI() {
         repElement.className = 'reputation';
     }
     
+    // Update adaptive scam odds
+    const trickeryElement = document.getElementById('trickery-factor');
+    if (trickeryElement) {
+        trickeryElement.textContent = '×' + (gameState.trickery_factor || 1).toFixed(2);
+    }
+    
     // Update invite code section
     const inviteCodeSection = document.getElementById('invite-code-section');
     const inviteCodeDisplay = document.getElementById('invite-code-display');
     if (gameState.invite_code) {
         if (inviteCodeSection) inviteCodeSection.style.display = 'block';
-        if (inviteCodeDisplay) inviteCodeDisplay.textContent = gameState.invite_code;
+        if (inviteCodeDisplay) {
+            inviteCodeDisplay.textContent = gameState.invite_code;
+            inviteCodeDisplay.title = gameState.invite_expires_at
+                ? 'Valid until ' + new Date(gameState.invite_expires_at).toLocaleString()
+                : '';
+        }
     } else {
         if (inviteCodeSection) inviteCodeSection.style.display = 'none';
     }
@@ -1205,12 +1398,17 @@ function updateUI() {
         document.getElementById('current-time').textContent = displayTime.toLocaleTimeString('en-US', { hour: '2-digit', minute: '2-digit', hour12: false });
     }
     
-    document.getElementById('job').textContent = gameState.job ? gameState.job.title : 'None';
+    document.getElementById('job').textContent = gameState.job
+        ? gameState.job.title + (gameState.job.work_type !== 'fixed_time' && gameState.hours_worked_this_period ? ` (${gameState.hours_worked_this_period.toFixed(1)} h since payday)` : '')
+        : 'None';
     document.getElementById('apartment').textContent = gameState.apartment ? gameState.apartment.title : 'None';
+    updateProperties();
     
-    // Update apartment quit button
-    const quitApartmentBtn = document.getElementById('btn-quit-apartment');
-    quitApartmentBtn.disabled = !gameState.apartment;
+    // Update apartment, rest and insurance buttons from what the server allows
+    setActionButton('btn-quit-apartment', 'quit_apartment');
+    setActionButton('btn-rest', 'rest');
+    setActionButton('btn-buy-insurance', 'buy_insurance');
+    setActionButton('btn-cancel-insurance', 'cancel_insurance');
     
     // Show health warning if no apartment
     const healthWarning = document.getElementById('health-warning');
@@ -1230,29 +1428,30 @@ function updateUI() {
     const startWorkBtn = document.getElementById('btn-start-work');
     const stopWorkBtn = document.getElementById('btn-stop-work');
     const quitJobBtn = document.getElementById('btn-quit-job');
+    const dayOffBtn = document.getElementById('btn-day-off');
+    dayOffBtn.style.display = 'none';
     
     if (gameState.job) {
         const workType = gameState.job.work_type || 'hourly';
         
         if (workType === 'fixed_time') {
-            // Fixed-time jobs: hide start/stop buttons
+            // Fixed-time jobs: hide start/stop buttons, but a shift can be taken off
             startWorkBtn.style.display = 'none';
             stopWorkBtn.style.display = 'none';
-            quitJobBtn.disabled = false;
+            dayOffBtn.style.display = 'inline-block';
         } else {
             // Hourly jobs: show start/stop buttons
             startWorkBtn.style.display = 'inline-block';
             stopWorkBtn.style.display = gameState.is_working ? 'inline-block' : 'none';
-            startWorkBtn.disabled = gameState.is_working;
-            stopWorkBtn.disabled = !gameState.is_working;
-            quitJobBtn.disabled = false;
         }
     } else {
         startWorkBtn.style.display = 'inline-block';
-        startWorkBtn.disabled = true;
         stopWorkBtn.style.display = 'none';
-        quitJobBtn.disabled = true;
     }
+    setActionButton('btn-start-work', 'start_work');
+    setActionButton('btn-stop-work', 'stop_work');
+    setActionButton('btn-day-off', 'take_day_off');
+    setActionButton('btn-quit-job', 'quit_job');
     
     // Update investments
     updateInvestments();
@@ -1415,7 +1614,7 @@ function updateJobOffers() {
                 <h4>${offer.title}</h4>
                 <p>${offer.description}</p>
                 <div class="job-offer-details">
-                    <p><strong>Monthly Salary:</strong> €${salary.toFixed(2)}</p>
+                    <p><strong>Monthly Salary:</strong> €${salary.toFixed(2)}${offer.pay_frequency && offer.pay_frequency !== 'monthly' ? ` (paid ${offer.pay_frequency})` : ''}</p>
                     <p><strong>Hours per Day:</strong> ${hoursPerDay}</p>
                     <p><strong>Work Type:</strong> ${workTypeDisplay}</p>
                     ${salaryPerHour > 0 ? `<p><strong>Salary per Hour:</strong> €${salaryPerHour}</p>` : '<p><strong>Commission Only</strong></p>'}
@@ -1431,6 +1630,7 @@ function updateJobOffers() {
                     ${isNightTime 
                         ? '<button class="btn btn-primary" disabled title="Cannot accept jobs during night hours (00:00 - 07:00)">Accept Job (Night Time)</button>' 
                         : `<button class="btn btn-primary" onclick="acceptJobOffer('${offer.id}')">Accept Job${(offer.upfront_cost || 0) > 0 ? ` (€${offer.upfront_cost.toFixed(2)})` : ''}</button>`}
+                    <button class="btn btn-warning" onclick="reportOffer('job', '${offer.id}')">🚩 Report Scam</button>
                 </div>
             </div>
         `;
@@ -1446,7 +1646,7 @@ async function acceptJobOffer(offerId) {
 // Show hint for job offer (costs 10 EUR)
 async function showHint(offerId) {
     try {
-        const response = await fetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
+        const response = await apiFetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ 
@@ -1479,6 +1679,14 @@ async function showHint(offerId) {
 }
 
 // Update investments
+// newsTipHtml shows the paid tip about a stock's next company news, or the button to buy one
+function newsTipHtml(stock) {
+    if (stock.news_hint) {
+        return `<div class="news-hint">📰 ${stock.news_hint}</div>`;
+    }
+    return `<button class="btn btn-sm btn-info" onclick="performAction('show_news_hint', { symbol: '${stock.symbol}' })">News tip</button>`;
+}
+
 function updateInvestments() {
     const div = document.getElementById('investments');
     if (gameState.stocks.length === 0 && gameState.crypto.length === 0) {
@@ -1491,13 +1699,27 @@ function updateInvestments() {
         const buyPrice = stock.buy_price || 0;
         const currentPrice = stock.current_price || buyPrice;
         const shares = stock.shares || 0;
-        const profit = (currentPrice - buyPrice) * shares;
+        // Short positions gain when the price falls
+        const profit = (stock.short ? buyPrice - currentPrice : currentPrice - buyPrice) * shares;
         const profitClass = profit >= 0 ? 'positive' : 'negative';
+        if (stock.short) {
+            html += `
+            <div class="investment-item">
+                <strong>${stock.symbol}</strong>: ${shares} shares <span class="badge-short">SHORT</span>
+                <br>Shorted: €${buyPrice.toFixed(2)} | Current: €${currentPrice.toFixed(2)} | Margin: €${(stock.margin || 0).toFixed(2)}
+                <span class="${profitClass}">(${profit >= 0 ? '+' : ''}€${profit.toFixed(2)})</span>
+                <br><button class="btn btn-sm" onclick="performAction('cover_short', { symbol: '${stock.symbol}', shares: ${shares} })">Cover</button>
+                ${newsTipHtml(stock)}
+            </div>
+        `;
+            return;
+        }
         html += `
             <div class="investment-item">
                 <strong>${stock.symbol}</strong>: ${shares} shares
                 <br>Bought: €${buyPrice.toFixed(2)} | Current: €${currentPrice.toFixed(2)}
                 <span class="${profitClass}">(${profit >= 0 ? '+' : ''}€${profit.toFixed(2)})</span>
+                ${newsTipHtml(stock)}
             </div>
         `;
     });
@@ -1551,8 +1773,9 @@ function updateInventory() {
         html += `
             <div class="inventory-item">
                 <strong>${item.name}</strong>
-                <br>Bought: €${buyPrice.toFixed(2)} | Market: €${marketPrice.toFixed(2)}
+                <br>Bought: €${buyPrice.toFixed(2)} | Market: €${marketPrice.toFixed(2)}${item.depreciation ? ` (−€${item.depreciation.toFixed(2)} since bought)` : ''}
                 ${statEffects.length > 0 ? `<br><strong>Effects:</strong> ${statEffects.join(', ')} ${item.effect_frequency ? `(${item.effect_frequency})` : ''}` : ''}
+                ${statEffects.length > 0 && item.effect_frequency === 'on_use' ? `<br><button class="btn btn-primary btn-sm" onclick="useItem('${item.id}')">Use</button>` : ''}
             </div>
         `;
     });
@@ -1597,6 +1820,26 @@ async function acceptOffer(offerId) {
     await performAction('accept_offer', { offer_id: offerId });
 }
 
+// Withdraw an offer the player created, removing it for the whole network
+async function useItem(itemId) {
+    await performAction('use_item', { item_id: itemId });
+}
+
+async function withdrawOffer(offerId) {
+    if (!confirm('Withdraw this offer? Nobody in your network will be able to accept it any more.')) {
+        return;
+    }
+    await performAction('withdraw_offer', { offer_id: offerId });
+}
+
+// Report an offer as a scam: a correct report earns a reward, a false one costs reputation
+async function reportOffer(offerType, offerId) {
+    if (!confirm('Report this offer as a scam? A false report costs reputation.')) {
+        return;
+    }
+    await performAction('report_offer', { offer_type: offerType, offer_id: offerId });
+}
+
 // Send message to an offer
 async function sendOfferMessage(offerId) {
     const input = document.getElementById(`message-input-${offerId}`);
@@ -1722,6 +1965,12 @@ function updateOfferMessageModalMessages(offer) {
         html += `<span class="message-text">${escapeHtml(msg)}</span>`;
         html += '</div>';
     });
+    // Price changes agreed over the conversation
+    (offer.negotiation || []).forEach(round => {
+        if (round.new_price !== round.old_price) {
+            html += `<div class="message-item message-negotiation">Round ${round.round}: price €${(round.old_price || 0).toFixed(2)} → €${(round.new_price || 0).toFixed(2)}</div>`;
+        }
+    });
     messageListEl.innerHTML = html;
     
     // Scroll to bottom
@@ -1818,7 +2067,7 @@ function updateApartmentOffers() {
                 <h4>${offer.title}</h4>
                 <p>${offer.description}</p>
                 <div class="apartment-offer-details">
-                    <p><strong>Monthly Rent:</strong> €${rent.toFixed(2)}</p>
+                    <p><strong>Monthly Rent:</strong> €${rent.toFixed(2)}${offer.rent_frequency && offer.rent_frequency !== 'monthly' ? ` (paid ${offer.rent_frequency})` : ''}</p>
                     <p><strong>Health Gain:</strong> +${healthGain}/hour</p>
                     <p><strong>Energy Gain:</strong> +${energyGain}/hour</p>
                     <p><strong>Expires:</strong> ${expiresDate.toLocaleDateString()}</p>
@@ -1830,6 +2079,7 @@ function updateApartmentOffers() {
                 <div style="margin-top: 10px;">
                     <button class="btn btn-info" onclick="showApartmentHint('${offer.id}')" id="apartment-hint-btn-${offer.id}" ${offer.hint_shown ? 'disabled' : ''}>${offer.hint_shown ? 'Hint (Used)' : 'Hint (€10)'}</button>
                     <button class="btn btn-primary" onclick="acceptApartmentOffer('${offer.id}')">Rent Apartment</button>
+                    <button class="btn btn-warning" onclick="reportOffer('apartment', '${offer.id}')">🚩 Report Scam</button>
                 </div>
             </div>
         `;
@@ -1845,7 +2095,7 @@ async function acceptApartmentOffer(offerId) {
 // Show hint for apartment offer (costs 10 EUR)
 async function showApartmentHint(offerId) {
     try {
-        const response = await fetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
+        const response = await apiFetch(`${API_BASE}/action?player_id=${PLAYER_ID}`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify({ 
@@ -1899,6 +2149,7 @@ function updateAllOffers() {
                     <p>${offer.description}</p>
                     <p><strong>Salary:</strong> €${salary.toFixed(2)}/month | <strong>Hours:</strong> ${hoursPerDay}/day</p>
                     <button class="btn btn-primary btn-sm" onclick="acceptJobOffer('${offer.id}')">Accept</button>
+                    <button class="btn btn-warning btn-sm" onclick="reportOffer('job', '${offer.id}')">🚩 Report</button>
                 </div>
             `;
         });
@@ -1924,6 +2175,7 @@ function updateAllOffers() {
                     <p>${offer.description}</p>
                     <p><strong>Rent:</strong> €${rent.toFixed(2)}/month | <strong>Health:</strong> +${offer.health_gain || 0}/h | <strong>Energy:</strong> +${offer.energy_gain || 0}/h</p>
                     <button class="btn btn-primary btn-sm" onclick="acceptApartmentOffer('${offer.id}')">Rent</button>
+                    <button class="btn btn-warning btn-sm" onclick="reportOffer('apartment', '${offer.id}')">🚩 Report</button>
                 </div>
             `;
         });
@@ -1975,6 +2227,7 @@ function updateAllOffers() {
                     <div id="hint-${offer.id}" class="offer-hint-section">
                         ${offer.reason ? `<p class="offer-reason"><em>${offer.reason}</em></p>` : ''}
                         <p class="offer-type-badge ${offerClass}">${offer.is_trickery ? '⚠️ TRICKERY/SCAM' : '✅ LEGITIMATE OFFER'}</p>
+                        ${offer.category ? `<p class="offer-category">Category: ${offer.category.replace('_', ' ')}</p>` : ''}
                     </div>
                 `;
             }
@@ -1987,8 +2240,10 @@ function updateAllOffers() {
                     ${statEffects.length > 0 ? `<p><strong>Effects:</strong> ${statEffects.join(', ')}</p>` : ''}
                     ${hintHtml}
                     <div class="offer-actions">
+                        ${offer.created_by === PLAYER_ID ? `<button class="btn btn-danger btn-sm" onclick="withdrawOffer('${offer.id}')">Withdraw</button>` : `
                         <button class="btn btn-primary btn-sm" onclick="acceptOffer('${offer.id}')">Accept</button>
-                        ${offer.hint_shown === undefined || offer.hint_shown === false ? `<button class="btn btn-info btn-sm" onclick="showOtherOfferHint('${offer.id}')">Hint (€10)</button>` : ''}
+                        <button class="btn btn-warning btn-sm" onclick="reportOffer('other', '${offer.id}')">🚩 Report</button>
+                        ${offer.hint_shown === undefined || offer.hint_shown === false ? `<button class="btn btn-info btn-sm" onclick="showOtherOfferHint('${offer.id}')">Hint (€10)</button>` : ''}`}
                         <button class="btn btn-secondary btn-sm" onclick="openOfferMessageModal('${offer.id}')">💬 Message${hasMessages ? ` (${offer.messages.length})` : ''}</button>
                     </div>
                     ${hasMessages ? `<div style="margin-top: 10px;"><button class="btn btn-link btn-sm" onclick="openOfferMessageModal('${offer.id}')" style="padding: 0; text-decoration: underline; color: #667eea; background: none; border: none; cursor: pointer;">View ${offer.messages.length} message${offer.messages.length !== 1 ? 's' : ''}</button></div>` : ''}
@@ -1999,6 +2254,38 @@ function updateAllOffers() {
     }
 }
 
+// Enable a button only when the state's available_actions allow its action, explaining why not on hover
+function setActionButton(id, action) {
+    const button = document.getElementById(id);
+    const status = (gameState.available_actions || []).find(a => a.action === action);
+    if (button.dataset.hint === undefined) button.dataset.hint = button.title; // The button's own tooltip
+    button.disabled = !!status && !status.available;
+    button.title = status && !status.available ? status.reason : button.dataset.hint;
+}
+
+// Update the apartments the player rents besides the one they live in
+function updateProperties() {
+    const properties = gameState.properties || [];
+    document.getElementById('properties').style.display = properties.length > 0 ? 'block' : 'none';
+    
+    let html = '';
+    properties.forEach(property => {
+        const status = property.rented_out
+            ? `rented out for €${property.sublet_rent.toFixed(2)}/month`
+            : 'empty';
+        html += `
+            <div class="property-item">
+                <span>${property.title} (rent €${property.rent.toFixed(2)}/month, ${status})</span>
+                ${property.rented_out
+                    ? `<button class="btn btn-sm btn-warning" onclick="performAction('stop_renting_out', { apartment_id: '${property.id}' })">Stop Renting Out</button>`
+                    : `<button class="btn btn-sm btn-success" onclick="performAction('rent_out', { apartment_id: '${property.id}' })">Rent Out</button>`}
+                <button class="btn btn-sm btn-danger" onclick="performAction('quit_apartment', { apartment_id: '${property.id}' })">Give Up</button>
+            </div>
+        `;
+    });
+    document.getElementById('properties-list').innerHTML = html;
+}
+
 // Update agreements list
 function updateAgreements() {
     const div = document.getElementById('agreements-list');
@@ -2066,6 +2353,7 @@ function updateAgreements() {
                     <p><strong>Days Active:</strong> ${daysActive}</p>
                     <p><strong>Effects per ${agreement.recurrence_type}:</strong> ${statEffects.length > 0 ? statEffects.join(', ') : 'None'}</p>
                     <p class="penalty-text">${penaltyText}</p>
+                    ${agreement.suspended ? `<p class="penalty-text"><strong>Suspended</strong> after ${agreement.missed_payments} missed payments</p>` : agreement.missed_payments ? `<p class="penalty-text">Missed payments: ${agreement.missed_payments}</p>` : ''}
                     ${agreement.reason ? `<p class="agreement-reason"><em>${agreement.reason}</em></p>` : ''}
                 </div>
                 <button class="btn btn-danger btn-sm" onclick="quitAgreement('${agreement.id}')">Cancel Agreement</button>
@@ -2092,6 +2380,11 @@ function updateDashboard() {
     let investmentsValue = 0;
     if (gameState.stocks) {
         gameState.stocks.forEach(stock => {
+            if (stock.short) {
+                // A short is worth its margin plus the gain since it was opened
+                investmentsValue += (stock.margin || 0) + ((stock.buy_price || 0) - (stock.current_price || stock.buy_price || 0)) * (stock.shares || 0);
+                return;
+            }
             investmentsValue += (stock.current_price || stock.buy_price || 0) * (stock.shares || 0);
         });
     }
@@ -2103,6 +2396,14 @@ function updateDashboard() {
     document.getElementById('dashboard-investments').textContent = `€${investmentsValue.toFixed(2)}`;
     document.getElementById('dashboard-total').textContent = `€${(money + investmentsValue).toFixed(2)}`;
     
+    // Market index and its last daily move
+    const marketIndex = document.getElementById('dashboard-market-index');
+    if (marketIndex && gameState.market_index) {
+        const change = gameState.market_index_change || 0;
+        marketIndex.textContent = `${gameState.market_index.toFixed(1)} (${change >= 0 ? '+' : ''}${change.toFixed(1)}%)`;
+        marketIndex.className = change >= 0 ? 'positive' : 'negative';
+    }
+    
     // Update health and energy
     const health = gameState.health !== undefined ? gameState.health : 100;
     const energy = gameState.energy !== undefined ? gameState.energy : 100;
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Hourly work tuning
+const (
+	workDaysPerMonth    = 20   // Turns a monthly salary into an hourly rate, with HoursPerDay hours a day
+	overtimeMultiplier  = 1.5  // Pay for hours past HoursPerDay in one in-game day
+	lowEnergyWork       = 30   // Below this energy, output falls in proportion
+	minWorkProductivity = 0.25 // Output with no energy left
+)
+
+// isHourly reports whether the job is paid for the hours logged with StartWork rather than a
+// flat salary
+func (job *Job) isHourly() bool {
+	return job.WorkType != "fixed_time"
+}
+
+// hourlyRate returns the pay for one regular hour: the monthly salary spread over
+// workDaysPerMonth days of HoursPerDay hours
+func (job *Job) hourlyRate() float64 {
+	hoursPerDay := job.HoursPerDay
+	if hoursPerDay <= 0 {
+		hoursPerDay = 8
+	}
+	return job.Salary / float64(hoursPerDay*workDaysPerMonth)
+}
+
+// hoursWorkedOn returns the hours the player has already worked on the in-game day of date
+func (gs *GameState) hoursWorkedOn(date time.Time) float64 {
+	if !gs.WorkedDay.Equal(time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())) {
+		return 0
+	}
+	return gs.HoursWorkedToday
+}
+
+// workProductivity returns the share of a full hour's output the player manages at their current
+// energy
+func (gs *GameState) workProductivity() float64 {
+	return min(max(float64(gs.Energy)/lowEnergyWork, minWorkProductivity), 1)
+}
+
+// scheduleOffsets returns when a fixed-time job's daily shift starts after midnight and how long
+// it lasts; ok is false when WorkStart or WorkEnd is missing or not "HH:MM". A shift that ends
+// before it starts runs past midnight.
+func (job *Job) scheduleOffsets() (start time.Duration, length time.Duration, ok bool) {
+	startTime, errStart := time.Parse("15:04", job.WorkStart)
+	endTime, errEnd := time.Parse("15:04", job.WorkEnd)
+	if errStart != nil || errEnd != nil {
+		return 0, 0, false
+	}
+	start = time.Duration(startTime.Hour())*time.Hour + time.Duration(startTime.Minute())*time.Minute
+	end := time.Duration(endTime.Hour())*time.Hour + time.Duration(endTime.Minute())*time.Minute
+	length = end - start
+	if length < 0 {
+		length += 24 * time.Hour
+	}
+	return start, length, length > 0
+}
+
+// onSchedule reports whether t falls within one of a fixed-time job's shifts
+func (job *Job) onSchedule(t time.Time) bool {
+	return job.scheduledHours(t, t.Add(time.Minute)) > 0
+}
+
+// scheduledHours returns the hours of a fixed-time job's shifts that fall between from and to,
+// however many shifts that spans
+func (job *Job) scheduledHours(from, to time.Time) float64 {
+	start, length, ok := job.scheduleOffsets()
+	if !ok || !from.Before(to) {
+		return 0
+	}
+	var worked time.Duration
+	// Begin the day before from, whose shift may still be running past midnight
+	for day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()).AddDate(0, 0, -1); day.Before(to); day = day.AddDate(0, 0, 1) {
+		shiftStart, shiftEnd := day.Add(start), day.Add(start+length)
+		if shiftStart.Before(from) {
+			shiftStart = from
+		}
+		if shiftEnd.After(to) {
+			shiftEnd = to
+		}
+		if shiftStart.Before(shiftEnd) {
+			worked += shiftEnd.Sub(shiftStart)
+		}
+	}
+	return worked.Hours()
+}
+
+// currentShift returns the fixed-time job's shift running at t or, if none is, the next one
+func (job *Job) currentShift(t time.Time) (time.Time, time.Time, bool) {
+	start, length, ok := job.scheduleOffsets()
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	// The day before t's shift may still be running past midnight
+	for day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, -1); ; day = day.AddDate(0, 0, 1) {
+		if shiftEnd := day.Add(start + length); shiftEnd.After(t) {
+			return day.Add(start), shiftEnd, true
+		}
+	}
+}
+
+// onDayOff reports whether t falls within the shift the player took off
+func (gs *GameState) onDayOff(t time.Time) bool {
+	return !t.Before(gs.DayOffStart) && t.Before(gs.DayOffEnd)
+}
+
+// shiftHours returns the hours of the fixed-time job's shifts between from and to, without the
+// shift the player took off
+func (gs *GameState) shiftHours(from, to time.Time) float64 {
+	hours := gs.Job.scheduledHours(from, to)
+	offFrom, offTo := from, to
+	if gs.DayOffStart.After(offFrom) {
+		offFrom = gs.DayOffStart
+	}
+	if gs.DayOffEnd.Before(offTo) {
+		offTo = gs.DayOffEnd
+	}
+	if offFrom.Before(offTo) {
+		hours -= gs.Job.scheduledHours(offFrom, offTo)
+	}
+	return hours
+}
+
+// daysOffIn returns how many shifts the player took off in the in-game month of t
+func (gs *GameState) daysOffIn(t time.Time) int {
+	count := 0
+	for _, day := range gs.DaysOff {
+		if day.Year() == t.Year() && day.Month() == t.Month() {
+			count++
+		}
+	}
+	return count
+}
+
+// TakeDayOff skips the fixed-time job's current shift, or its next one between shifts, while
+// keeping the job: the player goes home if at work and rests instead. It is limited to
+// work.days_off_per_month days per in-game month and costs reputation.day_off reputation and
+// work.day_off_pay_share of a working day's salary.
+func (gs *GameState) TakeDayOff() error {
+	if err := gs.actionGuard("take_day_off"); err != nil {
+		return err
+	}
+	shiftStart, shiftEnd, _ := gs.Job.currentShift(gs.CurrentDate)
+	settings := GetConfig().Work
+	
+	gs.DayOffStart, gs.DayOffEnd = shiftStart, shiftEnd
+	// Only this month's and last month's days off are needed
+	lastMonth := time.Date(shiftStart.Year(), shiftStart.Month(), 1, 0, 0, 0, 0, shiftStart.Location()).AddDate(0, -1, 0)
+	gs.DaysOff = slices.DeleteFunc(gs.DaysOff, func(day time.Time) bool { return day.Before(lastMonth) })
+	gs.DaysOff = append(gs.DaysOff, shiftStart)
+	
+	if gs.IsWorking {
+		gs.IsWorking = false
+		gs.WorkStartTime = time.Time{}
+		gs.WorkEndTime = time.Time{}
+	}
+	docked := gs.Job.Salary / workDaysPerMonth * settings.DayOffPayShare
+	gs.Money -= docked
+	gs.addEvent("day_off", fmt.Sprintf("Took the %s shift off from %s (%d of %d days off this month)", shiftStart.Format("2006-01-02"), gs.Job.Title, gs.daysOffIn(shiftStart), settings.DaysOffPerMonth), -docked)
+	gs.adjustReputation(GetConfig().Reputation.DayOff, "took a day off")
+	return nil
+}
+
+// logWork records the hours worked between since and the current date and returns them. An
+// hourly job counts its work session: hours past the job's HoursPerDay on one in-game day count
+// as overtime, and every hour is weighted by the productivity at the player's energy when the
+// time started. A fixed-time job counts every hour of its shifts in that time, so a long advance
+// doesn't skip a shift it passes over.
+func (gs *GameState) logWork(since time.Time) float64 {
+	if gs.Job == nil || gs.IsInHospital {
+		return 0
+	}
+	if !gs.Job.isHourly() {
+		hours := gs.shiftHours(since, gs.CurrentDate)
+		gs.HoursWorkedThisPeriod += hours
+		return hours
+	}
+	if !gs.IsWorking {
+		return 0
+	}
+	start, end := since, gs.CurrentDate
+	if gs.WorkStartTime.After(start) {
+		start = gs.WorkStartTime
+	}
+	if !gs.WorkEndTime.IsZero() && gs.WorkEndTime.Before(end) {
+		end = gs.WorkEndTime
+	}
+	productivity := gs.workProductivity()
+	worked := 0.0
+	for start.Before(end) {
+		day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+		gs.HoursWorkedToday = gs.hoursWorkedOn(start)
+		gs.WorkedDay = day
+		dayEnd := end
+		if nextDay := day.AddDate(0, 0, 1); nextDay.Before(dayEnd) {
+			dayEnd = nextDay
+		}
+		hours := dayEnd.Sub(start).Hours()
+		regular := max(min(hours, float64(gs.Job.HoursPerDay)-gs.HoursWorkedToday), 0)
+		gs.HoursWorkedToday += hours
+		gs.HoursWorkedThisPeriod += hours
+		gs.PaidHoursThisPeriod += (regular + (hours-regular)*overtimeMultiplier) * productivity
+		worked += hours
+		start = dayEnd
+	}
+	return worked
+}
+
+// payWorkedHours pays an hourly job for the hours logged since its last payday and starts a new
+// period. label names the payment in the event, e.g. "weekly pay".
+func (gs *GameState) payWorkedHours(label string) {
+	hours, paidHours := gs.HoursWorkedThisPeriod, gs.PaidHoursThisPeriod
+	gs.HoursWorkedThisPeriod, gs.PaidHoursThisPeriod = 0, 0
+	if hours == 0 {
+		gs.addEvent("salary", "No "+label+" from "+gs.Job.Title+": you did not work any hours", 0)
+		return
+	}
+	amount := gs.Job.hourlyRate() * paidHours
+	gs.Money += amount
+	gs.addEvent("salary", fmt.Sprintf("Received %s: €%s for %.1f hours at %s", label, formatMoney(amount), hours, gs.Job.Title), amount)
+}
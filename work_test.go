@@ -0,0 +1,67 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// One advance across a whole 09:00-17:00 shift works its 8 hours, with their health and energy
+// cost, the same as advancing hour by hour through it
+func TestFixedShiftWorkedInOneAdvance(t *testing.T) {
+	run := func(steps int, step time.Duration) *GameState {
+		game := newTestState(t, time.Date(2025, 1, 6, 8, 0, 0, 0, time.UTC))
+		game.Job = paidTestJob()
+		game.Job.HealthLossPerHour = 1
+		game.Job.EnergyLossPerHour = 2
+		game.Health, game.Energy = 100, 100
+		for i := 0; i < steps; i++ {
+			game.AdvanceTime(step)
+		}
+		return game
+	}
+	
+	oneStep := run(1, 10*time.Hour)
+	if oneStep.HoursWorkedThisPeriod != 8 {
+		t.Errorf("worked %.2f hours advancing 08:00-18:00 in one step, want 8", oneStep.HoursWorkedThisPeriod)
+	}
+	if oneStep.IsWorking {
+		t.Error("still at work at 18:00, after the shift ended")
+	}
+	ended := eventsOfType(oneStep, "work_end")
+	if len(ended) != 1 || !strings.Contains(ended[0].Message, "8.0 hours") {
+		t.Errorf("got work_end events %+v, want one for the 8 hours worked", ended)
+	}
+	
+	hourly := run(10, time.Hour)
+	if hourly.HoursWorkedThisPeriod != oneStep.HoursWorkedThisPeriod || hourly.Health != oneStep.Health || hourly.Energy != oneStep.Energy {
+		t.Errorf("hour by hour worked %.2f hours ending at %d health and %d energy, one step worked %.2f at %d and %d; want the same",
+			hourly.HoursWorkedThisPeriod, hourly.Health, hourly.Energy, oneStep.HoursWorkedThisPeriod, oneStep.Health, oneStep.Energy)
+	}
+	if want := 100 - 8*2; oneStep.Energy != want {
+		t.Errorf("ended with %d energy, want %d after 8 hours at 2 per hour", oneStep.Energy, want)
+	}
+}
+
+func TestScheduledHours(t *testing.T) {
+	day := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		start, end string
+		from, to   time.Duration // After midnight on day
+		want       float64
+	}{
+		{"whole shift", "09:00", "17:00", 8 * time.Hour, 18 * time.Hour, 8},
+		{"part of a shift", "09:00", "17:00", 12 * time.Hour, 20 * time.Hour, 5},
+		{"outside the shift", "09:00", "17:00", 18 * time.Hour, 32 * time.Hour, 0},
+		{"three days", "09:00", "17:00", 0, 72 * time.Hour, 24},
+		{"night shift past midnight", "22:00", "06:00", 0, 24 * time.Hour, 8},
+		{"missing schedule", "", "17:00", 0, 24 * time.Hour, 0},
+	}
+	for _, tt := range tests {
+		job := &Job{WorkType: "fixed_time", WorkStart: tt.start, WorkEnd: tt.end}
+		if got := job.scheduledHours(day.Add(tt.from), day.Add(tt.to)); got != tt.want {
+			t.Errorf("%s: got %.2f hours, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}